@@ -0,0 +1,169 @@
+package slog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader is implemented by sinks that report how loaded they are,
+// e.g. by the fill of their queue, for Adaptive.
+type Loader interface {
+	// Load returns the load from 0 for idle to 1 for overloaded.
+	Load() float64
+}
+
+// AdaptiveOptions represents the options for the sink returned by Adaptive.
+type AdaptiveOptions struct {
+	// Load returns the load of the sink from 0 for idle to 1 for
+	// overloaded. Defaults to Load of the sink if it implements Loader.
+	Load func() float64
+	// Latency is the average duration of LogEntry of the sink at which
+	// it is overloaded. The load is the larger of the two if both are
+	// set. Disabled if zero.
+	Latency time.Duration
+	// Interval is the interval of the entries that report the rates
+	// while entries are shed. Defaults to 10 seconds.
+	Interval time.Duration
+}
+
+// Adaptive returns a sink that logs entries to s and sheds Debug and
+// then Info entries progressively as s gets loaded so that it keeps up
+// with the important entries. Every entry is logged while the load is
+// below 0.25. Debug entries are shed from 0.25 until none are logged at
+// 0.5, and Info entries from 0.5 until none are logged at 0.75.
+//
+// While entries are shed, an entry at LevelInfo is logged to s every
+// opts.Interval with the load, the rates of Debug and Info entries
+// that are kept and the number of entries that were shed. The interval
+// is measured by the times of the entries.
+//
+// If opts is nil, the defaults are used.
+func Adaptive(s Sink, opts *AdaptiveOptions) Sink {
+	if opts == nil {
+		opts = &AdaptiveOptions{}
+	}
+	a := &adaptiveSink{
+		s:        s,
+		load:     opts.Load,
+		latency:  opts.Latency,
+		interval: opts.Interval,
+	}
+	if a.load == nil {
+		if l, ok := s.(Loader); ok {
+			a.load = l.Load
+		}
+	}
+	if a.interval <= 0 {
+		a.interval = 10 * time.Second
+	}
+	return a
+}
+
+type adaptiveSink struct {
+	s        Sink
+	load     func() float64
+	latency  time.Duration
+	interval time.Duration
+
+	mu sync.Mutex
+	// avg is the moving average of the duration of LogEntry of s.
+	avg time.Duration
+	// credit accumulates the rates of Debug and Info
+	// entries to keep a fraction of them.
+	credit [2]float64
+	shed   [2]uint64
+	// last is when the rates were last reported.
+	last time.Time
+}
+
+// shedRate returns the rate of the entries to keep that are shed
+// from load from until none are kept at from+0.25.
+func shedRate(load, from float64) float64 {
+	r := 1 - (load-from)/0.25
+	switch {
+	case r > 1:
+		return 1
+	case r < 0:
+		return 0
+	}
+	return r
+}
+
+func (a *adaptiveSink) currentLoad() float64 {
+	var load float64
+	if a.load != nil {
+		load = a.load()
+	}
+	if a.latency > 0 {
+		if l := float64(a.avg) / float64(a.latency); l > load {
+			load = l
+		}
+	}
+	return load
+}
+
+func (a *adaptiveSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	a.mu.Lock()
+	load := a.currentLoad()
+	rates := [2]float64{shedRate(load, 0.25), shedRate(load, 0.5)}
+
+	keep := true
+	if i := int(ent.Level - LevelDebug); ent.Level < LevelWarn && i >= 0 && i < len(rates) {
+		a.credit[i] += rates[i]
+		if a.credit[i] >= 1 {
+			a.credit[i]--
+		} else {
+			keep = false
+			a.shed[i]++
+		}
+	}
+
+	var report *SinkEntry
+	if a.shed != [2]uint64{} && ent.Time.Sub(a.last) >= a.interval {
+		r := SinkEntry{
+			Time:    ent.Time,
+			Level:   LevelInfo,
+			Message: "slog: shedding entries under load",
+			Fields: M(
+				F("load", load),
+				F("rates", M(
+					F("debug", rates[0]),
+					F("info", rates[1]),
+				)),
+				F("shed", M(
+					F("debug", a.shed[0]),
+					F("info", a.shed[1]),
+				)),
+			),
+		}
+		report = &r
+		a.shed = [2]uint64{}
+		a.last = ent.Time
+	}
+	a.mu.Unlock()
+
+	if report != nil {
+		a.s.LogEntry(ctx, *report)
+	}
+	if !keep {
+		return
+	}
+
+	start := time.Now()
+	a.s.LogEntry(ctx, ent)
+	if a.latency > 0 {
+		d := time.Since(start)
+		a.mu.Lock()
+		a.avg += (d - a.avg) / 8
+		a.mu.Unlock()
+	}
+}
+
+func (a *adaptiveSink) Sync() {
+	a.s.Sync()
+}
+
+func (a *adaptiveSink) Flush(ctx context.Context) error {
+	return Flush(ctx, a.s)
+}
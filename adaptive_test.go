@@ -0,0 +1,68 @@
+package slog_test
+
+import (
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestAdaptive(t *testing.T) {
+	t.Parallel()
+
+	var load float64
+	s := &fakeSink{}
+	a := slog.Adaptive(s, &slog.AdaptiveOptions{
+		Load:     func() float64 { return load },
+		Interval: time.Minute,
+	})
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// count logs 100 entries at each level at load l
+	// and returns the logged entries by level.
+	count := func(l float64) map[slog.Level]int {
+		load = l
+		s.entries = nil
+		for i := 0; i < 100; i++ {
+			for _, level := range []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn} {
+				a.LogEntry(bg, slog.SinkEntry{Time: start, Level: level, Message: "hi"})
+			}
+		}
+		n := make(map[slog.Level]int)
+		for _, ent := range s.entries {
+			if ent.Message == "hi" {
+				n[ent.Level]++
+			}
+		}
+		return n
+	}
+
+	assert.Equal(t, "idle", map[slog.Level]int{slog.LevelDebug: 100, slog.LevelInfo: 100, slog.LevelWarn: 100}, count(0.1))
+	assert.Len(t, "no report", 300, s.entries)
+	assert.Equal(t, "debug shed", map[slog.Level]int{slog.LevelDebug: 50, slog.LevelInfo: 100, slog.LevelWarn: 100}, count(0.375))
+	assert.Equal(t, "info shed", map[slog.Level]int{slog.LevelInfo: 60, slog.LevelWarn: 100}, count(0.6))
+	assert.Equal(t, "overloaded", map[slog.Level]int{slog.LevelWarn: 100}, count(1))
+
+	// The report is logged once per interval.
+	start = start.Add(time.Minute)
+	count(1)
+	var reports []slog.SinkEntry
+	for _, ent := range s.entries {
+		if ent.Message != "hi" {
+			reports = append(reports, ent)
+		}
+	}
+	assert.Len(t, "reports", 1, reports)
+	assert.Equal(t, "report", slog.M(
+		slog.F("load", 1.0),
+		slog.F("rates", slog.M(
+			slog.F("debug", 0.0),
+			slog.F("info", 0.0),
+		)),
+		slog.F("shed", slog.M(
+			slog.F("debug", uint64(250)),
+			slog.F("info", uint64(140)),
+		)),
+	), reports[0].Fields)
+}
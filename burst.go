@@ -0,0 +1,185 @@
+package slog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BurstOptions represents the options for the sink returned by Burst.
+type BurstOptions struct {
+	// First is the number of entries of a burst logged as they come.
+	// Defaults to 5.
+	First int
+	// Last is the number of the last suppressed entries of a burst
+	// logged when it ends. Defaults to 5.
+	Last int
+	// Gap is the duration without entries after which a burst ends.
+	// Defaults to 10 seconds.
+	Gap time.Duration
+	// Fingerprint returns the key of the entries of a burst.
+	// Defaults to the logger names, the location and the message.
+	Fingerprint func(ent SinkEntry) string
+}
+
+// Burst returns a sink that limits bursts of entries with the same
+// fingerprint logged to s while keeping their edges, which usually tell
+// how a problem started and ended. The first opts.First entries of a
+// burst are logged as they come and the rest are suppressed. When the
+// burst ends, a summary entry with the number of entries is logged,
+// followed by the last opts.Last suppressed entries.
+//
+// A burst ends with the next entry of its fingerprint that is more than
+// opts.Gap after the previous one, measured by the times of the entries,
+// or with Sync or Flush, which log the summaries of all bursts.
+//
+// If opts is nil, the defaults are used.
+func Burst(s Sink, opts *BurstOptions) Sink {
+	if opts == nil {
+		opts = &BurstOptions{}
+	}
+	b := &burstSink{
+		s:           s,
+		first:       opts.First,
+		last:        opts.Last,
+		gap:         opts.Gap,
+		fingerprint: opts.Fingerprint,
+		bursts:      make(map[string]*burst),
+	}
+	if b.first <= 0 {
+		b.first = 5
+	}
+	if b.last <= 0 {
+		b.last = 5
+	}
+	if b.gap <= 0 {
+		b.gap = 10 * time.Second
+	}
+	if b.fingerprint == nil {
+		b.fingerprint = defaultFingerprint
+	}
+	return b
+}
+
+type burstSink struct {
+	s           Sink
+	first, last int
+	gap         time.Duration
+	fingerprint func(SinkEntry) string
+
+	mu     sync.Mutex
+	bursts map[string]*burst
+}
+
+// burst is the state of the entries of a fingerprint.
+type burst struct {
+	count       int
+	start, end  time.Time
+	suppressed  int
+	lastEntries []SinkEntry
+	// next is the index of the oldest entry once lastEntries is full.
+	next int
+}
+
+// ended returns the entries to log at the end of the burst.
+func (b *burst) ended() []SinkEntry {
+	if b.suppressed == 0 {
+		return nil
+	}
+	lastEntries := make([]SinkEntry, 0, len(b.lastEntries))
+	lastEntries = append(lastEntries, b.lastEntries[b.next:]...)
+	lastEntries = append(lastEntries, b.lastEntries[:b.next]...)
+
+	ent := lastEntries[len(lastEntries)-1]
+	summary := SinkEntry{
+		Time:        b.end,
+		Level:       ent.Level,
+		Message:     "slog: suppressed entries of burst",
+		LoggerNames: ent.LoggerNames,
+		Func:        ent.Func,
+		File:        ent.File,
+		Line:        ent.Line,
+		Fields: M(
+			F("burst", M(
+				F("msg", ent.Message),
+				F("count", b.count),
+				F("suppressed", b.suppressed),
+				F("omitted", b.suppressed-len(lastEntries)),
+				F("start", b.start),
+				F("end", b.end),
+			)),
+		),
+	}
+	return append([]SinkEntry{summary}, lastEntries...)
+}
+
+func (b *burstSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	fp := b.fingerprint(ent)
+
+	b.mu.Lock()
+	var ended []SinkEntry
+	if len(b.bursts) >= maxFingerprints {
+		for fp2, br := range b.bursts {
+			if ent.Time.Sub(br.end) > b.gap {
+				ended = append(ended, br.ended()...)
+				delete(b.bursts, fp2)
+			}
+		}
+	}
+
+	br, ok := b.bursts[fp]
+	if ok && ent.Time.Sub(br.end) > b.gap {
+		ended = append(ended, br.ended()...)
+		ok = false
+	}
+	if !ok {
+		br = &burst{start: ent.Time}
+		b.bursts[fp] = br
+	}
+	br.count++
+	br.end = ent.Time
+
+	log := br.count <= b.first
+	if !log {
+		br.suppressed++
+		if len(br.lastEntries) < b.last {
+			br.lastEntries = append(br.lastEntries, ent)
+		} else {
+			br.lastEntries[br.next] = ent
+			br.next = (br.next + 1) % len(br.lastEntries)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, ent := range ended {
+		b.s.LogEntry(ctx, ent)
+	}
+	if log {
+		b.s.LogEntry(ctx, ent)
+	}
+}
+
+// endAll ends every burst and logs their summaries to s.
+func (b *burstSink) endAll(ctx context.Context) {
+	b.mu.Lock()
+	var ended []SinkEntry
+	for _, br := range b.bursts {
+		ended = append(ended, br.ended()...)
+	}
+	b.bursts = make(map[string]*burst)
+	b.mu.Unlock()
+
+	for _, ent := range ended {
+		b.s.LogEntry(ctx, ent)
+	}
+}
+
+func (b *burstSink) Sync() {
+	b.endAll(context.Background())
+	b.s.Sync()
+}
+
+func (b *burstSink) Flush(ctx context.Context) error {
+	b.endAll(ctx)
+	return Flush(ctx, b.s)
+}
@@ -0,0 +1,74 @@
+package slog_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestBurst(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	b := slog.Burst(s, &slog.BurstOptions{
+		First: 2,
+		Last:  2,
+		Gap:   time.Second,
+	})
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := func(msg string, i int, after time.Duration) {
+		b.LogEntry(bg, slog.SinkEntry{
+			Time:    start.Add(after),
+			Level:   slog.LevelWarn,
+			Message: msg,
+			Fields:  slog.M(slog.F("i", i)),
+		})
+	}
+	for i := 0; i < 6; i++ {
+		log("timeout", i, time.Duration(i)*100*time.Millisecond)
+	}
+	log("other", 0, 0)
+	assert.Len(t, "first entries", 3, s.entries)
+
+	// The burst ends after the gap.
+	log("timeout", 6, 3*time.Second)
+
+	var msgs []string
+	for _, ent := range s.entries {
+		if i, ok := fieldValue(ent.Fields, "i").(int); ok {
+			msgs = append(msgs, ent.Message+" "+strconv.Itoa(i))
+		} else {
+			msgs = append(msgs, "summary")
+		}
+	}
+	summary := s.entries[3]
+	assert.Equal(t, "summary msg", "slog: suppressed entries of burst", summary.Message)
+	assert.Equal(t, "summary level", slog.LevelWarn, summary.Level)
+	assert.Equal(t, "summary", slog.M(
+		slog.F("msg", "timeout"),
+		slog.F("count", 6),
+		slog.F("suppressed", 4),
+		slog.F("omitted", 2),
+		slog.F("start", start),
+		slog.F("end", start.Add(500*time.Millisecond)),
+	), fieldValue(summary.Fields, "burst"))
+
+	assert.Equal(t, "entries", []string{
+		"timeout 0", "timeout 1", "other 0",
+		"summary", "timeout 4", "timeout 5",
+		"timeout 6",
+	}, msgs)
+
+	// Sync ends the bursts.
+	for i := 7; i < 10; i++ {
+		log("timeout", i, 3*time.Second)
+	}
+	b.Sync()
+	assert.Len(t, "synced", 11, s.entries)
+	assert.Equal(t, "last", 9, fieldValue(s.entries[10].Fields, "i"))
+	assert.Equal(t, "syncs", 1, s.syncs)
+}
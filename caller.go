@@ -0,0 +1,146 @@
+package slog
+
+import (
+	"path"
+	"runtime/debug"
+	"strings"
+)
+
+// PathStyle is how sinks write the file of the location of entries.
+type PathStyle int
+
+// The supported path styles.
+const (
+	// PathDefault is the default style of the sink.
+	PathDefault PathStyle = iota
+	// PathFull is the absolute path of the file on the machine
+	// that built the binary.
+	// e.g. /home/user/src/slog/internal/entryhuman/entry.go
+	PathFull
+	// PathModule is the path of the file relative to the root of the
+	// main module or its import path if it is in another module.
+	// e.g. ./internal/entryhuman/entry.go or github.com/pkg/errors/errors.go
+	PathModule
+	// PathPackage is the name of the directory of the package
+	// and the name of the file.
+	// e.g. entryhuman/entry.go
+	PathPackage
+	// PathBase is the name of the file.
+	// e.g. entry.go
+	PathBase
+)
+
+// FuncStyle is how sinks write the function of the location of entries.
+type FuncStyle int
+
+// The supported function styles.
+const (
+	// FuncDefault is the default style of the sink.
+	FuncDefault FuncStyle = iota
+	// FuncFull is the import path of the package and the name
+	// of the function.
+	// e.g. cdr.dev/slog/sloggers/sloghttp.(*Handler).ServeHTTP
+	FuncFull
+	// FuncPackage is the name of the package and of the function.
+	// e.g. sloghttp.(*Handler).ServeHTTP
+	FuncPackage
+	// FuncShort is the name of the function.
+	// e.g. (*Handler).ServeHTTP
+	FuncShort
+	// FuncOmit omits the function.
+	FuncOmit
+)
+
+var (
+	mainPackagePath string
+	mainModulePath  string
+)
+
+func init() {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	mainPackagePath = bi.Path
+	mainModulePath = bi.Main.Path
+}
+
+// TrimPath returns file, the path of the file of the function fn as in
+// SinkEntry, in style. fn is used to find the package of the file and
+// is from runtime.Frame.Function. PathDefault is the same as PathFull.
+func TrimPath(file, fn string, style PathStyle) string {
+	base := path.Base(file)
+	switch style {
+	case PathBase:
+		return base
+	case PathModule, PathPackage:
+	default:
+		return file
+	}
+
+	pkg := packagePath(fn)
+	if pkg == "" {
+		// Without the package, the directory is the best guess.
+		if style == PathPackage && path.Dir(file) != "." {
+			return path.Join(path.Base(path.Dir(file)), base)
+		}
+		return path.Clean(file)
+	}
+	// External test packages are in the directory of their package.
+	pkg = strings.TrimSuffix(pkg, "_test")
+	if style == PathPackage {
+		return path.Join(path.Base(pkg), base)
+	}
+
+	hpath := path.Join(pkg, base)
+	if mainModulePath != "" && strings.HasPrefix(hpath, mainModulePath+"/") {
+		hpath = "./" + strings.TrimPrefix(hpath, mainModulePath+"/")
+	}
+	return hpath
+}
+
+// TrimFunc returns fn, the function as in SinkEntry, in style.
+// FuncDefault is the same as FuncFull.
+func TrimFunc(fn string, style FuncStyle) string {
+	switch style {
+	case FuncOmit:
+		return ""
+	case FuncPackage, FuncShort:
+	default:
+		return fn
+	}
+
+	// The function is after the first period after the last slash.
+	base := fn[strings.LastIndexByte(fn, '/')+1:]
+	if style == FuncPackage {
+		return base
+	}
+	i := strings.IndexByte(base, '.')
+	if i < 0 {
+		return base
+	}
+	return base[i+1:]
+}
+
+// packagePath returns the import path of the package of fn.
+func packagePath(fn string) string {
+	// The package path ends with the first period after the last slash
+	// as methods and closures have more periods.
+	//   e.g. cdr.dev/slog/internal/entryhuman.(*T).method.func1
+	i := strings.LastIndexByte(fn, '/')
+	j := strings.IndexByte(fn[i+1:], '.')
+	if j < 0 {
+		return ""
+	}
+	pkg := fn[:i+1+j]
+	if pkg == "main" {
+		// runtime.Frame.Function has main rather than the import path
+		// of the main package, which only the build info has.
+		// It is command-line-arguments with go run main.go.
+		if mainPackagePath == "" || mainPackagePath == "command-line-arguments" {
+			return ""
+		}
+		return mainPackagePath
+	}
+	return pkg
+}
@@ -0,0 +1,45 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestTrimPath(t *testing.T) {
+	t.Parallel()
+
+	const (
+		file = "/home/user/src/slog/sloggers/slogjson/slogjson.go"
+		fn   = "cdr.dev/slog/sloggers/slogjson.jsonEncoder.Encode"
+	)
+	assert.Equal(t, "full", file, slog.TrimPath(file, fn, slog.PathFull))
+	assert.Equal(t, "default", file, slog.TrimPath(file, fn, slog.PathDefault))
+	assert.Equal(t, "module", "./sloggers/slogjson/slogjson.go", slog.TrimPath(file, fn, slog.PathModule))
+	assert.Equal(t, "package", "slogjson/slogjson.go", slog.TrimPath(file, fn, slog.PathPackage))
+	assert.Equal(t, "base", "slogjson.go", slog.TrimPath(file, fn, slog.PathBase))
+
+	const (
+		otherFile = "/go/pkg/mod/github.com/pkg/errors@v0.9.1/errors.go"
+		otherFn   = "github.com/pkg/errors.(*fundamental).Format.func1"
+	)
+	assert.Equal(t, "other module", "github.com/pkg/errors/errors.go", slog.TrimPath(otherFile, otherFn, slog.PathModule))
+	assert.Equal(t, "other package", "errors/errors.go", slog.TrimPath(otherFile, otherFn, slog.PathPackage))
+
+	ent := slog.Make().Entry(bg, slog.LevelInfo, "hi")
+	assert.Equal(t, "test package", "./caller_test.go", slog.TrimPath(ent.File, ent.Func, slog.PathModule))
+	assert.Equal(t, "no function", "slog/x.go", slog.TrimPath("/src/slog/x.go", "", slog.PathPackage))
+}
+
+func TestTrimFunc(t *testing.T) {
+	t.Parallel()
+
+	const fn = "cdr.dev/slog/sloggers/sloghttp.(*Handler).ServeHTTP.func1"
+	assert.Equal(t, "full", fn, slog.TrimFunc(fn, slog.FuncFull))
+	assert.Equal(t, "default", fn, slog.TrimFunc(fn, slog.FuncDefault))
+	assert.Equal(t, "package", "sloghttp.(*Handler).ServeHTTP.func1", slog.TrimFunc(fn, slog.FuncPackage))
+	assert.Equal(t, "short", "(*Handler).ServeHTTP.func1", slog.TrimFunc(fn, slog.FuncShort))
+	assert.Equal(t, "omit", "", slog.TrimFunc(fn, slog.FuncOmit))
+	assert.Equal(t, "main", "run", slog.TrimFunc("main.run", slog.FuncShort))
+}
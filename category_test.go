@@ -0,0 +1,44 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestCategory(t *testing.T) {
+	t.Parallel()
+
+	audit := &fakeSink{}
+	rest := &fakeSink{}
+	l := slog.Make(slog.RouteCategories(map[slog.Category]slog.Sink{
+		slog.CategoryAudit: audit,
+	}, rest))
+
+	l.Audit(bg, "user deleted", slog.F("user", "alice"))
+	l.Security(bg, "login failed")
+	l.Category(slog.CategoryRequest).Info(bg, "request")
+	l.Info(bg, "hello")
+
+	assert.Len(t, "audit", 1, audit.entries)
+	assert.Equal(t, "audit level", slog.LevelInfo, audit.entries[0].Level)
+	assert.Equal(t, "audit fields", slog.M(
+		slog.CategoryAudit.Field(),
+		slog.F("user", "alice"),
+	), audit.entries[0].Fields)
+	assert.True(t, "audit location", audit.entries[0].Line > 0 && audit.entries[0].Func == "cdr.dev/slog_test.TestCategory")
+
+	assert.Len(t, "rest", 3, rest.entries)
+	assert.Equal(t, "security", slog.CategorySecurity, slog.CategoryOf(rest.entries[0]))
+	assert.Equal(t, "security level", slog.LevelWarn, rest.entries[0].Level)
+	assert.Equal(t, "request", slog.CategoryRequest, slog.CategoryOf(rest.entries[1]))
+	assert.Equal(t, "none", slog.Category(""), slog.CategoryOf(rest.entries[2]))
+
+	decoded := slog.SinkEntry{Fields: slog.M(slog.F("category", "metric"))}
+	assert.Equal(t, "string", slog.CategoryMetric, slog.CategoryOf(decoded))
+
+	assert.Success(t, "flush", l.Flush(bg))
+	assert.Equal(t, "syncs", 1, audit.syncs)
+	assert.Equal(t, "syncs", 1, rest.syncs)
+}
@@ -2,6 +2,8 @@ lint: govet golint
 
 govet:
 	go vet ./...
+	go vet -tags slogdiscard ./...
+	go vet -tags slogdiscardinfo ./...
 
 golint:
 	golint -set_exit_status ./...
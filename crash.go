@@ -0,0 +1,172 @@
+package slog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// CrashOptions represents the options for CaptureCrash.
+type CrashOptions struct {
+	// File is the path of the crash file that the crash entry is
+	// appended to as a line of JSON before it is logged to the sinks,
+	// so that it survives sinks that cannot be flushed anymore.
+	// Disabled if empty.
+	File string
+	// Signals are the signals that are handled as crashes.
+	// Defaults to os.Interrupt and syscall.SIGTERM.
+	Signals []os.Signal
+}
+
+// CaptureCrash captures panics of the calling goroutine and the fatal
+// signals in opts. A crash is logged to l at LevelFatal with the panic
+// value or signal and the stack of every goroutine and then l is synced
+// so that buffered and asynchronous sinks do not lose the most important
+// entries.
+//
+// The returned function must be deferred, usually in main:
+//
+//	defer slog.CaptureCrash(l, &slog.CrashOptions{File: "/var/log/app.crash"})()
+//
+// After a panic has been logged, the panic continues.
+// After a signal, the program exits with 128 plus the signal number.
+// When the returned function is called without a panic,
+// the signals are no longer handled.
+//
+// If opts is nil, the defaults are used.
+func CaptureCrash(l Logger, opts *CrashOptions) (done func()) {
+	o := CrashOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.Signals) == 0 {
+		o.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	signal.Notify(sigs, o.Signals...)
+	go func() {
+		select {
+		case <-stop:
+		case sig := <-sigs:
+			crash(l, &o, SinkEntry{
+				Message: "crash: received signal " + sig.String(),
+				Fields:  M(F("signal", sig.String())),
+			})
+			code := 1
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+			exit := l.exit
+			if exit == nil {
+				exit = defaultExitFn
+			}
+			exit(code)
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigs)
+		close(stop)
+
+		r := recover()
+		if r == nil {
+			return
+		}
+		ent := SinkEntry{
+			Message: "crash: panic",
+			Fields:  M(F("panic", r)),
+		}
+		if f, ok := panicFrame(); ok {
+			ent = ent.fillFromFrame(f)
+		}
+		crash(l, &o, ent)
+		panic(r)
+	}
+}
+
+// crash writes ent to the crash file and logs it to l.
+func crash(l Logger, opts *CrashOptions, ent SinkEntry) {
+	ent.Time = time.Now().UTC()
+	ent.Level = LevelFatal
+	ent.Fields = append(ent.Fields,
+		F("goroutines", runtime.NumGoroutine()),
+		F("stack", allStacks()),
+	)
+	ent.Fields = l.fields.append(ent.Fields)
+	ent.LoggerNames = l.names
+
+	if opts.File != "" {
+		writeCrashFile(opts.File, ent)
+	}
+	for _, s := range l.sinks {
+		s.LogEntry(context.Background(), ent)
+	}
+	l.Sync()
+}
+
+func writeCrashFile(path string, ent SinkEntry) {
+	// No error is guaranteed due to slog.Map handling errors itself.
+	b, _ := json.Marshal(M(
+		F("ts", ent.Time),
+		F("level", ent.Level),
+		F("msg", ent.Message),
+		F("logger_names", ent.LoggerNames),
+		F("func", ent.Func),
+		F("file", ent.File),
+		F("line", ent.Line),
+		F("fields", ent.Fields),
+	))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		println("slog: failed to open crash file:", err.Error())
+		return
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		println("slog: failed to write crash file:", err.Error())
+	}
+}
+
+// panicFrame returns the frame that panicked when called
+// by a deferred function during a panic.
+func panicFrame() (runtime.Frame, bool) {
+	var pc [64]uintptr
+	n := runtime.Callers(2, pc[:])
+	frames := runtime.CallersFrames(pc[:n])
+	panicking := false
+	for {
+		f, more := frames.Next()
+		if panicking && !strings.HasPrefix(f.Function, "runtime.") {
+			return f, true
+		}
+		if f.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// allStacks returns the stacks of every goroutine.
+func allStacks() string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,65 @@
+package slog_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestCaptureCrash(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slog")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.crash")
+
+	s := &fakeSink{}
+	l := slog.Make(s).Named("app").With(slog.F("version", "1.0"))
+
+	var r interface{}
+	func() {
+		defer func() {
+			r = recover()
+		}()
+		defer slog.CaptureCrash(l, &slog.CrashOptions{File: path})()
+		panic("boom")
+	}()
+
+	assert.Equal(t, "repanicked", "boom", r)
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "syncs", 1, s.syncs)
+
+	ent := s.entries[0]
+	assert.Equal(t, "level", slog.LevelFatal, ent.Level)
+	assert.Equal(t, "msg", "crash: panic", ent.Message)
+	assert.Equal(t, "names", []string{"app"}, ent.LoggerNames)
+	assert.True(t, "func", strings.HasPrefix(ent.Func, "cdr.dev/slog_test.TestCaptureCrash"))
+	assert.Len(t, "fields", 4, ent.Fields)
+	assert.Equal(t, "version", slog.F("version", "1.0"), ent.Fields[0])
+	assert.Equal(t, "panic", slog.F("panic", "boom"), ent.Fields[1])
+	stack := ent.Fields[3].Value.(string)
+	assert.True(t, "stack", strings.Contains(stack, "TestCaptureCrash"))
+
+	b, err := ioutil.ReadFile(path)
+	assert.Success(t, "read crash file", err)
+	var m slog.Map
+	err = m.UnmarshalJSON(b)
+	assert.Success(t, "unmarshal crash file", err)
+	assert.Equal(t, "msg", slog.F("msg", "crash: panic"), m[2])
+}
+
+func TestCaptureCrash_NoPanic(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	func() {
+		defer slog.CaptureCrash(slog.Make(s), nil)()
+	}()
+	assert.Len(t, "entries", 0, s.entries)
+}
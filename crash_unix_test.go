@@ -0,0 +1,44 @@
+//go:build !windows
+// +build !windows
+
+package slog_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestCaptureCrash_Signal(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(s)
+	codes := make(chan int, 1)
+	l.SetExit(func(code int) {
+		codes <- code
+	})
+	defer slog.CaptureCrash(l, &slog.CrashOptions{
+		Signals: []os.Signal{syscall.SIGUSR1},
+	})()
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.Success(t, "find process", err)
+	err = p.Signal(syscall.SIGUSR1)
+	assert.Success(t, "signal", err)
+
+	select {
+	case code := <-codes:
+		assert.Equal(t, "code", 128+int(syscall.SIGUSR1), code)
+	case <-time.After(10 * time.Second):
+		t.Fatal("signal not handled")
+	}
+	entries := s.entries
+	assert.Len(t, "entries", 1, entries)
+	assert.Equal(t, "msg", "crash: received signal user defined signal 1", entries[0].Message)
+	assert.Equal(t, "signal", slog.F("signal", "user defined signal 1"), entries[0].Fields[0])
+}
@@ -0,0 +1,39 @@
+package slog
+
+import (
+	"context"
+	"time"
+)
+
+// Ctx returns a ctx field of the state of ctx when the entry is logged
+// to debug timeouts and cancellations:
+//
+//   - err is the error of ctx if it is done.
+//   - cause is the cause of ctx if it differs from its error, see
+//     context.Cause. It requires Go 1.20.
+//   - deadline is the deadline of ctx if it has one.
+//   - remaining is the time left until the deadline, negative if it
+//     has passed.
+//
+// A context that is neither done nor has a deadline is logged as an
+// empty Map.
+func Ctx(ctx context.Context) Field {
+	return F("ctx", ctxFields(ctx, time.Now()))
+}
+
+func ctxFields(ctx context.Context, now time.Time) Map {
+	m := Map{}
+	if err := ctx.Err(); err != nil {
+		m = append(m, F("err", err))
+		if cause := contextCause(ctx); cause != nil && cause != err {
+			m = append(m, F("cause", cause))
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		m = append(m,
+			F("deadline", deadline),
+			F("remaining", deadline.Sub(now)),
+		)
+	}
+	return m
+}
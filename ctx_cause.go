@@ -0,0 +1,12 @@
+//go:build go1.20
+// +build go1.20
+
+package slog
+
+import (
+	"context"
+)
+
+func contextCause(ctx context.Context) error {
+	return context.Cause(ctx)
+}
@@ -0,0 +1,13 @@
+//go:build !go1.20
+// +build !go1.20
+
+package slog
+
+import (
+	"context"
+)
+
+// contextCause returns nil as context.Cause requires Go 1.20.
+func contextCause(ctx context.Context) error {
+	return nil
+}
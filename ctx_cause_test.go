@@ -0,0 +1,24 @@
+//go:build go1.20
+// +build go1.20
+
+package slog_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestCtx_Cause(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancelCause(bg)
+	cancel(io.EOF)
+	assert.Equal(t, "cause", slog.F("ctx", slog.M(
+		slog.F("err", context.Canceled),
+		slog.F("cause", io.EOF),
+	)), slog.Ctx(ctx))
+}
@@ -0,0 +1,31 @@
+package slog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestCtx(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "background", slog.F("ctx", slog.Map{}), slog.Ctx(bg))
+
+	ctx, cancel := context.WithCancel(bg)
+	cancel()
+	assert.Equal(t, "canceled", slog.F("ctx", slog.M(
+		slog.F("err", context.Canceled),
+	)), slog.Ctx(ctx))
+
+	deadline := time.Now().Add(-time.Second)
+	ctx, cancel = context.WithDeadline(bg, deadline)
+	defer cancel()
+	m := slog.Ctx(ctx).Value.(slog.Map)
+	assert.Len(t, "fields", 3, m)
+	assert.Equal(t, "err", context.DeadlineExceeded, fieldValue(m, "err"))
+	assert.Equal(t, "deadline", deadline, fieldValue(m, "deadline"))
+	assert.True(t, "remaining", fieldValue(m, "remaining").(time.Duration) <= -time.Second)
+}
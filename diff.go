@@ -0,0 +1,107 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// Change is a difference between two values. See Diff.
+type Change struct {
+	// Op is "add", "remove" or "replace".
+	Op string `json:"op"`
+	// Path is the path to the changed value with the names of fields
+	// and the indexes of elements joined with a period. It is empty if
+	// the values themselves differ.
+	Path string `json:"path"`
+	// Old is the removed or replaced value.
+	Old interface{} `json:"old,omitempty"`
+	// New is the added or replacing value.
+	New interface{} `json:"new,omitempty"`
+}
+
+// Changes are the differences between two values in the order of their
+// paths in the values.
+//
+// They are encoded in JSON as a list of the changes and by sloghuman as
+// a colorized diff.
+type Changes []Change
+
+// Diff returns a field of the changes from old to new, e.g. to log
+// a configuration reload or a state transition without logging both
+// values in full.
+//
+// The values are compared as they are encoded in JSON, see
+// Map.MarshalJSON. Objects are compared by their keys, arrays by their
+// indexes and other values as a whole. The value of the field is Changes.
+func Diff(name string, old, new interface{}) Field {
+	return F(name, appendChanges(Changes{}, "", diffValue(old), diffValue(new)))
+}
+
+// diffValue returns v decoded from its JSON encoding.
+func diffValue(v interface{}) interface{} {
+	b := encode(v)
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	dv, err := decodeValue(d)
+	if err != nil {
+		// encode never returns invalid JSON.
+		return string(b)
+	}
+	return dv
+}
+
+func appendChanges(changes Changes, path string, old, new interface{}) Changes {
+	switch old := old.(type) {
+	case Map:
+		new, ok := new.(Map)
+		if !ok {
+			break
+		}
+		for _, f := range old {
+			p := joinPath(path, f.Name)
+			v, ok := new.Get(f.Name)
+			if !ok {
+				changes = append(changes, Change{Op: "remove", Path: p, Old: f.Value})
+				continue
+			}
+			changes = appendChanges(changes, p, f.Value, v)
+		}
+		for _, f := range new {
+			if _, ok := old.Get(f.Name); !ok {
+				changes = append(changes, Change{Op: "add", Path: joinPath(path, f.Name), New: f.Value})
+			}
+		}
+		return changes
+	case []interface{}:
+		new, ok := new.([]interface{})
+		if !ok {
+			break
+		}
+		for i, v := range old {
+			p := joinPath(path, strconv.Itoa(i))
+			if i >= len(new) {
+				changes = append(changes, Change{Op: "remove", Path: p, Old: v})
+				continue
+			}
+			changes = appendChanges(changes, p, v, new[i])
+		}
+		for i := len(old); i < len(new); i++ {
+			changes = append(changes, Change{Op: "add", Path: joinPath(path, strconv.Itoa(i)), New: new[i]})
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(old, new) {
+		changes = append(changes, Change{Op: "replace", Path: path, Old: old, New: new})
+	}
+	return changes
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
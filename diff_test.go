@@ -0,0 +1,41 @@
+package slog_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		Name  string            `json:"name"`
+		Port  int               `json:"port"`
+		Peers []string          `json:"peers"`
+		Tags  map[string]string `json:"tags,omitempty"`
+	}
+	old := config{Name: "api", Port: 80, Peers: []string{"a", "b"}, Tags: map[string]string{"env": "dev"}}
+	new := config{Name: "api", Port: 8080, Peers: []string{"a"}}
+
+	f := slog.Diff("config", old, new)
+	assert.Equal(t, "name", "config", f.Name)
+	assert.Equal(t, "JSON", `{"config":[`+
+		`{"op":"replace","path":"port","old":80,"new":8080},`+
+		`{"op":"remove","path":"peers.1","old":"b"},`+
+		`{"op":"remove","path":"tags","old":{"env":"dev"}}`+
+		`]}`, compactJSON(t, slog.M(f)))
+
+	assert.Equal(t, "equal", `{"same":[]}`, compactJSON(t, slog.M(slog.Diff("same", old, old))))
+	assert.Equal(t, "scalar", slog.Changes{
+		{Op: "replace", Old: "a", New: slog.M(slog.F("b", true))},
+	}, slog.Diff("v", "a", slog.M(slog.F("b", true))).Value)
+}
+
+func compactJSON(t *testing.T, m slog.Map) string {
+	b, err := json.Marshal(m)
+	assert.Success(t, "marshal map to JSON", err)
+	return string(b)
+}
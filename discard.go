@@ -0,0 +1,69 @@
+package slog
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Discard returns a logger that discards every entry without encoding it
+// and a counter of the entries logged by level. It is meant for tests and
+// benchmarks of code that logs.
+//
+// The logger logs at LevelDebug. Fatal still exits.
+func Discard() (Logger, *Counter) {
+	c := &Counter{}
+	return Make(c).Leveled(LevelDebug), c
+}
+
+// Counter is a sink that counts entries by level and discards them.
+//
+// The zero value is ready to use.
+type Counter struct {
+	// counts holds the counts of the levels from LevelDebug to LevelFatal
+	// and then the count of all other levels. It is first in the struct
+	// for the alignment required by the atomic operations.
+	counts [LevelFatal + 2]uint64
+}
+
+var _ Sink = &Counter{}
+
+// LogEntry implements Sink.
+func (c *Counter) LogEntry(ctx context.Context, ent SinkEntry) {
+	atomic.AddUint64(&c.counts[c.index(ent.Level)], 1)
+}
+
+// Sync implements Sink.
+func (c *Counter) Sync() {}
+
+func (c *Counter) index(level Level) int {
+	if level < LevelDebug || level > LevelFatal {
+		return len(c.counts) - 1
+	}
+	return int(level)
+}
+
+// Count returns the number of entries logged at level.
+// Entries at levels other than the predefined ones
+// are only counted by Total.
+func (c *Counter) Count(level Level) uint64 {
+	if level < LevelDebug || level > LevelFatal {
+		return 0
+	}
+	return atomic.LoadUint64(&c.counts[level])
+}
+
+// Total returns the number of entries logged at any level.
+func (c *Counter) Total() uint64 {
+	var n uint64
+	for i := range c.counts {
+		n += atomic.LoadUint64(&c.counts[i])
+	}
+	return n
+}
+
+// Reset sets all counts to zero.
+func (c *Counter) Reset() {
+	for i := range c.counts {
+		atomic.StoreUint64(&c.counts[i], 0)
+	}
+}
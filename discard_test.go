@@ -0,0 +1,36 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestDiscard(t *testing.T) {
+	t.Parallel()
+
+	l, c := slog.Discard()
+	l.Debug(bg, "hi")
+	l.Info(bg, "hi")
+	l.Info(bg, "hi")
+	l.Error(bg, "hi")
+	l.Log(bg, slog.SinkEntry{Level: slog.Level(42)})
+
+	var exits []int
+	l.SetExit(func(code int) {
+		exits = append(exits, code)
+	})
+	l.Fatal(bg, "hi")
+
+	assert.Equal(t, "debug", uint64(1), c.Count(slog.LevelDebug))
+	assert.Equal(t, "info", uint64(2), c.Count(slog.LevelInfo))
+	assert.Equal(t, "error", uint64(1), c.Count(slog.LevelError))
+	assert.Equal(t, "fatal", uint64(1), c.Count(slog.LevelFatal))
+	assert.Equal(t, "custom", uint64(0), c.Count(slog.Level(42)))
+	assert.Equal(t, "total", uint64(6), c.Total())
+	assert.Equal(t, "exits", []int{1}, exits)
+
+	c.Reset()
+	assert.Equal(t, "reset", uint64(0), c.Total())
+}
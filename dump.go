@@ -0,0 +1,167 @@
+package slog
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// DumpGoroutines logs the stack of every goroutine to l at LevelInfo
+// as an entry per goroutine with the parsed frames, so that a dump can
+// be searched like any other entry instead of being grepped from stderr.
+//
+// The first entry has the number of goroutines. Every goroutine entry
+// has the fields goroutine (the ID), state (e.g. "chan receive"),
+// wait (e.g. "5 minutes", if any), frames and created_by (if any).
+// Frames have the fields func, file and line.
+func DumpGoroutines(ctx context.Context, l Logger) {
+	Helper()
+
+	gs := parseStacks(allStacks())
+	l.Info(ctx, "goroutine dump", F("goroutines", len(gs)))
+	for _, g := range gs {
+		fields := M(
+			F("goroutine", g.id),
+			F("state", g.state),
+		)
+		if g.wait != "" {
+			fields = append(fields, F("wait", g.wait))
+		}
+		fields = append(fields, F("frames", g.frames))
+		if g.createdBy != nil {
+			fields = append(fields, F("created_by", g.createdBy))
+		}
+		l.Info(ctx, "goroutine "+strconv.FormatUint(g.id, 10), fields...)
+	}
+	l.Sync()
+}
+
+// DumpOnSignal calls DumpGoroutines every time one of sigs is received
+// until stop is called. The program keeps running.
+//
+// sigs defaults to SIGQUIT. Note that handling SIGQUIT replaces the
+// dump the runtime writes to stderr before exiting. On Unix,
+// syscall.SIGUSR1 is a common alternative.
+func DumpOnSignal(l Logger, sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGQUIT}
+	}
+
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-c:
+				ctx := With(context.Background(), F("signal", sig.String()))
+				DumpGoroutines(ctx, l)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
+
+type goroutineStack struct {
+	id        uint64
+	state     string
+	wait      string
+	frames    []Map
+	createdBy Map
+}
+
+// parseStacks parses the output of runtime.Stack with all set.
+//
+//	goroutine 1 [chan receive, 5 minutes]:
+//	main.main()
+//		/src/main.go:10 +0x20
+//	created by main.start in goroutine 1
+//		/src/main.go:5 +0x1c
+func parseStacks(dump string) []goroutineStack {
+	var gs []goroutineStack
+	var g *goroutineStack
+	var fn string
+	var createdBy bool
+
+	for _, line := range strings.Split(dump, "\n") {
+		switch {
+		case strings.HasPrefix(line, "goroutine ") && strings.HasSuffix(line, "]:"):
+			gs = append(gs, parseGoroutineHeader(line))
+			g = &gs[len(gs)-1]
+			fn = ""
+		case g == nil || line == "":
+		case strings.HasPrefix(line, "\t"):
+			if fn == "" {
+				continue
+			}
+			frame := parseFrameLocation(fn, line[1:])
+			if createdBy {
+				g.createdBy = frame
+			} else {
+				g.frames = append(g.frames, frame)
+			}
+			fn = ""
+		case strings.HasPrefix(line, "created by "):
+			fn = strings.TrimPrefix(line, "created by ")
+			if i := strings.Index(fn, " in goroutine "); i >= 0 {
+				fn = fn[:i]
+			}
+			createdBy = true
+		default:
+			fn = line
+			if i := strings.LastIndexByte(fn, '('); i > 0 {
+				fn = fn[:i]
+			}
+			createdBy = false
+		}
+	}
+	return gs
+}
+
+func parseGoroutineHeader(line string) goroutineStack {
+	// goroutine 1 [chan receive, 5 minutes]:
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "goroutine "), "]:")
+	var g goroutineStack
+	i := strings.Index(line, " [")
+	if i < 0 {
+		return g
+	}
+	g.id, _ = strconv.ParseUint(line[:i], 10, 64)
+	parts := strings.Split(line[i+2:], ", ")
+	g.state = parts[0]
+	for _, p := range parts[1:] {
+		if strings.HasSuffix(p, "minutes") || strings.HasSuffix(p, "minute") {
+			g.wait = p
+		}
+	}
+	return g
+}
+
+func parseFrameLocation(fn, loc string) Map {
+	// /src/main.go:10 +0x20
+	if i := strings.LastIndex(loc, " +0x"); i >= 0 {
+		loc = loc[:i]
+	}
+	file := loc
+	var line int
+	if i := strings.LastIndexByte(loc, ':'); i >= 0 {
+		n, err := strconv.Atoi(loc[i+1:])
+		if err == nil {
+			file, line = loc[:i], n
+		}
+	}
+	return M(
+		F("func", fn),
+		F("file", file),
+		F("line", line),
+	)
+}
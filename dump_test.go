@@ -0,0 +1,59 @@
+package slog_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+// chanSink sends every entry on the channel.
+type chanSink chan slog.SinkEntry
+
+func (s chanSink) LogEntry(_ context.Context, ent slog.SinkEntry) {
+	s <- ent
+}
+
+func (s chanSink) Sync() {}
+
+func blockedGoroutine(started chan<- struct{}, c <-chan struct{}) {
+	close(started)
+	<-c
+}
+
+func TestDumpGoroutines(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+	c := make(chan struct{})
+	defer close(c)
+	go blockedGoroutine(started, c)
+	<-started
+
+	s := &fakeSink{}
+	slog.DumpGoroutines(bg, slog.Make(s))
+
+	assert.True(t, "entries", len(s.entries) >= 3)
+	assert.Equal(t, "summary", "goroutine dump", s.entries[0].Message)
+	assert.Equal(t, "count", len(s.entries)-1, s.entries[0].Fields[0].Value)
+	assert.True(t, "location", strings.HasSuffix(s.entries[0].File, "dump_test.go"))
+
+	var found bool
+	for _, ent := range s.entries[1:] {
+		frames := ent.Fields[2].Value.([]slog.Map)
+		if len(frames) == 0 || frames[0][0].Value != "cdr.dev/slog_test.blockedGoroutine" {
+			continue
+		}
+		found = true
+		assert.Equal(t, "msg", "goroutine", strings.Fields(ent.Message)[0])
+		assert.Equal(t, "state", slog.F("state", "chan receive"), ent.Fields[1])
+		assert.True(t, "file", strings.HasSuffix(frames[0][1].Value.(string), "dump_test.go"))
+		assert.True(t, "line", frames[0][2].Value.(int) > 0)
+		created := ent.Fields[len(ent.Fields)-1]
+		assert.Equal(t, "created_by", "created_by", created.Name)
+		assert.Equal(t, "created_by func", "cdr.dev/slog_test.TestDumpGoroutines", created.Value.(slog.Map)[0].Value)
+	}
+	assert.True(t, "found blocked goroutine", found)
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package slog_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestDumpOnSignal(t *testing.T) {
+	t.Parallel()
+
+	s := make(chanSink, 1024)
+	stop := slog.DumpOnSignal(slog.Make(s), syscall.SIGUSR2)
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.Success(t, "find process", err)
+	err = p.Signal(syscall.SIGUSR2)
+	assert.Success(t, "signal", err)
+
+	select {
+	case ent := <-s:
+		assert.Equal(t, "msg", "goroutine dump", ent.Message)
+		assert.Equal(t, "signal", slog.F("signal", "user defined signal 2"), ent.Fields[0])
+	case <-time.After(10 * time.Second):
+		t.Fatal("signal not handled")
+	}
+}
@@ -0,0 +1,52 @@
+package slog
+
+// Emphasis is a hint to the human readable format to emphasize an entry
+// or a field, e.g. to mark the boundaries of the steps of a test or
+// important state transitions during development. Other formats ignore
+// it.
+//
+// When the output is not colored, emphasized text is surrounded by
+// double asterisks instead.
+type Emphasis struct {
+	Bold      bool
+	Underline bool
+	// Color is the name of the color, one of black, red, green, yellow,
+	// blue, magenta, cyan or white. For entries, it overrides the color
+	// of the level.
+	Color string
+}
+
+// MarshalJSON implements json.Marshaler. Emphasis is encoded as true
+// so that the emph field of entries can be filtered on.
+func (e Emphasis) MarshalJSON() ([]byte, error) {
+	return []byte("true"), nil
+}
+
+// Emph returns an emph field that emphasizes the entry in bold and
+// underlined. Any field with an Emphasis value emphasizes the entry,
+// e.g. slog.F("emph", slog.Emphasis{Color: "green"}).
+//
+// The human readable format omits the field.
+func Emph() Field {
+	return F("emph", Emphasis{Bold: true, Underline: true})
+}
+
+// Emphasized is a field value that the human readable format
+// emphasizes. Other formats encode Value.
+type Emphasized struct {
+	Value    interface{}
+	Emphasis Emphasis
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e Emphasized) MarshalJSON() ([]byte, error) {
+	return encode(e.Value), nil
+}
+
+// Highlight returns f with its value emphasized in bold and underlined.
+func Highlight(f Field) Field {
+	return F(f.Name, Emphasized{
+		Value:    f.Value,
+		Emphasis: Emphasis{Bold: true, Underline: true},
+	})
+}
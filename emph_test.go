@@ -0,0 +1,20 @@
+package slog_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestEmph(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(slog.M(
+		slog.Emph(),
+		slog.Highlight(slog.F("state", slog.M(slog.F("from", "idle"), slog.F("to", "running")))),
+	))
+	assert.Success(t, "marshal", err)
+	assert.Equal(t, "json", `{"emph":true,"state":{"from":"idle","to":"running"}}`, string(b))
+}
@@ -0,0 +1,74 @@
+package slog
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"cdr.dev/slog/internal/syncwriter"
+)
+
+// Encoder encodes entries in a format such as JSON.
+//
+// Sinks that transport entries, such as to files or over the network,
+// accept an Encoder so that any format can be used with any destination.
+// The formats of the sloggers subdirectory are available as encoders.
+//
+// Encoders may reference the output of earlier entries, such as slogjson
+// with Options.DeltaSegment or Options.DedupMinSize, so sinks must write
+// entries to a single stream in the order they were encoded. That is,
+// a sink encodes and writes an entry as one step while holding a lock,
+// and an entry that is queued is encoded when it is written.
+type Encoder interface {
+	// Encode appends the encoding of ent to buf and returns the result.
+	// It must not append a trailing newline, that is up to the sink.
+	Encode(buf []byte, ent SinkEntry) []byte
+}
+
+// EncoderFunc implements Encoder with a function.
+type EncoderFunc func(buf []byte, ent SinkEntry) []byte
+
+// Encode implements Encoder.
+func (f EncoderFunc) Encode(buf []byte, ent SinkEntry) []byte {
+	return f(buf, ent)
+}
+
+// WriterSink creates a sink that writes every entry encoded
+// with enc followed by a newline to w.
+//
+// Entries are encoded and written one at a time
+// so enc does not need to be safe for concurrent use.
+//
+// If the writer implements Sync() error then
+// it will be called when syncing.
+func WriterSink(w io.Writer, enc Encoder) Sink {
+	return &writerSink{
+		w:   syncwriter.New(w),
+		enc: enc,
+	}
+}
+
+type writerSink struct {
+	mu  sync.Mutex
+	buf []byte
+	w   *syncwriter.Writer
+	enc Encoder
+}
+
+func (s *writerSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = s.enc.Encode(s.buf[:0], ent)
+	s.buf = append(s.buf, '\n')
+	s.w.Write("slog", s.buf)
+
+	if cap(s.buf) > 64<<10 {
+		// Do not hold on to the buffer of a large entry.
+		s.buf = nil
+	}
+}
+
+func (s *writerSink) Sync() {
+	s.w.Sync("slog")
+}
@@ -0,0 +1,27 @@
+package slog_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestWriterSink(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	enc := slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+		buf = append(buf, ent.Level.String()...)
+		buf = append(buf, ' ')
+		return append(buf, ent.Message...)
+	})
+	l := slog.Make(slog.WriterSink(b, enc))
+	l.Info(context.Background(), "hello")
+	l.Warn(context.Background(), "world")
+	l.Sync()
+
+	assert.Equal(t, "output", "INFO hello\nWARN world\n", b.String())
+}
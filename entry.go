@@ -0,0 +1,399 @@
+package slog
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
+)
+
+var (
+	_ json.Marshaler             = SinkEntry{}
+	_ json.Unmarshaler           = &SinkEntry{}
+	_ encoding.BinaryMarshaler   = SinkEntry{}
+	_ encoding.BinaryUnmarshaler = &SinkEntry{}
+)
+
+// MarshalJSON implements json.Marshaler.
+//
+// The format is the one written by sloggers/slogjson:
+//
+//	{
+//	  "ts": "2019-09-10T20:19:07.159852-05:00",
+//	  "level": "INFO",
+//	  "msg": "hi",
+//	  "caller": "slog/examples_test.go:62",
+//	  "func": "cdr.dev/slog/sloggers/slogtest_test.TestExampleTest",
+//	  "logger_names": ["comp", "subcomp"],
+//	  "trace": "<traceid>",
+//	  "span": "<spanid>",
+//	  "tags": {
+//	    "region": "us-east-1"
+//	  },
+//	  "fields": {
+//	    "my_field": "field value"
+//	  }
+//	}
+//
+// func, logger_names, trace, span, tags and fields are omitted when empty.
+// Field values are encoded as described in Map.MarshalJSON.
+func (ent SinkEntry) MarshalJSON() ([]byte, error) {
+	m := M(
+		F("ts", ent.Time),
+		F("level", ent.Level),
+		F("msg", ent.Message),
+		F("caller", fmt.Sprintf("%v:%v", ent.File, ent.Line)),
+	)
+
+	if ent.Func != "" {
+		m = append(m, F("func", ent.Func))
+	}
+
+	if len(ent.LoggerNames) > 0 {
+		m = append(m, F("logger_names", ent.LoggerNames))
+	}
+
+	if ent.SpanContext != (trace.SpanContext{}) {
+		m = append(m,
+			F("trace", ent.SpanContext.TraceID),
+			F("span", ent.SpanContext.SpanID),
+		)
+	}
+
+	if len(ent.Tags) > 0 {
+		m = append(m,
+			F("tags", ent.Tags),
+		)
+	}
+
+	if len(ent.Fields) > 0 {
+		m = append(m,
+			F("fields", ent.Fields),
+		)
+	}
+
+	return m.MarshalJSON()
+}
+
+type jsonEntry struct {
+	Time        time.Time `json:"ts"`
+	Level       Level     `json:"level"`
+	Message     string    `json:"msg"`
+	Caller      string    `json:"caller"`
+	Func        string    `json:"func"`
+	LoggerNames []string  `json:"logger_names"`
+	Trace       string    `json:"trace"`
+	Span        string    `json:"span"`
+	Tags        Map       `json:"tags"`
+	Fields      Map       `json:"fields"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// It parses the format written by MarshalJSON.
+// Field values are decoded as described in Map.UnmarshalJSON.
+// The trace options of the span context are not part of the format
+// and so are never set.
+func (ent *SinkEntry) UnmarshalJSON(b []byte) error {
+	var je jsonEntry
+	err := json.Unmarshal(b, &je)
+	if err != nil {
+		return xerrors.Errorf("failed to unmarshal entry: %w", err)
+	}
+
+	e := SinkEntry{
+		Time:        je.Time,
+		Level:       je.Level,
+		Message:     je.Message,
+		LoggerNames: je.LoggerNames,
+		Func:        je.Func,
+		Fields:      je.Fields,
+		Tags:        je.Tags,
+	}
+
+	if je.Caller != "" {
+		i := strings.LastIndexByte(je.Caller, ':')
+		if i < 0 {
+			return xerrors.Errorf("invalid caller %q", je.Caller)
+		}
+		e.File = je.Caller[:i]
+		e.Line, err = strconv.Atoi(je.Caller[i+1:])
+		if err != nil {
+			return xerrors.Errorf("invalid caller %q: %w", je.Caller, err)
+		}
+	}
+
+	err = decodeHex(je.Trace, e.SpanContext.TraceID[:])
+	if err != nil {
+		return xerrors.Errorf("invalid trace %q: %w", je.Trace, err)
+	}
+	err = decodeHex(je.Span, e.SpanContext.SpanID[:])
+	if err != nil {
+		return xerrors.Errorf("invalid span %q: %w", je.Span, err)
+	}
+
+	*ent = e
+	return nil
+}
+
+func decodeHex(s string, dst []byte) error {
+	if s == "" {
+		return nil
+	}
+	if hex.DecodedLen(len(s)) != len(dst) {
+		return xerrors.Errorf("expected %v hex encoded bytes", len(dst))
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err
+}
+
+// binaryVersion is the first byte of the binary encoding.
+// It must be incremented on any incompatible change.
+const binaryVersion = 1
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+//
+// The binary format is more compact than JSON and preserves
+// the time zone and trace options. Fields are still encoded
+// as JSON. Tags are appended after the fields only if there are
+// any so that entries without tags decode with older versions.
+func (ent SinkEntry) MarshalBinary() ([]byte, error) {
+	ts, err := ent.Time.MarshalBinary()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal time: %w", err)
+	}
+
+	var fields []byte
+	if len(ent.Fields) > 0 {
+		fields, _ = ent.Fields.MarshalJSON()
+	}
+
+	b := make([]byte, 0, 64+len(ent.Message)+len(ent.File)+len(ent.Func)+len(fields))
+	b = append(b, binaryVersion)
+	b = appendBytes(b, ts)
+	b = appendVarint(b, int64(ent.Level))
+	b = appendBytes(b, []byte(ent.Message))
+	b = appendUvarint(b, uint64(len(ent.LoggerNames)))
+	for _, name := range ent.LoggerNames {
+		b = appendBytes(b, []byte(name))
+	}
+	b = appendBytes(b, []byte(ent.Func))
+	b = appendBytes(b, []byte(ent.File))
+	b = appendVarint(b, int64(ent.Line))
+	b = append(b, ent.SpanContext.TraceID[:]...)
+	b = append(b, ent.SpanContext.SpanID[:]...)
+	b = appendUvarint(b, uint64(ent.SpanContext.TraceOptions))
+	b = appendBytes(b, fields)
+	if len(ent.Tags) > 0 {
+		b = appendUvarint(b, uint64(len(ent.Tags)))
+		for _, t := range ent.Tags {
+			b = appendBytes(b, []byte(t.Name))
+			b = appendBytes(b, []byte(fmt.Sprint(t.Value)))
+		}
+	}
+	return b, nil
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+func appendBytes(b, p []byte) []byte {
+	b = appendUvarint(b, uint64(len(p)))
+	return append(b, p...)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+//
+// It parses the format written by MarshalBinary.
+func (ent *SinkEntry) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 || b[0] != binaryVersion {
+		return xerrors.New("failed to unmarshal entry: unknown binary version")
+	}
+	r := &binaryReader{b: b[1:]}
+
+	var e SinkEntry
+	err := e.Time.UnmarshalBinary(r.bytes())
+	if r.err == nil && err != nil {
+		r.err = err
+	}
+	e.Level = Level(r.varint())
+	e.Message = string(r.bytes())
+	n := r.uvarint()
+	if r.err == nil && n > uint64(len(r.b)) {
+		r.err = xerrors.New("too many logger names")
+	}
+	for i := uint64(0); r.err == nil && i < n; i++ {
+		e.LoggerNames = append(e.LoggerNames, string(r.bytes()))
+	}
+	e.Func = string(r.bytes())
+	e.File = string(r.bytes())
+	e.Line = int(r.varint())
+	copy(e.SpanContext.TraceID[:], r.next(len(e.SpanContext.TraceID)))
+	copy(e.SpanContext.SpanID[:], r.next(len(e.SpanContext.SpanID)))
+	e.SpanContext.TraceOptions = trace.TraceOptions(r.uvarint())
+	fields := r.bytes()
+	if r.err == nil && len(r.b) > 0 {
+		n := r.uvarint()
+		if r.err == nil && n > uint64(len(r.b)) {
+			r.err = xerrors.New("too many tags")
+		}
+		for i := uint64(0); r.err == nil && i < n; i++ {
+			name := string(r.bytes())
+			e.Tags = append(e.Tags, F(name, string(r.bytes())))
+		}
+	}
+	if r.err != nil {
+		return xerrors.Errorf("failed to unmarshal entry: %w", r.err)
+	}
+
+	if len(fields) > 0 {
+		err = e.Fields.UnmarshalJSON(fields)
+		if err != nil {
+			return xerrors.Errorf("failed to unmarshal entry fields: %w", err)
+		}
+	}
+
+	*ent = e
+	return nil
+}
+
+// binaryReader reads the binary entry encoding.
+// The first error is sticky and every later read
+// returns a zero value.
+type binaryReader struct {
+	b   []byte
+	err error
+}
+
+func (r *binaryReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = xerrors.New("unexpected end of entry")
+		return nil
+	}
+	p := r.b[:n]
+	r.b = r.b[n:]
+	return p
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.b)
+	if n <= 0 {
+		r.err = xerrors.New("invalid uvarint")
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *binaryReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.b)
+	if n <= 0 {
+		r.err = xerrors.New("invalid varint")
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *binaryReader) bytes() []byte {
+	n := r.uvarint()
+	if r.err == nil && n > uint64(len(r.b)) {
+		r.err = xerrors.New("unexpected end of entry")
+		return nil
+	}
+	return r.next(int(n))
+}
+
+var (
+	_ encoding.TextMarshaler   = Level(0)
+	_ encoding.TextUnmarshaler = new(Level)
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+//
+// It accepts the output of Level.String.
+func (l *Level) UnmarshalText(b []byte) error {
+	s := string(b)
+	for lvl, str := range levelStrings {
+		if s == str {
+			*l = lvl
+			return nil
+		}
+	}
+
+	if strings.HasPrefix(s, "slog.Level(") && strings.HasSuffix(s, ")") {
+		n, err := strconv.Atoi(s[len("slog.Level(") : len(s)-1])
+		if err == nil {
+			*l = Level(n)
+			return nil
+		}
+	}
+
+	return xerrors.Errorf("unknown level %q", s)
+}
+
+// Merge returns ent with fields merged into its fields as described
+// in Map.Merge. It is meant for sinks that wrap other sinks and add or
+// override fields as the fields of ent are shared with other sinks
+// and must not be modified in place.
+func (ent SinkEntry) Merge(fields ...Field) SinkEntry {
+	ent.Fields = ent.Fields.Merge(fields)
+	return ent
+}
+
+// Clone returns a copy of ent that does not share the logger names,
+// fields, tags and nested Maps of the fields with ent so that they can
+// be modified, e.g. by log forwarders before logging the entry again.
+// Other field values are not copied.
+func (ent SinkEntry) Clone() SinkEntry {
+	if ent.LoggerNames != nil {
+		ent.LoggerNames = append([]string(nil), ent.LoggerNames...)
+	}
+	ent.Fields = ent.Fields.clone()
+	ent.Tags = ent.Tags.clone()
+	return ent
+}
+
+func (m Map) clone() Map {
+	if m == nil {
+		return nil
+	}
+	m2 := make(Map, len(m))
+	for i, f := range m {
+		if nested, ok := f.Value.(Map); ok {
+			f.Value = nested.clone()
+		}
+		m2[i] = f
+	}
+	return m2
+}
@@ -0,0 +1,135 @@
+package slog_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSinkEntry(t *testing.T) {
+	t.Parallel()
+
+	ent := slog.SinkEntry{
+		Time:        time.Date(2000, time.February, 5, 4, 4, 4, 4, time.UTC),
+		Level:       slog.LevelWarn,
+		Message:     "hello",
+		LoggerNames: []string{"named", "meow"},
+		Func:        "cdr.dev/slog_test.TestSinkEntry",
+		File:        "/src/entry_test.go",
+		Line:        42,
+		SpanContext: trace.SpanContext{
+			SpanID:  trace.SpanID{0, 1, 2, 3, 4, 5, 6, 7},
+			TraceID: trace.TraceID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		},
+		Fields: slog.M(
+			slog.F("b", 1),
+			slog.F("a", slog.M(
+				slog.F("list", []string{"x"}),
+			)),
+		),
+	}
+
+	// Field values decode into their generic JSON representation.
+	exp := ent
+	exp.Fields = slog.M(
+		slog.F("b", json.Number("1")),
+		slog.F("a", slog.M(
+			slog.F("list", []interface{}{"x"}),
+		)),
+	)
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := json.Marshal(ent)
+		assert.Success(t, "marshal", err)
+
+		var act slog.SinkEntry
+		err = json.Unmarshal(b, &act)
+		assert.Success(t, "unmarshal", err)
+		assert.Equal(t, "entry", exp, act)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		t.Parallel()
+
+		ent := ent
+		ent.SpanContext.TraceOptions = 1
+		exp := exp
+		exp.SpanContext.TraceOptions = 1
+
+		b, err := ent.MarshalBinary()
+		assert.Success(t, "marshal", err)
+
+		var act slog.SinkEntry
+		err = act.UnmarshalBinary(b)
+		assert.Success(t, "unmarshal", err)
+		assert.Equal(t, "entry", exp, act)
+
+		err = act.UnmarshalBinary(b[:len(b)/2])
+		assert.Error(t, "unmarshal truncated", err)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		b, err := json.Marshal(slog.SinkEntry{})
+		assert.Success(t, "marshal", err)
+
+		var act slog.SinkEntry
+		err = json.Unmarshal(b, &act)
+		assert.Success(t, "unmarshal", err)
+		assert.Equal(t, "entry", slog.SinkEntry{}, act)
+	})
+}
+
+func TestLevel_UnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	var l slog.Level
+	assert.Success(t, "unmarshal", l.UnmarshalText([]byte("CRITICAL")))
+	assert.Equal(t, "level", slog.LevelCritical, l)
+	assert.Success(t, "unmarshal", l.UnmarshalText([]byte("slog.Level(12)")))
+	assert.Equal(t, "level", slog.Level(12), l)
+	assert.Error(t, "unmarshal", l.UnmarshalText([]byte("meow")))
+}
+
+func TestSinkEntryMerge(t *testing.T) {
+	t.Parallel()
+
+	fields := slog.M(slog.F("a", 1), slog.F("b", 2))
+	ent := slog.SinkEntry{Message: "msg", Fields: fields}
+	ent2 := ent.Merge(slog.F("b", 3), slog.F("c", 4))
+
+	assert.Equal(t, "fields", slog.M(slog.F("a", 1), slog.F("b", 3), slog.F("c", 4)), ent2.Fields)
+	assert.Equal(t, "msg", "msg", ent2.Message)
+	assert.Equal(t, "shared fields", slog.M(slog.F("a", 1), slog.F("b", 2)), fields)
+}
+
+func TestSinkEntryClone(t *testing.T) {
+	t.Parallel()
+
+	ent := slog.SinkEntry{
+		Message:     "msg",
+		LoggerNames: []string{"a"},
+		Fields:      slog.M(slog.F("nested", slog.M(slog.F("b", 1)))),
+		Tags:        slog.M(slog.F("region", "us-east-1")),
+	}
+	ent2 := ent.Clone()
+	assert.Equal(t, "clone", ent, ent2)
+
+	ent2.LoggerNames[0] = "c"
+	ent2.Fields[0].Value.(slog.Map)[0].Value = 2
+	ent2.Tags[0].Value = "eu-west-1"
+	assert.Equal(t, "original", slog.SinkEntry{
+		Message:     "msg",
+		LoggerNames: []string{"a"},
+		Fields:      slog.M(slog.F("nested", slog.M(slog.F("b", 1)))),
+		Tags:        slog.M(slog.F("region", "us-east-1")),
+	}, ent)
+}
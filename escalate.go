@@ -0,0 +1,137 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EscalateOptions represents the options for the sink returned by Escalate.
+type EscalateOptions struct {
+	// Count is the number of entries with the same fingerprint within
+	// Window that is tolerated. The entry after them is escalated.
+	// Defaults to 10.
+	Count int
+	// Window is the duration the entries are counted over.
+	// Defaults to 5 minutes.
+	Window time.Duration
+	// Fingerprint returns the key of the entries counted together.
+	// Defaults to the logger names, the location and the message.
+	Fingerprint func(ent SinkEntry) string
+}
+
+// Escalate returns a sink that logs entries to s and counts the entries
+// at level from by their fingerprint. When more than opts.Count entries
+// of a fingerprint are logged within opts.Window, e.g. the same warning
+// repeating, it also logs a copy of the last one at level to so that the
+// pattern reaches the thresholds of alerts. The copy has an escalation
+// field with the original level, the count and the window.
+//
+// The entries are counted by their time. After an escalation, the count
+// of the fingerprint starts over so that it escalates at most once per
+// opts.Count entries.
+//
+// If opts is nil, the defaults are used.
+func Escalate(s Sink, from, to Level, opts *EscalateOptions) Sink {
+	if opts == nil {
+		opts = &EscalateOptions{}
+	}
+	e := &escalateSink{
+		s:           s,
+		from:        from,
+		to:          to,
+		count:       opts.Count,
+		window:      opts.Window,
+		fingerprint: opts.Fingerprint,
+		seen:        make(map[string][]time.Time),
+	}
+	if e.count <= 0 {
+		e.count = 10
+	}
+	if e.window <= 0 {
+		e.window = 5 * time.Minute
+	}
+	if e.fingerprint == nil {
+		e.fingerprint = defaultFingerprint
+	}
+	return e
+}
+
+func defaultFingerprint(ent SinkEntry) string {
+	return fmt.Sprintf("%v\x00%v:%v\x00%v", strings.Join(ent.LoggerNames, "."), ent.File, ent.Line, ent.Message)
+}
+
+// maxFingerprints is the number of fingerprints after which
+// the ones without entries in the window are forgotten.
+const maxFingerprints = 4096
+
+type escalateSink struct {
+	s           Sink
+	from, to    Level
+	count       int
+	window      time.Duration
+	fingerprint func(SinkEntry) string
+
+	mu sync.Mutex
+	// seen holds the times of the entries of every fingerprint
+	// within the window, oldest first.
+	seen map[string][]time.Time
+}
+
+func (e *escalateSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	e.s.LogEntry(ctx, ent)
+	if ent.Level != e.from {
+		return
+	}
+
+	n, ok := e.add(e.fingerprint(ent), ent.Time)
+	if !ok {
+		return
+	}
+	ent.Level = e.to
+	ent.Fields = ent.Fields.append(M(F("escalation", M(
+		F("from", e.from),
+		F("count", n),
+		F("window", e.window.String()),
+	))))
+	e.s.LogEntry(ctx, ent)
+}
+
+// add records an entry of fp at t and reports whether to escalate
+// it along with the number of entries in the window.
+func (e *escalateSink) add(fp string, t time.Time) (int, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	start := t.Add(-e.window)
+	if len(e.seen) >= maxFingerprints {
+		for fp, times := range e.seen {
+			if !times[len(times)-1].After(start) {
+				delete(e.seen, fp)
+			}
+		}
+	}
+
+	times := e.seen[fp]
+	i := 0
+	for i < len(times) && !times[i].After(start) {
+		i++
+	}
+	times = append(times[i:], t)
+	if len(times) <= e.count {
+		e.seen[fp] = times
+		return 0, false
+	}
+	delete(e.seen, fp)
+	return len(times), true
+}
+
+func (e *escalateSink) Sync() {
+	e.s.Sync()
+}
+
+func (e *escalateSink) Flush(ctx context.Context) error {
+	return Flush(ctx, e.s)
+}
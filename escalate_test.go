@@ -0,0 +1,58 @@
+package slog_test
+
+import (
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestEscalate(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	e := slog.Escalate(s, slog.LevelWarn, slog.LevelError, &slog.EscalateOptions{
+		Count:  2,
+		Window: time.Minute,
+	})
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	warn := func(msg string, after time.Duration) {
+		e.LogEntry(bg, slog.SinkEntry{
+			Time:    start.Add(after),
+			Level:   slog.LevelWarn,
+			Message: msg,
+			Fields:  slog.M(slog.F("a", 1)),
+		})
+	}
+	warn("disk slow", 0)
+	warn("disk slow", 30*time.Second)
+	warn("other", 20*time.Second)
+	// The first entry is outside of the window.
+	warn("disk slow", 70*time.Second)
+	assert.Len(t, "not escalated", 4, s.entries)
+
+	warn("disk slow", 75*time.Second)
+	assert.Len(t, "escalated", 6, s.entries)
+	esc := s.entries[5]
+	assert.Equal(t, "level", slog.LevelError, esc.Level)
+	assert.Equal(t, "msg", "disk slow", esc.Message)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("a", 1),
+		slog.F("escalation", slog.M(
+			slog.F("from", slog.LevelWarn),
+			slog.F("count", 3),
+			slog.F("window", "1m0s"),
+		)),
+	), esc.Fields)
+	assert.Len(t, "original fields", 1, s.entries[4].Fields)
+
+	// The count starts over after an escalation.
+	warn("disk slow", 90*time.Second)
+	warn("disk slow", 100*time.Second)
+	assert.Len(t, "count reset", 8, s.entries)
+
+	e.LogEntry(bg, slog.SinkEntry{Level: slog.LevelError, Message: "disk slow"})
+	assert.Len(t, "other level", 9, s.entries)
+}
@@ -3,3 +3,7 @@ package slog
 func (l *Logger) SetExit(fn func(int)) {
 	l.exit = fn
 }
+
+func SetTeeErrorf(s Sink, fn func(f string, v ...interface{})) {
+	s.(*teeSink).errorf = fn
+}
@@ -0,0 +1,79 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// DefaultFatalTimeout is the time Fatal waits for the sinks to log
+// and sync the entry before it writes it to stderr and exits.
+const DefaultFatalTimeout = 5 * time.Second
+
+// fatalStderr is where Fatal writes entries that time out.
+var fatalStderr io.Writer = os.Stderr
+
+// WithFatalTimeout returns a Logger whose Fatal waits at most d for the
+// sinks to log and sync the entry. Afterwards, the entry is written to
+// stderr and the process exits while the sinks may still be logging.
+// If d is negative, Fatal waits for the sinks indefinitely.
+//
+// Defaults to DefaultFatalTimeout.
+func (l Logger) WithFatalTimeout(d time.Duration) Logger {
+	l.fatalTimeout = d
+	return l
+}
+
+// logFatal logs and syncs ent and then exits. If that takes longer than
+// the fatal timeout, a watchdog writes ent to stderr and exits instead so
+// that a stuck sink cannot keep the process from exiting.
+//
+// The sinks are called in the goroutine of Fatal as they may rely on it,
+// e.g. slogtest calls t.Fatal.
+func (l Logger) logFatal(ctx context.Context, ent SinkEntry) {
+	l.testHelper(ctx).Helper()
+	timeout := l.fatalTimeout
+	if timeout == 0 {
+		timeout = DefaultFatalTimeout
+	}
+	if timeout < 0 {
+		l.Log(ctx, ent)
+		l.Sync()
+		l.exit(1)
+		return
+	}
+
+	exited := make(chan struct{})
+	t := time.AfterFunc(timeout, func() {
+		defer close(exited)
+		// Avoid anything that could take a lock of the sinks.
+		fields, _ := l.fields.append(ent.Fields).MarshalJSON()
+		fmt.Fprintf(fatalStderr, "slog: timed out after %v logging fatal entry: %v [%v] <%v:%v> %q %s\n",
+			timeout, ent.Time.Format(time.RFC3339Nano), ent.Level, ent.File, ent.Line, ent.Message, compactJSON(fields))
+		l.exit(1)
+	})
+	// The sinks may exit the goroutine, e.g. with runtime.Goexit.
+	defer t.Stop()
+
+	l.Log(ctx, ent)
+	l.Sync()
+	if !t.Stop() {
+		// The watchdog fired and exits.
+		<-exited
+		return
+	}
+	l.exit(1)
+}
+
+// compactJSON returns b without the newlines of Map.MarshalJSON.
+func compactJSON(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for _, c := range b {
+		if c != '\n' {
+			out = append(out, c)
+		}
+	}
+	return out
+}
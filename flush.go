@@ -0,0 +1,72 @@
+package slog
+
+import (
+	"context"
+	"sync"
+)
+
+// Flusher is implemented by sinks that queue entries or wrap other
+// sinks so that Flush can drain them with a deadline.
+type Flusher interface {
+	// Flush writes the queued entries of the sink and flushes the sinks
+	// it wraps. It returns once they are written or ctx is done.
+	Flush(ctx context.Context) error
+}
+
+// Flush drains s and the sinks it wraps, e.g. before the process exits.
+//
+// If s implements Flusher, its Flush is called. Otherwise s is synced
+// with SyncErr if it implements ErrorSink or Sync. If ctx is done before
+// the sync returns, ctx.Err() is returned and the sync continues in
+// the background.
+func Flush(ctx context.Context, s Sink) error {
+	if f, ok := s.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		if es, ok := s.(ErrorSink); ok {
+			errc <- es.SyncErr()
+			return
+		}
+		s.Sync()
+		errc <- nil
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush drains all the underlying sinks and the sinks they wrap, such
+// as those of Tee, concurrently until ctx is done. Sync only reaches
+// the underlying sinks.
+//
+// The failures are returned as a *TeeError with the index of the sinks
+// in the arguments to Make.
+func (l Logger) Flush(ctx context.Context) error {
+	return flushAll(ctx, l.sinks).err()
+}
+
+// flushAll flushes sinks concurrently and returns their failures.
+func flushAll(ctx context.Context, sinks []Sink) *TeeError {
+	errs := make([]error, len(sinks))
+	var wg sync.WaitGroup
+	for i, s := range sinks {
+		wg.Add(1)
+		go func(i int, s Sink) {
+			defer wg.Done()
+			errs[i] = Flush(ctx, s)
+		}(i, s)
+	}
+	wg.Wait()
+
+	var te *TeeError
+	for i, err := range errs {
+		te = te.add(i, sinks[i], err)
+	}
+	return te
+}
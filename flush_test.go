@@ -0,0 +1,70 @@
+package slog_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+// blockSink blocks Sync until unblock is closed.
+type blockSink struct {
+	fakeSink
+	unblock chan struct{}
+}
+
+func (s *blockSink) Sync() {
+	<-s.unblock
+}
+
+// flushSink records the contexts it is flushed with.
+type flushSink struct {
+	fakeSink
+	flushed []context.Context
+}
+
+func (s *flushSink) Flush(ctx context.Context) error {
+	s.flushed = append(s.flushed, ctx)
+	return nil
+}
+
+func TestFlush(t *testing.T) {
+	t.Parallel()
+
+	ok := &fakeSink{}
+	f := &flushSink{}
+	eof := &failSink{err: io.EOF}
+	block := &blockSink{unblock: make(chan struct{})}
+	defer close(block.unblock)
+
+	l := slog.Make(ok, slog.Tee(f, eof, slog.Sanitize(block, nil)))
+
+	ctx, cancel := context.WithTimeout(bg, 50*time.Millisecond)
+	defer cancel()
+	err := l.Flush(ctx)
+	assert.Error(t, "flush", err)
+	assert.Equal(t, "syncs", 1, ok.syncs)
+	assert.Len(t, "flushed", 1, f.flushed)
+	assert.True(t, "flushed with ctx", f.flushed[0] == ctx)
+
+	var te *slog.TeeError
+	assert.True(t, "logger error", xerrors.As(err, &te))
+	assert.Len(t, "logger errors", 1, te.Errors)
+	assert.Equal(t, "tee index", 1, te.Errors[0].Index)
+
+	assert.True(t, "tee error", xerrors.As(te.Errors[0].Err, &te))
+	assert.Len(t, "tee errors", 2, te.Errors)
+	assert.Equal(t, "eof index", 1, te.Errors[0].Index)
+	assert.Equal(t, "eof", io.EOF, te.Errors[0].Err)
+	assert.Equal(t, "block index", 2, te.Errors[1].Index)
+	assert.Equal(t, "deadline", context.DeadlineExceeded, te.Errors[1].Err)
+
+	err = slog.Make(ok).Flush(bg)
+	assert.Success(t, "flush ok", err)
+	assert.Equal(t, "syncs", 2, ok.syncs)
+}
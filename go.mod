@@ -12,4 +12,5 @@ require (
 	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 	golang.org/x/xerrors v0.0.0-20220411194840-2f41105eb62f
 	google.golang.org/genproto v0.0.0-20220421151946-72621c1f0bd3
+	google.golang.org/protobuf v1.28.0
 )
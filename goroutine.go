@@ -0,0 +1,79 @@
+package slog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"cdr.dev/slog/internal/goid"
+)
+
+// goroutines holds the fields attached to goroutines
+// by WithGoroutine, keyed by goroutine ID.
+var goroutines struct {
+	// n is the number of goroutines with fields. Entries only
+	// look up the ID of their goroutine if there are any.
+	n      int32
+	fields sync.Map
+}
+
+// WithGoroutine attaches the fields to the current goroutine and goroutines
+// started from it with Go. Entries logged on those goroutines have the
+// fields prepended, before the fields of the context.
+//
+// It is meant for code without a context parameter. Prefer With.
+//
+// It will append to any fields already attached to the goroutine.
+// Call restore on the same goroutine to detach them, usually with defer:
+//
+//	defer slog.WithGoroutine(slog.F("request_id", id))()
+func WithGoroutine(fields ...Field) (restore func()) {
+	id := goid.ID()
+	prev := fieldsFromGoroutineID(id)
+	setGoroutineFields(id, prev.append(fields))
+	return func() {
+		setGoroutineFields(id, prev)
+	}
+}
+
+// Go runs fn in a new goroutine with the fields
+// attached to the current goroutine.
+func Go(fn func()) {
+	fields := fieldsFromGoroutine()
+	go func() {
+		if len(fields) > 0 {
+			id := goid.ID()
+			setGoroutineFields(id, fields)
+			defer setGoroutineFields(id, nil)
+		}
+		fn()
+	}()
+}
+
+func setGoroutineFields(id uint64, fields Map) {
+	if len(fields) == 0 {
+		if _, ok := goroutines.fields.Load(id); ok {
+			goroutines.fields.Delete(id)
+			atomic.AddInt32(&goroutines.n, -1)
+		}
+		return
+	}
+	if _, loaded := goroutines.fields.Load(id); !loaded {
+		atomic.AddInt32(&goroutines.n, 1)
+	}
+	goroutines.fields.Store(id, fields)
+}
+
+func fieldsFromGoroutine() Map {
+	if atomic.LoadInt32(&goroutines.n) == 0 {
+		return nil
+	}
+	return fieldsFromGoroutineID(goid.ID())
+}
+
+func fieldsFromGoroutineID(id uint64) Map {
+	v, ok := goroutines.fields.Load(id)
+	if !ok {
+		return nil
+	}
+	return v.(Map)
+}
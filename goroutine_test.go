@@ -0,0 +1,45 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestWithGoroutine(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(s)
+
+	restore := slog.WithGoroutine(slog.F("a", 1))
+	restore2 := slog.WithGoroutine(slog.F("b", 2))
+	l.Info(slog.With(bg, slog.F("c", 3)), "msg", slog.F("d", 4))
+
+	done := make(chan struct{})
+	slog.Go(func() {
+		defer close(done)
+		l.Info(bg, "child")
+	})
+	<-done
+
+	// Goroutines started without Go do not inherit the fields.
+	done = make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Info(bg, "other")
+	}()
+	<-done
+
+	restore2()
+	l.Info(bg, "restored")
+	restore()
+	l.Info(bg, "detached")
+
+	assert.Equal(t, "fields", slog.M(slog.F("a", 1), slog.F("b", 2), slog.F("c", 3), slog.F("d", 4)), s.entries[0].Fields)
+	assert.Equal(t, "child", slog.M(slog.F("a", 1), slog.F("b", 2)), s.entries[1].Fields)
+	assert.Len(t, "other", 0, s.entries[2].Fields)
+	assert.Equal(t, "restored", slog.M(slog.F("a", 1)), s.entries[3].Fields)
+	assert.Len(t, "detached", 0, s.entries[4].Fields)
+}
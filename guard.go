@@ -0,0 +1,73 @@
+package slog
+
+import (
+	"context"
+	"sync"
+
+	"cdr.dev/slog/internal/goid"
+)
+
+// Guard returns a sink that logs entries to s and diverts the entries
+// logged while s is logging an entry to fallback instead of recursing
+// or deadlocking on a lock held by s, e.g. the entries of an HTTP client
+// instrumented with slog that s exports entries with.
+//
+// Such entries are detected on the goroutine that called s and, for the
+// goroutines started by s, through the context passed to s. Entries that
+// are not logged with a context derived from it on other goroutines are
+// not detected. If fallback is nil, the entries are dropped.
+//
+// Detecting the goroutine costs the parsing of the header of its stack
+// for every entry so only use Guard in front of sinks that can log.
+func Guard(s, fallback Sink) Sink {
+	return &guardSink{
+		s:        s,
+		fallback: fallback,
+	}
+}
+
+type guardSink struct {
+	s        Sink
+	fallback Sink
+	// active contains the IDs of the goroutines in s.LogEntry.
+	active sync.Map
+}
+
+type guardKey struct {
+	g *guardSink
+}
+
+func (g *guardSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	if ctx.Value(guardKey{g}) != nil {
+		g.divert(ctx, ent)
+		return
+	}
+	id := goid.ID()
+	if _, loaded := g.active.LoadOrStore(id, struct{}{}); loaded {
+		g.divert(ctx, ent)
+		return
+	}
+	defer g.active.Delete(id)
+
+	g.s.LogEntry(context.WithValue(ctx, guardKey{g}, true), ent)
+}
+
+func (g *guardSink) divert(ctx context.Context, ent SinkEntry) {
+	if g.fallback != nil {
+		g.fallback.LogEntry(ctx, ent)
+	}
+}
+
+func (g *guardSink) Sync() {
+	g.s.Sync()
+	if g.fallback != nil {
+		g.fallback.Sync()
+	}
+}
+
+func (g *guardSink) Flush(ctx context.Context) error {
+	if g.fallback == nil {
+		return Flush(ctx, g.s)
+	}
+	return Flush(ctx, Tee(g.s, g.fallback))
+}
@@ -0,0 +1,61 @@
+package slog_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+// loggingSink logs an entry of its own with l for every entry,
+// like an exporter with an instrumented client.
+type loggingSink struct {
+	fakeSink
+	mu sync.Mutex
+	l  slog.Logger
+	// async logs on another goroutine with the context of the entry.
+	async bool
+}
+
+func (s *loggingSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	// The lock would deadlock if the entry of l reached s.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fakeSink.LogEntry(ctx, ent)
+	if !s.async {
+		s.l.Info(context.Background(), "exported")
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.l.Info(ctx, "exported")
+	}()
+	<-done
+}
+
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	for _, async := range []bool{false, true} {
+		s := &loggingSink{async: async}
+		fallback := &fakeSink{}
+		s.l = slog.Make(slog.Guard(s, fallback))
+
+		s.l.Info(bg, "hello")
+		assert.Len(t, "entries", 1, s.entries)
+		assert.Equal(t, "msg", "hello", s.entries[0].Message)
+		assert.Len(t, "fallback entries", 1, fallback.entries)
+		assert.Equal(t, "fallback msg", "exported", fallback.entries[0].Message)
+	}
+
+	s := &loggingSink{}
+	s.l = slog.Make(slog.Guard(s, nil))
+	s.l.Info(bg, "hello")
+	assert.Len(t, "dropped", 1, s.entries)
+	err := s.l.Flush(bg)
+	assert.Success(t, "flush", err)
+}
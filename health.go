@@ -0,0 +1,126 @@
+package slog
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthTracker is a sink that records the entries at or above
+// LevelError of every component, i.e. the names of the logger
+// joined with a period.
+//
+// See TrackHealth.
+type HealthTracker struct {
+	mu         sync.Mutex
+	components map[string]ComponentHealth
+}
+
+// ComponentHealth is the health of a component.
+type ComponentHealth struct {
+	// LastError is the time of the last entry at or above LevelError.
+	LastError time.Time
+	// LastMessage is the message of that entry.
+	LastMessage string
+	// Errors is the number of entries at or above LevelError.
+	Errors uint64
+}
+
+// LogEntry implements Sink.
+func (h *HealthTracker) LogEntry(ctx context.Context, ent SinkEntry) {
+	if ent.Level < LevelError {
+		return
+	}
+	component := strings.Join(ent.LoggerNames, ".")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.components == nil {
+		h.components = make(map[string]ComponentHealth)
+	}
+	c := h.components[component]
+	if !ent.Time.Before(c.LastError) {
+		c.LastError = ent.Time
+		c.LastMessage = ent.Message
+	}
+	c.Errors++
+	h.components[component] = c
+}
+
+// Sync implements Sink.
+func (h *HealthTracker) Sync() {}
+
+// Snapshot returns the current health of every component.
+func (h *HealthTracker) Snapshot() HealthSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := HealthSnapshot{
+		Time:       time.Now(),
+		Components: make(map[string]ComponentHealth, len(h.components)),
+	}
+	for name, c := range h.components {
+		s.Components[name] = c
+	}
+	return s
+}
+
+// TrackHealth returns l with a HealthTracker appended to its sinks
+// so that Health can report whether errors were logged recently,
+// e.g. in readiness probes. Loggers derived from the returned logger
+// share the tracker.
+//
+// Only entries at or above the level of the logger are tracked.
+func TrackHealth(l Logger) Logger {
+	return l.AppendSinks(&HealthTracker{})
+}
+
+// Health returns the health of the components logged to by l,
+// as recorded by the first HealthTracker in its sinks.
+//
+// If l has no HealthTracker, every component is healthy.
+func Health(l Logger) HealthSnapshot {
+	for _, s := range l.sinks {
+		if h, ok := s.(*HealthTracker); ok {
+			return h.Snapshot()
+		}
+	}
+	return HealthSnapshot{Time: time.Now()}
+}
+
+// HealthSnapshot is the health of components at a point in time.
+type HealthSnapshot struct {
+	// Time is when the snapshot was taken.
+	Time time.Time
+	// Components is the health of every component
+	// with at least one error.
+	Components map[string]ComponentHealth
+}
+
+// Healthy reports whether no error has been logged
+// in the window before the snapshot was taken.
+func (s HealthSnapshot) Healthy(window time.Duration) bool {
+	return len(s.Unhealthy(window)) == 0
+}
+
+// ComponentHealthy reports whether the component has not
+// logged an error in the window before the snapshot was taken.
+func (s HealthSnapshot) ComponentHealthy(component string, window time.Duration) bool {
+	c, ok := s.Components[component]
+	return !ok || c.LastError.Before(s.Time.Add(-window))
+}
+
+// Unhealthy returns the sorted components that logged an error
+// in the window before the snapshot was taken.
+func (s HealthSnapshot) Unhealthy(window time.Duration) []string {
+	var names []string
+	for name := range s.Components {
+		if !s.ComponentHealthy(name, window) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
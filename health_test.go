@@ -0,0 +1,52 @@
+package slog_test
+
+import (
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestHealth(t *testing.T) {
+	t.Parallel()
+
+	l := slog.TrackHealth(slog.Make(&fakeSink{}))
+	db := l.Named("db")
+	db.Warn(bg, "slow query")
+	assert.True(t, "healthy", slog.Health(l).Healthy(time.Minute))
+
+	db.Error(bg, "connection refused")
+	l.Named("http").Named("server").Critical(bg, "listen failed")
+
+	h := slog.Health(db)
+	assert.False(t, "healthy", h.Healthy(time.Minute))
+	assert.Equal(t, "unhealthy", []string{"db", "http.server"}, h.Unhealthy(time.Minute))
+	assert.False(t, "db", h.ComponentHealthy("db", time.Minute))
+	assert.True(t, "root", h.ComponentHealthy("", time.Minute))
+	assert.Equal(t, "message", "connection refused", h.Components["db"].LastMessage)
+	assert.Equal(t, "errors", uint64(1), h.Components["db"].Errors)
+
+	// Errors outside of the window do not count.
+	h.Time = h.Time.Add(2 * time.Minute)
+	assert.True(t, "healthy later", h.Healthy(time.Minute))
+
+	assert.True(t, "untracked", slog.Health(slog.Make()).Healthy(time.Minute))
+}
+
+func TestHealthTracker(t *testing.T) {
+	t.Parallel()
+
+	h := &slog.HealthTracker{}
+	t2 := time.Unix(2, 0)
+	h.LogEntry(bg, slog.SinkEntry{Level: slog.LevelError, Time: t2, Message: "second"})
+	// Entries logged out of order do not move the last error back.
+	h.LogEntry(bg, slog.SinkEntry{Level: slog.LevelFatal, Time: time.Unix(1, 0), Message: "first"})
+	h.LogEntry(bg, slog.SinkEntry{Level: slog.LevelInfo, Time: time.Unix(3, 0)})
+
+	assert.Equal(t, "health", slog.ComponentHealth{
+		LastError:   t2,
+		LastMessage: "second",
+		Errors:      2,
+	}, h.Snapshot().Components[""])
+}
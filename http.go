@@ -0,0 +1,158 @@
+package slog
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the values that HTTPOptions redacts.
+const Redacted = "REDACTED"
+
+// DefaultRedactQuery are the query parameters redacted by default.
+var DefaultRedactQuery = []string{"access_token", "api_key", "key", "password", "secret", "sig", "signature", "token"}
+
+// DefaultRedactHeaders are the headers redacted by default.
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie", "X-Api-Key"}
+
+// HTTPOptions configures the fields of Request and Response.
+// The nil value uses the defaults.
+type HTTPOptions struct {
+	// RedactQuery are the query parameters whose values are
+	// replaced with Redacted, ignoring case.
+	// Defaults to DefaultRedactQuery.
+	RedactQuery []string
+	// Headers are the headers included. Defaults to all.
+	Headers []string
+	// RedactHeaders are the headers whose values are replaced with
+	// Redacted. Defaults to DefaultRedactHeaders.
+	RedactHeaders []string
+}
+
+// Request returns a request field of the method, URL, protocol, content
+// length and headers of r with the defaults of HTTPOptions, e.g.
+//
+//	"request": {
+//	  "method": "GET",
+//	  "url": "https://example.com/search?q=cat&token=REDACTED",
+//	  "proto": "HTTP/1.1",
+//	  "content_length": 42,
+//	  "headers": {"Accept": "*/*", "Authorization": "REDACTED"}
+//	}
+//
+// The content length is omitted if it is unknown.
+func Request(r *http.Request) Field {
+	return (*HTTPOptions)(nil).Request(r)
+}
+
+// Response returns a response field of the status, protocol, content
+// length and headers of resp with the defaults of HTTPOptions, e.g.
+//
+//	"response": {
+//	  "status": 200,
+//	  "proto": "HTTP/1.1",
+//	  "content_length": 42,
+//	  "headers": {"Content-Type": "application/json"}
+//	}
+//
+// The content length is omitted if it is unknown.
+func Response(resp *http.Response) Field {
+	return (*HTTPOptions)(nil).Response(resp)
+}
+
+// Request is like the Request function but configured by opts.
+func (opts *HTTPOptions) Request(r *http.Request) Field {
+	m := M(
+		F("method", r.Method),
+		F("url", opts.url(r.URL)),
+		F("proto", r.Proto),
+	)
+	if r.ContentLength >= 0 {
+		m = append(m, F("content_length", r.ContentLength))
+	}
+	if h := opts.headers(r.Header); len(h) > 0 {
+		m = append(m, F("headers", h))
+	}
+	return F("request", m)
+}
+
+// Response is like the Response function but configured by opts.
+func (opts *HTTPOptions) Response(resp *http.Response) Field {
+	m := M(
+		F("status", resp.StatusCode),
+		F("proto", resp.Proto),
+	)
+	if resp.ContentLength >= 0 {
+		m = append(m, F("content_length", resp.ContentLength))
+	}
+	if h := opts.headers(resp.Header); len(h) > 0 {
+		m = append(m, F("headers", h))
+	}
+	return F("response", m)
+}
+
+func (opts *HTTPOptions) url(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redact := DefaultRedactQuery
+	if opts != nil && opts.RedactQuery != nil {
+		redact = opts.RedactQuery
+	}
+
+	u2 := *u
+	if _, ok := u2.User.Password(); ok {
+		u2.User = url.UserPassword(u2.User.Username(), Redacted)
+	}
+	if u2.RawQuery != "" {
+		q := u2.Query()
+		for name := range q {
+			if containsFold(redact, name) {
+				q[name] = []string{Redacted}
+			}
+		}
+		u2.RawQuery = q.Encode()
+	}
+	return u2.String()
+}
+
+// headers returns the included headers sorted by name with
+// the values of a header joined by commas.
+func (opts *HTTPOptions) headers(h http.Header) Map {
+	include := []string(nil)
+	redact := DefaultRedactHeaders
+	if opts != nil {
+		include = opts.Headers
+		if opts.RedactHeaders != nil {
+			redact = opts.RedactHeaders
+		}
+	}
+
+	names := make([]string, 0, len(h))
+	for name := range h {
+		if include == nil || containsFold(include, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	m := make(Map, 0, len(names))
+	for _, name := range names {
+		v := strings.Join(h[name], ", ")
+		if containsFold(redact, name) {
+			v = Redacted
+		}
+		m = append(m, F(name, v))
+	}
+	return m
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Package dedup replaces repeated large field values with
+// references to their first occurrence.
+//
+// The first occurrence of a value is written as is with an extra
+// field named after the original field with a _sha256 suffix:
+//
+//	{"stack": "goroutine 1 [running]: ...", "stack_sha256": "1b4f0e9851971998"}
+//
+// Later occurrences in the same output stream are replaced with:
+//
+//	{"stack": {"sha256_ref": "1b4f0e9851971998"}}
+package dedup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// maxEntries is the maximum number of hashes remembered.
+// Once reached, all are forgotten and values are written
+// in full again.
+const maxEntries = 4096
+
+// Cache remembers the values written to an output stream.
+//
+// Callers must hold the lock while calling Fields. References never
+// precede the value only if the entries are written in the order
+// they were encoded, see slog.Encoder.
+type Cache struct {
+	sync.Mutex
+
+	minSize int
+	seen    map[string]struct{}
+}
+
+// New returns a Cache that de-duplicates values of at least minSize bytes.
+func New(minSize int) *Cache {
+	return &Cache{
+		minSize: minSize,
+		seen:    make(map[string]struct{}),
+	}
+}
+
+// Fields returns fields with repeated large values replaced.
+//
+// fields is never modified.
+func (c *Cache) Fields(fields slog.Map) slog.Map {
+	var fields2 slog.Map
+	for i, f := range fields {
+		p := c.encode(f.Value)
+		if len(p) < c.minSize {
+			if fields2 != nil {
+				fields2 = append(fields2, f)
+			}
+			continue
+		}
+
+		if fields2 == nil {
+			fields2 = make(slog.Map, 0, len(fields)+1)
+			fields2 = append(fields2, fields[:i]...)
+		}
+
+		sum := sha256.Sum256(p)
+		id := hex.EncodeToString(sum[:8])
+		if _, ok := c.seen[id]; ok {
+			fields2 = append(fields2, slog.F(f.Name, slog.M(
+				slog.F("sha256_ref", id),
+			)))
+			continue
+		}
+
+		if len(c.seen) >= maxEntries {
+			c.seen = make(map[string]struct{})
+		}
+		c.seen[id] = struct{}{}
+		fields2 = append(fields2, f, slog.F(f.Name+"_sha256", id))
+	}
+
+	if fields2 == nil {
+		return fields
+	}
+	return fields2
+}
+
+func (c *Cache) encode(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	case error, xerrors.Formatter:
+		return []byte(fmt.Sprintf("%+v", v))
+	}
+	// No error is guaranteed due to slog.Map handling errors itself.
+	p, _ := json.Marshal(slog.M(slog.F("v", v)))
+	return p
+}
@@ -0,0 +1,35 @@
+package dedup
+
+import (
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	c := New(8)
+	big := strings.Repeat("a", 8)
+
+	fields := slog.M(slog.F("small", "hi"), slog.F("big", big))
+	act := c.Fields(fields)
+	assert.Len(t, "fields", 3, act)
+	id := act[2].Value.(string)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("small", "hi"),
+		slog.F("big", big),
+		slog.F("big_sha256", id),
+	), act)
+
+	act = c.Fields(slog.M(slog.F("other", big)))
+	assert.Equal(t, "fields", slog.M(
+		slog.F("other", slog.M(slog.F("sha256_ref", id))),
+	), act)
+
+	act = c.Fields(fields[:1])
+	assert.Equal(t, "fields", fields[:1], act)
+	assert.Len(t, "original", 2, fields)
+}
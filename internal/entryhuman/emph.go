@@ -0,0 +1,114 @@
+package entryhuman
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/fatih/color"
+
+	"cdr.dev/slog"
+)
+
+var emphColors = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// extractEmphasis removes the fields with an Emphasis value from fields
+// and returns the last one. The fields are only copied if there is one
+// as they are shared.
+func extractEmphasis(fields slog.Map) (slog.Map, *slog.Emphasis) {
+	var emph *slog.Emphasis
+	var rest slog.Map
+	for i, f := range fields {
+		e, ok := f.Value.(slog.Emphasis)
+		if !ok {
+			if emph != nil {
+				rest = append(rest, f)
+			}
+			continue
+		}
+		if emph == nil {
+			rest = append(make(slog.Map, 0, len(fields)-1), fields[:i]...)
+		}
+		emph = &e
+	}
+	if emph == nil {
+		return fields, nil
+	}
+	return rest, emph
+}
+
+// emphasize returns s emphasized with e. If w is not colored,
+// s is surrounded by double asterisks instead.
+func emphasize(w io.Writer, s string, e slog.Emphasis) string {
+	if !shouldColor(w) {
+		return "**" + s + "**"
+	}
+	var attrs []color.Attribute
+	if e.Bold {
+		attrs = append(attrs, color.Bold)
+	}
+	if e.Underline {
+		attrs = append(attrs, color.Underline)
+	}
+	if a, ok := emphColors[e.Color]; ok {
+		attrs = append(attrs, a)
+	}
+	return c(w, attrs...).Sprint(s)
+}
+
+// hasEmphasized reports whether a field of fields has an
+// Emphasized value.
+func hasEmphasized(fields slog.Map) bool {
+	for _, f := range fields {
+		if _, ok := f.Value.(slog.Emphasized); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// formatFields formats fields as a single line JSON object.
+func formatFields(w io.Writer, fields slog.Map, raw bool) string {
+	if !hasEmphasized(fields) {
+		return formatFieldsJSON(w, fields, raw)
+	}
+
+	// The fields are formatted one at a time so that the emphasized
+	// ones are not colored as JSON, which would reset their emphasis.
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		e, ok := f.Value.(slog.Emphasized)
+		if !ok {
+			s := formatFieldsJSON(w, slog.M(f), raw)
+			b.WriteString(s[1 : len(s)-1])
+			continue
+		}
+		s := formatFieldsJSON(ioutil.Discard, slog.M(f), raw)
+		b.WriteString(emphasize(w, s[1:len(s)-1], e.Emphasis))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatFieldsJSON(w io.Writer, fields slog.Map, raw bool) string {
+	// No error is guaranteed due to slog.Map handling errors itself.
+	b, _ := json.MarshalIndent(fields, "", "")
+	b = bytes.ReplaceAll(b, []byte(",\n"), []byte(", "))
+	b = bytes.ReplaceAll(b, []byte("\n"), []byte(""))
+	b = []byte(escape(string(b), raw))
+	return string(formatJSON(w, b))
+}
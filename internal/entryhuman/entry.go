@@ -8,9 +8,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
-	"runtime/debug"
-	"strconv"
 	"strings"
 	"time"
 
@@ -43,6 +40,54 @@ func c(w io.Writer, attrs ...color.Attribute) *color.Color {
 	return c
 }
 
+// Multiline controls how messages and string or error fields
+// that contain newlines are formatted.
+type Multiline int
+
+const (
+	// MultilineIndent prints the multiline value after the entry with
+	// every continuation line indented to line up with the first line.
+	// Only the message or the first multiline field is printed this way.
+	MultilineIndent Multiline = iota
+
+	// MultilineEscape escapes newlines so that every entry is
+	// on a single line.
+	MultilineEscape
+
+	// MultilineSplit prints every line of the multiline value as a
+	// separate entry with the same timestamp, level, names and location.
+	// Only the message or the first multiline field is printed this way.
+	MultilineSplit
+)
+
+// Options configures the format.
+//
+// The zero value is the default format.
+type Options struct {
+	Multiline Multiline
+	// ContinuationMarker is printed at the start of every line of a
+	// multiline value printed with MultilineIndent.
+	ContinuationMarker string
+	// Raw disables escaping control characters, ANSI sequences and
+	// invalid UTF-8 in the message, names, fields and multiline values.
+	Raw bool
+	// OmitTime and OmitLocation omit the timestamp and the location
+	// of the entry from the header.
+	OmitTime     bool
+	OmitLocation bool
+	// Since prints the timestamp as the duration since it, e.g.
+	// +12.345s, instead of the wall clock if it is not zero.
+	// See Relative.
+	Since time.Time
+	// Path and Func are the styles of the file and the function of the
+	// location. Default to slog.PathModule and slog.FuncShort.
+	Path slog.PathStyle
+	Func slog.FuncStyle
+	// Table formats slices and arrays of structs and maps as aligned
+	// tables printed like multiline values. Disabled if nil.
+	Table *TableOptions
+}
+
 // Fmt returns a human readable format for ent.
 //
 // We never return with a trailing newline because Go's testing framework adds one
@@ -51,35 +96,77 @@ func c(w io.Writer, attrs ...color.Attribute) *color.Color {
 // for extra lines in a log so if we did it here, the fields would be indented
 // twice in test logs. So the Stderr logger indents all the fields itself.
 func Fmt(w io.Writer, ent slog.SinkEntry) string {
-	ents := c(w, color.Reset).Sprint("")
-	ts := ent.Time.Format(TimeFormat)
-	ents += ts + " "
+	return FmtOpts(w, ent, nil)
+}
+
+// FmtOpts is like Fmt but formats ent according to opts.
+//
+// If opts is nil, the default format is used.
+func FmtOpts(w io.Writer, ent slog.SinkEntry, opts *Options) string {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var emph *slog.Emphasis
+	ent.Fields, emph = extractEmphasis(ent.Fields)
+
+	header := c(w, color.Reset).Sprint("")
+	if !opts.OmitTime {
+		ts := ent.Time.Format(TimeFormat)
+		if !opts.Since.IsZero() {
+			ts = formatSince(ent.Time.Sub(opts.Since))
+		}
+		header += ts + " "
+	}
 
 	level := "[" + ent.Level.String() + "]"
-	level = c(w, levelColor(ent.Level)).Sprint(level)
-	ents += fmt.Sprintf("%v\t", level)
+	levelAttr := levelColor(ent.Level)
+	if emph != nil {
+		if a, ok := emphColors[emph.Color]; ok {
+			levelAttr = a
+		}
+	}
+	level = c(w, levelAttr).Sprint(level)
+	header += fmt.Sprintf("%v\t", level)
 
 	if len(ent.LoggerNames) > 0 {
-		loggerName := "(" + quoteKey(strings.Join(ent.LoggerNames, ".")) + ")"
+		loggerName := "(" + quoteKey(strings.Join(ent.LoggerNames, "."), opts.Raw) + ")"
 		loggerName = c(w, color.FgMagenta).Sprint(loggerName)
-		ents += fmt.Sprintf("%v\t", loggerName)
+		header += fmt.Sprintf("%v\t", loggerName)
 	}
 
-	hpath, hfn := humanPathAndFunc(ent.File, ent.Func)
-	loc := fmt.Sprintf("<%v:%v>\t%v", hpath, ent.Line, hfn)
-	loc = c(w, color.FgCyan).Sprint(loc)
-	ents += fmt.Sprintf("%v\t", loc)
+	if !opts.OmitLocation {
+		hpath, hfn := humanPathAndFunc(ent.File, ent.Func, opts)
+		loc := fmt.Sprintf("<%v:%v>\t%v", hpath, ent.Line, hfn)
+		loc = c(w, color.FgCyan).Sprint(loc)
+		header += fmt.Sprintf("%v\t", loc)
+	}
+	ents := header
 
 	var multilineKey string
 	var multilineVal string
+	// multilineDiff reports whether multilineVal is a diff to colorize.
+	var multilineDiff bool
 	msg := strings.TrimSpace(ent.Message)
-	if strings.Contains(msg, "\n") {
+	if opts.Multiline != MultilineEscape && strings.Contains(msg, "\n") {
 		multilineKey = "msg"
 		multilineVal = msg
 		msg = "..."
+		if opts.Multiline == MultilineSplit {
+			msg = strings.SplitN(multilineVal, "\n", 2)[0]
+		}
+	}
+	msg = quote(msg, opts.Raw)
+	if emph != nil {
+		ents += emphasize(w, msg, *emph)
+	} else {
+		ents += msg
+	}
+
+	// Tags are written as the first fields.
+	if len(ent.Tags) > 0 {
+		ent.Fields = append(append(slog.Map(nil), ent.Tags...), ent.Fields...)
 	}
-	msg = quote(msg)
-	ents += msg
 
 	if ent.SpanContext != (trace.SpanContext{}) {
 		ent.Fields = append(slog.M(
@@ -89,58 +176,161 @@ func Fmt(w io.Writer, ent slog.SinkEntry) string {
 	}
 
 	for i, f := range ent.Fields {
-		if multilineVal != "" {
+		if multilineVal != "" || opts.Multiline == MultilineEscape {
 			break
 		}
 
 		var s string
+		var diff bool
 		switch v := f.Value.(type) {
 		case string:
 			s = v
+		case slog.Changes:
+			s = formatChanges(v)
+			diff = true
+		case multiError:
+			s = formatMultiError(v)
 		case error, xerrors.Formatter:
 			s = fmt.Sprintf("%+v", v)
+		default:
+			if opts.Table != nil {
+				s, _ = formatTable(v, opts.Table)
+			}
 		}
 		s = strings.TrimSpace(s)
 		if !strings.Contains(s, "\n") {
 			continue
 		}
 
-		// Remove this field.
-		ent.Fields = append(ent.Fields[:i], ent.Fields[i+1:]...)
+		// Remove this field without modifying the
+		// entry's fields as other sinks share them.
+		fields := make(slog.Map, 0, len(ent.Fields)-1)
+		fields = append(fields, ent.Fields[:i]...)
+		ent.Fields = append(fields, ent.Fields[i+1:]...)
 		multilineKey = f.Name
 		multilineVal = s
+		multilineDiff = diff
 	}
 
 	if len(ent.Fields) > 0 {
-		// No error is guaranteed due to slog.Map handling errors itself.
-		fields, _ := json.MarshalIndent(ent.Fields, "", "")
-		fields = bytes.ReplaceAll(fields, []byte(",\n"), []byte(", "))
-		fields = bytes.ReplaceAll(fields, []byte("\n"), []byte(""))
-		fields = formatJSON(w, fields)
-		ents += "\t" + string(fields)
+		ents += "\t" + formatFields(w, ent.Fields, opts.Raw)
+	}
+
+	if multilineVal == "" {
+		return ents
 	}
 
-	if multilineVal != "" {
-		if msg != "..." {
-			ents += " ..."
+	lines := strings.Split(multilineVal, "\n")
+	if opts.Multiline != MultilineSplit || multilineKey != "msg" {
+		// Split lines of the message are quoted instead.
+		multilineKey = escape(multilineKey, opts.Raw)
+		for i, line := range lines {
+			lines[i] = escape(line, opts.Raw)
+		}
+	}
+	if multilineDiff {
+		for i, line := range lines {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				lines[i] = c(w, color.FgRed).Sprint(line)
+			case strings.HasPrefix(line, "+"):
+				lines[i] = c(w, color.FgGreen).Sprint(line)
+			}
 		}
+	}
 
-		// Proper indentation.
-		lines := strings.Split(multilineVal, "\n")
-		for i, line := range lines[1:] {
-			if line != "" {
-				lines[i+1] = c(w, color.Reset).Sprint("") + strings.Repeat(" ", len(multilineKey)+4) + line
+	if opts.Multiline == MultilineSplit {
+		if multilineKey == "msg" {
+			// The first line is the message of the entry.
+			lines = lines[1:]
+		}
+		for _, line := range lines {
+			if multilineKey != "msg" {
+				line = c(w, color.FgBlue).Sprintf(`"%v"`, multilineKey) + ": " + line
+			} else {
+				line = quote(line, opts.Raw)
 			}
+			ents += "\n" + header + line
 		}
-		multilineVal = strings.Join(lines, "\n")
+		return ents
+	}
 
-		multilineKey = c(w, color.FgBlue).Sprintf(`"%v"`, multilineKey)
-		ents += fmt.Sprintf("\n%v: %v", multilineKey, multilineVal)
+	if msg != "..." {
+		ents += " ..."
 	}
 
+	// Proper indentation.
+	for i, line := range lines[1:] {
+		if line != "" {
+			lines[i+1] = c(w, color.Reset).Sprint("") + opts.ContinuationMarker + strings.Repeat(" ", len(multilineKey)+4) + line
+		}
+	}
+	multilineVal = strings.Join(lines, "\n")
+
+	multilineKey = c(w, color.FgBlue).Sprintf(`"%v"`, multilineKey)
+	ents += fmt.Sprintf("\n%v%v: %v", opts.ContinuationMarker, multilineKey, multilineVal)
+
 	return ents
 }
 
+// formatChanges formats changes as a diff with a line per removed
+// and added value.
+func formatChanges(changes slog.Changes) string {
+	var b strings.Builder
+	for _, ch := range changes {
+		var prefix string
+		if ch.Path != "" {
+			prefix = ch.Path + ": "
+		}
+		if ch.Op != "add" {
+			b.WriteString("- " + prefix + formatChange(ch.Old) + "\n")
+		}
+		if ch.Op != "remove" {
+			b.WriteString("+ " + prefix + formatChange(ch.New) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func formatChange(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+	return string(b)
+}
+
+// multiError is implemented by errors that wrap multiple errors,
+// e.g. those returned by errors.Join.
+type multiError interface {
+	error
+	Unwrap() []error
+}
+
+// formatMultiError formats the errors of err as a list of their chains
+// so that they are not concatenated.
+func formatMultiError(err multiError) string {
+	var b strings.Builder
+	for _, err := range err.Unwrap() {
+		var s string
+		if me, ok := err.(multiError); ok {
+			s = formatMultiError(me)
+		} else {
+			s = fmt.Sprintf("%+v", err)
+		}
+		for i, line := range strings.Split(strings.TrimSpace(s), "\n") {
+			if i == 0 {
+				b.WriteString("- ")
+			} else {
+				b.WriteString("  ")
+			}
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
 func levelColor(level slog.Level) color.Attribute {
 	switch level {
 	case slog.LevelDebug:
@@ -169,91 +359,28 @@ func isTTY(w io.Writer) bool {
 	return ok && terminal.IsTerminal(int(f.Fd()))
 }
 
+// shouldColor reports whether to color the output to w.
+// NO_COLOR disables colors for terminals and FORCE_COLOR enables
+// them for every writer. See https://no-color.org
 func shouldColor(w io.Writer) bool {
-	return isTTY(w) || os.Getenv("FORCE_COLOR") != ""
-}
-
-// quotes quotes a string so that it is suitable
-// as a key for a map or in general some output that
-// cannot span multiple lines or have weird characters.
-func quote(key string) string {
-	// strconv.Quote does not quote an empty string so we need this.
-	if key == "" {
-		return `""`
-	}
-
-	quoted := strconv.Quote(key)
-	// If the key doesn't need to be quoted, don't quote it.
-	// We do not use strconv.CanBackquote because it doesn't
-	// account tabs.
-	if quoted[1:len(quoted)-1] == key {
-		return key
-	}
-	return quoted
-}
-
-func quoteKey(key string) string {
-	// Replace spaces in the map keys with underscores.
-	return strings.ReplaceAll(key, " ", "_")
-}
-
-var mainPackagePath string
-var mainModulePath string
-
-func init() {
-	// Unfortunately does not work for tests yet :(
-	// See https://github.com/golang/go/issues/33976
-	bi, ok := debug.ReadBuildInfo()
-	if !ok {
-		return
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
 	}
-	mainPackagePath = bi.Path
-	mainModulePath = bi.Main.Path
+	return os.Getenv("NO_COLOR") == "" && isTTY(w)
 }
 
-// humanPathAndFunc takes the absolute path to a file and an absolute module path to a
-// function in that file and returns the module path to the file. It also returns
-// the path to the function stripped of its module prefix.
-//
-// If the file is in the main Go module then its path is returned
-// relative to the main Go module's root.
+// humanPathAndFunc returns the path of filename and the name of the
+// function fn in the styles of opts, slog.PathModule and slog.FuncShort
+// by default.
 //
 // fn is from https://pkg.go.dev/runtime#Func.Name
-func humanPathAndFunc(filename, fn string) (hpath, hfn string) {
-	// pkgDir is the dir of the pkg.
-	//   e.g. cdr.dev/slog/internal
-	// base is the package name and the function name separated by a period.
-	//   e.g. entryhuman.humanPathAndFunc
-	// There can be multiple periods when methods of types are involved.
-	pkgDir, base := filepath.Split(fn)
-	s := strings.Split(base, ".")
-	pkg := s[0]
-	hfn = strings.Join(s[1:], ".")
-
-	if pkg == "main" {
-		// This happens with go build main.go
-		if mainPackagePath == "command-line-arguments" {
-			// Without a real mainPath, we can't find the path to the file
-			// relative to the module. So we just return the base.
-			return filepath.Base(filename), hfn
-		}
-		// Go doesn't return the full main path in runtime.Func.Name()
-		// It just returns the path "main"
-		// Only runtime.ReadBuildInfo returns it so we have to check and replace.
-		pkgDir = mainPackagePath
-		// pkg main isn't reflected on the disk so we should not add it
-		// into the pkgpath.
-		pkg = ""
+func humanPathAndFunc(filename, fn string, opts *Options) (hpath, hfn string) {
+	pathStyle, funcStyle := opts.Path, opts.Func
+	if pathStyle == slog.PathDefault {
+		pathStyle = slog.PathModule
 	}
-
-	hpath = filepath.Join(pkgDir, pkg, filepath.Base(filename))
-
-	if mainModulePath != "" {
-		relhpath, err := filepath.Rel(mainModulePath, hpath)
-		if err == nil {
-			hpath = "./" + relhpath
-		}
+	if funcStyle == slog.FuncDefault {
+		funcStyle = slog.FuncShort
 	}
-
-	return hpath, hfn
+	return slog.TrimPath(filename, fn, pathStyle), slog.TrimFunc(fn, funcStyle)
 }
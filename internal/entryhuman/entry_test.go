@@ -1,7 +1,9 @@
 package entryhuman_test
 
 import (
+	"errors"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
@@ -91,4 +93,194 @@ func TestEntry(t *testing.T) {
 		})
 		assert.Equal(t, "entry", "\x1b[0m\x1b[0m0001-01-01 00:00:00.000 \x1b[91m[CRITICAL]\x1b[0m\t\x1b[36m<.:0>	\x1b[0m\t\"\"\t{\x1b[34m\"hey\"\x1b[0m: \x1b[32m\"hi\"\x1b[0m}", act)
 	})
+
+	t.Run("multilineEscape", func(t *testing.T) {
+		t.Parallel()
+
+		act := entryhuman.FmtOpts(ioutil.Discard, slog.SinkEntry{
+			Message: "line1\nline2",
+			Fields:  slog.M(slog.F("field", "line3\nline4")),
+		}, &entryhuman.Options{
+			Multiline: entryhuman.MultilineEscape,
+		})
+		assert.False(t, "newline", strings.Contains(act, "\n"))
+		assert.True(t, "message", strings.HasSuffix(act, `	"line1\nline2"	{"field": "line3\nline4"}`))
+	})
+
+	t.Run("multilineSplit", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &entryhuman.Options{
+			Multiline: entryhuman.MultilineSplit,
+		}
+		ent := slog.SinkEntry{
+			Message: "line1\nline2",
+			Fields:  slog.M(slog.F("hey", "hi")),
+		}
+		act := entryhuman.FmtOpts(ioutil.Discard, ent, opts)
+
+		ent1 := ent
+		ent1.Message = "line1"
+		ent2 := ent
+		ent2.Message = "line2"
+		ent2.Fields = nil
+		exp := entryhuman.Fmt(ioutil.Discard, ent1) + "\n" + entryhuman.Fmt(ioutil.Discard, ent2)
+		assert.Equal(t, "entry", exp, act)
+
+		fields := slog.M(slog.F("field", "line1\nline2"))
+		act = entryhuman.FmtOpts(ioutil.Discard, slog.SinkEntry{
+			Message: "msg",
+			Fields:  fields,
+		}, opts)
+		header := strings.TrimSuffix(entryhuman.Fmt(ioutil.Discard, slog.SinkEntry{}), `""`)
+		exp = header + "msg\n" + header + `"field": line1` + "\n" + header + `"field": line2`
+		assert.Equal(t, "entry", exp, act)
+		assert.Equal(t, "fields", slog.M(slog.F("field", "line1\nline2")), fields)
+	})
+
+	t.Run("continuationMarker", func(t *testing.T) {
+		t.Parallel()
+
+		act := entryhuman.FmtOpts(ioutil.Discard, slog.SinkEntry{
+			Message: "line1\nline2",
+		}, &entryhuman.Options{
+			ContinuationMarker: "| ",
+		})
+		lines := strings.Split(act, "\n")
+		assert.Equal(t, "lines", []string{
+			`"msg": line1`,
+			"       line2",
+		}, []string{
+			strings.TrimPrefix(lines[1], "| "),
+			strings.TrimPrefix(lines[2], "| "),
+		})
+		assert.True(t, "marker", strings.HasPrefix(lines[1], "| ") && strings.HasPrefix(lines[2], "| "))
+	})
+
+	t.Run("multiError", func(t *testing.T) {
+		t.Parallel()
+
+		err := joinError{
+			errors.New("first"),
+			joinError{errors.New("second"), errors.New("third\nline")},
+		}
+		act := entryhuman.Fmt(ioutil.Discard, slog.SinkEntry{
+			Message: "msg",
+			Fields:  slog.M(slog.F("errors", err)),
+		})
+		lines := strings.Split(act, "\n")
+		assert.Equal(t, "lines", []string{
+			`"errors": - first`,
+			"          - - second",
+			"            - third",
+			"              line",
+		}, lines[1:])
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		t.Parallel()
+
+		act := entryhuman.Fmt(ioutil.Discard, slog.SinkEntry{
+			Message: "reloaded",
+			Fields: slog.M(slog.Diff("config",
+				slog.M(slog.F("port", 80), slog.F("peers", []string{"a", "b"})),
+				slog.M(slog.F("port", 8080), slog.F("peers", []string{"a"}), slog.F("debug", true)),
+			)),
+		})
+		lines := strings.Split(act, "\n")
+		assert.Equal(t, "lines", []string{
+			`"config": - port: 80`,
+			"          + port: 8080",
+			`          - peers.1: "b"`,
+			"          + debug: true",
+		}, lines[1:])
+	})
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+
+		type peer struct {
+			Name string `json:"name"`
+			Addr string `json:"addr"`
+			Up   bool   `json:"up"`
+		}
+		act := entryhuman.FmtOpts(ioutil.Discard, slog.SinkEntry{
+			Message: "peers",
+			Fields: slog.M(
+				slog.F("peers", []peer{
+					{Name: "alice", Addr: "10.0.0.1", Up: true},
+					{Name: "carol\nmallory", Addr: "10.0.0.3"},
+					{Name: "bob", Addr: "10.0.0.10"},
+				}),
+				slog.F("ids", []int{1, 2}),
+			),
+		}, &entryhuman.Options{
+			Table: &entryhuman.TableOptions{MaxRows: 2, MaxWidth: 8},
+		})
+		lines := strings.Split(act, "\n")
+		assert.Equal(t, "first line", `peers	{"ids": [1, 2]} ...`, lines[0][strings.LastIndex(lines[0], "peers"):])
+		assert.Equal(t, "lines", []string{
+			`"peers": name      addr      up`,
+			"         alice     10.0.0.1  true",
+			`         carol\n…  10.0.0.3  false`,
+			"         ... 1 more rows",
+		}, lines[1:])
+	})
+}
+
+func TestEmph(t *testing.T) {
+	t.Parallel()
+
+	ent := slog.SinkEntry{
+		Message: "step 2",
+		Fields: slog.M(
+			slog.F("a", 1),
+			slog.Highlight(slog.F("state", "running")),
+			slog.Emph(),
+		),
+	}
+	opts := &entryhuman.Options{OmitTime: true, OmitLocation: true}
+	act := entryhuman.FmtOpts(ioutil.Discard, ent, opts)
+	assert.Equal(t, "plain", `[DEBUG]	**step 2**	{"a": 1, **"state": "running"**}`, act)
+
+	ent.Fields = slog.M(slog.F("emph", slog.Emphasis{Color: "green"}))
+	act = entryhuman.FmtOpts(entryhuman.ForceColorWriter, ent, opts)
+	assert.Equal(t, "color", "\x1b[0m\x1b[0m\x1b[32m[DEBUG]\x1b[0m\t\x1b[32mstep 2\x1b[0m", act)
+}
+
+func TestRelative(t *testing.T) {
+	t.Parallel()
+
+	ent := slog.SinkEntry{
+		Time:    kt.Add(12345 * time.Millisecond),
+		Message: "hi",
+	}
+	act := entryhuman.FmtOpts(ioutil.Discard, ent, &entryhuman.Options{
+		OmitLocation: true,
+		Since:        kt,
+	})
+	assert.Equal(t, "since", `+12.345s [DEBUG]	hi`, act)
+
+	r := &entryhuman.Relative{}
+	assert.Equal(t, "start", entryhuman.ProcessStart, r.Since(kt))
+
+	r = &entryhuman.Relative{Previous: true}
+	assert.Equal(t, "first", kt, r.Since(kt))
+	assert.Equal(t, "previous", kt, r.Since(kt.Add(time.Second)))
+	assert.Equal(t, "next", kt.Add(time.Second), r.Since(kt.Add(3*time.Second)))
+}
+
+// joinError wraps multiple errors like those of errors.Join.
+type joinError []error
+
+func (e joinError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e joinError) Unwrap() []error {
+	return e
 }
@@ -0,0 +1,110 @@
+package entryhuman
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// quote quotes a string so that it is suitable
+// as a key for a map or in general some output that
+// cannot span multiple lines or have weird characters.
+//
+// Control characters, including the escape character that starts ANSI
+// sequences, other unprintable characters such as bidirectional overrides
+// and invalid UTF-8 are escaped so that user provided strings cannot
+// forge entries or control the terminal.
+//
+// If raw is set, key is returned as is.
+func quote(key string, raw bool) string {
+	// strconv.Quote does not quote an empty string so we need this.
+	if key == "" {
+		return `""`
+	}
+	if raw {
+		return key
+	}
+
+	quoted := strconv.Quote(key)
+	// If the key doesn't need to be quoted, don't quote it.
+	// We do not use strconv.CanBackquote because it doesn't
+	// account tabs.
+	if quoted[1:len(quoted)-1] == key {
+		return key
+	}
+	return quoted
+}
+
+// quoteKey is like quote but replaces spaces with underscores
+// so that the key is a single word.
+func quoteKey(key string, raw bool) string {
+	key = strings.ReplaceAll(key, " ", "_")
+	if key == "" {
+		return key
+	}
+	return quote(key, raw)
+}
+
+// escape escapes the characters that quote escapes in a line of
+// a multiline value or in the JSON of the fields, except for tabs,
+// quotes and backslashes which are left as is.
+//
+// Unprintable characters become JSON compatible \u escapes so that
+// escaping JSON keeps it valid. Invalid UTF-8 becomes \x escapes.
+//
+// If raw is set, s is returned as is.
+func escape(s string, raw bool) string {
+	if raw || !needsEscape(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 16)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteString(`\x`)
+			writeHex(&b, uint32(s[i]), 2)
+		case printable(r):
+			b.WriteString(s[i : i+size])
+		case r > 0xffff:
+			r1, r2 := utf16.EncodeRune(r)
+			writeU(&b, r1)
+			writeU(&b, r2)
+		default:
+			writeU(&b, r)
+		}
+		i += size
+	}
+	return b.String()
+}
+
+func needsEscape(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || !printable(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func printable(r rune) bool {
+	return r == '\t' || unicode.IsPrint(r)
+}
+
+func writeU(b *strings.Builder, r rune) {
+	b.WriteString(`\u`)
+	writeHex(b, uint32(r), 4)
+}
+
+func writeHex(b *strings.Builder, v uint32, digits int) {
+	const hex = "0123456789abcdef"
+	for i := digits - 1; i >= 0; i-- {
+		b.WriteByte(hex[(v>>(uint(i)*4))&0xf])
+	}
+}
@@ -0,0 +1,75 @@
+package entryhuman_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/internal/entryhuman"
+)
+
+func TestEscape(t *testing.T) {
+	t.Parallel()
+
+	header := strings.TrimSuffix(entryhuman.Fmt(ioutil.Discard, slog.SinkEntry{}), `""`)
+	test := func(t *testing.T, ent slog.SinkEntry, opts *entryhuman.Options, exp string) {
+		t.Helper()
+		act := entryhuman.FmtOpts(ioutil.Discard, ent, opts)
+		assert.Equal(t, "entry", exp, strings.TrimPrefix(act, header))
+	}
+
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.SinkEntry{Message: "\x1b[2Jcleared\r"}, nil, `"\x1b[2Jcleared"`)
+		test(t, slog.SinkEntry{Message: "bad \xff utf8"}, nil, `"bad \xff utf8"`)
+		test(t, slog.SinkEntry{Message: "evil\u202etxt.exe"}, nil, `"evil\u202etxt.exe"`)
+		test(t, slog.SinkEntry{Message: "héllo wörld"}, nil, `héllo wörld`)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.SinkEntry{
+			Message: "msg",
+			Fields:  slog.M(slog.F("k", "a\x7fb\u202ec"), slog.F("\x1b", "\U000e0001")),
+		}, nil, `msg	{"k": "a\u007fb\u202ec", "\u001b": "\udb40\udc01"}`)
+	})
+
+	t.Run("multiline", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.SinkEntry{
+			Message: "line1\n\x1b[31mline2\t\"x\"",
+		}, nil, "...\n\"msg\": line1\n       \\u001b[31mline2\t\"x\"")
+	})
+
+	t.Run("multilineSplit", func(t *testing.T) {
+		t.Parallel()
+
+		act := entryhuman.FmtOpts(ioutil.Discard, slog.SinkEntry{
+			Message: "line1\n\x1b[31mline2",
+		}, &entryhuman.Options{Multiline: entryhuman.MultilineSplit})
+		assert.Equal(t, "entry", header+"line1\n"+header+`"\x1b[31mline2"`, act)
+	})
+
+	t.Run("names", func(t *testing.T) {
+		t.Parallel()
+
+		act := entryhuman.Fmt(ioutil.Discard, slog.SinkEntry{
+			LoggerNames: []string{"a b", "c\nd"},
+		})
+		assert.True(t, "quoted", strings.Contains(act, `("a_b.c\nd")`))
+	})
+
+	t.Run("raw", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.SinkEntry{
+			Message: "\x1b[1mbold",
+			Fields:  slog.M(slog.F("k", "\u202e")),
+		}, &entryhuman.Options{Raw: true}, "\x1b[1mbold\t{\"k\": \"\u202e\"}")
+	})
+}
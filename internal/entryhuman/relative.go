@@ -0,0 +1,46 @@
+package entryhuman
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ProcessStart is the time the process started, approximately.
+var ProcessStart = time.Now()
+
+// formatSince formats d like +12.345s.
+func formatSince(d time.Duration) string {
+	s := strconv.FormatFloat(d.Seconds(), 'f', 3, 64) + "s"
+	if d >= 0 {
+		s = "+" + s
+	}
+	return s
+}
+
+// Relative returns the times that relative timestamps are printed since,
+// see Options.Since.
+type Relative struct {
+	// Previous prints the timestamps since the previous entry
+	// instead of since ProcessStart.
+	Previous bool
+
+	mu   sync.Mutex
+	prev time.Time
+}
+
+// Since returns the time to print the timestamp t since. The first
+// entry is printed since itself if r.Previous is set.
+func (r *Relative) Since(t time.Time) time.Time {
+	if !r.Previous {
+		return ProcessStart
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := r.prev
+	if prev.IsZero() {
+		prev = t
+	}
+	r.prev = t
+	return prev
+}
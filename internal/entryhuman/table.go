@@ -0,0 +1,155 @@
+package entryhuman
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"cdr.dev/slog"
+)
+
+// TableOptions configures the tables of slices of structs and maps.
+type TableOptions struct {
+	// MaxRows is the number of rows after which the rest are
+	// summarized. Defaults to 20.
+	MaxRows int
+	// MaxWidth is the width after which the values of a column
+	// are truncated. Defaults to 40.
+	MaxWidth int
+}
+
+var mapType = reflect.TypeOf(slog.Map(nil))
+
+// formatTable formats v as an aligned table with a column per field if
+// it is a non empty slice or array of structs or maps. The columns are in
+// the order of the first appearance of their fields.
+func formatTable(v interface{}, opts *TableOptions) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Len() == 0 {
+		return "", false
+	}
+	if _, ok := v.(slog.Map); ok {
+		return "", false
+	}
+	elem := rv.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	switch elem.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+	default:
+		if elem != mapType {
+			return "", false
+		}
+	}
+
+	// The rows are decoded from their JSON so that the table has the
+	// same fields and values as the other encodings.
+	b, err := json.Marshal(slog.M(slog.F("v", v)))
+	if err != nil {
+		return "", false
+	}
+	var m slog.Map
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return "", false
+	}
+	rows, ok := m[0].Value.([]interface{})
+	if !ok {
+		return "", false
+	}
+
+	var columns []string
+	index := make(map[string]int)
+	for _, row := range rows {
+		row, ok := row.(slog.Map)
+		if !ok {
+			return "", false
+		}
+		for _, f := range row {
+			if _, ok := index[f.Name]; !ok {
+				index[f.Name] = len(columns)
+				columns = append(columns, f.Name)
+			}
+		}
+	}
+
+	maxRows := opts.MaxRows
+	if maxRows <= 0 {
+		maxRows = 20
+	}
+	maxWidth := opts.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = 40
+	}
+
+	more := 0
+	if len(rows) > maxRows {
+		more = len(rows) - maxRows
+		rows = rows[:maxRows]
+	}
+
+	cells := make([][]string, 0, len(rows)+1)
+	cells = append(cells, columns)
+	for _, row := range rows {
+		r := make([]string, len(columns))
+		for _, f := range row.(slog.Map) {
+			r[index[f.Name]] = formatCell(f.Value, maxWidth)
+		}
+		cells = append(cells, r)
+	}
+
+	widths := make([]int, len(columns))
+	for _, r := range cells {
+		for i, cell := range r {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for _, r := range cells {
+		var line strings.Builder
+		for i, cell := range r {
+			line.WriteString(cell)
+			if i < len(r)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		sb.WriteString(strings.TrimRight(line.String(), " "))
+		sb.WriteByte('\n')
+	}
+	if more > 0 {
+		fmt.Fprintf(&sb, "... %v more rows\n", more)
+	}
+	return sb.String(), true
+}
+
+// formatCell formats a value of a table. Strings are written as is
+// and other values as JSON. Newlines are escaped to keep the rows on
+// a line.
+func formatCell(v interface{}, maxWidth int) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+	case string:
+		s = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprintf("%+v", v)
+		} else {
+			s = string(b)
+		}
+	}
+	s = strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+
+	if utf8.RuneCountInString(s) > maxWidth {
+		r := []rune(s)
+		s = string(r[:maxWidth-1]) + "…"
+	}
+	return s
+}
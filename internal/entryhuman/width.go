@@ -0,0 +1,166 @@
+package entryhuman
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// TerminalWidth returns the width of the terminal w writes to or 0 if
+// it is not a terminal. The COLUMNS environment variable overrides it.
+func TerminalWidth(w io.Writer) int {
+	f, ok := w.(interface {
+		Fd() uintptr
+	})
+	if !ok || !terminal.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	width, _, err := terminal.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// tabWidth is the distance between the tab stops of terminals.
+const tabWidth = 8
+
+// wrapIndent is the indent of the continuation lines of wrapped lines.
+const wrapIndent = "  "
+
+// FitWidth truncates or, if wrap is set, wraps the lines of s that are
+// wider than width columns. Truncated lines end with an ellipsis and
+// the number of columns cut, e.g. "…(+42)". Wrapped lines continue on
+// lines indented by two spaces.
+//
+// ANSI escape sequences do not count towards the width and tabs count
+// up to the next tab stop.
+func FitWidth(s string, width int, wrap bool) string {
+	if width <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if visibleWidth(line) <= width {
+			continue
+		}
+		if wrap {
+			lines[i] = wrapLine(line, width)
+		} else {
+			lines[i] = truncateLine(line, width)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scanLine calls fn with every escape sequence and rune of line and the
+// column the rune ends at. It stops when fn returns false.
+func scanLine(line string, fn func(s string, escape bool, end int) bool) {
+	col := 0
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			if !fn(line[i:i+n], true, col) {
+				return
+			}
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if r == '\t' {
+			col = (col/tabWidth + 1) * tabWidth
+		} else {
+			col++
+		}
+		if !fn(line[i:i+size], false, col) {
+			return
+		}
+		i += size
+	}
+}
+
+// escapeLen returns the length of the ANSI escape sequence at the
+// start of s or 0 if there is none.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func visibleWidth(line string) int {
+	width := 0
+	scanLine(line, func(_ string, _ bool, end int) bool {
+		width = end
+		return true
+	})
+	return width
+}
+
+func truncateLine(line string, width int) string {
+	total := visibleWidth(line)
+	// The number of cut columns has at most as many digits as total.
+	avail := width - utf8.RuneCountInString("…(+"+strconv.Itoa(total)+")")
+
+	var b strings.Builder
+	var colored bool
+	col := 0
+	scanLine(line, func(s string, escape bool, end int) bool {
+		if escape {
+			colored = true
+			b.WriteString(s)
+			return true
+		}
+		if end > avail {
+			return false
+		}
+		b.WriteString(s)
+		col = end
+		return true
+	})
+	b.WriteString("…(+" + strconv.Itoa(total-col) + ")")
+	if colored {
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+func wrapLine(line string, width int) string {
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(line); {
+		if n := escapeLen(line[i:]); n > 0 {
+			b.WriteString(line[i : i+n])
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		end := col + 1
+		if r == '\t' {
+			end = (col/tabWidth + 1) * tabWidth
+		}
+		if end > width && col > len(wrapIndent) {
+			b.WriteString("\n" + wrapIndent)
+			col = len(wrapIndent)
+			end = col + 1
+			if r == '\t' {
+				end = (col/tabWidth + 1) * tabWidth
+			}
+		}
+		b.WriteString(line[i : i+size])
+		col = end
+		i += size
+	}
+	return b.String()
+}
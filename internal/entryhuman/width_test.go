@@ -0,0 +1,28 @@
+package entryhuman_test
+
+import (
+	"bytes"
+	"testing"
+
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/internal/entryhuman"
+)
+
+func TestFitWidth(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "fits", "short\nlines", entryhuman.FitWidth("short\nlines", 5, false))
+	assert.Equal(t, "disabled", "long line", entryhuman.FitWidth("long line", 0, false))
+	assert.Equal(t, "truncate", "0123…(+12)\nok", entryhuman.FitWidth("0123456789abcdef\nok", 10, false))
+	assert.Equal(t, "tabs", "a…(+9)", entryhuman.FitWidth("a\tbc", 8, false))
+	assert.Equal(t, "colored", "\x1b[31m01…(+8)\x1b[0m", entryhuman.FitWidth("\x1b[31m0123456789\x1b[0m", 8, false))
+
+	assert.Equal(t, "wrap", "012345\n  6789\n  ab", entryhuman.FitWidth("0123456789ab", 6, true))
+	assert.Equal(t, "wrap colored", "\x1b[31m0123\n  45\x1b[0m", entryhuman.FitWidth("\x1b[31m012345\x1b[0m", 4, true))
+}
+
+func TestTerminalWidth(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "buffer", 0, entryhuman.TerminalWidth(&bytes.Buffer{}))
+}
@@ -0,0 +1,54 @@
+// Package exportctx creates the contexts that sinks export entries with.
+package exportctx
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout returns a context for exporting an entry logged with ctx
+// that is done after timeout or when ctx is done, so that a slow backend
+// does not block the caller for longer than either allows. A timeout of
+// zero or less does not limit the time.
+//
+// If ctx is already done, e.g. the entry is the error of a canceled
+// request, only its values are kept so that the entry is still exported
+// within timeout.
+func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if ctx.Err() != nil {
+		ctx = valuesOnly{ctx}
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Deadline returns the earlier of the time after timeout and the
+// deadline of ctx if it is not done, e.g. for the write deadline of a
+// connection.
+func Deadline(ctx context.Context, timeout time.Duration) time.Time {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && ctx.Err() == nil && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+// valuesOnly is a context with the values of a done context
+// that is never done.
+type valuesOnly struct {
+	context.Context
+}
+
+func (valuesOnly) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (valuesOnly) Done() <-chan struct{} {
+	return nil
+}
+
+func (valuesOnly) Err() error {
+	return nil
+}
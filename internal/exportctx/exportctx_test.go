@@ -0,0 +1,46 @@
+package exportctx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cdr.dev/slog/internal/assert"
+)
+
+type key struct{}
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	ctx, cancel2 := WithTimeout(parent, time.Hour)
+	defer cancel2()
+	cancel()
+	<-ctx.Done()
+	assert.Equal(t, "err", context.Canceled, ctx.Err())
+
+	// Done contexts only keep their values.
+	ctx, cancel3 := WithTimeout(parent, time.Hour)
+	defer cancel3()
+	assert.Success(t, "err", ctx.Err())
+	assert.Equal(t, "value", "v", ctx.Value(key{}))
+	deadline, ok := ctx.Deadline()
+	assert.True(t, "deadline", ok && time.Until(deadline) > 59*time.Minute)
+}
+
+func TestDeadline(t *testing.T) {
+	t.Parallel()
+
+	d := Deadline(context.Background(), time.Second)
+	assert.True(t, "timeout", time.Until(d) > 900*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	d = Deadline(ctx, time.Second)
+	assert.True(t, "ctx", time.Until(d) <= 10*time.Millisecond)
+
+	<-ctx.Done()
+	d = Deadline(ctx, time.Second)
+	assert.True(t, "done", time.Until(d) > 900*time.Millisecond)
+}
@@ -0,0 +1,23 @@
+// Package goid parses the ID of the current goroutine.
+package goid
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+)
+
+var prefix = []byte("goroutine ")
+
+// ID parses the ID of the current goroutine
+// from the header of its stack trace.
+func ID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, prefix)
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
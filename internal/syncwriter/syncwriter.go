@@ -55,6 +55,9 @@ func (w *Writer) Sync(sinkName string) {
 		return
 	}
 	err := s.Sync()
+	if err == nil {
+		return
+	}
 	if _, ok := w.w.(*os.File); ok {
 		// Opened files do not necessarily support syncing.
 		// E.g. stdout and stderr both do not so we need
@@ -51,6 +51,18 @@ func TestWriter_Sync(t *testing.T) {
 		assert.Equal(t, "errors", 2, tw.errors)
 	})
 
+	t.Run("syncSuccess", func(t *testing.T) {
+		t.Parallel()
+
+		tw := newWriter(syncWriter{
+			sf: func() error {
+				return nil
+			},
+		})
+		tw.w.Sync("test")
+		assert.Equal(t, "errors", 0, tw.errors)
+	})
+
 	t.Run("stdout", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,128 @@
+package slog
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// SnakeCasePattern matches snake_case keys such as http_status.
+var SnakeCasePattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// KeyOptions represents the options for the sink returned by CheckKeys.
+type KeyOptions struct {
+	// Pattern is the pattern field keys must match.
+	// Defaults to SnakeCasePattern.
+	Pattern *regexp.Regexp
+	// Convert converts every key with SnakeCase before it is checked.
+	Convert bool
+}
+
+// CheckKeys returns a sink that logs entries to s and warns about
+// field keys that do not match a naming convention. Keys of nested
+// maps are checked as well and reported joined with a period.
+//
+// The warning is logged to s after the entry at LevelWarn with
+// the location of the entry and the invalid keys in the keys field.
+//
+// If opts is nil, the defaults are used.
+func CheckKeys(s Sink, opts *KeyOptions) Sink {
+	o := KeyOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Pattern == nil {
+		o.Pattern = SnakeCasePattern
+	}
+	return keySink{s: s, opts: &o}
+}
+
+type keySink struct {
+	s    Sink
+	opts *KeyOptions
+}
+
+func (s keySink) LogEntry(ctx context.Context, ent SinkEntry) {
+	if s.opts.Convert {
+		ent.Fields = convertKeys(ent.Fields)
+	}
+	s.s.LogEntry(ctx, ent)
+
+	invalid := InvalidKeys(ent.Fields, s.opts.Pattern)
+	if len(invalid) > 0 {
+		s.s.LogEntry(ctx, warning(ent, "slog: invalid field keys", F("keys", invalid)))
+	}
+}
+
+func (s keySink) Sync() {
+	s.s.Sync()
+}
+
+func (s keySink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
+
+// InvalidKeys returns the keys in m, including those of nested maps
+// joined with a period, that do not match pattern.
+func InvalidKeys(m Map, pattern *regexp.Regexp) []string {
+	return appendInvalidKeys(nil, "", m, pattern)
+}
+
+func appendInvalidKeys(invalid []string, prefix string, m Map, pattern *regexp.Regexp) []string {
+	for _, f := range m {
+		if !pattern.MatchString(f.Name) {
+			invalid = append(invalid, prefix+f.Name)
+		}
+		if m2, ok := f.Value.(Map); ok {
+			invalid = appendInvalidKeys(invalid, prefix+f.Name+".", m2, pattern)
+		}
+	}
+	return invalid
+}
+
+func convertKeys(m Map) Map {
+	m2 := make(Map, len(m))
+	for i, f := range m {
+		if v, ok := f.Value.(Map); ok {
+			f.Value = convertKeys(v)
+		}
+		f.Name = SnakeCase(f.Name)
+		m2[i] = f
+	}
+	return m2
+}
+
+// SnakeCase converts a camelCase, PascalCase, kebab-case or space
+// separated key to snake_case. Acronyms are kept together,
+// e.g. HTTPStatusCode becomes http_status_code.
+func SnakeCase(key string) string {
+	rs := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+
+	underscore := func() {
+		s := b.String()
+		if len(s) > 0 && s[len(s)-1] != '_' {
+			b.WriteByte('_')
+		}
+	}
+	for i, r := range rs {
+		switch {
+		case r == '-' || r == ' ' || r == '_':
+			underscore()
+		case unicode.IsUpper(r):
+			if i > 0 {
+				prev := rs[i-1]
+				nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					underscore()
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
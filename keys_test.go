@@ -0,0 +1,51 @@
+package slog_test
+
+import (
+	"regexp"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	t.Parallel()
+
+	for in, exp := range map[string]string{
+		"userID":         "user_id",
+		"HTTPStatusCode": "http_status_code",
+		"already_snake":  "already_snake",
+		"kebab-case key": "kebab_case_key",
+		"_private":       "private",
+		"v2Name":         "v2_name",
+		"ID":             "id",
+	} {
+		assert.Equal(t, in, exp, slog.SnakeCase(in))
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.CheckKeys(s, nil))
+	l.Info(bg, "msg", slog.F("userID", 1), slog.F("ok", slog.M(slog.F("Bad Key", 2))))
+
+	assert.Len(t, "entries", 2, s.entries)
+	assert.Equal(t, "keys", slog.M(slog.F("keys", []string{"userID", "ok.Bad Key"})), s.entries[1].Fields)
+
+	s = &fakeSink{}
+	l = slog.Make(slog.CheckKeys(s, &slog.KeyOptions{
+		Convert: true,
+	}))
+	l.Info(bg, "msg", slog.F("userID", 1), slog.F("ok", slog.M(slog.F("Bad Key", 2))))
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "converted", slog.M(slog.F("user_id", 1), slog.F("ok", slog.M(slog.F("bad_key", 2)))), s.entries[0].Fields)
+
+	s = &fakeSink{}
+	l = slog.Make(slog.CheckKeys(s, &slog.KeyOptions{
+		Pattern: regexp.MustCompile(`^[a-z][a-zA-Z]*$`),
+	}))
+	l.Info(bg, "msg", slog.F("userId", 1))
+	assert.Len(t, "custom pattern", 1, s.entries)
+}
@@ -0,0 +1,158 @@
+package slog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StartInfo describes a starting service.
+type StartInfo struct {
+	// Version is the version of the service.
+	Version string
+	// Config is the configuration of the service. Only its hash is
+	// logged so that instances with different configurations can be
+	// told apart without logging secrets.
+	Config interface{}
+	// Addrs are the addresses the service listens on.
+	Addrs []string
+	// Fields are logged in addition to the standard fields.
+	Fields Map
+}
+
+// LifecycleLogger logs the start and stop of a service.
+//
+// See Lifecycle.
+type LifecycleLogger struct {
+	l Logger
+
+	mu      sync.Mutex
+	started time.Time
+	stopped bool
+	tasks   map[string]int
+}
+
+// Lifecycle returns a logger of the start and stop of the service that
+// logs to l so that every service logs them consistently.
+//
+// The entries are logged at LevelInfo with the messages "service started"
+// and "service stopped" and a lifecycle field of "start" or "stop".
+func Lifecycle(l Logger) *LifecycleLogger {
+	return &LifecycleLogger{
+		l:     l,
+		tasks: make(map[string]int),
+	}
+}
+
+// Start logs that the service started with the version, the SHA-256 of
+// the JSON of the config, the listen addresses, the process ID and the
+// Go version.
+func (ll *LifecycleLogger) Start(ctx context.Context, info StartInfo) {
+	Helper()
+
+	ll.mu.Lock()
+	ll.started = time.Now()
+	ll.stopped = false
+	ll.mu.Unlock()
+
+	fields := M(
+		F("lifecycle", "start"),
+		F("version", info.Version),
+	)
+	if info.Config != nil {
+		sum := sha256.Sum256(encode(info.Config))
+		fields = append(fields, F("config_hash", hex.EncodeToString(sum[:])))
+	}
+	if len(info.Addrs) > 0 {
+		fields = append(fields, F("addrs", info.Addrs))
+	}
+	fields = append(fields,
+		F("pid", os.Getpid()),
+		F("go_version", runtime.Version()),
+	)
+	ll.l.Info(ctx, "service started", append(fields, info.Fields...)...)
+}
+
+// Task registers a task that must complete before the service can stop
+// cleanly, e.g. an in flight request or a background job. The names of
+// the tasks still pending when the service stops are logged.
+//
+// Call done when the task completes.
+func (ll *LifecycleLogger) Task(name string) (done func()) {
+	ll.mu.Lock()
+	ll.tasks[name]++
+	ll.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ll.mu.Lock()
+			defer ll.mu.Unlock()
+			ll.tasks[name]--
+			if ll.tasks[name] == 0 {
+				delete(ll.tasks, name)
+			}
+		})
+	}
+}
+
+// Stop logs that the service stopped for reason with its uptime and
+// the pending tasks. Only the first call after Start logs.
+func (ll *LifecycleLogger) Stop(ctx context.Context, reason string, fields ...Field) {
+	Helper()
+
+	ll.mu.Lock()
+	if ll.stopped {
+		ll.mu.Unlock()
+		return
+	}
+	ll.stopped = true
+	var uptime time.Duration
+	if !ll.started.IsZero() {
+		uptime = time.Since(ll.started)
+	}
+	pending := make([]string, 0, len(ll.tasks))
+	for name, n := range ll.tasks {
+		for i := 0; i < n; i++ {
+			pending = append(pending, name)
+		}
+	}
+	ll.mu.Unlock()
+	sort.Strings(pending)
+
+	ll.l.Info(ctx, "service stopped", append(M(
+		F("lifecycle", "stop"),
+		F("reason", reason),
+		F("uptime", uptime),
+		F("pending_tasks", pending),
+	), fields...)...)
+}
+
+// StopOnDone logs that the service stopped with the error of ctx as the
+// reason once ctx is done, e.g. the context canceled on SIGTERM.
+//
+// Call stop to stop waiting, usually with defer if the service
+// calls Stop itself.
+func (ll *LifecycleLogger) StopOnDone(ctx context.Context) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			// ctx is done so the stop entry is logged without it.
+			ll.Stop(context.Background(), ctx.Err().Error())
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
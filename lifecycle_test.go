@@ -0,0 +1,73 @@
+package slog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestLifecycle(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	ll := slog.Lifecycle(slog.Make(s))
+	ll.Start(bg, slog.StartInfo{
+		Version: "v1.2.3",
+		Config:  map[string]int{"workers": 4},
+		Addrs:   []string{":8080"},
+		Fields:  slog.M(slog.F("region", "us-east-1")),
+	})
+	assert.Len(t, "entries", 1, s.entries)
+	start := s.entries[0]
+	assert.Equal(t, "msg", "service started", start.Message)
+	assert.Equal(t, "file", slogTestFile[:len(slogTestFile)-len("slog_test.go")]+"lifecycle_test.go", start.File)
+	assert.Equal(t, "version", "v1.2.3", fieldValue(start.Fields, "version"))
+	assert.Equal(t, "config hash", 64, len(fieldValue(start.Fields, "config_hash").(string)))
+	assert.Equal(t, "addrs", []string{":8080"}, fieldValue(start.Fields, "addrs"))
+	assert.Equal(t, "region", "us-east-1", fieldValue(start.Fields, "region"))
+
+	done := ll.Task("flush")
+	ll.Task("upload")
+	done()
+	done()
+
+	ll.Stop(bg, "shutdown")
+	ll.Stop(bg, "again")
+	assert.Len(t, "entries", 2, s.entries)
+	stop := s.entries[1]
+	assert.Equal(t, "msg", "service stopped", stop.Message)
+	assert.Equal(t, "lifecycle", "stop", fieldValue(stop.Fields, "lifecycle"))
+	assert.Equal(t, "reason", "shutdown", fieldValue(stop.Fields, "reason"))
+	assert.Equal(t, "pending", []string{"upload"}, fieldValue(stop.Fields, "pending_tasks"))
+	assert.True(t, "uptime", fieldValue(stop.Fields, "uptime").(time.Duration) >= 0)
+}
+
+func TestLifecycle_StopOnDone(t *testing.T) {
+	t.Parallel()
+
+	s := make(chanSink, 2)
+	ll := slog.Lifecycle(slog.Make(s))
+	ll.Start(bg, slog.StartInfo{})
+	<-s
+
+	ctx, cancel := context.WithCancel(bg)
+	stop := ll.StopOnDone(ctx)
+	defer stop()
+	cancel()
+
+	select {
+	case ent := <-s:
+		assert.Equal(t, "reason", context.Canceled.Error(), fieldValue(ent.Fields, "reason"))
+	case <-time.After(10 * time.Second):
+		t.Fatal("no stop entry")
+	}
+}
+
+// fieldValue returns the value of the last field of m named name.
+func fieldValue(m slog.Map, name string) interface{} {
+	v, _ := m.Get(name)
+	return v
+}
@@ -20,17 +20,22 @@ var _ json.Marshaler = Map(nil)
 // It is guaranteed to return a nil error.
 // Any error marshalling a field will become the field's value.
 //
-// Every field value is encoded with the following process:
+// Every field value is encoded with the following process
+// unless there is an encoder for its type. See RegisterEncoder.
 //
 // 1. json.Marshaller is handled.
 //
-// 2. xerrors.Formatter is handled.
+// 2. xerrors.Formatter is handled. Errors that wrap multiple errors with
+// an Unwrap() []error method, e.g. those of errors.Join, are encoded as
+// a list of their errors.
 //
 // 3. structs that have a field with a json tag are encoded with json.Marshal.
 //
 // 4. error and fmt.Stringer is handled.
 //
 // 5. slices and arrays go through the encode function for every element.
+// Maps with non string keys go through it for every value with their keys
+// stringified and sorted. See Pairs to keep the keys' types.
 //
 // 6. For values that cannot be encoded with json.Marshal, fmt.Sprintf("%+v") is used.
 //
@@ -70,9 +75,15 @@ func marshalList(rv reflect.Value) []byte {
 }
 
 func encode(v interface{}) []byte {
+	if val, ok := registeredEncoders().encode(v); ok {
+		return encodeJSON(val)
+	}
+
 	switch v := v.(type) {
 	case json.Marshaler:
 		return encodeJSON(v)
+	case multiError:
+		return encode(v.Unwrap())
 	case xerrors.Formatter:
 		return encode(errorChain(v))
 	}
@@ -90,6 +101,10 @@ func encode(v interface{}) []byte {
 	}
 
 	switch v.(type) {
+	case json.Number:
+		// json.Number implements fmt.Stringer but must be
+		// encoded as a number.
+		return encodeJSON(v)
 	case error, fmt.Stringer:
 		return encode(fmt.Sprint(v))
 	}
@@ -101,6 +116,10 @@ func encode(v interface{}) []byte {
 		}
 	case reflect.Array:
 		return marshalList(rv)
+	case reflect.Map:
+		if !rv.IsNil() && rv.Type().Key().Kind() != reflect.String {
+			return marshalMap(rv)
+		}
 	case reflect.Struct, reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.Func:
 		// These types cannot be directly encoded with json.Marshal.
 		// See https://golang.org/pkg/encoding/json/#Marshal
@@ -153,6 +172,13 @@ func errorChain(f xerrors.Formatter) []interface{} {
 	}
 }
 
+// multiError is implemented by errors that wrap multiple errors,
+// e.g. those returned by errors.Join.
+type multiError interface {
+	error
+	Unwrap() []error
+}
+
 type wrapError struct {
 	Msg string `json:"msg"`
 	Fun string `json:"fun"`
@@ -196,3 +222,130 @@ func (m Map) append(m2 Map) Map {
 	m3 = append(m3, m2...)
 	return m3
 }
+
+var _ json.Unmarshaler = &Map{}
+
+// UnmarshalJSON implements json.Unmarshaler.
+//
+// The order of the fields in the JSON object is preserved.
+// Field values are decoded as with json.Unmarshal into an interface{}
+// except that numbers become json.Number to avoid losing precision
+// and objects become Maps to preserve their order.
+func (m *Map) UnmarshalJSON(b []byte) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	v, err := decodeValue(d)
+	if err != nil {
+		return xerrors.Errorf("failed to unmarshal map: %w", err)
+	}
+	if v == nil {
+		*m = nil
+		return nil
+	}
+	m2, ok := v.(Map)
+	if !ok {
+		return xerrors.Errorf("failed to unmarshal map: expected JSON object but got %T", v)
+	}
+	*m = m2
+	return nil
+}
+
+func decodeValue(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		m := Map{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, F(k.(string), v))
+		}
+		_, err = d.Token()
+		return m, err
+	case json.Delim('['):
+		l := []interface{}{}
+		for d.More() {
+			v, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, v)
+		}
+		_, err = d.Token()
+		return l, err
+	}
+
+	return t, nil
+}
+
+// Merge returns the fields of m followed by those of maps with every name
+// only once. A later field takes precedence over an earlier one with the
+// same name: its value replaces the earlier value at the position of the
+// name's first occurrence so the order of the names is preserved.
+//
+// Nested maps are not merged. m and maps are not modified.
+func (m Map) Merge(maps ...Map) Map {
+	n := len(m)
+	for _, m2 := range maps {
+		n += len(m2)
+	}
+
+	merged := make(Map, 0, n)
+	index := make(map[string]int, n)
+	add := func(fields Map) {
+		for _, f := range fields {
+			if i, ok := index[f.Name]; ok {
+				merged[i].Value = f.Value
+				continue
+			}
+			index[f.Name] = len(merged)
+			merged = append(merged, f)
+		}
+	}
+	add(m)
+	for _, m2 := range maps {
+		add(m2)
+	}
+	return merged
+}
+
+// Get returns the value of the last field in m named name,
+// i.e. the one that takes precedence in Merge.
+func (m Map) Get(name string) (interface{}, bool) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if m[i].Name == name {
+			return m[i].Value, true
+		}
+	}
+	return nil, false
+}
+
+// Delete returns m without the fields with the given names.
+// m is not modified.
+func (m Map) Delete(names ...string) Map {
+	m2 := make(Map, 0, len(m))
+	for _, f := range m {
+		deleted := false
+		for _, name := range names {
+			if f.Name == name {
+				deleted = true
+				break
+			}
+		}
+		if !deleted {
+			m2 = append(m2, f)
+		}
+	}
+	return m2
+}
@@ -93,7 +93,7 @@ func TestMap(t *testing.T) {
 					{
 						"msg": "failed to marshal to JSON",
 						"fun": "cdr.dev/slog.encodeJSON",
-						"loc": "`+mapTestFile+`:131"
+						"loc": "`+mapTestFile+`:150"
 					},
 					"json: error calling MarshalJSON for type slog_test.complexJSON: json: unsupported type: complex128"
 				],
@@ -127,6 +127,25 @@ func TestMap(t *testing.T) {
 		}`)
 	})
 
+	t.Run("multiError", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.M(
+			slog.Error(joinError{
+				io.EOF,
+				joinError{io.ErrClosedPipe, io.ErrUnexpectedEOF},
+			}),
+		), `{
+			"error": [
+				"EOF",
+				[
+					"io: read/write on closed pipe",
+					"unexpected EOF"
+				]
+			]
+		}`)
+	})
+
 	t.Run("slice", func(t *testing.T) {
 		t.Parallel()
 
@@ -193,6 +212,16 @@ func TestMap(t *testing.T) {
 		}`)
 	})
 
+	t.Run("json.Number", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.M(
+			slog.F("val", json.Number("1.5")),
+		), `{
+			"val": 1.5
+		}`)
+	})
+
 	t.Run("complex", func(t *testing.T) {
 		t.Parallel()
 
@@ -245,3 +274,40 @@ type complexJSON complex128
 func (c complexJSON) MarshalJSON() ([]byte, error) {
 	return json.Marshal(complex128(c))
 }
+
+func TestMapMerge(t *testing.T) {
+	t.Parallel()
+
+	m := slog.M(slog.F("a", 1), slog.F("b", 2), slog.F("a", 3))
+	m2 := slog.M(slog.F("c", 4), slog.F("b", 5))
+	merged := m.Merge(m2, slog.M(slog.F("c", 6)))
+
+	assert.Equal(t, "merged", slog.M(slog.F("a", 3), slog.F("b", 5), slog.F("c", 6)), merged)
+	assert.Equal(t, "m", slog.M(slog.F("a", 1), slog.F("b", 2), slog.F("a", 3)), m)
+	assert.Equal(t, "m2", slog.M(slog.F("c", 4), slog.F("b", 5)), m2)
+	assert.Equal(t, "empty", slog.Map{}, slog.Map(nil).Merge())
+
+	v, ok := m.Get("a")
+	assert.True(t, "found", ok)
+	assert.Equal(t, "last wins", 3, v)
+	_, ok = m.Get("z")
+	assert.False(t, "not found", ok)
+
+	assert.Equal(t, "deleted", slog.M(slog.F("b", 2)), m.Delete("a", "z"))
+	assert.Len(t, "m", 3, m)
+}
+
+// joinError wraps multiple errors like those of errors.Join.
+type joinError []error
+
+func (e joinError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e joinError) Unwrap() []error {
+	return e
+}
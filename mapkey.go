@@ -0,0 +1,148 @@
+package slog
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// mapEntry is a key value pair of a map.
+type mapEntry struct {
+	key reflect.Value
+	str string
+	val reflect.Value
+}
+
+// sortedEntries returns the entries of the map rv ordered by key.
+//
+// Numeric keys are ordered by value so that 2 comes before 10.
+// All other keys, including those that implement encoding.TextMarshaler,
+// are ordered by their string form.
+func sortedEntries(rv reflect.Value) []mapEntry {
+	entries := make([]mapEntry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		entries = append(entries, mapEntry{
+			key: iter.Key(),
+			str: mapKey(iter.Key()),
+			val: iter.Value(),
+		})
+	}
+
+	less := func(i, j int) bool {
+		return entries[i].str < entries[j].str
+	}
+	switch rv.Type().Key().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(i, j int) bool {
+			return entries[i].key.Int() < entries[j].key.Int()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less = func(i, j int) bool {
+			return entries[i].key.Uint() < entries[j].key.Uint()
+		}
+	case reflect.Float32, reflect.Float64:
+		less = func(i, j int) bool {
+			return entries[i].key.Float() < entries[j].key.Float()
+		}
+	}
+	if rv.Type().Key().Implements(textMarshalerType) {
+		less = func(i, j int) bool {
+			return entries[i].str < entries[j].str
+		}
+	}
+	sort.SliceStable(entries, less)
+	return entries
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// mapKey returns the string form of a map key.
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		if err == nil {
+			return string(b)
+		}
+	}
+	switch k.Kind() {
+	case reflect.String:
+		return k.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(k.Float(), 'g', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(k.Bool())
+	}
+	return fmt.Sprintf("%+v", k.Interface())
+}
+
+// marshalMap encodes a map with non string keys as an object
+// with the keys stringified and sorted.
+//
+// Distinct keys with the same string form, e.g. structs with
+// identical printed fields, result in duplicate object keys.
+func marshalMap(rv reflect.Value) []byte {
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, e := range sortedEntries(rv) {
+		b.WriteByte('\n')
+		b.Write(encode(e.str))
+		b.WriteByte(':')
+		b.Write(encode(e.val.Interface()))
+
+		if i < rv.Len()-1 {
+			b.WriteByte(',')
+		}
+	}
+	b.WriteByte('}')
+
+	return b.Bytes()
+}
+
+// Pairs returns a field value that encodes the map m as a list of
+// {"key": k, "value": v} objects in the same order as the keys
+// of maps with non string keys.
+//
+// Unlike with an object, keys are not stringified but encoded like any
+// other value, e.g. integer keys remain numbers and structs with json
+// tags remain objects.
+//
+// Values that are not maps are encoded as usual.
+func Pairs(m interface{}) json.Marshaler {
+	return pairs{m}
+}
+
+type pairs struct {
+	m interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p pairs) MarshalJSON() ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(p.m))
+	if rv.Kind() != reflect.Map {
+		return encode(p.m), nil
+	}
+	if rv.IsNil() {
+		return []byte("null"), nil
+	}
+
+	l := make([]interface{}, 0, rv.Len())
+	for _, e := range sortedEntries(rv) {
+		l = append(l, M(
+			F("key", e.key.Interface()),
+			F("value", e.val.Interface()),
+		))
+	}
+	return encode(l), nil
+}
@@ -0,0 +1,75 @@
+package slog_test
+
+import (
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestMapKeys(t *testing.T) {
+	t.Parallel()
+
+	test := func(t *testing.T, v interface{}, exp string) {
+		t.Helper()
+		act := marshalJSON(t, slog.M(slog.F("v", v)))
+		assert.Equal(t, "JSON", indentJSON(t, `{"v": `+exp+`}`), act)
+	}
+
+	t.Run("int", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, map[int]string{10: "ten", 2: "two", -1: "minus one"},
+			`{"-1": "minus one", "2": "two", "10": "ten"}`)
+	})
+
+	t.Run("float", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, map[float64]bool{1.5: true, 0.25: false},
+			`{"0.25": false, "1.5": true}`)
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		t.Parallel()
+
+		type point struct {
+			X, Y int
+		}
+		test(t, map[point]int{{2, 1}: 2, {1, 2}: 1},
+			`{"{X:1 Y:2}": 1, "{X:2 Y:1}": 2}`)
+	})
+
+	t.Run("textMarshaler", func(t *testing.T) {
+		t.Parallel()
+
+		t1 := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+		t2 := t1.Add(time.Hour)
+		test(t, map[time.Time]int{t2: 2, t1: 1},
+			`{"2020-01-02T00:00:00Z": 1, "2020-01-02T01:00:00Z": 2}`)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+
+		// Values are encoded like fields.
+		test(t, map[int]interface{}{1: slog.M(slog.F("b", 1), slog.F("a", 2))},
+			`{"1": {"b": 1, "a": 2}}`)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, map[int]int(nil), `null`)
+	})
+
+	t.Run("pairs", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.Pairs(map[int]string{10: "ten", 2: "two"}),
+			`[{"key": 2, "value": "two"}, {"key": 10, "value": "ten"}]`)
+		test(t, slog.Pairs(map[int]string(nil)), `null`)
+		test(t, slog.Pairs("notamap"), `"notamap"`)
+	})
+}
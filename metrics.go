@@ -0,0 +1,93 @@
+package slog
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Metric is a numeric field of an entry. See Metrics.
+type Metric struct {
+	// Name is the name of the field.
+	Name  string
+	Value float64
+	// Entry is the entry of the field, e.g. to label the metric
+	// with the logger names or tags.
+	Entry SinkEntry
+}
+
+// Metrics returns a sink that logs entries to s and calls record with
+// every field of the entries named one of names that has a numeric value,
+// e.g. to observe latency_ms in a histogram or add bytes to a counter.
+// If no names are given, every numeric field is recorded.
+//
+// Integers and floats of any type are recorded as float64, while
+// time.Duration values are recorded in seconds. Other values, including
+// numbers in strings, are ignored.
+//
+// record is called for the entries in the goroutine that logs them and
+// before they are logged to s, so it should be quick.
+func Metrics(s Sink, record func(ctx context.Context, m Metric), names ...string) Sink {
+	m := &metricsSink{
+		s:      s,
+		record: record,
+	}
+	if len(names) > 0 {
+		m.names = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			m.names[name] = struct{}{}
+		}
+	}
+	return m
+}
+
+type metricsSink struct {
+	s      Sink
+	record func(context.Context, Metric)
+	// names is nil if every field is recorded.
+	names map[string]struct{}
+}
+
+func (m *metricsSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	for _, f := range ent.Fields {
+		if m.names != nil {
+			if _, ok := m.names[f.Name]; !ok {
+				continue
+			}
+		}
+		v, ok := metricValue(f.Value)
+		if ok {
+			m.record(ctx, Metric{
+				Name:  f.Name,
+				Value: v,
+				Entry: ent,
+			})
+		}
+	}
+	m.s.LogEntry(ctx, ent)
+}
+
+// metricValue returns v as a float64 if it is a number.
+func metricValue(v interface{}) (float64, bool) {
+	if d, ok := v.(time.Duration); ok {
+		return d.Seconds(), true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+func (m *metricsSink) Sync() {
+	m.s.Sync()
+}
+
+func (m *metricsSink) Flush(ctx context.Context) error {
+	return Flush(ctx, m.s)
+}
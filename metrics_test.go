@@ -0,0 +1,44 @@
+package slog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	type bytesCount uint32
+
+	var got []slog.Metric
+	record := func(_ context.Context, m slog.Metric) {
+		got = append(got, m)
+	}
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Metrics(s, record, "latency", "bytes", "status")).Named("http")
+	l.Info(bg, "request",
+		slog.F("latency", 1500*time.Millisecond),
+		slog.F("bytes", bytesCount(512)),
+		slog.F("status", "500"),
+		slog.F("other", 3),
+	)
+
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Len(t, "metrics", 2, got)
+	assert.Equal(t, "latency", "latency", got[0].Name)
+	assert.Equal(t, "latency seconds", 1.5, got[0].Value)
+	assert.Equal(t, "bytes", 512.0, got[1].Value)
+	assert.Equal(t, "logger", []string{"http"}, got[1].Entry.LoggerNames)
+
+	got = nil
+	l = slog.Make(slog.Metrics(s, record))
+	l.Info(bg, "all", slog.F("a", -2), slog.F("b", float32(0.5)), slog.F("c", true))
+	assert.Len(t, "all metrics", 2, got)
+	assert.Equal(t, "int", -2.0, got[0].Value)
+	assert.Equal(t, "float", 0.5, got[1].Value)
+}
@@ -0,0 +1,38 @@
+package slog
+
+import (
+	"context"
+	"time"
+)
+
+// processStart is the reference of the monotonic timestamps.
+var processStart = time.Now()
+
+// Monotonic returns a sink that logs entries to s with a mono_ns field
+// of the nanoseconds since the process started, read from the monotonic
+// clock when the entry reaches the sink.
+//
+// Unlike the time of entries, the field is not affected by steps of the
+// wall clock, e.g. by NTP, so it orders the entries of a process exactly
+// and measures the time between them. Compare it only within a process.
+func Monotonic(s Sink) Sink {
+	return monotonicSink{s}
+}
+
+type monotonicSink struct {
+	s Sink
+}
+
+func (s monotonicSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	mono := time.Since(processStart)
+	ent.Fields = ent.Fields.append(M(F("mono_ns", int64(mono))))
+	s.s.LogEntry(ctx, ent)
+}
+
+func (s monotonicSink) Sync() {
+	s.s.Sync()
+}
+
+func (s monotonicSink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
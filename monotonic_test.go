@@ -0,0 +1,26 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestMonotonic(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Monotonic(s))
+	l.Info(bg, "first", slog.F("a", 1))
+	l.Info(bg, "second")
+
+	assert.Len(t, "entries", 2, s.entries)
+	assert.Len(t, "fields", 2, s.entries[0].Fields)
+	assert.Equal(t, "first field", "a", s.entries[0].Fields[0].Name)
+
+	first := fieldValue(s.entries[0].Fields, "mono_ns").(int64)
+	second := fieldValue(s.entries[1].Fields, "mono_ns").(int64)
+	assert.True(t, "positive", first > 0)
+	assert.True(t, "increasing", second >= first)
+}
@@ -0,0 +1,200 @@
+package slog
+
+import (
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// FormatOpener creates an encoder for a format from the URL passed
+// to Open. w is the destination of the entries if the sink writes
+// to an io.Writer and nil otherwise. Formats can use it to detect
+// terminals.
+type FormatOpener func(u *url.URL, w io.Writer) (Encoder, error)
+
+// SinkOpener creates a sink from the URL passed to Open.
+// newEncoder creates the encoder of the format chosen by the URL.
+type SinkOpener func(u *url.URL, newEncoder func(w io.Writer) (Encoder, error)) (Sink, error)
+
+var registry struct {
+	mu      sync.RWMutex
+	sinks   map[string]sinkEntry
+	formats map[string]formatEntry
+}
+
+type sinkEntry struct {
+	open   SinkOpener
+	params []string
+}
+
+type formatEntry struct {
+	open   FormatOpener
+	params []string
+}
+
+// RegisterSink makes a sink available to Open under scheme.
+// Packages in the sloggers subdirectory register their sinks
+// when they are imported.
+//
+// params are the names of the query parameters that open reads.
+// Open rejects URLs with query parameters that neither the sink
+// nor the format reads so that a typo is not silently ignored.
+//
+// It panics if a sink is already registered under scheme
+// or if scheme contains a plus sign.
+func RegisterSink(scheme string, open SinkOpener, params ...string) {
+	if strings.Contains(scheme, "+") {
+		panic(xerrors.Errorf("slog: sink scheme %q contains a plus sign", scheme))
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.sinks == nil {
+		registry.sinks = make(map[string]sinkEntry)
+	}
+	if _, ok := registry.sinks[scheme]; ok {
+		panic(xerrors.Errorf("slog: sink %q already registered", scheme))
+	}
+	registry.sinks[scheme] = sinkEntry{open: open, params: params}
+}
+
+// RegisterFormat makes a format available to Open under name.
+// Packages in the sloggers subdirectory register their formats
+// when they are imported.
+//
+// params are the names of the query parameters that open reads.
+// See RegisterSink.
+//
+// It panics if a format is already registered under name.
+func RegisterFormat(name string, open FormatOpener, params ...string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.formats == nil {
+		registry.formats = make(map[string]formatEntry)
+	}
+	if _, ok := registry.formats[name]; ok {
+		panic(xerrors.Errorf("slog: format %q already registered", name))
+	}
+	registry.formats[name] = formatEntry{open: open, params: params}
+}
+
+func init() {
+	RegisterSink("stderr", writerOpener(os.Stderr))
+	RegisterSink("stdout", writerOpener(os.Stdout))
+}
+
+func writerOpener(w io.Writer) SinkOpener {
+	return func(u *url.URL, newEncoder func(w io.Writer) (Encoder, error)) (Sink, error) {
+		enc, err := newEncoder(w)
+		if err != nil {
+			return nil, err
+		}
+		return WriterSink(w, enc), nil
+	}
+}
+
+// Open creates a sink from a URL so that the destination and format
+// of logs can be chosen with a flag or environment variable.
+//
+// The scheme of the URL is the name of a format and of a sink joined
+// with a plus sign. The format defaults to json. The rest of the URL
+// and the query parameters configure the sink and the format.
+// e.g.
+//
+//	human+stderr://
+//	json+file:///var/log/app.log
+//	json+tcp://logs.internal:5170?framing=length
+//
+// The stderr and stdout sinks are always available. Other sinks and
+// all formats are registered by their packages, so they must be imported:
+//
+//	import _ "cdr.dev/slog/sloggers/slogjson"
+//
+// Query parameters that neither the sink nor the format reads
+// are rejected.
+//
+// If the sink holds resources such as a file, it implements io.Closer.
+func Open(rawurl string) (Sink, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse sink URL: %w", err)
+	}
+	if u.Scheme == "" {
+		return nil, xerrors.Errorf("sink URL %q has no scheme", rawurl)
+	}
+
+	format, scheme := "json", u.Scheme
+	if i := strings.IndexByte(scheme, '+'); i >= 0 {
+		format, scheme = scheme[:i], scheme[i+1:]
+	}
+
+	registry.mu.RLock()
+	se, ok := registry.sinks[scheme]
+	fe, ok2 := registry.formats[format]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return nil, xerrors.Errorf("unknown sink %q, registered sinks are %v", scheme, sinkNames())
+	}
+	if !ok2 {
+		return nil, xerrors.Errorf("unknown format %q, registered formats are %v", format, formatNames())
+	}
+	for name := range u.Query() {
+		if !contains(se.params, name) && !contains(fe.params, name) {
+			return nil, xerrors.Errorf("unknown query parameter %q, sink %q reads %v and format %q reads %v",
+				name, scheme, se.params, format, fe.params)
+		}
+	}
+
+	s, err := se.open(u, func(w io.Writer) (Encoder, error) {
+		enc, err := fe.open(u, w)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to open format %q: %w", format, err)
+		}
+		return enc, nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open sink %q: %w", scheme, err)
+	}
+	return s, nil
+}
+
+func contains(l []string, s string) bool {
+	for _, s2 := range l {
+		if s2 == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sinkNames() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	var names []string
+	for name := range registry.sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func formatNames() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	var names []string
+	for name := range registry.formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+package slog_test
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+type urlSink struct {
+	slog.Sink
+	u *url.URL
+	b *strings.Builder
+}
+
+// registerOpen registers the sink and format of TestOpen only once
+// as the registry is global and tests may run more than once.
+var registerOpen sync.Once
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	registerOpen.Do(func() {
+		slog.RegisterFormat("opentest", func(u *url.URL, w io.Writer) (slog.Encoder, error) {
+			prefix := u.Query().Get("prefix")
+			return slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+				buf = append(buf, prefix...)
+				return append(buf, ent.Message...)
+			}), nil
+		}, "prefix")
+		slog.RegisterSink("opentest", func(u *url.URL, newEncoder func(w io.Writer) (slog.Encoder, error)) (slog.Sink, error) {
+			b := &strings.Builder{}
+			enc, err := newEncoder(b)
+			if err != nil {
+				return nil, err
+			}
+			return urlSink{slog.WriterSink(b, enc), u, b}, nil
+		})
+	})
+
+	s, err := slog.Open("opentest+opentest://host/path?prefix=>")
+	assert.Success(t, "open", err)
+	assert.Equal(t, "host", "host", s.(urlSink).u.Host)
+	slog.Make(s).Info(bg, "hello")
+	assert.Equal(t, "output", ">hello\n", s.(urlSink).b.String())
+
+	_, err = slog.Open("opentest+nope://")
+	assert.Error(t, "unknown sink", err)
+	assert.True(t, "sinks", strings.Contains(err.Error(), "stderr"))
+
+	_, err = slog.Open("nope+stderr://")
+	assert.Error(t, "unknown format", err)
+	assert.True(t, "formats", strings.Contains(err.Error(), "opentest"))
+
+	_, err = slog.Open("stderr")
+	assert.Error(t, "no scheme", err)
+
+	_, err = slog.Open("opentest+opentest://host?prefix=>&prefx=>")
+	assert.Error(t, "unknown query parameter", err)
+	assert.True(t, "parameter", strings.Contains(err.Error(), `"prefx"`))
+}
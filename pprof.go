@@ -0,0 +1,38 @@
+package slog
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+
+	"go.opencensus.io/trace"
+)
+
+// Labels returns the pprof labels for entries logged by l with ctx.
+//
+// The labels are trace_id, the trace ID of the span in ctx, and component,
+// the names of l joined with a period. Labels without a value are omitted.
+func Labels(ctx context.Context, l Logger) pprof.LabelSet {
+	var kv []string
+	if sc := trace.FromContext(ctx).SpanContext(); sc != (trace.SpanContext{}) {
+		kv = append(kv, "trace_id", sc.TraceID.String())
+	}
+	if len(l.names) > 0 {
+		kv = append(kv, "component", strings.Join(l.names, "."))
+	}
+	return pprof.Labels(kv...)
+}
+
+// Do calls fn with a context that has the pprof labels returned
+// by Labels. The labels are set on the current goroutine while fn
+// runs, so CPU profiles can be filtered by the same dimensions as
+// the logs of fn. Goroutines started by fn inherit them.
+//
+//	slog.Do(ctx, log.Named("indexer"), func(ctx context.Context) {
+//		// ...
+//	})
+//
+// See pprof.Do.
+func Do(ctx context.Context, l Logger, fn func(ctx context.Context)) {
+	pprof.Do(ctx, Labels(ctx, l), fn)
+}
@@ -0,0 +1,38 @@
+package slog_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"go.opencensus.io/trace"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	ctx, span := trace.StartSpan(bg, "span")
+	defer span.End()
+
+	l := slog.Make().Named("a").Named("b")
+	var called bool
+	slog.Do(ctx, l, func(ctx context.Context) {
+		called = true
+
+		component, _ := pprof.Label(ctx, "component")
+		assert.Equal(t, "component", "a.b", component)
+		traceID, _ := pprof.Label(ctx, "trace_id")
+		assert.Equal(t, "trace_id", span.SpanContext().TraceID.String(), traceID)
+	})
+	assert.True(t, "called", called)
+
+	slog.Do(bg, slog.Make(), func(ctx context.Context) {
+		_, ok := pprof.Label(ctx, "component")
+		assert.False(t, "component", ok)
+		_, ok = pprof.Label(ctx, "trace_id")
+		assert.False(t, "trace_id", ok)
+	})
+}
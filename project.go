@@ -0,0 +1,112 @@
+package slog
+
+import (
+	"context"
+)
+
+// ProjectOptions represents the options for the sink returned by Project.
+//
+// Fields of nested Maps are named by their path joined with a period,
+// e.g. http.status for the status field of the http field.
+type ProjectOptions struct {
+	// Allow are the fields kept with all their nested fields. If a
+	// nested field is allowed, its parents are kept with only the
+	// allowed fields. If empty, every field is allowed.
+	Allow []string
+	// Deny are the fields removed even if they are allowed.
+	Deny []string
+}
+
+// Project returns a sink that logs entries to s with only the fields
+// allowed and not denied by opts, e.g. to ship a known schema of fields
+// to a collector while a local file gets every field:
+//
+//	l := slog.Make(
+//		slogjson.Sink(file),
+//		slog.Project(exporter, &slog.ProjectOptions{
+//			Allow: []string{"request_id", "http.status", "duration"},
+//		}),
+//	)
+//
+// The tags, message and other properties of the entries are kept.
+// The fields of s are copies as the fields of entries are shared with
+// other sinks.
+//
+// If opts is nil, every field is kept.
+func Project(s Sink, opts *ProjectOptions) Sink {
+	p := &projectSink{s: s}
+	if opts != nil {
+		p.allow, p.allowParents = pathSets(opts.Allow)
+		p.deny, p.denyParents = pathSets(opts.Deny)
+	}
+	return p
+}
+
+// pathSets returns the set of paths and of their parents.
+func pathSets(paths []string) (set, parents map[string]struct{}) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	set = make(map[string]struct{}, len(paths))
+	parents = make(map[string]struct{})
+	for _, p := range paths {
+		set[p] = struct{}{}
+		for i := 0; i < len(p); i++ {
+			if p[i] == '.' {
+				parents[p[:i]] = struct{}{}
+			}
+		}
+	}
+	return set, parents
+}
+
+type projectSink struct {
+	s Sink
+	// allow is nil if every field is allowed.
+	allow, allowParents map[string]struct{}
+	deny, denyParents   map[string]struct{}
+}
+
+func (p *projectSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	ent.Fields = p.project(ent.Fields, "", p.allow == nil)
+	p.s.LogEntry(ctx, ent)
+}
+
+// project returns the fields of m at prefix that are allowed and not
+// denied. allowed reports whether a parent of m is allowed.
+func (p *projectSink) project(m Map, prefix string, allowed bool) Map {
+	var m2 Map
+	for _, f := range m {
+		name := prefix + f.Name
+		if _, ok := p.deny[name]; ok {
+			continue
+		}
+
+		fallowed := allowed
+		if !fallowed {
+			_, fallowed = p.allow[name]
+		}
+		_, denyNested := p.denyParents[name]
+		_, allowNested := p.allowParents[name]
+
+		if nested, ok := f.Value.(Map); ok && (denyNested || (!fallowed && allowNested)) {
+			nested = p.project(nested, name+".", fallowed)
+			if len(nested) == 0 && !fallowed {
+				continue
+			}
+			f.Value = nested
+		} else if !fallowed {
+			continue
+		}
+		m2 = append(m2, f)
+	}
+	return m2
+}
+
+func (p *projectSink) Sync() {
+	p.s.Sync()
+}
+
+func (p *projectSink) Flush(ctx context.Context) error {
+	return Flush(ctx, p.s)
+}
@@ -0,0 +1,67 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	fields := slog.M(
+		slog.F("request_id", "abc"),
+		slog.F("user", slog.M(
+			slog.F("id", 1),
+			slog.F("email", "bob@example.com"),
+		)),
+		slog.F("http", slog.M(
+			slog.F("status", 200),
+			slog.F("headers", slog.M(slog.F("Cookie", "secret"))),
+		)),
+		slog.F("debug", true),
+	)
+
+	test := func(t *testing.T, opts *slog.ProjectOptions, exp slog.Map) {
+		t.Helper()
+
+		local, shipped := &fakeSink{}, &fakeSink{}
+		l := slog.Make(local, slog.Project(shipped, opts))
+		l.Info(bg, "hi", fields...)
+
+		assert.Equal(t, "local", fields, local.entries[0].Fields)
+		assert.Equal(t, "shipped", exp, shipped.entries[0].Fields)
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		test(t, nil, fields)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		t.Parallel()
+		test(t, &slog.ProjectOptions{
+			Allow: []string{"request_id", "user.id", "http", "missing.field"},
+			Deny:  []string{"http.headers"},
+		}, slog.M(
+			slog.F("request_id", "abc"),
+			slog.F("user", slog.M(slog.F("id", 1))),
+			slog.F("http", slog.M(slog.F("status", 200))),
+		))
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		t.Parallel()
+		test(t, &slog.ProjectOptions{
+			Deny: []string{"debug", "user.email", "http.headers.Cookie"},
+		}, slog.M(
+			slog.F("request_id", "abc"),
+			slog.F("user", slog.M(slog.F("id", 1))),
+			slog.F("http", slog.M(
+				slog.F("status", 200),
+				slog.F("headers", slog.Map(nil)),
+			)),
+		))
+	})
+}
@@ -0,0 +1,67 @@
+package slog
+
+import (
+	"context"
+	"hash/fnv"
+
+	"go.opencensus.io/trace"
+)
+
+// Sampler decides which entries are logged by the sink returned by Sample.
+// Samplers must be safe for concurrent use.
+type Sampler interface {
+	// Sample reports whether to log ent.
+	Sample(ctx context.Context, ent SinkEntry) bool
+}
+
+// SamplerFunc is a Sampler of a function.
+type SamplerFunc func(ctx context.Context, ent SinkEntry) bool
+
+// Sample implements Sampler.
+func (fn SamplerFunc) Sample(ctx context.Context, ent SinkEntry) bool {
+	return fn(ctx, ent)
+}
+
+// Sample returns a sink that logs the entries that sampler keeps to s.
+func Sample(s Sink, sampler Sampler) Sink {
+	return &sampleSink{
+		s:       s,
+		sampler: sampler,
+	}
+}
+
+type sampleSink struct {
+	s       Sink
+	sampler Sampler
+}
+
+func (s *sampleSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	if s.sampler.Sample(ctx, ent) {
+		s.s.LogEntry(ctx, ent)
+	}
+}
+
+func (s *sampleSink) Sync() {
+	s.s.Sync()
+}
+
+func (s *sampleSink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
+
+// TraceSampler returns a sampler that keeps the entries of 1 in rate
+// traces. Whether a trace is kept depends only on a hash of its ID, so
+// every entry of a kept trace is logged, even by other processes with
+// the same rate, and no entry of a dropped trace is. Entries without
+// a trace are kept. If rate is less than 2, every entry is kept.
+func TraceSampler(rate uint64) Sampler {
+	return SamplerFunc(func(_ context.Context, ent SinkEntry) bool {
+		return rate < 2 || ent.SpanContext.TraceID == (trace.TraceID{}) || traceHash(ent.SpanContext.TraceID)%rate == 0
+	})
+}
+
+func traceHash(id trace.TraceID) uint64 {
+	h := fnv.New64a()
+	h.Write(id[:])
+	return h.Sum64()
+}
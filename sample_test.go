@@ -0,0 +1,55 @@
+package slog_test
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"go.opencensus.io/trace"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSample(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Sample(s, slog.SamplerFunc(func(_ context.Context, ent slog.SinkEntry) bool {
+		return ent.Level >= slog.LevelWarn
+	})))
+	l.Info(bg, "dropped")
+	l.Warn(bg, "kept")
+	l.Sync()
+
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "msg", "kept", s.entries[0].Message)
+	assert.Equal(t, "syncs", 1, s.syncs)
+}
+
+func TestTraceSampler(t *testing.T) {
+	t.Parallel()
+
+	sampler := slog.TraceSampler(4)
+	kept := 0
+	const traces = 1000
+	for i := 0; i < traces; i++ {
+		var ent slog.SinkEntry
+		_, err := rand.Read(ent.SpanContext.TraceID[:])
+		assert.Success(t, "rand", err)
+
+		first := sampler.Sample(bg, ent)
+		for j := 0; j < 3; j++ {
+			ent.SpanContext.SpanID[0] = byte(j)
+			assert.Equal(t, "whole trace", first, sampler.Sample(bg, ent))
+		}
+		if first {
+			kept++
+		}
+	}
+	assert.True(t, "rate", kept > traces/8 && kept < traces*3/8)
+
+	assert.True(t, "no trace", sampler.Sample(bg, slog.SinkEntry{}))
+	ent := slog.SinkEntry{SpanContext: trace.SpanContext{TraceID: trace.TraceID{1}}}
+	assert.True(t, "rate 1", slog.TraceSampler(1).Sample(bg, ent))
+}
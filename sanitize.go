@@ -0,0 +1,151 @@
+package slog
+
+import (
+	"context"
+	"strings"
+)
+
+// SanitizeOptions configures how Sanitize and SanitizeEntry
+// neutralize line breaks and NUL characters.
+type SanitizeOptions struct {
+	// Strip removes the characters instead of escaping them
+	// as \r, \n and \0.
+	Strip bool
+}
+
+var (
+	sanitizeEscaper  = strings.NewReplacer("\r", `\r`, "\n", `\n`, "\x00", `\0`)
+	sanitizeStripper = strings.NewReplacer("\r", "", "\n", "", "\x00", "")
+)
+
+func (opts *SanitizeOptions) sanitize(s string) string {
+	if !strings.ContainsAny(s, "\r\n\x00") {
+		return s
+	}
+	if opts != nil && opts.Strip {
+		return sanitizeStripper.Replace(s)
+	}
+	return sanitizeEscaper.Replace(s)
+}
+
+// Sanitize returns a sink that logs entries to s after
+// neutralizing CR, LF and NUL characters with SanitizeEntry.
+//
+// Use it in front of sinks whose receivers split records on line breaks,
+// such as syslog or line based TCP collectors, so that user provided
+// strings cannot forge additional records.
+//
+// If opts is nil, the characters are escaped.
+func Sanitize(s Sink, opts *SanitizeOptions) Sink {
+	return sanitizeSink{s: s, opts: opts}
+}
+
+type sanitizeSink struct {
+	s    Sink
+	opts *SanitizeOptions
+}
+
+func (s sanitizeSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	s.s.LogEntry(ctx, SanitizeEntry(ent, s.opts))
+}
+
+func (s sanitizeSink) Sync() {
+	s.s.Sync()
+}
+
+func (s sanitizeSink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
+
+// SanitizeEntry returns ent with CR, LF and NUL characters escaped or
+// stripped according to opts in the message, the logger names, the field
+// names and the string and error field values, including those in
+// nested Maps and slices of strings or interface{}, and the tags.
+//
+// Errors that contain the characters become strings of their sanitized
+// message. Escaping is not reversible as backslashes are left as is.
+// ent is not modified as its fields are shared with other sinks.
+//
+// If opts is nil, the characters are escaped.
+func SanitizeEntry(ent SinkEntry, opts *SanitizeOptions) SinkEntry {
+	ent.Message = opts.sanitize(ent.Message)
+
+	for i, name := range ent.LoggerNames {
+		if s := opts.sanitize(name); s != name {
+			names := make([]string, len(ent.LoggerNames))
+			copy(names, ent.LoggerNames)
+			for j := i; j < len(names); j++ {
+				names[j] = opts.sanitize(names[j])
+			}
+			ent.LoggerNames = names
+			break
+		}
+	}
+
+	ent.Fields, _ = opts.sanitizeMap(ent.Fields)
+	ent.Tags, _ = opts.sanitizeMap(ent.Tags)
+	return ent
+}
+
+// sanitizeMap returns m or a sanitized copy of it if it has to be changed.
+func (opts *SanitizeOptions) sanitizeMap(m Map) (Map, bool) {
+	for i, f := range m {
+		name := opts.sanitize(f.Name)
+		v, changed := opts.sanitizeValue(f.Value)
+		if name == f.Name && !changed {
+			continue
+		}
+
+		m2 := make(Map, len(m))
+		copy(m2, m)
+		m2[i] = F(name, v)
+		for j := i + 1; j < len(m2); j++ {
+			v, _ := opts.sanitizeValue(m2[j].Value)
+			m2[j] = F(opts.sanitize(m2[j].Name), v)
+		}
+		return m2, true
+	}
+	return m, false
+}
+
+func (opts *SanitizeOptions) sanitizeValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		s := opts.sanitize(v)
+		return s, s != v
+	case error:
+		msg := v.Error()
+		s := opts.sanitize(msg)
+		if s == msg {
+			return v, false
+		}
+		return s, true
+	case Map:
+		return opts.sanitizeMap(v)
+	case []string:
+		for i, s := range v {
+			if opts.sanitize(s) == s {
+				continue
+			}
+			l := make([]string, len(v))
+			copy(l, v)
+			for j := i; j < len(l); j++ {
+				l[j] = opts.sanitize(l[j])
+			}
+			return l, true
+		}
+	case []interface{}:
+		for i, e := range v {
+			if _, changed := opts.sanitizeValue(e); !changed {
+				continue
+			}
+			l := make([]interface{}, len(v))
+			copy(l, v)
+			for j := i; j < len(l); j++ {
+				l[j], _ = opts.sanitizeValue(l[j])
+			}
+			return l, true
+		}
+	}
+	return v, false
+}
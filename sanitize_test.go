@@ -0,0 +1,51 @@
+package slog_test
+
+import (
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Sanitize(s, nil)).Named("a\nb")
+	fields := slog.M(
+		slog.F("clean", "ok"),
+		slog.F("str", "a\r\nb"),
+		slog.F("nested\x00", slog.M(slog.F("k", []string{"x", "y\nz"}))),
+		slog.Error(xerrors.New("bad\nerror")),
+		slog.F("list", []interface{}{1, "c\nd"}),
+	)
+	l.Info(bg, "msg\nforged", fields...)
+
+	assert.Len(t, "entries", 1, s.entries)
+	ent := s.entries[0]
+	assert.Equal(t, "msg", `msg\nforged`, ent.Message)
+	assert.Equal(t, "names", []string{`a\nb`}, ent.LoggerNames)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("clean", "ok"),
+		slog.F("str", `a\r\nb`),
+		slog.F(`nested\0`, slog.M(slog.F("k", []string{"x", `y\nz`}))),
+		slog.F("error", `bad\nerror`),
+		slog.F("list", []interface{}{1, `c\nd`}),
+	), ent.Fields)
+	// The fields are shared with other sinks.
+	assert.Equal(t, "original", "a\r\nb", fields[1].Value)
+}
+
+func TestSanitizeEntry(t *testing.T) {
+	t.Parallel()
+
+	fields := slog.M(slog.F("a", 1), slog.F("b", "two"))
+	ent := slog.SanitizeEntry(slog.SinkEntry{Message: "clean", Fields: fields}, nil)
+	assert.Equal(t, "msg", "clean", ent.Message)
+	assert.True(t, "same fields", &fields[0] == &ent.Fields[0])
+
+	ent = slog.SanitizeEntry(slog.SinkEntry{Message: "a\r\n\x00b"}, &slog.SanitizeOptions{Strip: true})
+	assert.Equal(t, "msg", "ab", ent.Message)
+}
@@ -0,0 +1,121 @@
+package slog
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// EventSchema describes an event, an entry with a fixed level, message
+// and set of fields that consumers of the logs can rely on, e.g. to
+// count logins. The typed logging functions generated by slogschema
+// register the schemas of their events and log them with Emit.
+type EventSchema struct {
+	// Name is the name of the event in the event field.
+	// e.g. user_login
+	Name string `json:"name"`
+	// Doc describes the event.
+	Doc     string `json:"doc,omitempty"`
+	Level   Level  `json:"level"`
+	Message string `json:"message"`
+	// Fields are the fields of the event in the order they are logged.
+	Fields []FieldSchema `json:"fields,omitempty"`
+}
+
+// FieldSchema describes a field of an event.
+type FieldSchema struct {
+	Name string `json:"name"`
+	// Doc describes the field.
+	Doc string `json:"doc,omitempty"`
+	// Type is the Go type of the values of the field.
+	// e.g. string or time.Duration
+	Type string `json:"type"`
+	// Required reports whether entries of the event must have the field.
+	Required bool `json:"required,omitempty"`
+}
+
+var events struct {
+	mu sync.RWMutex
+	m  map[string]EventSchema
+}
+
+// RegisterEvent registers the schema of an event for Emit and Events.
+// It panics if an event with the same name is already registered.
+//
+// It is meant to be called during initialization.
+func RegisterEvent(e EventSchema) {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+
+	if events.m == nil {
+		events.m = make(map[string]EventSchema)
+	}
+	if _, ok := events.m[e.Name]; ok {
+		panic(xerrors.Errorf("slog: event %q already registered", e.Name))
+	}
+	events.m[e.Name] = e
+}
+
+// LookupEvent returns the schema of the registered event name.
+func LookupEvent(name string) (EventSchema, bool) {
+	events.mu.RLock()
+	defer events.mu.RUnlock()
+
+	e, ok := events.m[name]
+	return e, ok
+}
+
+// Events returns the schemas of the registered events sorted by name,
+// e.g. to document them or to configure consumers.
+func Events() []EventSchema {
+	events.mu.RLock()
+	defer events.mu.RUnlock()
+
+	es := make([]EventSchema, 0, len(events.m))
+	for _, e := range events.m {
+		es = append(es, e)
+	}
+	sort.Slice(es, func(i, j int) bool {
+		return es[i].Name < es[j].Name
+	})
+	return es
+}
+
+// Emit logs the registered event name to l at its level with its message,
+// an event field of its name and the fields. Required fields of the event
+// that are missing are listed in a missing_fields field so that they are
+// noticed when the event is not logged by its generated function.
+//
+// If the event is not registered, it is logged at LevelInfo with the
+// name as its message.
+func Emit(ctx context.Context, l Logger, name string, fields ...Field) {
+	e, ok := LookupEvent(name)
+	if !ok {
+		e = EventSchema{
+			Name:    name,
+			Level:   LevelInfo,
+			Message: name,
+		}
+	}
+
+	m := make(Map, 0, len(fields)+2)
+	m = append(m, F("event", name))
+	m = append(m, fields...)
+
+	var missing []string
+	for _, f := range e.Fields {
+		if !f.Required {
+			continue
+		}
+		if _, ok := Map(fields).Get(f.Name); !ok {
+			missing = append(missing, f.Name)
+		}
+	}
+	if len(missing) > 0 {
+		m = append(m, F("missing_fields", missing))
+	}
+
+	l.Log(ctx, l.entry(ctx, e.Level, e.Message, m, 1))
+}
@@ -0,0 +1,58 @@
+package slog_test
+
+import (
+	"sync"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+// registerDeploy registers the event of TestEmit only once as
+// the registry is global and tests may run more than once.
+var registerDeploy sync.Once
+
+func TestEmit(t *testing.T) {
+	t.Parallel()
+
+	registerDeploy.Do(func() {
+		slog.RegisterEvent(slog.EventSchema{
+			Name:    "schema_test_deploy",
+			Level:   slog.LevelWarn,
+			Message: "deployed",
+			Fields: []slog.FieldSchema{
+				{Name: "version", Type: "string", Required: true},
+				{Name: "region", Type: "string", Required: true},
+			},
+		})
+	})
+
+	s := &fakeSink{}
+	l := slog.Make(s)
+	slog.Emit(bg, l, "schema_test_deploy", slog.F("version", "v1"))
+	slog.Emit(bg, l, "schema_test_unknown", slog.F("a", 1))
+
+	assert.Len(t, "entries", 2, s.entries)
+	assert.Equal(t, "level", slog.LevelWarn, s.entries[0].Level)
+	assert.Equal(t, "msg", "deployed", s.entries[0].Message)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("event", "schema_test_deploy"),
+		slog.F("version", "v1"),
+		slog.F("missing_fields", []string{"region"}),
+	), s.entries[0].Fields)
+	assert.Equal(t, "location", "schema_test.go", slog.TrimPath(s.entries[0].File, "", slog.PathBase))
+
+	assert.Equal(t, "unknown level", slog.LevelInfo, s.entries[1].Level)
+	assert.Equal(t, "unknown msg", "schema_test_unknown", s.entries[1].Message)
+
+	var found bool
+	for _, e := range slog.Events() {
+		found = found || e.Name == "schema_test_deploy"
+	}
+	assert.True(t, "events", found)
+
+	defer func() {
+		assert.True(t, "duplicate panics", recover() != nil)
+	}()
+	slog.RegisterEvent(slog.EventSchema{Name: "schema_test_deploy"})
+}
@@ -0,0 +1,51 @@
+package slog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync/atomic"
+)
+
+// Sequence returns a sink that logs entries to s with a seq field of
+// their number, starting at 1, and a logger_instance field of a random
+// ID of the returned sink so that consumers can detect entries dropped
+// or reordered on their way, e.g. by asynchronous shipping.
+//
+// The numbers are only increasing within an instance, wrap s once per
+// process in front of the sinks that ship entries. Concurrent entries
+// may reach s in a different order than their numbers.
+func Sequence(s Sink) Sink {
+	var id [8]byte
+	// The ID only needs to tell instances apart so a failure
+	// leaves it zero rather than failing the logger.
+	_, _ = rand.Read(id[:])
+	return &sequenceSink{
+		s:  s,
+		id: hex.EncodeToString(id[:]),
+	}
+}
+
+type sequenceSink struct {
+	// n is first for 64 bit alignment.
+	n  uint64
+	s  Sink
+	id string
+}
+
+func (s *sequenceSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	seq := atomic.AddUint64(&s.n, 1)
+	ent.Fields = ent.Fields.append(M(
+		F("seq", seq),
+		F("logger_instance", s.id),
+	))
+	s.s.LogEntry(ctx, ent)
+}
+
+func (s *sequenceSink) Sync() {
+	s.s.Sync()
+}
+
+func (s *sequenceSink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
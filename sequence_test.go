@@ -0,0 +1,32 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSequence(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Sequence(s), slog.Sequence(s))
+	l = l.With(slog.F("a", 1))
+	l.Info(bg, "first")
+	l.Info(bg, "second")
+
+	assert.Len(t, "entries", 4, s.entries)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("a", 1),
+		slog.F("seq", uint64(2)),
+		slog.F("logger_instance", fieldValue(s.entries[2].Fields, "logger_instance")),
+	), s.entries[2].Fields)
+	assert.Equal(t, "first seq", uint64(1), fieldValue(s.entries[0].Fields, "seq"))
+	assert.Equal(t, "second seq", uint64(2), fieldValue(s.entries[3].Fields, "seq"))
+
+	id := fieldValue(s.entries[0].Fields, "logger_instance").(string)
+	assert.Len(t, "instance", 16, id)
+	assert.Equal(t, "same instance", id, fieldValue(s.entries[2].Fields, "logger_instance"))
+	assert.True(t, "other instance", id != fieldValue(s.entries[1].Fields, "logger_instance"))
+}
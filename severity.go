@@ -0,0 +1,121 @@
+package slog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+)
+
+// Severity describes a level in terms of other logging systems
+// so that every sink maps levels the same way.
+type Severity struct {
+	// Syslog is the RFC 5424 severity from 0 (emergency) to 7 (debug).
+	// It is also used for the sd-daemon(3) priority.
+	Syslog int
+	// OTel is the OpenTelemetry SeverityNumber from 1 (TRACE) to 24 (FATAL4).
+	OTel int
+	// Stackdriver is the name of the Google Cloud Logging LogSeverity.
+	// e.g. "WARNING"
+	Stackdriver string
+}
+
+// syslogNames are the lowercase names of the syslog severities.
+var syslogNames = [...]string{
+	"emergency",
+	"alert",
+	"critical",
+	"error",
+	"warning",
+	"notice",
+	"info",
+	"debug",
+}
+
+// SyslogName returns the lowercase name of the syslog severity.
+// e.g. "warning"
+func (s Severity) SyslogName() string {
+	if s.Syslog < 0 || s.Syslog >= len(syslogNames) {
+		return "info"
+	}
+	return syslogNames[s.Syslog]
+}
+
+// stackdriverNames are the names of the Google Cloud Logging
+// LogSeverity values.
+var stackdriverNames = map[string]bool{
+	"DEFAULT":   true,
+	"DEBUG":     true,
+	"INFO":      true,
+	"NOTICE":    true,
+	"WARNING":   true,
+	"ERROR":     true,
+	"CRITICAL":  true,
+	"ALERT":     true,
+	"EMERGENCY": true,
+}
+
+var severities struct {
+	mu sync.Mutex
+	// m holds a map[Level]Severity that is replaced rather than modified.
+	m atomic.Value
+}
+
+func init() {
+	// FATAL is alert rather than emergency as journald broadcasts
+	// emergency messages to every terminal and emergency means the
+	// whole system is unusable. It stays CRITICAL in Stackdriver as
+	// it always was so that existing alerting filters keep matching.
+	severities.m.Store(map[Level]Severity{
+		LevelDebug:    {Syslog: 7, OTel: 5, Stackdriver: "DEBUG"},
+		LevelInfo:     {Syslog: 6, OTel: 9, Stackdriver: "INFO"},
+		LevelWarn:     {Syslog: 4, OTel: 13, Stackdriver: "WARNING"},
+		LevelError:    {Syslog: 3, OTel: 17, Stackdriver: "ERROR"},
+		LevelCritical: {Syslog: 2, OTel: 18, Stackdriver: "CRITICAL"},
+		LevelFatal:    {Syslog: 1, OTel: 21, Stackdriver: "CRITICAL"},
+	})
+}
+
+// SetSeverity overrides the severity of level or
+// sets the severity of a custom level.
+//
+// It is meant to be called during initialization.
+// It panics if s.Stackdriver is not the name of a LogSeverity.
+func SetSeverity(level Level, s Severity) {
+	if !stackdriverNames[s.Stackdriver] {
+		panic(xerrors.Errorf("slog: invalid Stackdriver severity %q", s.Stackdriver))
+	}
+
+	severities.mu.Lock()
+	defer severities.mu.Unlock()
+
+	old := severities.m.Load().(map[Level]Severity)
+	m := make(map[Level]Severity, len(old)+1)
+	for l, s := range old {
+		m[l] = s
+	}
+	m[level] = s
+	severities.m.Store(m)
+}
+
+// Severity returns the severity of the level.
+//
+// Levels without a severity have the severity of the closest
+// lower level or of LevelDebug if there is none.
+func (l Level) Severity() Severity {
+	m := severities.m.Load().(map[Level]Severity)
+	if s, ok := m[l]; ok {
+		return s
+	}
+
+	closest, found := LevelDebug, false
+	for l2 := range m {
+		if l2 < l && (!found || l2 > closest) {
+			closest, found = l2, true
+		}
+	}
+	if !found {
+		closest = LevelDebug
+	}
+	return m[closest]
+}
@@ -0,0 +1,31 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSeverity(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "warn", slog.Severity{Syslog: 4, OTel: 13, Stackdriver: "WARNING"}, slog.LevelWarn.Severity())
+	assert.Equal(t, "warn name", "warning", slog.LevelWarn.Severity().SyslogName())
+	assert.Equal(t, "fatal name", "alert", slog.LevelFatal.Severity().SyslogName())
+
+	// Custom levels have the severity of the closest lower level.
+	assert.Equal(t, "above fatal", slog.LevelFatal.Severity(), slog.Level(100).Severity())
+	assert.Equal(t, "below debug", slog.LevelDebug.Severity(), slog.Level(-1).Severity())
+
+	notice := slog.Level(1000)
+	slog.SetSeverity(notice, slog.Severity{Syslog: 5, OTel: 10, Stackdriver: "NOTICE"})
+	assert.Equal(t, "custom", "notice", notice.Severity().SyslogName())
+	assert.Equal(t, "above custom", "NOTICE", slog.Level(1001).Severity().Stackdriver)
+
+	defer func() {
+		assert.True(t, "invalid stackdriver panics", recover() != nil)
+		assert.Equal(t, "not set", "NOTICE", slog.Level(2000).Severity().Stackdriver)
+	}()
+	slog.SetSeverity(slog.Level(2000), slog.Severity{Syslog: 5, OTel: 10, Stackdriver: "notice"})
+}
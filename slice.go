@@ -0,0 +1,86 @@
+package slog
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// SliceOption configures how Slice encodes a slice.
+type SliceOption func(o *sliceOptions)
+
+type sliceOptions struct {
+	limit int
+	elem  func(v interface{}) interface{}
+}
+
+// Limit limits the number of elements encoded by Slice to n.
+// If the slice is longer, the list ends with a string
+// describing how many elements were left out, e.g. "+80 more".
+func Limit(n int) SliceOption {
+	return func(o *sliceOptions) {
+		o.limit = n
+	}
+}
+
+// Elem sets the function that converts every element encoded by Slice
+// into the value that is encoded instead, e.g. to only log the ID
+// of every peer rather than the whole struct.
+func Elem(fn func(v interface{}) interface{}) SliceOption {
+	return func(o *sliceOptions) {
+		o.elem = fn
+	}
+}
+
+// Slice is a convenience constructor for a Field with a slice or array
+// value that is encoded as a list according to opts.
+//
+// Only the elements that are encoded are reflected so logging
+// a prefix of a large slice with Limit is cheap.
+//
+// If v is not a slice or array, it is encoded as usual.
+func Slice(name string, v interface{}, opts ...SliceOption) Field {
+	s := slice{v: v}
+	for _, opt := range opts {
+		opt(&s.opts)
+	}
+	return F(name, s)
+}
+
+type slice struct {
+	v    interface{}
+	opts sliceOptions
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s slice) MarshalJSON() ([]byte, error) {
+	rv := reflect.ValueOf(s.v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.IsNil() {
+			return []byte("null"), nil
+		}
+	case reflect.Array:
+	default:
+		return encode(s.v), nil
+	}
+
+	n := rv.Len()
+	if s.opts.limit > 0 && n > s.opts.limit {
+		n = s.opts.limit
+	}
+	l := make([]interface{}, 0, n+1)
+	for i := 0; i < n; i++ {
+		v := rv.Index(i).Interface()
+		if s.opts.elem != nil {
+			v = s.opts.elem(v)
+		}
+		l = append(l, v)
+	}
+	if more := rv.Len() - n; more > 0 {
+		l = append(l, "+"+strconv.Itoa(more)+" more")
+	}
+	return encode(l), nil
+}
+
+var _ json.Marshaler = slice{}
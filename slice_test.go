@@ -0,0 +1,69 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestSlice(t *testing.T) {
+	t.Parallel()
+
+	type peer struct {
+		ID   int    `json:"id"`
+		Addr string `json:"addr"`
+	}
+	peers := []peer{{1, "a:1"}, {2, "b:2"}, {3, "c:3"}}
+
+	test := func(t *testing.T, f slog.Field, exp string) {
+		t.Helper()
+		act := marshalJSON(t, slog.M(f))
+		assert.Equal(t, "JSON", indentJSON(t, exp), act)
+	}
+
+	t.Run("all", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.Slice("peers", peers), `{"peers": [
+			{"id": 1, "addr": "a:1"},
+			{"id": 2, "addr": "b:2"},
+			{"id": 3, "addr": "c:3"}
+		]}`)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.Slice("peers", peers, slog.Limit(1)), `{"peers": [
+			{"id": 1, "addr": "a:1"},
+			"+2 more"
+		]}`)
+		test(t, slog.Slice("peers", peers, slog.Limit(3)), `{"peers": [
+			{"id": 1, "addr": "a:1"},
+			{"id": 2, "addr": "b:2"},
+			{"id": 3, "addr": "c:3"}
+		]}`)
+	})
+
+	t.Run("elem", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.Slice("peers", peers, slog.Limit(2), slog.Elem(func(v interface{}) interface{} {
+			return v.(peer).ID
+		})), `{"peers": [1, 2, "+1 more"]}`)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.Slice("a", [3]int{1, 2, 3}, slog.Limit(2)), `{"a": [1, 2, "+1 more"]}`)
+	})
+
+	t.Run("notSlice", func(t *testing.T) {
+		t.Parallel()
+
+		test(t, slog.Slice("a", []int(nil)), `{"a": null}`)
+		test(t, slog.Slice("a", 3), `{"a": 3}`)
+	})
+}
@@ -0,0 +1,198 @@
+package slog
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SLO is the dimension of the error budget that the entries of a
+// request count against, e.g. for availability reporting.
+type SLO struct {
+	Service string
+	Route   string
+	Tier    string
+}
+
+// Map returns the fields of slo, omitting empty ones.
+func (slo SLO) Map() Map {
+	var m Map
+	if slo.Service != "" {
+		m = append(m, F("service", slo.Service))
+	}
+	if slo.Route != "" {
+		m = append(m, F("route", slo.Route))
+	}
+	if slo.Tier != "" {
+		m = append(m, F("tier", slo.Tier))
+	}
+	return m
+}
+
+type sloKey struct{}
+
+// WithSLO returns a context with the SLO dimension slo, which is used by
+// TagSLO and SummarizeSLO for the entries logged with it.
+func WithSLO(ctx context.Context, slo SLO) context.Context {
+	return context.WithValue(ctx, sloKey{}, slo)
+}
+
+// SLOFromContext returns the SLO dimension of ctx set with WithSLO.
+func SLOFromContext(ctx context.Context) (SLO, bool) {
+	slo, ok := ctx.Value(sloKey{}).(SLO)
+	return slo, ok
+}
+
+// TagSLO returns a sink that adds an slo field with the SLO dimension of
+// the context to the entries at LevelError and above logged to s.
+// Entries logged with a context without one are logged unchanged.
+func TagSLO(s Sink) Sink {
+	return &sloTagSink{s: s}
+}
+
+type sloTagSink struct {
+	s Sink
+}
+
+func (t *sloTagSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	if ent.Level >= LevelError {
+		if slo, ok := SLOFromContext(ctx); ok {
+			// The fields of entries share their backing array.
+			ent.Fields = append(ent.Fields[:len(ent.Fields):len(ent.Fields)], F("slo", slo.Map()))
+		}
+	}
+	t.s.LogEntry(ctx, ent)
+}
+
+func (t *sloTagSink) Sync() {
+	t.s.Sync()
+}
+
+func (t *sloTagSink) Flush(ctx context.Context) error {
+	return Flush(ctx, t.s)
+}
+
+// SLOSummaryOptions represents the options for the sink returned by
+// SummarizeSLO.
+type SLOSummaryOptions struct {
+	// Interval is the duration of the aggregates. Defaults to a minute.
+	Interval time.Duration
+}
+
+// SummarizeSLO returns a sink that logs entries to s and counts the
+// entries at LevelError and above per SLO dimension of their context.
+// At the end of every interval, it logs an entry at LevelInfo with the
+// message "slog: slo summary" and the fields slo, start, interval and
+// errors per dimension with errors in the interval.
+//
+// Intervals are aligned to multiples of opts.Interval and measured by
+// the times of the entries, so the summary of an interval is logged with
+// the first entry after it or with Sync or Flush.
+//
+// If opts is nil, the defaults are used.
+func SummarizeSLO(s Sink, opts *SLOSummaryOptions) Sink {
+	if opts == nil {
+		opts = &SLOSummaryOptions{}
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &sloSummarySink{
+		s:        s,
+		interval: interval,
+		errors:   make(map[SLO]int),
+	}
+}
+
+type sloSummarySink struct {
+	s        Sink
+	interval time.Duration
+
+	mu     sync.Mutex
+	start  time.Time
+	errors map[SLO]int
+}
+
+func (m *sloSummarySink) LogEntry(ctx context.Context, ent SinkEntry) {
+	m.mu.Lock()
+	var summaries []SinkEntry
+	start := ent.Time.Truncate(m.interval)
+	// Entries logged late count towards the current interval.
+	if start.After(m.start) {
+		summaries = m.summaries()
+		m.start = start
+	}
+	if ent.Level >= LevelError {
+		if slo, ok := SLOFromContext(ctx); ok {
+			m.errors[slo]++
+		}
+	}
+	m.mu.Unlock()
+
+	for _, summary := range summaries {
+		m.s.LogEntry(ctx, summary)
+	}
+	m.s.LogEntry(ctx, ent)
+}
+
+// summaries returns the summaries of the current interval and resets the
+// counts. The mutex must be held.
+func (m *sloSummarySink) summaries() []SinkEntry {
+	if len(m.errors) == 0 {
+		return nil
+	}
+	slos := make([]SLO, 0, len(m.errors))
+	for slo := range m.errors {
+		slos = append(slos, slo)
+	}
+	sort.Slice(slos, func(i, j int) bool {
+		a, b := slos[i], slos[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.Tier < b.Tier
+	})
+
+	summaries := make([]SinkEntry, 0, len(slos))
+	for _, slo := range slos {
+		summaries = append(summaries, SinkEntry{
+			Time:    m.start.Add(m.interval),
+			Level:   LevelInfo,
+			Message: "slog: slo summary",
+			Fields: M(
+				F("slo", slo.Map()),
+				F("start", m.start),
+				F("interval", m.interval),
+				F("errors", m.errors[slo]),
+			),
+		})
+	}
+	m.errors = make(map[SLO]int)
+	return summaries
+}
+
+// summarize logs the summaries of the current interval to s.
+func (m *sloSummarySink) summarize(ctx context.Context) {
+	m.mu.Lock()
+	summaries := m.summaries()
+	m.mu.Unlock()
+
+	for _, summary := range summaries {
+		m.s.LogEntry(ctx, summary)
+	}
+}
+
+func (m *sloSummarySink) Sync() {
+	m.summarize(context.Background())
+	m.s.Sync()
+}
+
+func (m *sloSummarySink) Flush(ctx context.Context) error {
+	m.summarize(ctx)
+	return Flush(ctx, m.s)
+}
@@ -0,0 +1,75 @@
+package slog_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestTagSLO(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.TagSLO(s))
+	ctx := slog.WithSLO(bg, slog.SLO{Service: "api", Route: "/users"})
+
+	l.Info(ctx, "ok")
+	l.Error(ctx, "failed")
+	l.Error(bg, "no slo")
+
+	assert.Len(t, "entries", 3, s.entries)
+	assert.Equal(t, "info", nil, fieldValue(s.entries[0].Fields, "slo"))
+	assert.Equal(t, "error", slog.M(
+		slog.F("service", "api"),
+		slog.F("route", "/users"),
+	), fieldValue(s.entries[1].Fields, "slo"))
+	assert.Equal(t, "no slo", nil, fieldValue(s.entries[2].Fields, "slo"))
+}
+
+func TestSummarizeSLO(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	m := slog.SummarizeSLO(s, nil)
+
+	api := slog.WithSLO(bg, slog.SLO{Service: "api", Tier: "gold"})
+	web := slog.WithSLO(bg, slog.SLO{Service: "web"})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	log := func(ctx context.Context, level slog.Level, after time.Duration) {
+		m.LogEntry(ctx, slog.SinkEntry{
+			Time:    start.Add(after),
+			Level:   level,
+			Message: "request",
+		})
+	}
+	log(api, slog.LevelError, time.Second)
+	log(api, slog.LevelInfo, 2*time.Second)
+	log(web, slog.LevelCritical, 3*time.Second)
+	log(api, slog.LevelError, 4*time.Second)
+	log(bg, slog.LevelError, 5*time.Second)
+	assert.Len(t, "entries", 5, s.entries)
+
+	log(api, slog.LevelInfo, time.Minute+time.Second)
+	assert.Len(t, "summarized", 8, s.entries)
+
+	summary := s.entries[5]
+	assert.Equal(t, "msg", "slog: slo summary", summary.Message)
+	assert.Equal(t, "time", start.Add(time.Minute), summary.Time)
+	assert.Equal(t, "api", slog.M(
+		slog.F("slo", slog.M(slog.F("service", "api"), slog.F("tier", "gold"))),
+		slog.F("start", start),
+		slog.F("interval", time.Minute),
+		slog.F("errors", 2),
+	), summary.Fields)
+	assert.Equal(t, "web", 1, fieldValue(s.entries[6].Fields, "errors"))
+	assert.Equal(t, "entry", "request", s.entries[7].Message)
+
+	log(web, slog.LevelError, time.Minute+2*time.Second)
+	m.Sync()
+	assert.Len(t, "synced", 10, s.entries)
+	assert.Equal(t, "synced start", start.Add(time.Minute), fieldValue(s.entries[9].Fields, "start"))
+	assert.Equal(t, "syncs", 1, s.syncs)
+}
@@ -25,29 +25,64 @@ var defaultExitFn = os.Exit
 
 // Sink is the destination of a Logger.
 //
+// LogEntry is called with the context passed to the Logger so that sinks
+// can honor its deadline or read request scoped values such as the
+// tenant or credentials. The fields from slog.With are already in e.
+//
 // All sinks must be safe for concurrent use.
 type Sink interface {
 	LogEntry(ctx context.Context, e SinkEntry)
 	Sync()
 }
 
+// ErrorSink is implemented by sinks that can report
+// failures to log or sync entries.
+//
+// Sinks that wrap other sinks can use it to detect
+// and handle failures.
+type ErrorSink interface {
+	Sink
+	LogEntryErr(ctx context.Context, e SinkEntry) error
+	SyncErr() error
+}
+
 // Log logs the given entry with the context to the
 // underlying sinks.
 //
-// It extends the entry with the set fields and names.
+// It extends the entry with the set fields and names. Use it with
+// Entry or with entries from other sources, e.g. to forward or replay
+// them. The entry is not otherwise modified, e.g. its time and location
+// are kept, and the sinks must not modify it so it can be logged again.
 func (l Logger) Log(ctx context.Context, e SinkEntry) {
-	if e.Level < l.level {
+	l.testHelper(ctx).Helper()
+	if e.Level < l.level || !levelEnabled(e.Level) {
 		return
 	}
 
 	e.Fields = l.fields.append(e.Fields)
 	e.LoggerNames = appendNames(l.names, e.LoggerNames...)
+	e = e.extractTags()
+	if l.encoders != nil {
+		e.Fields, _ = l.encoders.encodeMap(e.Fields)
+	}
 
 	for _, s := range l.sinks {
 		s.LogEntry(ctx, e)
 	}
 }
 
+// levelEnabled reports whether entries at level are compiled in.
+// See DebugEnabled.
+func levelEnabled(level Level) bool {
+	switch {
+	case !InfoEnabled && level < LevelWarn:
+		return false
+	case !DebugEnabled && level < LevelInfo:
+		return false
+	}
+	return true
+}
+
 // Sync calls Sync on all the underlying sinks.
 func (l Logger) Sync() {
 	for _, s := range l.sinks {
@@ -67,6 +102,10 @@ type Logger struct {
 
 	skip int
 	exit func(int)
+	// fatalTimeout is the time Fatal waits for the sinks.
+	fatalTimeout time.Duration
+
+	encoders *valueEncoders
 }
 
 // Make creates a logger that writes logs to the passed sinks at LevelInfo.
@@ -80,17 +119,30 @@ func Make(sinks ...Sink) Logger {
 }
 
 // Debug logs the msg and fields at LevelDebug.
+//
+// It is a no-op when built with the slogdiscard tag. See DebugEnabled.
 func (l Logger) Debug(ctx context.Context, msg string, fields ...Field) {
+	l.testHelper(ctx).Helper()
+	if !DebugEnabled {
+		return
+	}
 	l.log(ctx, LevelDebug, msg, fields)
 }
 
 // Info logs the msg and fields at LevelInfo.
+//
+// It is a no-op when built with the slogdiscardinfo tag. See InfoEnabled.
 func (l Logger) Info(ctx context.Context, msg string, fields ...Field) {
+	l.testHelper(ctx).Helper()
+	if !InfoEnabled {
+		return
+	}
 	l.log(ctx, LevelInfo, msg, fields)
 }
 
 // Warn logs the msg and fields at LevelWarn.
 func (l Logger) Warn(ctx context.Context, msg string, fields ...Field) {
+	l.testHelper(ctx).Helper()
 	l.log(ctx, LevelWarn, msg, fields)
 }
 
@@ -98,6 +150,7 @@ func (l Logger) Warn(ctx context.Context, msg string, fields ...Field) {
 //
 // It will then Sync().
 func (l Logger) Error(ctx context.Context, msg string, fields ...Field) {
+	l.testHelper(ctx).Helper()
 	l.log(ctx, LevelError, msg, fields)
 	l.Sync()
 }
@@ -106,22 +159,25 @@ func (l Logger) Error(ctx context.Context, msg string, fields ...Field) {
 //
 // It will then Sync().
 func (l Logger) Critical(ctx context.Context, msg string, fields ...Field) {
+	l.testHelper(ctx).Helper()
 	l.log(ctx, LevelCritical, msg, fields)
 	l.Sync()
 }
 
 // Fatal logs the msg and fields at LevelFatal.
 //
-// It will then Sync() and os.Exit(1).
+// It will then Sync() and os.Exit(1). If logging and syncing take longer
+// than the fatal timeout, e.g. as a sink is stuck writing to a hung file
+// system or another goroutine holds its lock, the entry is written to
+// stderr instead before exiting. See WithFatalTimeout.
 func (l Logger) Fatal(ctx context.Context, msg string, fields ...Field) {
-	l.log(ctx, LevelFatal, msg, fields)
-	l.Sync()
-
+	l.testHelper(ctx).Helper()
 	if l.exit == nil {
 		l.exit = defaultExitFn
 	}
 
-	l.exit(1)
+	ent := l.entry(ctx, LevelFatal, msg, fields, 1)
+	l.logFatal(ctx, ent)
 }
 
 // With returns a Logger that prepends the given fields on every
@@ -156,11 +212,24 @@ func (l Logger) AppendSinks(s ...Sink) Logger {
 }
 
 func (l Logger) log(ctx context.Context, level Level, msg string, fields Map) {
-	ent := l.entry(ctx, level, msg, fields)
+	l.testHelper(ctx).Helper()
+	ent := l.entry(ctx, level, msg, fields, 2)
 	l.Log(ctx, ent)
 }
 
-func (l Logger) entry(ctx context.Context, level Level, msg string, fields Map) SinkEntry {
+// Entry returns the entry that logging msg and fields at level would
+// create with the time, the location of the caller and the fields of the
+// context and goroutine, without logging it. It can be modified before
+// it is logged with Log, e.g. by adapters of other logging APIs.
+//
+// The fields and names of l are added by Log.
+func (l Logger) Entry(ctx context.Context, level Level, msg string, fields ...Field) SinkEntry {
+	return l.entry(ctx, level, msg, fields, 1)
+}
+
+// entry creates an entry with the location of the caller
+// skip frames above the caller of entry.
+func (l Logger) entry(ctx context.Context, level Level, msg string, fields Map, skip int) SinkEntry {
 	ent := SinkEntry{
 		Time:        time.Now().UTC(),
 		Level:       level,
@@ -168,7 +237,10 @@ func (l Logger) entry(ctx context.Context, level Level, msg string, fields Map)
 		Fields:      fieldsFromContext(ctx).append(fields),
 		SpanContext: trace.FromContext(ctx).SpanContext(),
 	}
-	ent = ent.fillLoc(l.skip + 3)
+	if gf := fieldsFromGoroutine(); len(gf) > 0 {
+		ent.Fields = gf.append(ent.Fields)
+	}
+	ent = ent.fillLoc(l.skip + skip + 1)
 	return ent
 }
 
@@ -285,6 +357,10 @@ type SinkEntry struct {
 	SpanContext trace.SpanContext
 
 	Fields Map
+
+	// Tags are the low-cardinality labels of the entry with string
+	// values. See Tag.
+	Tags Map
 }
 
 // Level represents a log level.
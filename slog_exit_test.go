@@ -1,8 +1,11 @@
 package slog
 
 import (
+	"bytes"
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"cdr.dev/slog/internal/assert"
 )
@@ -33,3 +36,50 @@ func TestExit(t *testing.T) {
 		assert.True(t, "default exit fn used", defaultExitFnCalled)
 	})
 }
+
+type stuckSink chan struct{}
+
+func (s stuckSink) LogEntry(context.Context, SinkEntry) {
+	<-s
+}
+
+func (s stuckSink) Sync() {}
+
+type chanSink chan SinkEntry
+
+func (s chanSink) LogEntry(_ context.Context, ent SinkEntry) {
+	s <- ent
+}
+
+func (s chanSink) Sync() {}
+
+func TestFatalTimeout(t *testing.T) {
+	// This can't be parallel since it modifies a global variable.
+	var (
+		ctx    = context.Background()
+		stderr bytes.Buffer
+		stuck  = make(stuckSink)
+		ok     = make(chanSink, 1)
+		code   = make(chan int, 1)
+	)
+	t.Cleanup(func() { close(stuck) })
+
+	prevStderr := fatalStderr
+	t.Cleanup(func() { fatalStderr = prevStderr })
+	fatalStderr = &stderr
+
+	l := Make(ok, stuck).With(F("svc", "api")).WithFatalTimeout(10 * time.Millisecond)
+	l.exit = func(c int) { code <- c }
+	go func() {
+		l.Fatal(ctx, "disk gone", F("path", "/mnt"))
+	}()
+
+	assert.Equal(t, "exit code", 1, <-code)
+	assert.Equal(t, "other sink", "disk gone", (<-ok).Message)
+
+	out := stderr.String()
+	for _, s := range []string{"timed out after 10ms", "[FATAL]", "slog_exit_test.go", `"disk gone"`, `"svc":"api"`, `"path":"/mnt"`} {
+		assert.True(t, "stderr contains "+s, strings.Contains(out, s))
+	}
+	assert.Equal(t, "one line", 1, strings.Count(out, "\n"))
+}
@@ -156,3 +156,50 @@ func TestLevel_String(t *testing.T) {
 
 	assert.Equal(t, "level string", "slog.Level(12)", slog.Level(12).String())
 }
+
+func TestLogger_Context(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(bg, key{}, "tenant"))
+	cancel()
+
+	var got context.Context
+	l := slog.Make(ctxSink(func(ctx context.Context) {
+		got = ctx
+	}))
+	l.Info(ctx, "wow")
+
+	assert.Equal(t, "value", "tenant", got.Value(key{}))
+	assert.Equal(t, "err", context.Canceled, got.Err())
+}
+
+// ctxSink calls the function with the context of every entry.
+type ctxSink func(ctx context.Context)
+
+func (s ctxSink) LogEntry(ctx context.Context, e slog.SinkEntry) {
+	s(ctx)
+}
+
+func (s ctxSink) Sync() {}
+
+func TestLogger_Entry(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(s).Named("forwarder").With(slog.F("a", 1))
+	ent := l.Entry(bg, slog.LevelInfo, "hello", slog.F("b", 2))
+	_, _, line, _ := runtime.Caller(0)
+	assert.Equal(t, "file", slogTestFile, ent.File)
+	assert.Equal(t, "line", line-1, ent.Line)
+	assert.Equal(t, "fields", slog.M(slog.F("b", 2)), ent.Fields)
+	assert.Len(t, "entries", 0, s.entries)
+
+	ent.Level = slog.LevelWarn
+	l.Log(bg, ent)
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "level", slog.LevelWarn, s.entries[0].Level)
+	assert.Equal(t, "line", line-1, s.entries[0].Line)
+	assert.Equal(t, "names", []string{"forwarder"}, s.entries[0].LoggerNames)
+	assert.Equal(t, "logged fields", slog.M(slog.F("a", 1), slog.F("b", 2)), s.entries[0].Fields)
+}
@@ -0,0 +1,9 @@
+package slogauto
+
+func SetOnGCE(v bool) func() {
+	old := onGCE
+	onGCE = func() bool { return v }
+	return func() {
+		onGCE = old
+	}
+}
@@ -0,0 +1,88 @@
+// Package slogauto chooses a sink for the environment the program runs in.
+//
+// It is a one call default for programs that run both in terminals
+// and in containers:
+//
+//	log := slog.Make(slogauto.Sink(os.Stderr))
+//
+// It cannot be part of package slog as the sinks import slog.
+package slogauto // import "cdr.dev/slog/slogauto"
+
+import (
+	"io"
+	"os"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"cdr.dev/slog/sloggers/slogjson"
+	"cdr.dev/slog/sloggers/slogstackdriver"
+)
+
+// Format is a format chosen by Detect.
+type Format int
+
+const (
+	// FormatHuman is the format of sloghuman.
+	FormatHuman Format = iota
+	// FormatJSON is the format of slogjson.
+	FormatJSON
+	// FormatStackdriver is the format of slogstackdriver.
+	FormatStackdriver
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatHuman:
+		return "human"
+	case FormatJSON:
+		return "json"
+	case FormatStackdriver:
+		return "stackdriver"
+	default:
+		return "unknown"
+	}
+}
+
+// onGCE is overridden in tests.
+var onGCE = metadata.OnGCE
+
+// Detect returns the format Sink uses for w.
+//
+// Terminals get the human format. Otherwise, on Google Compute Engine,
+// including GKE and Cloud Run, the logging agent parses the Stackdriver
+// format. Everywhere else, e.g. the stdout of Docker and Kubernetes
+// containers, the JSON format is used.
+//
+// Detecting GCE queries the metadata server once per process.
+func Detect(w io.Writer) Format {
+	if isTTY(w) {
+		return FormatHuman
+	}
+	if onGCE() {
+		return FormatStackdriver
+	}
+	return FormatJSON
+}
+
+// Sink returns a sink that writes to w in the format returned by Detect.
+//
+// The human format is colored unless the NO_COLOR environment
+// variable is set. See https://no-color.org
+func Sink(w io.Writer) slog.Sink {
+	switch Detect(w) {
+	case FormatHuman:
+		return sloghuman.Sink(w)
+	case FormatStackdriver:
+		return slogstackdriver.Sink(w)
+	default:
+		return slogjson.Sink(w)
+	}
+}
+
+func isTTY(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
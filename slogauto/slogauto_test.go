@@ -0,0 +1,39 @@
+package slogauto_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogauto"
+)
+
+var bg = context.Background()
+
+// The tests are not parallel as they override the GCE detection.
+
+func TestDetect(t *testing.T) {
+	reset := slogauto.SetOnGCE(false)
+	defer reset()
+
+	b := &bytes.Buffer{}
+	assert.Equal(t, "format", slogauto.FormatJSON, slogauto.Detect(b))
+
+	slog.Make(slogauto.Sink(b)).Info(bg, "hello")
+	var m map[string]interface{}
+	err := json.Unmarshal(b.Bytes(), &m)
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "msg", "hello", m["msg"])
+}
+
+func TestDetectGCE(t *testing.T) {
+	reset := slogauto.SetOnGCE(true)
+	defer reset()
+
+	b := &bytes.Buffer{}
+	assert.Equal(t, "format", slogauto.FormatStackdriver, slogauto.Detect(b))
+	assert.Equal(t, "string", "stackdriver", slogauto.Detect(b).String())
+}
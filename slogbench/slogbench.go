@@ -0,0 +1,202 @@
+// Package slogbench contains benchmarks of slog and its sinks.
+//
+// The same workloads can be run against other logging libraries,
+// such as zap or zerolog, by implementing Logger for them in a
+// separate module so that they do not become dependencies of slog:
+//
+//	func BenchmarkZap(b *testing.B) {
+//		slogbench.Benchmark(b, func(w io.Writer) slogbench.Logger {
+//			return zapLogger{zap.New(zapcore.NewCore(enc, zapcore.AddSync(w), zap.InfoLevel))}
+//		})
+//	}
+//
+// Run the benchmarks several times and compare the results
+// with golang.org/x/perf/cmd/benchstat:
+//
+//	go test -run=NONE -bench=. -benchmem -count=10 ./slogbench > new.txt
+//	benchstat old.txt new.txt
+package slogbench // import "cdr.dev/slog/slogbench"
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// Logger is a logger under benchmark. Its level must be info.
+type Logger interface {
+	// Info logs msg with the fields at the info level.
+	Info(msg string, fields ...slog.Field)
+	// Debug logs msg with the fields at the debug level,
+	// which is disabled.
+	Debug(msg string, fields ...slog.Field)
+	// With returns a logger that adds the fields to every entry.
+	With(fields ...slog.Field) Logger
+}
+
+// NewLogger creates a Logger that writes to w.
+type NewLogger func(w io.Writer) Logger
+
+// Slog returns a NewLogger for slog with the sink returned by newSink,
+// e.g. slogjson.Sink.
+func Slog(newSink func(w io.Writer) slog.Sink) NewLogger {
+	return func(w io.Writer) Logger {
+		return slogLogger{slog.Make(newSink(w))}
+	}
+}
+
+type slogLogger struct {
+	l slog.Logger
+}
+
+func (l slogLogger) Info(msg string, fields ...slog.Field) {
+	l.l.Info(context.Background(), msg, fields...)
+}
+
+func (l slogLogger) Debug(msg string, fields ...slog.Field) {
+	l.l.Debug(context.Background(), msg, fields...)
+}
+
+func (l slogLogger) With(fields ...slog.Field) Logger {
+	return slogLogger{l.l.With(fields...)}
+}
+
+type user struct {
+	ID    int       `json:"id"`
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Since time.Time `json:"since"`
+}
+
+var (
+	ts = time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	u  = user{ID: 42, Name: "Jane Doe", Email: "jane@example.com", Since: ts}
+
+	// typedFields are the fields of the benchmarks of field encoding by type.
+	typedFields = []struct {
+		name  string
+		field slog.Field
+	}{
+		{"string", slog.F("string", "a moderately long string value")},
+		{"int", slog.F("int", 123456789)},
+		{"float", slog.F("float", 3.14159)},
+		{"bool", slog.F("bool", true)},
+		{"time", slog.F("time", ts)},
+		{"duration", slog.F("duration", 1500*time.Millisecond)},
+		{"error", slog.Error(xerrors.New("something went wrong"))},
+		{"struct", slog.F("struct", u)},
+		{"map", slog.F("map", slog.M(slog.F("a", 1), slog.F("b", "two"), slog.F("c", 3.0)))},
+		{"slice", slog.F("slice", []int{1, 2, 3, 4, 5, 6, 7, 8})},
+	}
+
+	fiveFields = []slog.Field{
+		slog.F("string", "value"),
+		slog.F("int", 1),
+		slog.F("float", 2.5),
+		slog.F("bool", false),
+		slog.F("duration", time.Second),
+	}
+)
+
+// countingWriter counts the bytes written so that the benchmarks
+// can report them without the cost of a real destination.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	atomic.AddInt64(&w.n, int64(len(p)))
+	return len(p), nil
+}
+
+func run(b *testing.B, newLogger NewLogger, fn func(b *testing.B, l Logger)) {
+	w := &countingWriter{}
+	l := newLogger(w)
+	b.ReportAllocs()
+	b.ResetTimer()
+	fn(b, l)
+	b.StopTimer()
+	if b.N > 0 {
+		b.ReportMetric(float64(atomic.LoadInt64(&w.n))/float64(b.N), "B/entry")
+	}
+}
+
+// Benchmark runs every workload as a sub-benchmark
+// with loggers created by newLogger:
+//
+//   - disabled: a disabled debug call with five fields
+//   - message: an entry without fields
+//   - fields/<type>: an entry with a single field of each type
+//   - with_depth/<n>: an entry from a logger with n chained With calls
+//   - concurrent: entries with five fields logged from GOMAXPROCS goroutines
+func Benchmark(b *testing.B, newLogger NewLogger) {
+	b.Run("disabled", func(b *testing.B) {
+		run(b, newLogger, func(b *testing.B, l Logger) {
+			for i := 0; i < b.N; i++ {
+				l.Debug("disabled", fiveFields...)
+			}
+		})
+	})
+
+	b.Run("message", func(b *testing.B) {
+		run(b, newLogger, func(b *testing.B, l Logger) {
+			for i := 0; i < b.N; i++ {
+				l.Info("a message without fields")
+			}
+		})
+	})
+
+	b.Run("fields", func(b *testing.B) {
+		for _, tf := range typedFields {
+			f := tf.field
+			b.Run(tf.name, func(b *testing.B) {
+				run(b, newLogger, func(b *testing.B, l Logger) {
+					for i := 0; i < b.N; i++ {
+						l.Info("typed field", f)
+					}
+				})
+			})
+		}
+	})
+
+	b.Run("with_depth", func(b *testing.B) {
+		for _, depth := range []int{1, 4, 16} {
+			depth := depth
+			b.Run(strconv.Itoa(depth), func(b *testing.B) {
+				run(b, newLogger, func(b *testing.B, l Logger) {
+					for i := 0; i < depth; i++ {
+						l = l.With(slog.F("with"+strconv.Itoa(i), i))
+					}
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						l.Info("chained")
+					}
+				})
+			})
+		}
+	})
+
+	b.Run("concurrent", func(b *testing.B) {
+		run(b, newLogger, func(b *testing.B, l Logger) {
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					l.Info("concurrent", fiveFields...)
+				}
+			})
+		})
+	})
+}
+
+// Discard is a NewLogger for slog with a sink that discards entries
+// without encoding them. It measures the cost of the Logger alone.
+var Discard NewLogger = func(w io.Writer) Logger {
+	l, _ := slog.Discard()
+	return slogLogger{l.Leveled(slog.LevelInfo)}
+}
@@ -0,0 +1,21 @@
+package slogbench_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog/slogbench"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+func BenchmarkDiscard(b *testing.B) {
+	slogbench.Benchmark(b, slogbench.Discard)
+}
+
+func BenchmarkJSON(b *testing.B) {
+	slogbench.Benchmark(b, slogbench.Slog(slogjson.Sink))
+}
+
+func BenchmarkHuman(b *testing.B) {
+	slogbench.Benchmark(b, slogbench.Slog(sloghuman.Sink))
+}
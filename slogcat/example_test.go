@@ -0,0 +1,21 @@
+package slogcat_test
+
+import (
+	"os"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/slogcat"
+)
+
+func Example() {
+	// e.g. kubectl logs my-pod | my-slogcat
+	err := slogcat.Cat(os.Stdout, os.Stdin, &slogcat.Filter{
+		Level: slog.LevelWarn,
+		Names: []string{"http"},
+	})
+	if err != nil {
+		os.Exit(1)
+	}
+
+	// 2019-12-07 21:20:56.974 [WARN]	(http.client)	<cdr.dev/slog/examples/main.go:85>	main	request failed	{"status": 502}
+}
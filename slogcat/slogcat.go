@@ -0,0 +1,143 @@
+// Package slogcat converts the output of sloggers/slogjson
+// back into the human readable format of sloggers/sloghuman.
+//
+// It is meant for building tools that pretty print JSON logs
+// from e.g. kubectl logs. See the example.
+package slogcat // import "cdr.dev/slog/slogcat"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+// maxLineSize is the maximum size of a single line of input.
+const maxLineSize = 16 << 20
+
+// Filter selects the entries to write.
+//
+// The zero value matches every entry.
+type Filter struct {
+	// Level is the minimum level of entries.
+	Level slog.Level
+	// Names are the logger names of entries.
+	// An entry matches a name if its logger names joined
+	// by periods are equal to it or start with it followed
+	// by a period. e.g. "http" matches "http" and "http.client".
+	// If empty, entries with any logger names match.
+	Names []string
+	// Since is the earliest time of entries, inclusive.
+	Since time.Time
+	// Until is the latest time of entries, exclusive.
+	Until time.Time
+}
+
+// Match reports whether ent matches the filter.
+func (f *Filter) Match(ent slog.SinkEntry) bool {
+	if f == nil {
+		return true
+	}
+	if ent.Level < f.Level {
+		return false
+	}
+	if !f.Since.IsZero() && ent.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !ent.Time.Before(f.Until) {
+		return false
+	}
+	if len(f.Names) == 0 {
+		return true
+	}
+	name := strings.Join(ent.LoggerNames, ".")
+	for _, n := range f.Names {
+		if name == n || strings.HasPrefix(name, n+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Decoder reads JSON entries from a stream
+// with one entry per line.
+type Decoder struct {
+	s *bufio.Scanner
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxLineSize)
+	return &Decoder{
+		s: s,
+	}
+}
+
+// Next returns the next line of the stream.
+//
+// If the line is a JSON entry, ok is true and ent contains it.
+// Otherwise line contains the raw line, e.g. from a program
+// that writes to stdout directly.
+// At the end of the stream io.EOF is returned.
+func (d *Decoder) Next() (ent slog.SinkEntry, line []byte, ok bool, err error) {
+	if !d.s.Scan() {
+		err := d.s.Err()
+		if err == nil {
+			err = io.EOF
+		}
+		return slog.SinkEntry{}, nil, false, err
+	}
+
+	line = d.s.Bytes()
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return slog.SinkEntry{}, line, false, nil
+	}
+	err = json.Unmarshal(trimmed, &ent)
+	if err != nil {
+		return slog.SinkEntry{}, line, false, nil
+	}
+	return ent, line, true, nil
+}
+
+// Cat reads JSON entries from r and writes the ones matching
+// f to w in the human readable format.
+//
+// Lines that are not JSON entries are written to w unchanged.
+// As with sloghuman, colors are used if w is a terminal.
+func Cat(w io.Writer, r io.Reader, f *Filter) error {
+	sink := sloghuman.Sink(w)
+	defer sink.Sync()
+
+	d := NewDecoder(r)
+	for {
+		ent, line, ok, err := d.Next()
+		if err != nil {
+			if xerrors.Is(err, io.EOF) {
+				return nil
+			}
+			return xerrors.Errorf("failed to read entry: %w", err)
+		}
+
+		if !ok {
+			_, err = w.Write(append(line, '\n'))
+			if err != nil {
+				return xerrors.Errorf("failed to write line: %w", err)
+			}
+			continue
+		}
+
+		if f.Match(ent) {
+			sink.LogEntry(context.Background(), ent)
+		}
+	}
+}
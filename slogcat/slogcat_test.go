@@ -0,0 +1,90 @@
+package slogcat_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogcat"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+var kt = time.Date(2000, time.February, 5, 4, 4, 4, 4, time.UTC)
+
+func TestCat(t *testing.T) {
+	t.Parallel()
+
+	ents := []slog.SinkEntry{
+		{
+			Time:        kt,
+			Level:       slog.LevelInfo,
+			Message:     "skipped for level",
+			LoggerNames: []string{"http"},
+		},
+		{
+			Time:        kt,
+			Level:       slog.LevelError,
+			Message:     "hello",
+			LoggerNames: []string{"http", "client"},
+			File:        "/src/main.go",
+			Line:        10,
+			Func:        "main.main",
+			Fields:      slog.M(slog.F("status", 502)),
+		},
+		{
+			Time:        kt,
+			Level:       slog.LevelError,
+			Message:     "skipped for name",
+			LoggerNames: []string{"https"},
+		},
+		{
+			Time:    kt.Add(time.Hour),
+			Level:   slog.LevelError,
+			Message: "skipped for time",
+		},
+	}
+
+	in := &bytes.Buffer{}
+	for i, ent := range ents {
+		b, err := json.Marshal(ent)
+		assert.Success(t, "marshal entry", err)
+		in.Write(b)
+		in.WriteByte('\n')
+		if i == 0 {
+			in.WriteString("not json\n")
+		}
+	}
+
+	out := &bytes.Buffer{}
+	err := slogcat.Cat(out, in, &slogcat.Filter{
+		Level: slog.LevelWarn,
+		Names: []string{"http"},
+		Until: kt.Add(time.Minute),
+	})
+	assert.Success(t, "cat", err)
+
+	// The second entry after being parsed from JSON.
+	exp := &bytes.Buffer{}
+	ent := ents[1]
+	ent.Fields = slog.M(slog.F("status", json.Number("502")))
+	sloghuman.Sink(exp).LogEntry(context.Background(), ent)
+
+	assert.Equal(t, "output", "not json\n"+exp.String(), out.String())
+	assert.True(t, "status", strings.Contains(out.String(), `{"status": 502}`))
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	var f *slogcat.Filter
+	assert.True(t, "nil filter", f.Match(slog.SinkEntry{}))
+
+	f = &slogcat.Filter{Since: kt}
+	assert.False(t, "before since", f.Match(slog.SinkEntry{Time: kt.Add(-time.Second)}))
+	assert.True(t, "at since", f.Match(slog.SinkEntry{Time: kt}))
+}
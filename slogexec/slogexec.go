@@ -0,0 +1,178 @@
+// Package slogexec logs the output of subprocesses as entries.
+//
+// Every line the process writes to stdout or stderr becomes an entry of
+// a logger named after the command with the PID of the process in the
+// pid field. Lines of processes that log with slogjson, e.g. helper
+// binaries that use slog themselves, can be parsed as entries so that
+// their level, fields and location are kept. The levels of other lines
+// can be assigned by classifiers, e.g. so that the errors of third
+// party binaries are logged as errors.
+package slogexec // import "cdr.dev/slog/slogexec"
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"cdr.dev/slog"
+)
+
+// Options represents the options for Attach and Run.
+type Options struct {
+	// Name is the name of the logger of the entries.
+	// Defaults to the base name of the path of the command.
+	Name string
+	// JSON parses lines in the slogjson format as entries. The names of
+	// the loggers of the process are appended to Name. Lines that are
+	// not entries are logged as is.
+	JSON bool
+	// Classifiers assign levels to the lines that are not parsed as
+	// entries. The first classifier that matches a line sets its level.
+	// Lines that none match keep the level of their stream.
+	Classifiers []Classifier
+}
+
+// Classifier returns the level of a line of output
+// and whether it could classify the line.
+type Classifier func(line string) (slog.Level, bool)
+
+// Regexp returns a classifier that assigns level
+// to the lines that match re.
+func Regexp(re *regexp.Regexp, level slog.Level) Classifier {
+	return func(line string) (slog.Level, bool) {
+		return level, re.MatchString(line)
+	}
+}
+
+// JSONLevel returns a classifier of lines that are JSON objects with the
+// level in one of the fields with the given names, e.g. "level" or
+// "severity". Common level names such as "warning", "err" or "panic"
+// are recognized regardless of case.
+func JSONLevel(names ...string) Classifier {
+	return func(line string) (slog.Level, bool) {
+		if !strings.HasPrefix(line, "{") {
+			return 0, false
+		}
+		var obj map[string]interface{}
+		err := json.Unmarshal([]byte(line), &obj)
+		if err != nil {
+			return 0, false
+		}
+		for _, name := range names {
+			v, ok := obj[name].(string)
+			if !ok {
+				continue
+			}
+			if level, ok := levelNames[strings.ToLower(v)]; ok {
+				return level, true
+			}
+		}
+		return 0, false
+	}
+}
+
+var levelNames = map[string]slog.Level{
+	"trace":       slog.LevelDebug,
+	"debug":       slog.LevelDebug,
+	"info":        slog.LevelInfo,
+	"information": slog.LevelInfo,
+	"notice":      slog.LevelInfo,
+	"warn":        slog.LevelWarn,
+	"warning":     slog.LevelWarn,
+	"err":         slog.LevelError,
+	"error":       slog.LevelError,
+	"crit":        slog.LevelCritical,
+	"critical":    slog.LevelCritical,
+	"alert":       slog.LevelCritical,
+	"emerg":       slog.LevelCritical,
+	"emergency":   slog.LevelCritical,
+	"panic":       slog.LevelCritical,
+	"fatal":       slog.LevelFatal,
+}
+
+// Attach sets the Stdout and Stderr of cmd to writers that log every
+// line to l. Lines of stdout are logged at LevelInfo and of stderr at
+// LevelWarn unless classified otherwise with the stream field set to
+// "stdout" or "stderr".
+//
+// Call flush once the process has exited to log partial last lines.
+//
+// If opts is nil, the defaults are used.
+func Attach(ctx context.Context, l slog.Logger, cmd *exec.Cmd, opts *Options) (flush func()) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	name := opts.Name
+	if name == "" {
+		name = filepath.Base(cmd.Path)
+	}
+	l = l.Named(name)
+
+	stdout := writer(ctx, l, cmd, opts, "stdout", slog.LevelInfo)
+	stderr := writer(ctx, l, cmd, opts, "stderr", slog.LevelWarn)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return func() {
+		stdout.Close()
+		stderr.Close()
+	}
+}
+
+// Run runs cmd with its output logged to l as with Attach.
+func Run(ctx context.Context, l slog.Logger, cmd *exec.Cmd, opts *Options) error {
+	flush := Attach(ctx, l, cmd, opts)
+	defer flush()
+	return cmd.Run()
+}
+
+func writer(ctx context.Context, l slog.Logger, cmd *exec.Cmd, opts *Options, stream string, level slog.Level) io.WriteCloser {
+	s := &lineSink{
+		l:           l,
+		cmd:         cmd,
+		json:        opts.JSON,
+		classifiers: opts.Classifiers,
+		stream:      slog.F("stream", stream),
+	}
+	return slog.Writer(ctx, slog.Make(s).Leveled(slog.LevelDebug), level)
+}
+
+// lineSink logs the lines of a stream to l.
+type lineSink struct {
+	l           slog.Logger
+	cmd         *exec.Cmd
+	json        bool
+	classifiers []Classifier
+	stream      slog.Field
+}
+
+func (s *lineSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	l := s.l
+	if s.cmd.Process != nil {
+		l = l.With(slog.F("pid", s.cmd.Process.Pid))
+	}
+
+	if s.json && len(ent.Message) > 0 && ent.Message[0] == '{' {
+		var child slog.SinkEntry
+		err := child.UnmarshalJSON([]byte(ent.Message))
+		if err == nil && !child.Time.IsZero() {
+			l.Log(ctx, child)
+			return
+		}
+	}
+	for _, c := range s.classifiers {
+		if level, ok := c(ent.Message); ok {
+			ent.Level = level
+			break
+		}
+	}
+	ent.Fields = append(ent.Fields, s.stream)
+	l.Log(ctx, ent)
+}
+
+func (s *lineSink) Sync() {
+	s.l.Sync()
+}
@@ -0,0 +1,128 @@
+package slogexec_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogexec"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+var bg = context.Background()
+
+func TestMain(m *testing.M) {
+	if os.Getenv("SLOGEXEC_HELPER") == "1" {
+		fmt.Println("plain line")
+		fmt.Fprint(os.Stderr, "partial")
+		slog.Make(slogjson.Sink(os.Stdout)).Named("child").Warn(bg, "from child", slog.F("n", 1))
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type fakeSink struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Sync() {}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "SLOGEXEC_HELPER=1")
+	err := slogexec.Run(bg, slog.Make(s), cmd, &slogexec.Options{
+		Name: "helper",
+		JSON: true,
+	})
+	assert.Success(t, "run", err)
+
+	entries := make(map[string]slog.SinkEntry)
+	for _, ent := range s.entries {
+		entries[ent.Message] = ent
+	}
+	assert.Len(t, "entries", 3, entries)
+	pid := slog.F("pid", cmd.Process.Pid)
+
+	plain := entries["plain line"]
+	assert.Equal(t, "plain level", slog.LevelInfo, plain.Level)
+	assert.Equal(t, "plain names", []string{"helper"}, plain.LoggerNames)
+	assert.Equal(t, "plain fields", slog.M(pid, slog.F("stream", "stdout")), plain.Fields)
+
+	partial := entries["partial"]
+	assert.Equal(t, "partial level", slog.LevelWarn, partial.Level)
+	assert.Equal(t, "partial fields", slog.M(pid, slog.F("stream", "stderr")), partial.Fields)
+
+	child := entries["from child"]
+	assert.Equal(t, "child level", slog.LevelWarn, child.Level)
+	assert.Equal(t, "child names", []string{"helper", "child"}, child.LoggerNames)
+	assert.Equal(t, "child func", "cdr.dev/slog/slogexec_test.TestMain", child.Func)
+	assert.Equal(t, "child pid", pid, child.Fields[0])
+	assert.Equal(t, "child field", "n", child.Fields[1].Name)
+}
+
+func TestClassifiers(t *testing.T) {
+	t.Parallel()
+
+	classify := func(line string) (slog.Level, bool) {
+		for _, c := range []slogexec.Classifier{
+			slogexec.JSONLevel("lvl", "severity"),
+			slogexec.Regexp(regexp.MustCompile(`(?i)^error\b`), slog.LevelError),
+		} {
+			if level, ok := c(line); ok {
+				return level, true
+			}
+		}
+		return 0, false
+	}
+
+	level, ok := classify(`{"severity": "WARNING", "msg": "disk"}`)
+	assert.True(t, "json classified", ok)
+	assert.Equal(t, "json level", slog.LevelWarn, level)
+	level, ok = classify(`{"lvl": "panic"}`)
+	assert.True(t, "panic classified", ok)
+	assert.Equal(t, "panic level", slog.LevelCritical, level)
+	level, ok = classify("ERROR: cannot open file")
+	assert.True(t, "regexp classified", ok)
+	assert.Equal(t, "regexp level", slog.LevelError, level)
+	_, ok = classify(`{"severity": "loud"}`)
+	assert.False(t, "unknown level", ok)
+	_, ok = classify("all good")
+	assert.False(t, "unclassified", ok)
+}
+
+func TestRun_Classifiers(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "SLOGEXEC_HELPER=1")
+	err := slogexec.Run(bg, slog.Make(s).Leveled(slog.LevelDebug), cmd, &slogexec.Options{
+		Classifiers: []slogexec.Classifier{
+			slogexec.Regexp(regexp.MustCompile("^partial$"), slog.LevelError),
+		},
+	})
+	assert.Success(t, "run", err)
+
+	levels := make(map[string]slog.Level)
+	for _, ent := range s.entries {
+		levels[ent.Message] = ent.Level
+	}
+	assert.Equal(t, "classified", slog.LevelError, levels["partial"])
+	assert.Equal(t, "unclassified", slog.LevelInfo, levels["plain line"])
+}
@@ -0,0 +1,9 @@
+package slogbreaker
+
+import (
+	"time"
+)
+
+func SetNow(b *Breaker, now func() time.Time) {
+	b.now = now
+}
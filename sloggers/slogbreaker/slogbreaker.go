@@ -0,0 +1,320 @@
+// Package slogbreaker contains a slog.Sink wrapper that stops
+// logging to a sink that is failing or too slow.
+//
+// A logging backend that times out on every entry adds its timeout
+// to every request that logs. With a circuit breaker in front of it,
+// the sink is skipped after a number of consecutive failures and
+// entries are dropped or spooled to another sink. After a cooldown,
+// the next entry is logged to the sink as a probe and the sink is used
+// again if it succeeds.
+//
+// State changes are logged as entries of their own so that the gap
+// in the logs of the sink is explained.
+package slogbreaker // import "cdr.dev/slog/sloggers/slogbreaker"
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/exportctx"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// Closed means entries are logged to the sink.
+	Closed State = iota
+	// Open means entries are dropped or spooled.
+	Open
+	// HalfOpen means an entry is being logged to the sink
+	// to probe whether it has recovered.
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrOpen is returned by LogEntryErr when an entry is
+// dropped because the circuit is open.
+var ErrOpen = xerrors.New("slogbreaker: circuit is open")
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Name identifies the sink in the state change entries.
+	Name string
+	// Failures is the number of consecutive failures after which
+	// the circuit opens. Defaults to 5.
+	//
+	// An entry fails if the sink returns an error from LogEntryErr,
+	// see slog.ErrorSink, or if logging it exceeds Latency.
+	Failures int
+	// Latency is the latency budget of logging an entry. The sink is
+	// called with a context that is done after Latency and if it has
+	// not returned by then, the entry fails without waiting for it so
+	// that a sink that hangs does not block the callers of LogEntry.
+	// The context is also done with the context of the entry unless
+	// that is already done. Disabled if zero.
+	Latency time.Duration
+	// Cooldown is the time the circuit stays open before the
+	// sink is probed. Defaults to 10s.
+	Cooldown time.Duration
+	// Spool receives the entries logged while the circuit is open,
+	// e.g. a file sink. If nil, the entries are dropped.
+	Spool slog.Sink
+	// Events receives the entries about state changes.
+	// Defaults to Spool if set and to a sloghuman sink
+	// on stderr otherwise.
+	Events slog.Sink
+}
+
+// Breaker is a circuit breaker in front of a sink.
+//
+// See Make.
+type Breaker struct {
+	// dropped is first for 64 bit alignment.
+	dropped uint64
+
+	s    slog.Sink
+	opts *Options
+	now  func() time.Time
+
+	mu          sync.Mutex
+	state       State
+	failures    int
+	openedAt    time.Time
+	openDropped uint64
+}
+
+var _ slog.ErrorSink = &Breaker{}
+
+// Make returns a circuit breaker that logs entries to s
+// until it fails opts.Failures times in a row.
+//
+// If opts is nil, the defaults are used.
+func Make(s slog.Sink, opts *Options) *Breaker {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Failures <= 0 {
+		o.Failures = 5
+	}
+	if o.Cooldown <= 0 {
+		o.Cooldown = 10 * time.Second
+	}
+	if o.Events == nil {
+		o.Events = o.Spool
+	}
+	if o.Events == nil {
+		o.Events = sloghuman.Sink(os.Stderr)
+	}
+
+	return &Breaker{
+		s:    s,
+		opts: &o,
+		now:  time.Now,
+	}
+}
+
+// LogEntry implements slog.Sink.
+func (b *Breaker) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = b.LogEntryErr(ctx, ent)
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// It returns ErrOpen if the entry was dropped and the error
+// of the sink if it failed. Entries that exceed the latency
+// budget return an error unless the sink returned before
+// the deadline of its context.
+func (b *Breaker) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	if !b.allow() {
+		if b.opts.Spool != nil {
+			b.opts.Spool.LogEntry(ctx, ent)
+			return nil
+		}
+		atomic.AddUint64(&b.dropped, 1)
+		return ErrOpen
+	}
+
+	start := b.now()
+	err := b.logEntry(ctx, ent)
+	elapsed := b.now().Sub(start)
+
+	failure := err
+	if failure == nil && b.opts.Latency > 0 && elapsed > b.opts.Latency {
+		failure = xerrors.Errorf("logging an entry took %v, more than the latency budget of %v", elapsed, b.opts.Latency)
+	}
+	b.record(ctx, failure)
+	return err
+}
+
+// logEntry logs ent to the sink and waits at most for the latency budget.
+func (b *Breaker) logEntry(ctx context.Context, ent slog.SinkEntry) error {
+	if b.opts.Latency <= 0 {
+		return b.call(ctx, ent)
+	}
+
+	ctx, cancel := exportctx.WithTimeout(ctx, b.opts.Latency)
+	done := make(chan error, 1)
+	go func() {
+		defer cancel()
+		done <- b.call(ctx, ent)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// The sink keeps the entry and may still log it.
+		return xerrors.Errorf("failed to log entry within the latency budget of %v: %w", b.opts.Latency, ctx.Err())
+	}
+}
+
+func (b *Breaker) call(ctx context.Context, ent slog.SinkEntry) error {
+	if es, ok := b.s.(slog.ErrorSink); ok {
+		return es.LogEntryErr(ctx, ent)
+	}
+	b.s.LogEntry(ctx, ent)
+	return nil
+}
+
+// allow reports whether an entry may be logged to the sink.
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Closed:
+		return true
+	case Open:
+		if b.now().Sub(b.openedAt) < b.opts.Cooldown {
+			return false
+		}
+		b.state = HalfOpen
+		return true
+	default:
+		// Another entry is probing the sink.
+		return false
+	}
+}
+
+// record updates the state with the result of logging an entry.
+func (b *Breaker) record(ctx context.Context, failure error) {
+	b.mu.Lock()
+	var event *slog.SinkEntry
+	switch {
+	case failure == nil:
+		b.failures = 0
+		if b.state == HalfOpen {
+			b.state = Closed
+			event = b.event(slog.LevelInfo, "slogbreaker: circuit closed",
+				slog.F("open_for", b.now().Sub(b.openedAt)),
+				slog.F("dropped", atomic.LoadUint64(&b.dropped)-b.openDropped),
+			)
+		}
+	case b.state == HalfOpen:
+		// The probe failed so wait for another cooldown.
+		b.state = Open
+		b.openedAt = b.now()
+	default:
+		b.failures++
+		if b.state == Closed && b.failures >= b.opts.Failures {
+			b.state = Open
+			b.openedAt = b.now()
+			b.openDropped = atomic.LoadUint64(&b.dropped)
+			event = b.event(slog.LevelWarn, "slogbreaker: circuit opened",
+				slog.F("failures", b.failures),
+				slog.Error(failure),
+			)
+		}
+	}
+	b.mu.Unlock()
+
+	if event != nil {
+		b.opts.Events.LogEntry(ctx, *event)
+	}
+}
+
+func (b *Breaker) event(level slog.Level, msg string, fields ...slog.Field) *slog.SinkEntry {
+	if b.opts.Name != "" {
+		fields = append([]slog.Field{slog.F("sink", b.opts.Name)}, fields...)
+	}
+	return &slog.SinkEntry{
+		Time:    b.now(),
+		Level:   level,
+		Message: msg,
+		Fields:  fields,
+	}
+}
+
+// Sync implements slog.Sink.
+func (b *Breaker) Sync() {
+	_ = b.SyncErr()
+}
+
+// SyncErr implements slog.ErrorSink.
+//
+// The sink is only synced while the circuit is closed
+// as syncing a failing sink could block. The spool is
+// always synced.
+func (b *Breaker) SyncErr() error {
+	if b.opts.Spool != nil {
+		b.opts.Spool.Sync()
+	}
+	if b.State() != Closed {
+		return ErrOpen
+	}
+	if es, ok := b.s.(slog.ErrorSink); ok {
+		return es.SyncErr()
+	}
+	b.s.Sync()
+	return nil
+}
+
+// Flush implements slog.Flusher.
+//
+// Like SyncErr, the sink is only flushed while the circuit is closed.
+func (b *Breaker) Flush(ctx context.Context) error {
+	if b.opts.Spool != nil {
+		err := slog.Flush(ctx, b.opts.Spool)
+		if err != nil {
+			return xerrors.Errorf("failed to flush spool: %w", err)
+		}
+	}
+	if b.State() != Closed {
+		return ErrOpen
+	}
+	return slog.Flush(ctx, b.s)
+}
+
+// State returns the current state of the circuit.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Dropped returns the number of entries that were dropped
+// because the circuit was open.
+func (b *Breaker) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
@@ -0,0 +1,230 @@
+package slogbreaker_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogbreaker"
+)
+
+var bg = context.Background()
+
+// clock is a fake clock that only advances when told to.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// fakeSink fails with err and takes delay on the clock to log an entry.
+type fakeSink struct {
+	clock *clock
+
+	mu      sync.Mutex
+	err     error
+	delay   time.Duration
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = s.LogEntryErr(ctx, ent)
+}
+
+func (s *fakeSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clock != nil {
+		s.clock.Advance(s.delay)
+	}
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, ent)
+	return nil
+}
+
+func (s *fakeSink) Sync() {}
+
+func (s *fakeSink) SyncErr() error {
+	return nil
+}
+
+func (s *fakeSink) set(err error, delay time.Duration) {
+	s.mu.Lock()
+	s.err = err
+	s.delay = delay
+	s.mu.Unlock()
+}
+
+func (s *fakeSink) Entries() []slog.SinkEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]slog.SinkEntry(nil), s.entries...)
+}
+
+func TestBreaker(t *testing.T) {
+	t.Parallel()
+
+	c := &clock{now: time.Unix(0, 0)}
+	s := &fakeSink{clock: c, err: io.ErrClosedPipe}
+	events := &fakeSink{}
+	b := slogbreaker.Make(s, &slogbreaker.Options{
+		Name:     "backend",
+		Failures: 2,
+		Cooldown: time.Minute,
+		Events:   events,
+	})
+	slogbreaker.SetNow(b, c.Now)
+
+	err := b.LogEntryErr(bg, slog.SinkEntry{Message: "1"})
+	assert.Equal(t, "err", io.ErrClosedPipe, err)
+	assert.Equal(t, "state", slogbreaker.Closed, b.State())
+	err = b.LogEntryErr(bg, slog.SinkEntry{Message: "2"})
+	assert.Equal(t, "err", io.ErrClosedPipe, err)
+	assert.Equal(t, "state", slogbreaker.Open, b.State())
+
+	ev := events.Entries()
+	assert.Len(t, "events", 1, ev)
+	assert.Equal(t, "msg", "slogbreaker: circuit opened", ev[0].Message)
+	assert.Equal(t, "level", slog.LevelWarn, ev[0].Level)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("sink", "backend"),
+		slog.F("failures", 2),
+		slog.Error(io.ErrClosedPipe),
+	), ev[0].Fields)
+
+	// The sink is skipped while the circuit is open.
+	s.set(nil, 0)
+	err = b.LogEntryErr(bg, slog.SinkEntry{Message: "3"})
+	assert.Equal(t, "err", slogbreaker.ErrOpen, err)
+	assert.Equal(t, "dropped", uint64(1), b.Dropped())
+	assert.Equal(t, "sync", slogbreaker.ErrOpen, b.SyncErr())
+
+	// A failed probe keeps the circuit open for another cooldown.
+	s.set(io.ErrClosedPipe, 0)
+	c.Advance(time.Minute)
+	err = b.LogEntryErr(bg, slog.SinkEntry{Message: "4"})
+	assert.Equal(t, "err", io.ErrClosedPipe, err)
+	assert.Equal(t, "state", slogbreaker.Open, b.State())
+	err = b.LogEntryErr(bg, slog.SinkEntry{Message: "5"})
+	assert.Equal(t, "err", slogbreaker.ErrOpen, err)
+
+	// A successful probe closes it.
+	s.set(nil, 0)
+	c.Advance(time.Minute)
+	err = b.LogEntryErr(bg, slog.SinkEntry{Message: "6"})
+	assert.Success(t, "log entry", err)
+	assert.Equal(t, "state", slogbreaker.Closed, b.State())
+	assert.Success(t, "sync", b.SyncErr())
+
+	ev = events.Entries()
+	assert.Len(t, "events", 2, ev)
+	assert.Equal(t, "msg", "slogbreaker: circuit closed", ev[1].Message)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("sink", "backend"),
+		slog.F("open_for", time.Minute),
+		slog.F("dropped", uint64(2)),
+	), ev[1].Fields)
+
+	entries := s.Entries()
+	assert.Len(t, "entries", 1, entries)
+	assert.Equal(t, "msg", "6", entries[0].Message)
+}
+
+func TestBreaker_Latency(t *testing.T) {
+	t.Parallel()
+
+	c := &clock{now: time.Unix(0, 0)}
+	s := &fakeSink{clock: c, delay: time.Second}
+	spool := &fakeSink{}
+	b := slogbreaker.Make(s, &slogbreaker.Options{
+		Failures: 1,
+		Latency:  100 * time.Millisecond,
+		Spool:    spool,
+	})
+	slogbreaker.SetNow(b, c.Now)
+
+	// The slow entry is still logged.
+	err := b.LogEntryErr(bg, slog.SinkEntry{Message: "slow"})
+	assert.Success(t, "log entry", err)
+	assert.Equal(t, "state", slogbreaker.Open, b.State())
+	assert.Len(t, "entries", 1, s.Entries())
+
+	err = b.LogEntryErr(bg, slog.SinkEntry{Message: "spooled"})
+	assert.Success(t, "log entry", err)
+	assert.Equal(t, "dropped", uint64(0), b.Dropped())
+
+	// The events go to the spool by default.
+	spooled := spool.Entries()
+	assert.Len(t, "spooled", 2, spooled)
+	assert.Equal(t, "event", "slogbreaker: circuit opened", spooled[0].Message)
+	assert.Equal(t, "entry", "spooled", spooled[1].Message)
+}
+
+// hangingSink blocks until its context is done if honor is
+// set and until release is closed otherwise.
+type hangingSink struct {
+	honor   bool
+	release chan struct{}
+}
+
+func (s hangingSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = s.LogEntryErr(ctx, ent)
+}
+
+func (s hangingSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	if s.honor {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	<-s.release
+	return nil
+}
+
+func (s hangingSink) Sync() {}
+
+func (s hangingSink) SyncErr() error {
+	return nil
+}
+
+func TestBreaker_Hang(t *testing.T) {
+	t.Parallel()
+
+	for _, honor := range []bool{true, false} {
+		release := make(chan struct{})
+		defer close(release)
+
+		b := slogbreaker.Make(hangingSink{honor: honor, release: release}, &slogbreaker.Options{
+			Failures: 2,
+			Latency:  10 * time.Millisecond,
+			Events:   &fakeSink{},
+		})
+
+		// A sink that hangs trips the breaker, whether
+		// or not it honors the context.
+		for i := 0; i < 2; i++ {
+			err := b.LogEntryErr(bg, slog.SinkEntry{})
+			assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+		}
+		assert.Equal(t, "state", slogbreaker.Open, b.State())
+		assert.Equal(t, "open", slogbreaker.ErrOpen, b.LogEntryErr(bg, slog.SinkEntry{}))
+	}
+}
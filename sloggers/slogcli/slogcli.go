@@ -0,0 +1,91 @@
+// Package slogcli contains a preset logger for command line tools.
+//
+// Unlike the defaults for servers, entries are written to stderr in the
+// human readable format without the timestamp and location so that the
+// output of the tool on stdout is untouched and its messages are short:
+//
+//	var flags slogcli.Flags
+//	flags.Register(flag.CommandLine)
+//	flag.Parse()
+//	log := slogcli.Make(&slogcli.Options{Flags: flags})
+//
+// With cobra or pflag, bind the fields of Flags to the flags instead:
+//
+//	cmd.PersistentFlags().BoolVarP(&flags.Verbose, "verbose", "v", false, slogcli.VerboseUsage)
+//	cmd.PersistentFlags().BoolVarP(&flags.Quiet, "quiet", "q", false, slogcli.QuietUsage)
+package slogcli // import "cdr.dev/slog/sloggers/slogcli"
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+// The usage of the flags registered by Flags.Register.
+const (
+	VerboseUsage = "log debug messages with their location"
+	QuietUsage   = "only log errors"
+)
+
+// Flags are the verbosity flags of a command.
+type Flags struct {
+	// Verbose logs at LevelDebug with the location of the entries.
+	Verbose bool
+	// Quiet only logs at LevelError and above.
+	// It takes precedence over Verbose.
+	Quiet bool
+}
+
+// Register registers the -v and -verbose, and -q and -quiet flags on fs.
+func (f *Flags) Register(fs *flag.FlagSet) {
+	fs.BoolVar(&f.Verbose, "v", false, VerboseUsage)
+	fs.BoolVar(&f.Verbose, "verbose", false, VerboseUsage)
+	fs.BoolVar(&f.Quiet, "q", false, QuietUsage)
+	fs.BoolVar(&f.Quiet, "quiet", false, QuietUsage)
+}
+
+// Level returns the level of the flags: LevelError if Quiet is set,
+// LevelDebug if Verbose is set and LevelInfo otherwise.
+func (f Flags) Level() slog.Level {
+	switch {
+	case f.Quiet:
+		return slog.LevelError
+	case f.Verbose:
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// Options represents the options for the logger returned by Make.
+type Options struct {
+	Flags
+	// Stderr is where entries are written. Defaults to os.Stderr.
+	Stderr io.Writer
+	// Time writes the timestamp of the entries.
+	Time bool
+	// Human is the format of the entries. Its OmitTime and OmitLocation
+	// are set from Time and Verbose.
+	Human sloghuman.Options
+}
+
+// Make returns a logger for a command line tool that writes entries
+// to stderr at the level from the flags.
+//
+// If opts is nil, the defaults are used.
+func Make(opts *Options) slog.Logger {
+	if opts == nil {
+		opts = &Options{}
+	}
+	w := opts.Stderr
+	if w == nil {
+		w = os.Stderr
+	}
+
+	human := opts.Human
+	human.OmitTime = !opts.Time
+	human.OmitLocation = !opts.Verbose || opts.Quiet
+	return slog.Make(sloghuman.Make(w, &human)).Leveled(opts.Level())
+}
@@ -0,0 +1,50 @@
+package slogcli_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogcli"
+)
+
+var bg = context.Background()
+
+func TestFlags(t *testing.T) {
+	t.Parallel()
+
+	var f slogcli.Flags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Register(fs)
+	assert.Equal(t, "default", slog.LevelInfo, f.Level())
+
+	err := fs.Parse([]string{"-v"})
+	assert.Success(t, "parse", err)
+	assert.Equal(t, "verbose", slog.LevelDebug, f.Level())
+
+	err = fs.Parse([]string{"--quiet"})
+	assert.Success(t, "parse", err)
+	assert.Equal(t, "quiet", slog.LevelError, f.Level())
+}
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slogcli.Make(&slogcli.Options{Stderr: b})
+	l.Debug(bg, "hidden")
+	l.Info(bg, "downloaded", slog.F("file", "a.txt"))
+	assert.Equal(t, "default", "[INFO]\tdownloaded\t{\"file\": \"a.txt\"}\n", b.String())
+
+	b.Reset()
+	l = slogcli.Make(&slogcli.Options{
+		Flags:  slogcli.Flags{Verbose: true},
+		Stderr: b,
+	})
+	l.Debug(bg, "shown")
+	assert.True(t, "location", strings.Contains(b.String(), "slogcli_test.go:"))
+}
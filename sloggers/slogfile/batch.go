@@ -0,0 +1,197 @@
+package slogfile
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// BatchOptions represents the options for the writer returned by Batch.
+type BatchOptions struct {
+	// FlushInterval is how often buffered entries are written.
+	// At most this much of the log is lost on a crash.
+	// Disabled if zero.
+	FlushInterval time.Duration
+	// FlushSize is the number of buffered bytes after which they
+	// are written. Defaults to 256 KiB.
+	FlushSize int
+}
+
+// batchChunkSize is the size of the buffers entries are copied to.
+// Every buffer is an iovec of the vectored write.
+const batchChunkSize = 64 << 10
+
+// BatchWriter buffers writes and writes them to the underlying writer
+// in batches.
+//
+// See Batch.
+type BatchWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	flushSize int
+	chunks    [][]byte
+	buffered  int
+	err       error
+	closed    bool
+
+	done chan struct{}
+}
+
+// Batch returns a writer that buffers the entries written to it and
+// writes them to w with a single system call per batch so that the
+// syscall count per entry stops dominating at high log rates.
+//
+// Entries are copied to fixed size buffers which are written with
+// writev(2) on Linux if w is an *os.File and with a write per buffer
+// otherwise. Buffered entries are written when opts.FlushSize is
+// reached, every opts.FlushInterval and on Sync and Close.
+//
+// If opts is nil, the defaults are used.
+func Batch(w io.Writer, opts *BatchOptions) *BatchWriter {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	bw := &BatchWriter{
+		w:         w,
+		flushSize: opts.FlushSize,
+		done:      make(chan struct{}),
+	}
+	if bw.flushSize <= 0 {
+		bw.flushSize = 256 << 10
+	}
+	if opts.FlushInterval > 0 {
+		go bw.flushLoop(opts.FlushInterval)
+	}
+	return bw
+}
+
+func (bw *BatchWriter) flushLoop(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-bw.done:
+			return
+		case <-t.C:
+			bw.mu.Lock()
+			if bw.buffered > 0 && !bw.closed {
+				// Errors are returned by the next Write or Sync.
+				bw.err = bw.flush()
+			}
+			bw.mu.Unlock()
+		}
+	}
+}
+
+// Write buffers p.
+//
+// It returns the error of the last failed batch, if any.
+func (bw *BatchWriter) Write(p []byte) (int, error) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.closed {
+		return 0, xerrors.New("write to closed writer")
+	}
+	if bw.err != nil {
+		err := bw.err
+		bw.err = nil
+		return 0, err
+	}
+
+	n := len(p)
+	for len(p) > 0 {
+		last := len(bw.chunks) - 1
+		if last < 0 || len(bw.chunks[last]) == cap(bw.chunks[last]) {
+			bw.chunks = append(bw.chunks, make([]byte, 0, batchChunkSize))
+			last++
+		}
+		c := bw.chunks[last]
+		m := copy(c[len(c):cap(c)], p)
+		bw.chunks[last] = c[:len(c)+m]
+		p = p[m:]
+	}
+	bw.buffered += n
+
+	if bw.buffered >= bw.flushSize {
+		return n, bw.flush()
+	}
+	return n, nil
+}
+
+// flush writes the buffered entries.
+func (bw *BatchWriter) flush() error {
+	if bw.buffered == 0 {
+		return nil
+	}
+	err := writeBuffers(bw.w, bw.chunks)
+
+	// Keep the first buffer to avoid allocating it again.
+	bw.chunks[0] = bw.chunks[0][:0]
+	for i := 1; i < len(bw.chunks); i++ {
+		bw.chunks[i] = nil
+	}
+	bw.chunks = bw.chunks[:1]
+	bw.buffered = 0
+
+	if err != nil {
+		return xerrors.Errorf("failed to write batch: %w", err)
+	}
+	return nil
+}
+
+// Sync writes buffered entries and then calls Sync on
+// the underlying writer if it implements Sync() error.
+func (bw *BatchWriter) Sync() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.closed {
+		return nil
+	}
+
+	err := bw.flush()
+	if err != nil {
+		return err
+	}
+	if s, ok := bw.w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close writes buffered entries and then closes
+// the underlying writer if it implements io.Closer.
+func (bw *BatchWriter) Close() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.closed {
+		return nil
+	}
+	bw.closed = true
+	close(bw.done)
+
+	err := bw.flush()
+	if err != nil {
+		return err
+	}
+	if c, ok := bw.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// writeEach writes every buffer of bufs to w.
+func writeEach(w io.Writer, bufs [][]byte) error {
+	for _, b := range bufs {
+		_, err := w.Write(b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
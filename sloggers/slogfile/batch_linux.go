@@ -0,0 +1,72 @@
+package slogfile
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// maxIovecs is the maximum number of iovecs of a writev(2) call.
+const maxIovecs = 1024
+
+// writeBuffers writes bufs to w with writev(2) if it is a file.
+func writeBuffers(w io.Writer, bufs [][]byte) error {
+	f, ok := w.(*os.File)
+	if !ok {
+		return writeEach(w, bufs)
+	}
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return writeEach(w, bufs)
+	}
+
+	// bufs is advanced past partial writes.
+	bufs = append([][]byte(nil), bufs...)
+	iovs := make([]syscall.Iovec, 0, len(bufs))
+	for {
+		iovs = iovs[:0]
+		for _, b := range bufs {
+			if len(b) == 0 {
+				continue
+			}
+			iov := syscall.Iovec{Base: &b[0]}
+			iov.SetLen(len(b))
+			iovs = append(iovs, iov)
+			if len(iovs) == maxIovecs {
+				break
+			}
+		}
+		if len(iovs) == 0 {
+			return nil
+		}
+
+		var n uintptr
+		var errno syscall.Errno
+		err = rc.Write(func(fd uintptr) bool {
+			n, _, errno = syscall.Syscall(syscall.SYS_WRITEV, fd, uintptr(unsafe.Pointer(&iovs[0])), uintptr(len(iovs)))
+			return errno != syscall.EAGAIN
+		})
+		if err != nil {
+			return err
+		}
+		switch {
+		case errno == syscall.EINTR:
+			continue
+		case errno != 0:
+			return os.NewSyscallError("writev", errno)
+		case n == 0:
+			return io.ErrShortWrite
+		}
+
+		// Skip what was written in case of a partial write.
+		for i := range bufs {
+			m := uintptr(len(bufs[i]))
+			if m > n {
+				m = n
+			}
+			bufs[i] = bufs[i][m:]
+			n -= m
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package slogfile
+
+import (
+	"io"
+)
+
+// writeBuffers writes bufs to w.
+func writeBuffers(w io.Writer, bufs [][]byte) error {
+	return writeEach(w, bufs)
+}
@@ -0,0 +1,104 @@
+package slogfile_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	f, err := os.Create(path)
+	assert.Success(t, "create", err)
+	bw := slogfile.Batch(f, &slogfile.BatchOptions{
+		FlushSize: 100 << 10,
+	})
+
+	read := func() string {
+		t.Helper()
+		b, err := ioutil.ReadFile(path)
+		assert.Success(t, "read", err)
+		return string(b)
+	}
+
+	_, err = bw.Write([]byte("a\n"))
+	assert.Success(t, "write", err)
+	assert.Equal(t, "buffered", "", read())
+	assert.Success(t, "sync", bw.Sync())
+	assert.Equal(t, "synced", "a\n", read())
+
+	// Spans multiple buffers and reaches the flush size.
+	big := strings.Repeat("b", 150<<10) + "\n"
+	_, err = bw.Write([]byte(big))
+	assert.Success(t, "write big", err)
+	assert.Equal(t, "flushed", "a\n"+big, read())
+
+	_, err = bw.Write([]byte("c\n"))
+	assert.Success(t, "write", err)
+	assert.Success(t, "close", bw.Close())
+	assert.Equal(t, "closed", "a\n"+big+"c\n", read())
+
+	_, err = bw.Write([]byte("d\n"))
+	assert.Error(t, "write after close", err)
+}
+
+func TestBatch_Writer(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	bw := slogfile.Batch(&buf, nil)
+	l := slog.Make(slogjson.Sink(bw))
+	l.Info(bg, "hello")
+	l.Info(bg, "world")
+	assert.Equal(t, "buffered", 0, buf.Len())
+	l.Sync()
+	assert.Equal(t, "lines", 2, strings.Count(buf.String(), "\n"))
+}
+
+func BenchmarkBatch(b *testing.B) {
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(b, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	entry := []byte(strings.Repeat("x", 200) + "\n")
+	run := func(b *testing.B, name string, batch bool) {
+		b.Run(name, func(b *testing.B) {
+			f, err := os.Create(filepath.Join(dir, name+".log"))
+			assert.Success(b, "create", err)
+			defer f.Close()
+
+			var w io.Writer = f
+			if batch {
+				bw := slogfile.Batch(f, nil)
+				defer bw.Close()
+				w = bw
+			}
+
+			b.SetBytes(int64(len(entry)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := w.Write(entry)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+	run(b, "file", false)
+	run(b, "batch", true)
+}
@@ -0,0 +1,15 @@
+package slogfile
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// Gzip returns a NewEncoder that compresses with gzip at level.
+//
+// See compress/gzip for the levels.
+func Gzip(level int) NewEncoder {
+	return func(w io.Writer) (Encoder, error) {
+		return gzip.NewWriterLevel(w, level)
+	}
+}
@@ -0,0 +1,260 @@
+package slogfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/xerrors"
+)
+
+// The encrypted format is a header followed by chunks.
+//
+// The header is encryptMagic, the 32 byte ephemeral public key and the
+// 16 byte nonce prefix. Every chunk is a 4 byte big endian length followed
+// by a secretbox of a flag byte and the data. The nonce of a chunk is the
+// nonce prefix followed by the 8 byte big endian index of the chunk.
+// The flag byte is chunkFinal for the last chunk written by Close.
+const (
+	encryptMagic = "slogenc1"
+	noncePrefix  = 16
+	maxChunkSize = 64 << 10
+
+	chunkData  = 0
+	chunkFinal = 1
+)
+
+// GenerateKey generates a key pair for Encrypt and Decrypt.
+//
+// Only the public key needs to be on the machine writing the logs.
+func GenerateKey() (publicKey, privateKey *[32]byte, err error) {
+	publicKey, privateKey, err = box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("failed to generate key: %w", err)
+	}
+	return publicKey, privateKey, nil
+}
+
+// EncryptOptions represents the options for the writer returned by Encrypt.
+type EncryptOptions struct {
+	// FlushInterval is how often buffered data is encrypted and written.
+	// At most this much of the log is lost on a crash.
+	// Disabled if zero.
+	FlushInterval time.Duration
+	// FlushSize is the number of bytes written after which
+	// buffered data is encrypted and written. Disabled if zero.
+	FlushSize int
+}
+
+// EncryptWriter encrypts everything written to it with NaCl secretbox.
+//
+// Data is encrypted in chunks on every flush so that the output can
+// be decrypted up to the last flush even if the process crashes
+// before Close. Chunks are at most 64 KiB.
+type EncryptWriter struct {
+	encoderWriter
+}
+
+// Encrypt returns an EncryptWriter that encrypts to w such that only the
+// holder of the private key for publicKey can decrypt it with Decrypt.
+//
+// A new ephemeral key is generated for every call, so a compromised
+// machine cannot decrypt logs it has written.
+//
+// If opts is nil, data is only flushed on Sync and Close.
+func Encrypt(w io.Writer, publicKey *[32]byte, opts *EncryptOptions) (*EncryptWriter, error) {
+	if opts == nil {
+		opts = &EncryptOptions{}
+	}
+
+	ephPublic, ephPrivate, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate ephemeral key: %w", err)
+	}
+
+	enc := &secretboxEncoder{
+		w: w,
+	}
+	box.Precompute(&enc.key, publicKey, ephPrivate)
+	_, err = io.ReadFull(rand.Reader, enc.nonce[:noncePrefix])
+	if err != nil {
+		return nil, xerrors.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header := make([]byte, 0, len(encryptMagic)+len(ephPublic)+noncePrefix)
+	header = append(header, encryptMagic...)
+	header = append(header, ephPublic[:]...)
+	header = append(header, enc.nonce[:noncePrefix]...)
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to write header: %w", err)
+	}
+
+	ew := &EncryptWriter{}
+	ew.init(w, enc, opts.FlushInterval, opts.FlushSize)
+	return ew, nil
+}
+
+// secretboxEncoder is the Encoder of EncryptWriter.
+type secretboxEncoder struct {
+	w     io.Writer
+	key   [32]byte
+	nonce [24]byte
+	index uint64
+	buf   bytes.Buffer
+}
+
+func (e *secretboxEncoder) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		m := maxChunkSize - e.buf.Len()
+		if m > len(p) {
+			m = len(p)
+		}
+		e.buf.Write(p[:m])
+		p = p[m:]
+
+		if e.buf.Len() == maxChunkSize {
+			err := e.seal(chunkData)
+			if err != nil {
+				return n - len(p), err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (e *secretboxEncoder) Flush() error {
+	if e.buf.Len() == 0 {
+		return nil
+	}
+	return e.seal(chunkData)
+}
+
+func (e *secretboxEncoder) Close() error {
+	return e.seal(chunkFinal)
+}
+
+func (e *secretboxEncoder) seal(flag byte) error {
+	binary.BigEndian.PutUint64(e.nonce[noncePrefix:], e.index)
+	e.index++
+
+	msg := make([]byte, 0, 1+e.buf.Len())
+	msg = append(msg, flag)
+	msg = append(msg, e.buf.Bytes()...)
+	e.buf.Reset()
+
+	chunk := make([]byte, 4, 4+secretbox.Overhead+len(msg))
+	chunk = secretbox.Seal(chunk, msg, &e.nonce, &e.key)
+	binary.BigEndian.PutUint32(chunk, uint32(len(chunk)-4))
+
+	_, err := e.w.Write(chunk)
+	if err != nil {
+		return xerrors.Errorf("failed to write chunk: %w", err)
+	}
+	return nil
+}
+
+// Decrypt returns a reader of the data encrypted by Encrypt to r.
+//
+// If r ends before the last chunk written by Close, all the chunks
+// written before are returned followed by io.ErrUnexpectedEOF.
+func Decrypt(r io.Reader, privateKey *[32]byte) io.Reader {
+	return &decrypter{
+		r:          bufio.NewReader(r),
+		privateKey: privateKey,
+	}
+}
+
+type decrypter struct {
+	r          *bufio.Reader
+	privateKey *[32]byte
+	started    bool
+	key        [32]byte
+	nonce      [24]byte
+	index      uint64
+	buf        []byte
+	err        error
+}
+
+func (d *decrypter) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.next()
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
+
+func (d *decrypter) next() error {
+	if !d.started {
+		d.started = true
+		return d.readHeader()
+	}
+
+	var size [4]byte
+	_, err := io.ReadFull(d.r, size[:])
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+
+	n := binary.BigEndian.Uint32(size[:])
+	if n < secretbox.Overhead+1 || n > secretbox.Overhead+1+maxChunkSize {
+		return xerrors.Errorf("invalid chunk size %v", n)
+	}
+	chunk := make([]byte, n)
+	_, err = io.ReadFull(d.r, chunk)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint64(d.nonce[noncePrefix:], d.index)
+	d.index++
+	msg, ok := secretbox.Open(nil, chunk, &d.nonce, &d.key)
+	if !ok {
+		return xerrors.Errorf("failed to decrypt chunk %v", d.index-1)
+	}
+
+	d.buf = msg[1:]
+	switch msg[0] {
+	case chunkData:
+		return nil
+	case chunkFinal:
+		return io.EOF
+	default:
+		return xerrors.Errorf("invalid chunk flag %v", msg[0])
+	}
+}
+
+func (d *decrypter) readHeader() error {
+	header := make([]byte, len(encryptMagic)+32+noncePrefix)
+	_, err := io.ReadFull(d.r, header)
+	if err != nil {
+		return xerrors.Errorf("failed to read header: %w", err)
+	}
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return xerrors.New("not encrypted by slogfile.Encrypt")
+	}
+	header = header[len(encryptMagic):]
+
+	var ephPublic [32]byte
+	copy(ephPublic[:], header)
+	box.Precompute(&d.key, &ephPublic, d.privateKey)
+	copy(d.nonce[:noncePrefix], header[32:])
+	return nil
+}
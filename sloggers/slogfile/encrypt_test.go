@@ -0,0 +1,76 @@
+package slogfile_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := slogfile.GenerateKey()
+	assert.Success(t, "generate key", err)
+
+	f := &file{}
+	ew, err := slogfile.Encrypt(f, pub, nil)
+	assert.Success(t, "encrypt", err)
+
+	l := slog.Make(slogjson.Sink(ew))
+	l.Info(bg, "hello")
+	l.Sync()
+	assert.Equal(t, "syncs", 1, f.syncs)
+	assert.False(t, "plaintext", bytes.Contains(f.bytes(), []byte("hello")))
+
+	// Everything up to the sync can be read before Close.
+	out, err := ioutil.ReadAll(slogfile.Decrypt(bytes.NewReader(f.bytes()), priv))
+	assert.Equal(t, "err", io.ErrUnexpectedEOF, err)
+	assert.True(t, "hello", strings.Contains(string(out), `"msg":"hello"`))
+
+	big := strings.Repeat("x", 100<<10)
+	l.Info(bg, "world", slog.F("big", big))
+	err = ew.Close()
+	assert.Success(t, "close", err)
+
+	out, err = ioutil.ReadAll(slogfile.Decrypt(bytes.NewReader(f.bytes()), priv))
+	assert.Success(t, "decrypt", err)
+	assert.Equal(t, "lines", 2, strings.Count(string(out), "\n"))
+	assert.True(t, "big", strings.Contains(string(out), big))
+
+	_, priv2, err := slogfile.GenerateKey()
+	assert.Success(t, "generate key", err)
+	_, err = ioutil.ReadAll(slogfile.Decrypt(bytes.NewReader(f.bytes()), priv2))
+	assert.Error(t, "decrypt with wrong key", err)
+
+	b := f.bytes()
+	b[len(b)-1] ^= 1
+	_, err = ioutil.ReadAll(slogfile.Decrypt(bytes.NewReader(b), priv))
+	assert.Error(t, "decrypt corrupted", err)
+}
+
+func TestEncrypt_FlushSize(t *testing.T) {
+	t.Parallel()
+
+	pub, priv, err := slogfile.GenerateKey()
+	assert.Success(t, "generate key", err)
+
+	f := &file{}
+	ew, err := slogfile.Encrypt(f, pub, &slogfile.EncryptOptions{
+		FlushSize: 1,
+	})
+	assert.Success(t, "encrypt", err)
+	defer ew.Close()
+
+	_, err = ew.Write([]byte("hello\n"))
+	assert.Success(t, "write", err)
+
+	out, _ := ioutil.ReadAll(slogfile.Decrypt(bytes.NewReader(f.bytes()), priv))
+	assert.Equal(t, "output", "hello\n", string(out))
+}
@@ -0,0 +1,13 @@
+package slogfile
+
+import (
+	"time"
+)
+
+func SetPartitionNow(pw *PartitionWriter, now func() time.Time) {
+	pw.now = now
+}
+
+func SetRotateNow(rw *RotateWriter, now func() time.Time) {
+	rw.now = now
+}
@@ -0,0 +1,141 @@
+package slogfile
+
+import (
+	"compress/gzip"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+func init() {
+	slog.RegisterSink("file", open, "compress", "rotate", "backups")
+}
+
+// open opens the file sink for slog.Open. e.g.
+//
+//	json+file:///var/log/app.log
+//	json+file://app.log?compress=gzip
+//	json+file:///var/log/app.log?rotate=100MB&backups=10
+//
+// The file is created if it does not exist and appended to otherwise.
+// With compress=gzip, the output is compressed and flushed every second.
+// With rotate, the file is rotated by size with Rotate and backups sets
+// RotateOptions.MaxBackups. Sizes are in bytes or have a KB, MB or GB
+// suffix for powers of 1024. Compressed files cannot be rotated.
+func open(u *url.URL, newEncoder func(w io.Writer) (slog.Encoder, error)) (slog.Sink, error) {
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return nil, xerrors.New("no path")
+	}
+
+	q := u.Query()
+	var rotate *RotateOptions
+	if v := q.Get("rotate"); v != "" {
+		size, err := parseSize(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid rotate %q: %w", v, err)
+		}
+		rotate = &RotateOptions{MaxSize: size}
+	}
+	if v := q.Get("backups"); v != "" {
+		if rotate == nil {
+			return nil, xerrors.New("backups requires rotate")
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid backups %q: %w", v, err)
+		}
+		rotate.MaxBackups = n
+	}
+	compress := q.Get("compress")
+	if rotate != nil && compress != "" {
+		return nil, xerrors.New("compressed files cannot be rotated")
+	}
+
+	var f io.WriteCloser
+	var err error
+	if rotate != nil {
+		f, err = Rotate(path, rotate)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to open file: %w", err)
+		}
+	}
+
+	var w io.WriteCloser = f
+	switch compress {
+	case "":
+	case "gzip":
+		w, err = Compress(f, Gzip(gzip.DefaultCompression), &CompressOptions{
+			FlushInterval: time.Second,
+		})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	default:
+		f.Close()
+		return nil, xerrors.Errorf("unknown compression %q", compress)
+	}
+
+	enc, err := newEncoder(w)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return fileSink{
+		Sink: slog.WriterSink(w, enc),
+		w:    w,
+	}, nil
+}
+
+// parseSize parses a size in bytes with an optional KB, MB or GB suffix.
+func parseSize(s string) (int64, error) {
+	mult := int64(1)
+	for _, suffix := range []struct {
+		s    string
+		mult int64
+	}{
+		{"KB", 1 << 10},
+		{"MB", 1 << 20},
+		{"GB", 1 << 30},
+	} {
+		if strings.HasSuffix(s, suffix.s) {
+			s = strings.TrimSuffix(s, suffix.s)
+			mult = suffix.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 {
+		return 0, xerrors.New("size must be positive")
+	}
+	return n * mult, nil
+}
+
+type fileSink struct {
+	slog.Sink
+	w io.WriteCloser
+}
+
+// Close closes the file.
+func (s fileSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,82 @@
+package slogfile_test
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	_ "cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	for _, compress := range []string{"", "gzip"} {
+		path := filepath.Join(dir, "app"+compress+".log")
+		s, err := slog.Open("json+file://" + path + "?compress=" + compress)
+		assert.Success(t, "open", err)
+		slog.Make(s).Info(bg, "hello")
+		err = s.(io.Closer).Close()
+		assert.Success(t, "close", err)
+
+		f, err := os.Open(path)
+		assert.Success(t, "open file", err)
+		defer f.Close()
+		var r io.Reader = f
+		if compress == "gzip" {
+			r, err = gzip.NewReader(f)
+			assert.Success(t, "gzip reader", err)
+		}
+		b, err := ioutil.ReadAll(r)
+		assert.Success(t, "read", err)
+
+		var ent slog.SinkEntry
+		err = ent.UnmarshalJSON(b)
+		assert.Success(t, "unmarshal", err)
+		assert.Equal(t, "msg", "hello", ent.Message)
+	}
+
+	_, err = slog.Open("json+file://" + dir + "/x.log?compress=lz4")
+	assert.Error(t, "unknown compression", err)
+	_, err = slog.Open("json+file://" + dir + "/x.log?rotate=1TB")
+	assert.Error(t, "invalid rotate", err)
+	_, err = slog.Open("json+file://" + dir + "/x.log?rotate=1MB&compress=gzip")
+	assert.Error(t, "rotate compressed", err)
+	_, err = slog.Open("json+file://" + dir + "/x.log?rotat=1MB")
+	assert.Error(t, "unknown parameter", err)
+}
+
+func TestOpen_Rotate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	s, err := slog.Open("json+file://" + filepath.Join(dir, "app.log") + "?rotate=1KB&backups=1")
+	assert.Success(t, "open", err)
+	l := slog.Make(s)
+	for i := 0; i < 100; i++ {
+		l.Info(bg, "hello")
+	}
+	err = s.(io.Closer).Close()
+	assert.Success(t, "close", err)
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Success(t, "glob", err)
+	assert.Len(t, "files", 2, paths)
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		assert.Success(t, "stat", err)
+		assert.True(t, "size", fi.Size() <= 1<<10)
+	}
+}
@@ -0,0 +1,236 @@
+package slogfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// PartitionOptions represents the options for the writer returned by Partition.
+type PartitionOptions struct {
+	// Interval is the duration of a partition. Partitions start at
+	// multiples of it since midnight in Location, e.g. at the top of
+	// every hour. Intervals of a day or more partition by day.
+	// Defaults to an hour.
+	Interval time.Duration
+	// Location is the time zone of the partitions. Defaults to UTC.
+	Location *time.Location
+	// MaxAge enables removing the files of partitions that were last
+	// written more than MaxAge ago. They are found with Glob.
+	// Disabled if zero.
+	MaxAge time.Duration
+	// Glob matches the files of the partitions. Defaults to the
+	// template executed with every time formatted as "*".
+	Glob string
+}
+
+// PartitionWriter writes to a file per time partition.
+//
+// See Partition.
+type PartitionWriter struct {
+	tmpl *template.Template
+	opts *PartitionOptions
+	now  func() time.Time
+
+	mu     sync.Mutex
+	f      *os.File
+	start  time.Time
+	closed bool
+
+	errorf func(f string, v ...interface{})
+}
+
+// partitionTime is the value the path template is executed with.
+type partitionTime struct {
+	t    time.Time
+	glob bool
+}
+
+// Format formats the start of the partition like time.Time.Format.
+func (pt partitionTime) Format(layout string) string {
+	if pt.glob {
+		return "*"
+	}
+	return pt.t.Format(layout)
+}
+
+// Partition returns a writer that writes to a file per time partition,
+// e.g. a file per hour for batch jobs that process every hour's logs
+// once it is complete. Unlike rotation by size, the file of an entry
+// only depends on when it was written.
+//
+// The path of the file of a partition is the text/template tmpl executed
+// with a value whose Format method formats the start of the partition:
+//
+//	/var/log/app-{{.Format "2006-01-02-15"}}.log
+//	/var/log/{{.Format "2006/01/02"}}/app.log
+//
+// Directories are created as needed and files are appended to if they
+// exist. Old partitions are removed according to opts.MaxAge when a new
+// partition is started.
+//
+// If opts is nil, the defaults are used.
+func Partition(tmpl string, opts *PartitionOptions) (*PartitionWriter, error) {
+	t, err := template.New("partition").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse partition template: %w", err)
+	}
+
+	o := PartitionOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Hour
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+
+	pw := &PartitionWriter{
+		tmpl: t,
+		opts: &o,
+		now:  time.Now,
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+	if o.Glob == "" {
+		o.Glob, err = pw.execute(partitionTime{glob: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Catch template errors before the first Write.
+	_, err = pw.execute(partitionTime{})
+	if err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (pw *PartitionWriter) execute(pt partitionTime) (string, error) {
+	var sb strings.Builder
+	err := pw.tmpl.Execute(&sb, pt)
+	if err != nil {
+		return "", xerrors.Errorf("failed to execute partition template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// partitionStart returns the start of the partition of t.
+func (pw *PartitionWriter) partitionStart(t time.Time) time.Time {
+	t = t.In(pw.opts.Location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, pw.opts.Location)
+	if pw.opts.Interval >= 24*time.Hour {
+		return midnight
+	}
+	return midnight.Add(t.Sub(midnight).Truncate(pw.opts.Interval))
+}
+
+// Write writes p to the file of the current partition.
+func (pw *PartitionWriter) Write(p []byte) (int, error) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if pw.closed {
+		return 0, xerrors.New("write to closed writer")
+	}
+
+	start := pw.partitionStart(pw.now())
+	if pw.f == nil || !start.Equal(pw.start) {
+		err := pw.startPartition(start)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return pw.f.Write(p)
+}
+
+func (pw *PartitionWriter) startPartition(start time.Time) error {
+	if pw.f != nil {
+		err := pw.f.Close()
+		pw.f = nil
+		if err != nil {
+			return xerrors.Errorf("failed to close partition: %w", err)
+		}
+	}
+
+	path, err := pw.execute(partitionTime{t: start})
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return xerrors.Errorf("failed to create partition directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to open partition: %w", err)
+	}
+	pw.f = f
+	pw.start = start
+
+	if pw.opts.MaxAge > 0 {
+		pw.removeOld(path)
+	}
+	return nil
+}
+
+// removeOld removes the partitions last written before MaxAge ago
+// except for the current one.
+func (pw *PartitionWriter) removeOld(current string) {
+	paths, err := filepath.Glob(pw.opts.Glob)
+	if err != nil {
+		pw.errorf("slogfile: failed to find old partitions: %+v", err)
+		return
+	}
+	cutoff := pw.now().Add(-pw.opts.MaxAge)
+	for _, path := range paths {
+		if path == current {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil || fi.IsDir() || !fi.ModTime().Before(cutoff) {
+			continue
+		}
+		err = os.Remove(path)
+		if err != nil {
+			pw.errorf("slogfile: failed to remove old partition: %+v", err)
+		}
+	}
+}
+
+// Sync syncs the file of the current partition.
+func (pw *PartitionWriter) Sync() error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if pw.f == nil {
+		return nil
+	}
+	return pw.f.Sync()
+}
+
+// Close closes the file of the current partition.
+func (pw *PartitionWriter) Close() error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if pw.closed {
+		return nil
+	}
+	pw.closed = true
+	if pw.f == nil {
+		return nil
+	}
+	err := pw.f.Close()
+	pw.f = nil
+	return err
+}
@@ -0,0 +1,94 @@
+package slogfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+)
+
+func TestPartition(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	old := filepath.Join(dir, "logs", "app-2020-01-01-00.log")
+	err = os.MkdirAll(filepath.Dir(old), 0755)
+	assert.Success(t, "mkdir", err)
+	err = ioutil.WriteFile(old, []byte("old\n"), 0644)
+	assert.Success(t, "write old", err)
+	now := time.Date(2020, 5, 17, 15, 30, 0, 0, time.UTC)
+	err = os.Chtimes(old, now.Add(-48*time.Hour), now.Add(-48*time.Hour))
+	assert.Success(t, "chtimes", err)
+
+	pw, err := slogfile.Partition(filepath.Join(dir, "logs", `app-{{.Format "2006-01-02-15"}}.log`), &slogfile.PartitionOptions{
+		MaxAge: 24 * time.Hour,
+	})
+	assert.Success(t, "partition", err)
+	slogfile.SetPartitionNow(pw, func() time.Time {
+		return now
+	})
+
+	write := func(s string) {
+		t.Helper()
+		_, err := pw.Write([]byte(s))
+		assert.Success(t, "write", err)
+	}
+	write("a\n")
+	now = now.Add(29 * time.Minute)
+	write("b\n")
+	now = now.Add(2 * time.Minute)
+	write("c\n")
+	assert.Success(t, "sync", pw.Sync())
+	assert.Success(t, "close", pw.Close())
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := ioutil.ReadFile(filepath.Join(dir, "logs", name))
+		assert.Success(t, "read", err)
+		return string(b)
+	}
+	assert.Equal(t, "15", "a\nb\n", read("app-2020-05-17-15.log"))
+	assert.Equal(t, "16", "c\n", read("app-2020-05-17-16.log"))
+
+	_, err = os.Stat(old)
+	assert.True(t, "old removed", os.IsNotExist(err))
+
+	_, err = pw.Write([]byte("d\n"))
+	assert.Error(t, "write after close", err)
+}
+
+func TestPartition_Day(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	pw, err := slogfile.Partition(filepath.Join(dir, `{{.Format "2006/01/02"}}/app.log`), &slogfile.PartitionOptions{
+		Interval: 24 * time.Hour,
+		Location: loc,
+	})
+	assert.Success(t, "partition", err)
+	// 2020-05-17 in UTC but 2020-05-18 in loc.
+	slogfile.SetPartitionNow(pw, func() time.Time {
+		return time.Date(2020, 5, 17, 20, 0, 0, 0, time.UTC)
+	})
+	_, err = pw.Write([]byte("a\n"))
+	assert.Success(t, "write", err)
+	assert.Success(t, "close", pw.Close())
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "2020", "05", "18", "app.log"))
+	assert.Success(t, "read", err)
+	assert.Equal(t, "contents", "a\n", string(b))
+
+	_, err = slogfile.Partition(`{{.Missing}}`, nil)
+	assert.Error(t, "bad template", err)
+}
@@ -0,0 +1,229 @@
+package slogfile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// The ring file starts with a header of the magic and then the size of
+// the data, the position of the next record and the position of the
+// oldest record as little endian uint64s. Positions grow monotonically
+// and are taken modulo the size.
+//
+// Every record is its length as a little endian uint32 and then its
+// data. Records do not wrap around the end of the data. If there is no
+// room for a record, the rest of the data is skipped and the record is
+// written at the start. A length of ringSkip marks the skip if there is
+// room for it.
+const (
+	ringMagic      = "slogring"
+	ringHeaderSize = 32
+	ringSkip       = 0xffffffff
+)
+
+// RingWriter writes to a fixed size circular file.
+//
+// See Ring.
+type RingWriter struct {
+	mu     sync.Mutex
+	f      *os.File
+	m      []byte
+	closed bool
+}
+
+// Ring returns a writer that writes to a memory mapped circular file at
+// path that keeps the most recent size bytes of logs, overwriting the
+// oldest entries as necessary. It is meant as a flight recorder: writes
+// only copy to memory and the kernel writes the file in the background
+// so the file contains the last entries even if the process crashes.
+// Sync writes the file to disk to also survive a crash of the machine.
+//
+// Every Write is kept or overwritten as a whole so the writer must only
+// be used by a single sink. The entries of an existing ring file of the
+// same size are kept. Use ReadRing to read the file.
+//
+// Only supported on Unix systems.
+func Ring(path string, size int) (*RingWriter, error) {
+	if size < 64 {
+		return nil, xerrors.Errorf("ring size %v must be at least 64", size)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open ring: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, xerrors.Errorf("failed to stat ring: %w", err)
+	}
+	fileSize := int64(ringHeaderSize + size)
+	if fi.Size() != fileSize {
+		err = f.Truncate(fileSize)
+		if err != nil {
+			f.Close()
+			return nil, xerrors.Errorf("failed to resize ring: %w", err)
+		}
+	}
+
+	m, err := mmap(f, int(fileSize))
+	if err != nil {
+		f.Close()
+		return nil, xerrors.Errorf("failed to map ring: %w", err)
+	}
+
+	rw := &RingWriter{
+		f: f,
+		m: m,
+	}
+	if _, _, err := parseRingHeader(m); err != nil {
+		copy(m, ringMagic)
+		binary.LittleEndian.PutUint64(m[8:], uint64(size))
+		rw.setPositions(0, 0)
+	}
+	return rw, nil
+}
+
+func (rw *RingWriter) setPositions(head, tail uint64) {
+	binary.LittleEndian.PutUint64(rw.m[24:], tail)
+	binary.LittleEndian.PutUint64(rw.m[16:], head)
+}
+
+// parseRingHeader returns the head and tail positions of the ring b.
+func parseRingHeader(b []byte) (head, tail uint64, err error) {
+	if len(b) < ringHeaderSize || !bytes.Equal(b[:8], []byte(ringMagic)) {
+		return 0, 0, xerrors.New("not a ring file")
+	}
+	size := binary.LittleEndian.Uint64(b[8:])
+	head = binary.LittleEndian.Uint64(b[16:])
+	tail = binary.LittleEndian.Uint64(b[24:])
+	if size != uint64(len(b)-ringHeaderSize) || tail > head || head-tail > size {
+		return 0, 0, xerrors.New("corrupt ring header")
+	}
+	return head, tail, nil
+}
+
+// Write writes p as a record, overwriting the oldest records
+// if there is not enough room.
+func (rw *RingWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.closed {
+		return 0, xerrors.New("write to closed writer")
+	}
+
+	data := rw.m[ringHeaderSize:]
+	size := uint64(len(data))
+	n := uint64(4 + len(p))
+	if n > size {
+		return 0, xerrors.Errorf("entry of %v bytes does not fit in ring of %v bytes", len(p), size)
+	}
+
+	head := binary.LittleEndian.Uint64(rw.m[16:])
+	tail := binary.LittleEndian.Uint64(rw.m[24:])
+
+	start := head
+	if rest := size - head%size; rest < n {
+		start += rest
+	}
+	for start+n-tail > size && tail < head {
+		tail = nextRingRecord(data, tail)
+	}
+	if tail >= head {
+		// Every record is overwritten.
+		tail = start
+	}
+	// The tail is moved before the records are overwritten
+	// so that a crash never leaves it at a partial record.
+	binary.LittleEndian.PutUint64(rw.m[24:], tail)
+
+	if start != head && size-head%size >= 4 {
+		binary.LittleEndian.PutUint32(data[head%size:], ringSkip)
+	}
+	i := start % size
+	binary.LittleEndian.PutUint32(data[i:], uint32(len(p)))
+	copy(data[i+4:], p)
+	binary.LittleEndian.PutUint64(rw.m[16:], start+n)
+	return len(p), nil
+}
+
+// nextRingRecord returns the position of the record after the one at pos.
+func nextRingRecord(data []byte, pos uint64) uint64 {
+	size := uint64(len(data))
+	i := pos % size
+	if size-i < 4 {
+		return pos + size - i
+	}
+	l := binary.LittleEndian.Uint32(data[i:])
+	if l == ringSkip || uint64(l) > size-i-4 {
+		return pos + size - i
+	}
+	return pos + 4 + uint64(l)
+}
+
+// Sync writes the ring file to disk.
+func (rw *RingWriter) Sync() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.closed {
+		return nil
+	}
+	return rw.f.Sync()
+}
+
+// Close unmaps and closes the ring file.
+func (rw *RingWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.closed {
+		return nil
+	}
+	rw.closed = true
+	err := munmap(rw.m)
+	rw.m = nil
+	if err != nil {
+		rw.f.Close()
+		return xerrors.Errorf("failed to unmap ring: %w", err)
+	}
+	return rw.f.Close()
+}
+
+// ReadRing returns the records of the ring file at path written by
+// RingWriter, oldest first, e.g. to decode them with JSONDecoder.
+//
+// It works on every system and can be used on a file copied from
+// the machine that wrote it.
+func ReadRing(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read ring: %w", err)
+	}
+	head, tail, err := parseRingHeader(b)
+	if err != nil {
+		return nil, err
+	}
+
+	data := b[ringHeaderSize:]
+	size := uint64(len(data))
+	var out []byte
+	for pos := tail; pos < head; {
+		next := nextRingRecord(data, pos)
+		i := pos % size
+		if next-pos > 4 && next-pos <= size-i {
+			l := uint64(binary.LittleEndian.Uint32(data[i:]))
+			if next-pos == 4+l {
+				out = append(out, data[i+4:i+4+l]...)
+			}
+		}
+		pos = next
+	}
+	return out, nil
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package slogfile
+
+import (
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+func mmap(f *os.File, size int) ([]byte, error) {
+	return nil, xerrors.New("memory mapped files are not supported on this system")
+}
+
+func munmap(b []byte) error {
+	return nil
+}
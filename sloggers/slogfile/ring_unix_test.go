@@ -0,0 +1,93 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package slogfile_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestRing(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.ring")
+	rw, err := slogfile.Ring(path, 100)
+	assert.Success(t, "ring", err)
+
+	read := func() string {
+		t.Helper()
+		b, err := slogfile.ReadRing(path)
+		assert.Success(t, "read ring", err)
+		return string(b)
+	}
+	assert.Equal(t, "empty", "", read())
+
+	var lines []string
+	for i := 0; i < 20; i++ {
+		line := fmt.Sprintf("entry %v\n", i)
+		lines = append(lines, line)
+		_, err = rw.Write([]byte(line))
+		assert.Success(t, "write", err)
+	}
+	// Records of 13 bytes do not wrap around the end so the last 6 fit.
+	assert.Equal(t, "wrapped", strings.Join(lines[14:], ""), read())
+
+	_, err = rw.Write([]byte(strings.Repeat("x", 96)))
+	assert.Success(t, "write full", err)
+	assert.Equal(t, "full", strings.Repeat("x", 96), read())
+	_, err = rw.Write([]byte(strings.Repeat("x", 97)))
+	assert.Error(t, "write too large", err)
+
+	assert.Success(t, "sync", rw.Sync())
+	assert.Success(t, "close", rw.Close())
+
+	// Reopening keeps the entries.
+	rw, err = slogfile.Ring(path, 100)
+	assert.Success(t, "reopen", err)
+	_, err = rw.Write([]byte("after\n"))
+	assert.Success(t, "write", err)
+	assert.Success(t, "close", rw.Close())
+	assert.Equal(t, "reopened", "after\n", read())
+}
+
+func TestRing_Sink(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.ring")
+	rw, err := slogfile.Ring(path, 4<<10)
+	assert.Success(t, "ring", err)
+	l := slog.Make(slogjson.Sink(rw))
+	for i := 0; i < 100; i++ {
+		l.Info(bg, "hello", slog.F("i", i))
+	}
+	assert.Success(t, "close", rw.Close())
+
+	b, err := slogfile.ReadRing(path)
+	assert.Success(t, "read ring", err)
+	d := slogfile.JSONDecoder(strings.NewReader(string(b)))
+	var ent slog.SinkEntry
+	var n int
+	for d.Decode(&ent) == nil {
+		n++
+	}
+	assert.True(t, "entries kept", n > 10 && n < 100)
+	assert.Equal(t, "last", "99", fmt.Sprint(ent.Fields[0].Value))
+}
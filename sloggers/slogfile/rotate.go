@@ -0,0 +1,193 @@
+package slogfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// RotateOptions represents the options for the writer returned by Rotate.
+type RotateOptions struct {
+	// MaxSize is the size in bytes after which the file is rotated.
+	// Defaults to 100 MiB.
+	MaxSize int64
+	// MaxBackups enables removing the oldest rotated files
+	// once there are more than MaxBackups. Disabled if zero.
+	MaxBackups int
+}
+
+// RotateWriter writes to a file that is rotated by size.
+//
+// See Rotate.
+type RotateWriter struct {
+	path string
+	opts *RotateOptions
+	now  func() time.Time
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	closed bool
+
+	errorf func(f string, v ...interface{})
+}
+
+// rotateLayout is the layout of the time in the names of rotated files.
+// It sorts in the order of the rotations.
+const rotateLayout = "20060102T150405.000000000"
+
+// Rotate returns a writer that appends to the file at path and rotates
+// it once it would exceed opts.MaxSize. The file is renamed to its path
+// with the time of the rotation in UTC inserted before the extension and
+// a new file is started:
+//
+//	/var/log/app-20200517T153000.000000000.log
+//
+// A write is never split across files so a file only exceeds MaxSize
+// if a single write does. The oldest rotated files are removed according
+// to opts.MaxBackups.
+//
+// If opts is nil, the defaults are used.
+func Rotate(path string, opts *RotateOptions) (*RotateWriter, error) {
+	o := RotateOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = 100 << 20
+	}
+
+	rw := &RotateWriter{
+		path: path,
+		opts: &o,
+		now:  time.Now,
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+	err := rw.open()
+	if err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+func (rw *RotateWriter) open() error {
+	f, err := os.OpenFile(rw.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to open file: %w", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return xerrors.Errorf("failed to stat file: %w", err)
+	}
+	rw.f = f
+	rw.size = fi.Size()
+	return nil
+}
+
+// Write writes p to the file after rotating it if needed.
+func (rw *RotateWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.closed {
+		return 0, xerrors.New("write to closed writer")
+	}
+
+	if rw.f == nil || (rw.size > 0 && rw.size+int64(len(p)) > rw.opts.MaxSize) {
+		err := rw.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := rw.f.Write(p)
+	rw.size += int64(n)
+	return n, err
+}
+
+func (rw *RotateWriter) rotate() error {
+	if rw.f != nil {
+		err := rw.f.Close()
+		rw.f = nil
+		if err != nil {
+			return xerrors.Errorf("failed to close file: %w", err)
+		}
+
+		base, ext := rw.split()
+		err = os.Rename(rw.path, base+"-"+rw.now().UTC().Format(rotateLayout)+ext)
+		if err != nil {
+			return xerrors.Errorf("failed to rotate file: %w", err)
+		}
+	}
+
+	err := rw.open()
+	if err != nil {
+		return err
+	}
+	if rw.opts.MaxBackups > 0 {
+		rw.removeOld()
+	}
+	return nil
+}
+
+// split returns the path without and with only its extension.
+func (rw *RotateWriter) split() (string, string) {
+	ext := filepath.Ext(rw.path)
+	return strings.TrimSuffix(rw.path, ext), ext
+}
+
+// removeOld removes the oldest rotated files beyond MaxBackups.
+func (rw *RotateWriter) removeOld() {
+	base, ext := rw.split()
+	paths, err := filepath.Glob(base + "-[0-9]*T[0-9]*" + ext)
+	if err != nil {
+		rw.errorf("slogfile: failed to find rotated files: %+v", err)
+		return
+	}
+	if len(paths) <= rw.opts.MaxBackups {
+		return
+	}
+	sort.Strings(paths)
+	for _, path := range paths[:len(paths)-rw.opts.MaxBackups] {
+		err = os.Remove(path)
+		if err != nil {
+			rw.errorf("slogfile: failed to remove rotated file: %+v", err)
+		}
+	}
+}
+
+// Sync syncs the file.
+func (rw *RotateWriter) Sync() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.f == nil {
+		return nil
+	}
+	return rw.f.Sync()
+}
+
+// Close closes the file.
+func (rw *RotateWriter) Close() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.closed {
+		return nil
+	}
+	rw.closed = true
+	if rw.f == nil {
+		return nil
+	}
+	err := rw.f.Close()
+	rw.f = nil
+	return err
+}
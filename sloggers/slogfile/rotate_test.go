@@ -0,0 +1,59 @@
+package slogfile_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+)
+
+func TestRotate(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	err = ioutil.WriteFile(path, []byte("old\n"), 0644)
+	assert.Success(t, "write old", err)
+
+	rw, err := slogfile.Rotate(path, &slogfile.RotateOptions{
+		MaxSize:    6,
+		MaxBackups: 2,
+	})
+	assert.Success(t, "rotate", err)
+	now := time.Date(2020, 5, 17, 15, 30, 0, 0, time.UTC)
+	slogfile.SetRotateNow(rw, func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	})
+
+	for _, s := range []string{"a\n", "b\n", "c\n", "d\n", "toolong\n", "e\n"} {
+		_, err := rw.Write([]byte(s))
+		assert.Success(t, "write", err)
+	}
+	assert.Success(t, "sync", rw.Sync())
+	assert.Success(t, "close", rw.Close())
+	_, err = rw.Write([]byte("f\n"))
+	assert.Error(t, "write after close", err)
+
+	read := func(name string) string {
+		t.Helper()
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		assert.Success(t, "read", err)
+		return string(b)
+	}
+	// The existing file of "old\na\n" was rotated first and then
+	// removed as only the two newest rotated files are kept.
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.Success(t, "glob", err)
+	assert.Len(t, "files", 3, paths)
+	assert.Equal(t, "rotated 2", "b\nc\nd\n", read("app-20200517T153002.000000000.log"))
+	assert.Equal(t, "rotated 3", "toolong\n", read("app-20200517T153003.000000000.log"))
+	assert.Equal(t, "current", "e\n", read("app.log"))
+}
@@ -0,0 +1,225 @@
+package slogfile
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/goid"
+)
+
+// ShardOptions represents the options for the sink returned by Shard.
+type ShardOptions struct {
+	// Shards is the number of files. Defaults to GOMAXPROCS.
+	Shards int
+	// ByGoroutine writes all entries of a goroutine to the same shard,
+	// chosen by the hash of its ID, so that they stay in order within
+	// a file. Otherwise entries are written to the shards round-robin.
+	ByGoroutine bool
+}
+
+// ShardSink writes entries to a file per shard.
+//
+// See Shard.
+type ShardSink struct {
+	files       []*os.File
+	sinks       []slog.Sink
+	byGoroutine bool
+	next        uint32
+}
+
+var _ slog.Sink = &ShardSink{}
+
+// Shard returns a sink that spreads entries over multiple files so that
+// concurrent loggers do not contend on the lock and file descriptor of
+// a single sink. newSink creates the sink of every file, e.g.
+// slogjson.Sink.
+//
+// The paths of the shards are returned by ShardPaths. Files are appended
+// to if they exist. Entries are only in order within a shard, use Merge
+// to read the shards in order.
+//
+// If opts is nil, the defaults are used.
+func Shard(path string, newSink func(w io.Writer) slog.Sink, opts *ShardOptions) (*ShardSink, error) {
+	o := ShardOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Shards <= 0 {
+		o.Shards = runtime.GOMAXPROCS(0)
+	}
+
+	ss := &ShardSink{
+		byGoroutine: o.ByGoroutine,
+	}
+	for _, p := range ShardPaths(path, o.Shards) {
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			ss.Close()
+			return nil, xerrors.Errorf("failed to open shard: %w", err)
+		}
+		ss.files = append(ss.files, f)
+		ss.sinks = append(ss.sinks, newSink(f))
+	}
+	return ss, nil
+}
+
+// ShardPaths returns the paths of the n shards of path. The index of
+// a shard is inserted before the extension, e.g. app.log becomes
+// app.0.log, app.1.log and so on.
+func ShardPaths(path string, n int) []string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	paths := make([]string, n)
+	for i := range paths {
+		paths[i] = base + "." + strconv.Itoa(i) + ext
+	}
+	return paths
+}
+
+// LogEntry implements slog.Sink.
+func (ss *ShardSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	var i uint64
+	if ss.byGoroutine {
+		// Fibonacci hashing spreads sequential IDs over the shards.
+		i = (goid.ID() * 11400714819323198485) >> 32
+	} else {
+		i = uint64(atomic.AddUint32(&ss.next, 1))
+	}
+	ss.sinks[i%uint64(len(ss.sinks))].LogEntry(ctx, ent)
+}
+
+// Sync implements slog.Sink.
+func (ss *ShardSink) Sync() {
+	for _, s := range ss.sinks {
+		s.Sync()
+	}
+}
+
+// Flush implements slog.Flusher.
+func (ss *ShardSink) Flush(ctx context.Context) error {
+	return slog.Flush(ctx, slog.Tee(ss.sinks...))
+}
+
+// Close syncs and closes the files of the shards.
+// The sink must not be used afterwards.
+func (ss *ShardSink) Close() error {
+	ss.Sync()
+
+	var firstErr error
+	for _, f := range ss.files {
+		err := f.Close()
+		if err != nil && firstErr == nil {
+			firstErr = xerrors.Errorf("failed to close shard: %w", err)
+		}
+	}
+	return firstErr
+}
+
+// EntryDecoder reads entries one at a time.
+// It is implemented by sloghuman.Decoder.
+type EntryDecoder interface {
+	// Decode reads the next entry into ent.
+	// It returns io.EOF when there are no more entries.
+	Decode(ent *slog.SinkEntry) error
+}
+
+// JSONDecoder returns a decoder of the entries written by slogjson to r.
+func JSONDecoder(r io.Reader) EntryDecoder {
+	return jsonDecoder{json.NewDecoder(r)}
+}
+
+type jsonDecoder struct {
+	d *json.Decoder
+}
+
+func (d jsonDecoder) Decode(ent *slog.SinkEntry) error {
+	return d.d.Decode(ent)
+}
+
+// Merge returns a decoder that interleaves the entries of decoders by
+// time, e.g. to read the shards of a ShardSink in order. Entries of the
+// decoders must be in order. Entries with the same time are read in the
+// order of the decoders.
+func Merge(decoders ...EntryDecoder) EntryDecoder {
+	return &mergeDecoder{
+		pending: decoders,
+	}
+}
+
+type mergeDecoder struct {
+	// pending are the decoders yet to be read from.
+	pending []EntryDecoder
+	heads   mergeHeap
+}
+
+type mergeHead struct {
+	ent   slog.SinkEntry
+	d     EntryDecoder
+	index int
+}
+
+func (md *mergeDecoder) Decode(ent *slog.SinkEntry) error {
+	if md.pending != nil {
+		for i, d := range md.pending {
+			err := md.push(d, i)
+			if err != nil {
+				return err
+			}
+		}
+		md.pending = nil
+	}
+
+	if len(md.heads) == 0 {
+		return io.EOF
+	}
+	h := heap.Pop(&md.heads).(mergeHead)
+	*ent = h.ent
+	return md.push(h.d, h.index)
+}
+
+// push reads the next entry of d onto the heap.
+func (md *mergeDecoder) push(d EntryDecoder, index int) error {
+	var ent slog.SinkEntry
+	err := d.Decode(&ent)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("failed to decode entry of decoder %v: %w", index, err)
+	}
+	heap.Push(&md.heads, mergeHead{ent: ent, d: d, index: index})
+	return nil
+}
+
+type mergeHeap []mergeHead
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if !h[i].ent.Time.Equal(h[j].ent.Time) {
+		return h[i].ent.Time.Before(h[j].ent.Time)
+	}
+	return h[i].index < h[j].index
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeHead)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,95 @@
+package slogfile_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestShard(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	paths := slogfile.ShardPaths(path, 3)
+	assert.Equal(t, "paths", []string{
+		filepath.Join(dir, "app.0.log"),
+		filepath.Join(dir, "app.1.log"),
+		filepath.Join(dir, "app.2.log"),
+	}, paths)
+
+	ss, err := slogfile.Shard(path, slogjson.Sink, &slogfile.ShardOptions{
+		Shards: 3,
+	})
+	assert.Success(t, "shard", err)
+	start := time.Date(2020, 5, 17, 15, 30, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		ss.LogEntry(bg, slog.SinkEntry{
+			Time:    start.Add(time.Duration(i) * time.Second),
+			Message: "entry",
+			Fields:  slog.M(slog.F("i", i)),
+		})
+	}
+	assert.Success(t, "close", ss.Close())
+
+	var decoders []slogfile.EntryDecoder
+	for _, p := range paths {
+		f, err := os.Open(p)
+		assert.Success(t, "open shard", err)
+		defer f.Close()
+		fi, err := f.Stat()
+		assert.Success(t, "stat shard", err)
+		assert.True(t, "shard written", fi.Size() > 0)
+		decoders = append(decoders, slogfile.JSONDecoder(f))
+	}
+
+	d := slogfile.Merge(decoders...)
+	for i := 0; i < 10; i++ {
+		var ent slog.SinkEntry
+		err := d.Decode(&ent)
+		assert.Success(t, "decode", err)
+		assert.Equal(t, "time", start.Add(time.Duration(i)*time.Second), ent.Time.UTC())
+	}
+	var ent slog.SinkEntry
+	assert.Equal(t, "EOF", io.EOF, d.Decode(&ent))
+}
+
+func TestShard_ByGoroutine(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "slogfile")
+	assert.Success(t, "temp dir", err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	ss, err := slogfile.Shard(path, slogjson.Sink, &slogfile.ShardOptions{
+		Shards:      4,
+		ByGoroutine: true,
+	})
+	assert.Success(t, "shard", err)
+	for i := 0; i < 10; i++ {
+		ss.LogEntry(bg, slog.SinkEntry{Message: "entry"})
+	}
+	assert.Success(t, "close", ss.Close())
+
+	var written int
+	for _, p := range slogfile.ShardPaths(path, 4) {
+		fi, err := os.Stat(p)
+		assert.Success(t, "stat shard", err)
+		if fi.Size() > 0 {
+			written++
+		}
+	}
+	assert.Equal(t, "shards written", 1, written)
+}
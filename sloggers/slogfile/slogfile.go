@@ -0,0 +1,193 @@
+// Package slogfile contains io.Writer wrappers for writing
+// logs to files.
+//
+// The writers are meant to be passed to a sink such as
+// sloghuman.Sink or slogjson.Sink. They implement Sync() error
+// so that syncing the sink flushes them and syncs the file.
+//
+// Shard is a sink that spreads entries over multiple files
+// for loggers whose throughput is limited by a single file.
+//
+// Importing the package registers the file sink with slog.Open.
+package slogfile // import "cdr.dev/slog/sloggers/slogfile"
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+type syncer interface {
+	Sync() error
+}
+
+// Encoder is a compressing writer such as *gzip.Writer or
+// *zstd.Encoder from github.com/klauspost/compress/zstd.
+type Encoder interface {
+	io.WriteCloser
+	// Flush writes any buffered data to the underlying writer such that
+	// everything written so far can be decompressed.
+	Flush() error
+}
+
+// NewEncoder creates an Encoder that writes to w.
+//
+// For zstd, use:
+//
+//	func(w io.Writer) (slogfile.Encoder, error) {
+//		return zstd.NewWriter(w)
+//	}
+type NewEncoder func(w io.Writer) (Encoder, error)
+
+// CompressOptions represents the options for the writer returned by Compress.
+type CompressOptions struct {
+	// FlushInterval is how often buffered data is flushed.
+	// At most this much of the log is lost on a crash.
+	// Disabled if zero.
+	FlushInterval time.Duration
+	// FlushSize is the number of bytes written after which
+	// buffered data is flushed. Disabled if zero.
+	FlushSize int
+}
+
+// CompressWriter compresses everything written to it.
+//
+// Flushing it creates a point up to which the output can
+// be decompressed even if the process crashes before Close.
+type CompressWriter struct {
+	encoderWriter
+}
+
+// Compress returns a CompressWriter that compresses to w with the
+// Encoder returned by newEncoder.
+//
+// If opts is nil, data is only flushed on Sync and Close.
+func Compress(w io.Writer, newEncoder NewEncoder, opts *CompressOptions) (*CompressWriter, error) {
+	if opts == nil {
+		opts = &CompressOptions{}
+	}
+
+	enc, err := newEncoder(w)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create encoder: %w", err)
+	}
+
+	cw := &CompressWriter{}
+	cw.init(w, enc, opts.FlushInterval, opts.FlushSize)
+	return cw, nil
+}
+
+// encoderWriter writes to an Encoder and flushes it
+// periodically and on Sync.
+type encoderWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	enc       Encoder
+	flushSize int
+	unflushed int
+	closed    bool
+
+	done chan struct{}
+}
+
+func (ew *encoderWriter) init(w io.Writer, enc Encoder, flushInterval time.Duration, flushSize int) {
+	ew.w = w
+	ew.enc = enc
+	ew.flushSize = flushSize
+	ew.done = make(chan struct{})
+	if flushInterval > 0 {
+		go ew.flushLoop(flushInterval)
+	}
+}
+
+func (ew *encoderWriter) flushLoop(interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ew.done:
+			return
+		case <-t.C:
+			ew.mu.Lock()
+			if ew.unflushed > 0 && !ew.closed {
+				// Errors are returned by the next Write or Sync.
+				_ = ew.flush()
+			}
+			ew.mu.Unlock()
+		}
+	}
+}
+
+// Write writes p to the encoder.
+func (ew *encoderWriter) Write(p []byte) (int, error) {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.closed {
+		return 0, xerrors.New("write to closed writer")
+	}
+
+	n, err := ew.enc.Write(p)
+	if err != nil {
+		return n, err
+	}
+	ew.unflushed += n
+	if ew.flushSize > 0 && ew.unflushed >= ew.flushSize {
+		err = ew.flush()
+	}
+	return n, err
+}
+
+func (ew *encoderWriter) flush() error {
+	ew.unflushed = 0
+	err := ew.enc.Flush()
+	if err != nil {
+		return xerrors.Errorf("failed to flush encoder: %w", err)
+	}
+	return nil
+}
+
+// Sync flushes buffered data and then calls Sync on
+// the underlying writer if it implements Sync() error.
+func (ew *encoderWriter) Sync() error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.closed {
+		return nil
+	}
+
+	err := ew.flush()
+	if err != nil {
+		return err
+	}
+	if s, ok := ew.w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close flushes and closes the encoder and then closes
+// the underlying writer if it implements io.Closer.
+func (ew *encoderWriter) Close() error {
+	ew.mu.Lock()
+	defer ew.mu.Unlock()
+
+	if ew.closed {
+		return nil
+	}
+	ew.closed = true
+	close(ew.done)
+
+	err := ew.enc.Close()
+	if err != nil {
+		return xerrors.Errorf("failed to close encoder: %w", err)
+	}
+	if c, ok := ew.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
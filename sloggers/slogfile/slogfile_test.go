@@ -0,0 +1,101 @@
+package slogfile_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogfile"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+var bg = context.Background()
+
+// file is a concurrency safe bytes.Buffer that counts syncs.
+type file struct {
+	mu    sync.Mutex
+	b     bytes.Buffer
+	syncs int
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.b.Write(p)
+}
+
+func (f *file) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.syncs++
+	return nil
+}
+
+func (f *file) bytes() []byte {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]byte(nil), f.b.Bytes()...)
+}
+
+func gunzip(t *testing.T, b []byte) (string, error) {
+	t.Helper()
+
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	assert.Success(t, "gzip reader", err)
+	out, err := ioutil.ReadAll(r)
+	return string(out), err
+}
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+
+	f := &file{}
+	cw, err := slogfile.Compress(f, slogfile.Gzip(gzip.DefaultCompression), nil)
+	assert.Success(t, "compress", err)
+
+	l := slog.Make(slogjson.Sink(cw))
+	l.Info(bg, "hello")
+	l.Sync()
+	assert.Equal(t, "syncs", 1, f.syncs)
+
+	// Everything up to the sync can be read before Close.
+	out, err := gunzip(t, f.bytes())
+	assert.Equal(t, "err", io.ErrUnexpectedEOF, err)
+	assert.True(t, "hello", strings.Contains(out, `"msg":"hello"`))
+
+	l.Info(bg, "world")
+	err = cw.Close()
+	assert.Success(t, "close", err)
+
+	out, err = gunzip(t, f.bytes())
+	assert.Success(t, "gunzip", err)
+	assert.Equal(t, "lines", 2, strings.Count(out, "\n"))
+
+	_, err = cw.Write([]byte("closed"))
+	assert.Error(t, "write after close", err)
+}
+
+func TestCompress_FlushSize(t *testing.T) {
+	t.Parallel()
+
+	f := &file{}
+	cw, err := slogfile.Compress(f, slogfile.Gzip(gzip.BestSpeed), &slogfile.CompressOptions{
+		FlushSize: 1,
+	})
+	assert.Success(t, "compress", err)
+	defer cw.Close()
+
+	_, err = cw.Write([]byte("hello\n"))
+	assert.Success(t, "write", err)
+
+	out, _ := gunzip(t, f.bytes())
+	assert.Equal(t, "output", "hello\n", out)
+	assert.Equal(t, "syncs", 0, f.syncs)
+}
@@ -0,0 +1,104 @@
+package sloghttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// AzureOptions represents the options for the sink returned by Azure.
+type AzureOptions struct {
+	// Options configures the underlying sink.
+	// The Format is always a JSON array and Gzip is not supported.
+	Options
+
+	// WorkspaceID is the ID of the Log Analytics workspace.
+	WorkspaceID string
+	// SharedKey is the base64 encoded primary or secondary
+	// key of the workspace.
+	SharedKey string
+	// LogType is the name of the custom log table without
+	// the _CL suffix. e.g. "AppLogs"
+	LogType string
+}
+
+// Azure creates a sink that sends entries to the Azure Monitor HTTP Data
+// Collector API at url.
+// e.g. "https://<workspace id>.ods.opinsights.azure.com/api/logs?api-version=2016-04-01"
+//
+// Every record is the entry in the slogjson format and
+// the ts field is used as the TimeGenerated.
+//
+// It returns an error if SharedKey is not valid base64.
+func Azure(url string, opts *AzureOptions) (*BatchSink, error) {
+	if opts == nil {
+		opts = &AzureOptions{}
+	}
+	key, err := base64.StdEncoding.DecodeString(opts.SharedKey)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid Azure shared key: %w", err)
+	}
+
+	o := opts.Options
+	o.Format = jsonArray{}
+	o.Gzip = false
+	o.Header = cloneHeader(o.Header)
+	o.Header.Set("Log-Type", opts.LogType)
+	o.Header.Set("time-generated-field", "ts")
+
+	prepare := o.Prepare
+	o.Prepare = func(req *http.Request, body []byte) error {
+		azureSign(req, body, opts.WorkspaceID, key, time.Now())
+		if prepare != nil {
+			return prepare(req, body)
+		}
+		return nil
+	}
+	return newBatchSink("sloghttp.Azure", url, &o), nil
+}
+
+// azureSign sets the x-ms-date and Authorization headers of req.
+//
+// See https://docs.microsoft.com/en-us/azure/azure-monitor/logs/data-collector-api#authorization
+func azureSign(req *http.Request, body []byte, workspaceID string, key []byte, now time.Time) {
+	date := now.UTC().Format(http.TimeFormat)
+	req.Header.Set("x-ms-date", date)
+
+	toSign := "POST\n" +
+		strconv.Itoa(len(body)) + "\n" +
+		"application/json\n" +
+		"x-ms-date:" + date + "\n" +
+		"/api/logs"
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(toSign))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	req.Header.Set("Authorization", "SharedKey "+workspaceID+":"+sig)
+}
+
+// jsonArray writes a batch as a JSON array of entries
+// in the slogjson format.
+type jsonArray struct{}
+
+func (jsonArray) ContentType() string {
+	return "application/json"
+}
+
+func (jsonArray) Append(b []byte, ent slog.SinkEntry) []byte {
+	if len(b) == 0 {
+		b = append(b, '[')
+	} else {
+		b = append(b, ',')
+	}
+	return appendJSON(b, ent, 0)
+}
+
+func (jsonArray) Finish(b []byte) ([]byte, error) {
+	return append(b, ']'), nil
+}
@@ -0,0 +1,58 @@
+package sloghttp_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghttp"
+)
+
+func TestAzure(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("secret")
+	srv := newServer(t)
+	s, err := sloghttp.Azure(srv.URL+"/api/logs?api-version=2016-04-01", &sloghttp.AzureOptions{
+		WorkspaceID: "ws",
+		SharedKey:   base64.StdEncoding.EncodeToString(key),
+		LogType:     "AppLogs",
+	})
+	assert.Success(t, "azure", err)
+
+	l := slog.Make(s)
+	l.Info(bg, "1")
+	l.Info(bg, "2")
+	err = s.Close()
+	assert.Success(t, "close", err)
+
+	body := srv.Bodies()[0]
+	var ents []slog.SinkEntry
+	err = json.Unmarshal([]byte(body), &ents)
+	assert.Success(t, "unmarshal", err)
+	assert.Len(t, "entries", 2, ents)
+	assert.Equal(t, "msg", "2", ents[1].Message)
+
+	r := srv.Requests()[0]
+	assert.Equal(t, "log type", "AppLogs", r.Header.Get("Log-Type"))
+	assert.Equal(t, "time generated field", "ts", r.Header.Get("time-generated-field"))
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("POST\n" + strconv.Itoa(len(body)) + "\napplication/json\nx-ms-date:" + r.Header.Get("x-ms-date") + "\n/api/logs"))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	assert.Equal(t, "authorization", "SharedKey ws:"+sig, r.Header.Get("Authorization"))
+}
+
+func TestAzure_InvalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := sloghttp.Azure("https://ws.ods.opinsights.azure.com/api/logs", &sloghttp.AzureOptions{
+		SharedKey: "not base64!",
+	})
+	assert.Error(t, "invalid key", err)
+}
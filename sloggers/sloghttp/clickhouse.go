@@ -0,0 +1,252 @@
+package sloghttp
+
+import (
+	"encoding/binary"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// ClickHouseOptions represents the options for the sink returned by ClickHouse.
+type ClickHouseOptions struct {
+	// Options configures the underlying sink.
+	// The Format is always the ClickHouse Native format.
+	Options
+
+	// Table is the name of the table. Defaults to "logs".
+	Table string
+	// User and Password authenticate the requests if set.
+	User     string
+	Password string
+}
+
+// ClickHouse creates a sink that inserts batches of entries into a table
+// over the ClickHouse HTTP interface at url. e.g. "http://clickhouse:8123"
+//
+// Batches are sent in the columnar Native format. Field values are
+// strings with nested maps flattened and non string values
+// encoded as JSON. The table must have these columns:
+//
+//	CREATE TABLE logs (
+//		ts        DateTime64(9, 'UTC'),
+//		level     LowCardinality(String),
+//		component LowCardinality(String),
+//		message   String,
+//		caller    String,
+//		fields    Map(String, String),
+//		trace_id  String,
+//		span_id   String
+//	) ENGINE = MergeTree ORDER BY ts
+//
+// The component is the logger names joined with a period.
+//
+// If opts is nil, the defaults are used.
+func ClickHouse(u string, opts *ClickHouseOptions) *BatchSink {
+	if opts == nil {
+		opts = &ClickHouseOptions{}
+	}
+	table := opts.Table
+	if table == "" {
+		table = "logs"
+	}
+
+	o := opts.Options
+	o.Format = clickHouseFormat{}
+	o.Header = cloneHeader(o.Header)
+	if opts.User != "" {
+		o.Header.Set("X-ClickHouse-User", opts.User)
+		o.Header.Set("X-ClickHouse-Key", opts.Password)
+	}
+
+	q := url.Values{}
+	q.Set("query", "INSERT INTO "+table+" FORMAT Native")
+	u = strings.TrimSuffix(u, "/") + "/?" + q.Encode()
+	return newBatchSink("sloghttp.ClickHouse", u, &o)
+}
+
+// clickHouseColumns are the names and types of the columns
+// of the Native block.
+var clickHouseColumns = [...][2]string{
+	{"ts", "DateTime64(9, 'UTC')"},
+	{"level", "String"},
+	{"component", "String"},
+	{"message", "String"},
+	{"caller", "String"},
+	{"fields", "Map(String, String)"},
+	{"trace_id", "String"},
+	{"span_id", "String"},
+}
+
+// clickHouseFormat appends entries as rows and transposes
+// them into columns in Finish.
+//
+// A row is the timestamp as 8 bytes followed by the level, component,
+// message, caller, trace_id and span_id as length prefixed strings
+// and then the number of fields and the name and value of every field.
+type clickHouseFormat struct{}
+
+func (clickHouseFormat) ContentType() string {
+	return "application/octet-stream"
+}
+
+func (clickHouseFormat) Append(b []byte, ent slog.SinkEntry) []byte {
+	var ts [8]byte
+	binary.LittleEndian.PutUint64(ts[:], uint64(ent.Time.UnixNano()))
+	b = append(b, ts[:]...)
+
+	var traceID, spanID string
+	if ent.SpanContext != (trace.SpanContext{}) {
+		traceID = ent.SpanContext.TraceID.String()
+		spanID = ent.SpanContext.SpanID.String()
+	}
+	for _, s := range []string{
+		ent.Level.String(),
+		strings.Join(ent.LoggerNames, "."),
+		ent.Message,
+		ent.File + ":" + strconv.Itoa(ent.Line),
+		traceID,
+		spanID,
+	} {
+		b = appendString(b, s)
+	}
+
+	fields := flattenFields(nil, "", ent.Fields)
+	b = appendUvarint(b, uint64(len(fields)/2))
+	for _, s := range fields {
+		b = appendString(b, s)
+	}
+	return b
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	return append(b, buf[:n]...)
+}
+
+// flattenFields appends the name and value of every field
+// with nested maps flattened.
+func flattenFields(kv []string, prefix string, m slog.Map) []string {
+	for _, f := range m {
+		name := prefix + f.Name
+		switch v := f.Value.(type) {
+		case slog.Map:
+			kv = flattenFields(kv, name+".", v)
+		case string:
+			kv = append(kv, name, v)
+		case error:
+			kv = append(kv, name, v.Error())
+		default:
+			b := appendJSON(nil, slog.M(slog.F("v", v)), 0)
+			kv = append(kv, name, string(b[len(`{"v":`):len(b)-1]))
+		}
+	}
+	return kv
+}
+
+func (clickHouseFormat) Finish(b []byte) ([]byte, error) {
+	type row struct {
+		ts     []byte
+		strs   [6]string
+		fields []string
+	}
+
+	size := len(b)
+
+	var rows []row
+	for len(b) > 0 {
+		var r row
+		var err error
+		if len(b) < 8 {
+			return nil, errCorruptRow
+		}
+		r.ts, b = b[:8], b[8:]
+		for i := range r.strs {
+			r.strs[i], b, err = readString(b)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// Every field takes at least two bytes.
+		n, m := binary.Uvarint(b)
+		if m <= 0 || n > uint64(len(b)-m)/2 {
+			return nil, errCorruptRow
+		}
+		b = b[m:]
+		r.fields = make([]string, 2*n)
+		for i := range r.fields {
+			r.fields[i], b, err = readString(b)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rows = append(rows, r)
+	}
+
+	out := make([]byte, 0, size+len(rows)*16+256)
+	out = appendUvarint(out, uint64(len(clickHouseColumns)))
+	out = appendUvarint(out, uint64(len(rows)))
+	for i, c := range clickHouseColumns {
+		out = appendString(out, c[0])
+		out = appendString(out, c[1])
+
+		switch c[0] {
+		case "ts":
+			for _, r := range rows {
+				out = append(out, r.ts...)
+			}
+		case "fields":
+			// Map(String, String) is an Array(Tuple(String, String)):
+			// the cumulative offsets followed by the keys and the values.
+			var offset uint64
+			var off [8]byte
+			for _, r := range rows {
+				offset += uint64(len(r.fields) / 2)
+				binary.LittleEndian.PutUint64(off[:], offset)
+				out = append(out, off[:]...)
+			}
+			for _, r := range rows {
+				for j := 0; j < len(r.fields); j += 2 {
+					out = appendString(out, r.fields[j])
+				}
+			}
+			for _, r := range rows {
+				for j := 1; j < len(r.fields); j += 2 {
+					out = appendString(out, r.fields[j])
+				}
+			}
+		default:
+			// The string columns are in the order of the row strings
+			// with ts and fields skipped.
+			j := i - 1
+			if i > 5 {
+				j--
+			}
+			for _, r := range rows {
+				out = appendString(out, r.strs[j])
+			}
+		}
+	}
+	return out, nil
+}
+
+var errCorruptRow = xerrors.New("corrupt ClickHouse row")
+
+func readString(b []byte) (string, []byte, error) {
+	n, m := binary.Uvarint(b)
+	if m <= 0 || uint64(len(b)-m) < n {
+		return "", nil, errCorruptRow
+	}
+	b = b[m:]
+	return string(b[:n]), b[n:], nil
+}
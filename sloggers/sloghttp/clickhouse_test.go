@@ -0,0 +1,110 @@
+package sloghttp_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghttp"
+)
+
+// nativeReader reads the parts of the Native format used by the sink.
+type nativeReader struct {
+	b []byte
+}
+
+func (r *nativeReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *nativeReader) string() string {
+	n := r.uvarint()
+	s := string(r.b[:n])
+	r.b = r.b[n:]
+	return s
+}
+
+func (r *nativeReader) uint64() uint64 {
+	v := binary.LittleEndian.Uint64(r.b)
+	r.b = r.b[8:]
+	return v
+}
+
+func TestClickHouse(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	s := sloghttp.ClickHouse(srv.URL, &sloghttp.ClickHouseOptions{
+		Table: "app_logs",
+		User:  "default",
+	})
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	l := slog.Make(s).Named("api")
+	err := s.LogEntryErr(bg, slog.SinkEntry{
+		Time:        ts,
+		Level:       slog.LevelInfo,
+		Message:     "1",
+		LoggerNames: []string{"api"},
+		Fields: slog.M(
+			slog.F("user", "bob"),
+			slog.F("req", slog.M(slog.F("size", 3))),
+		),
+	})
+	assert.Success(t, "log entry", err)
+	l.Warn(bg, "2")
+	err = s.Close()
+	assert.Success(t, "close", err)
+
+	req := srv.Requests()[0]
+	assert.Equal(t, "query", "INSERT INTO app_logs FORMAT Native", req.URL.Query().Get("query"))
+	assert.Equal(t, "user", "default", req.Header.Get("X-ClickHouse-User"))
+
+	r := &nativeReader{[]byte(srv.Bodies()[0])}
+	assert.Equal(t, "columns", uint64(8), r.uvarint())
+	assert.Equal(t, "rows", uint64(2), r.uvarint())
+
+	cols := map[string][]interface{}{}
+	for i := 0; i < 8; i++ {
+		name, typ := r.string(), r.string()
+		switch typ {
+		case "DateTime64(9, 'UTC')":
+			cols[name] = []interface{}{r.uint64(), r.uint64()}
+		case "Map(String, String)":
+			offsets := []uint64{r.uint64(), r.uint64()}
+			var kv []interface{}
+			for j := uint64(0); j < 2*offsets[1]; j++ {
+				kv = append(kv, r.string())
+			}
+			cols[name] = append([]interface{}{offsets[0], offsets[1]}, kv...)
+		default:
+			cols[name] = []interface{}{r.string(), r.string()}
+		}
+	}
+	assert.Len(t, "rest", 0, r.b)
+
+	assert.Equal(t, "ts", uint64(ts.UnixNano()), cols["ts"][0])
+	assert.Equal(t, "level", []interface{}{"INFO", "WARN"}, cols["level"])
+	assert.Equal(t, "component", []interface{}{"api", "api"}, cols["component"])
+	assert.Equal(t, "message", []interface{}{"1", "2"}, cols["message"])
+	assert.Equal(t, "fields", []interface{}{uint64(2), uint64(2), "user", "req.size", "bob", "3"}, cols["fields"])
+	assert.Equal(t, "trace_id", []interface{}{"", ""}, cols["trace_id"])
+}
+
+func TestClickHouse_Corrupt(t *testing.T) {
+	t.Parallel()
+
+	f := sloghttp.ClickHouseFormat
+	b := f.Append(nil, slog.SinkEntry{Message: "hello", Fields: slog.M(slog.F("a", 1))})
+	_, err := f.Finish(append([]byte(nil), b...))
+	assert.Success(t, "finish", err)
+
+	for _, n := range []int{3, 12, len(b) - 1} {
+		_, err = f.Finish(append([]byte(nil), b[:n]...))
+		assert.Error(t, "truncated", err)
+	}
+}
@@ -0,0 +1,141 @@
+package sloghttp
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"go.opencensus.io/trace"
+
+	"cdr.dev/slog"
+)
+
+// DatadogOptions represents the options for the sink returned by Datadog.
+type DatadogOptions struct {
+	// Options configures the underlying sink.
+	// The Format is always the Datadog logs format.
+	Options
+
+	// APIKey is sent in the DD-API-KEY header.
+	APIKey string
+	// Source, Service and Hostname set the ddsource,
+	// service and hostname of every log.
+	Source   string
+	Service  string
+	Hostname string
+	// Tags are sent as the ddtags of every log. e.g. "env:prod"
+	// The tags of entries are appended. See slog.Tag.
+	Tags []string
+}
+
+// Datadog creates a sink that sends entries to the Datadog logs API at url.
+// e.g. "https://http-intake.logs.datadoghq.com/api/v2/logs"
+//
+// The level is sent as the status and the span context as dd.trace_id and
+// dd.span_id so that logs are linked to APM traces. Datadog trace IDs are
+// 64 bits so the lower 64 bits of the trace ID are sent.
+//
+// If opts is nil, the defaults are used.
+func Datadog(url string, opts *DatadogOptions) *BatchSink {
+	if opts == nil {
+		opts = &DatadogOptions{}
+	}
+
+	o := opts.Options
+	o.Format = datadogFormat{
+		source:   opts.Source,
+		service:  opts.Service,
+		hostname: opts.Hostname,
+		tags:     strings.Join(opts.Tags, ","),
+	}
+	o.Header = cloneHeader(o.Header)
+	o.Header.Set("DD-API-KEY", opts.APIKey)
+	return newBatchSink("sloghttp.Datadog", url, &o)
+}
+
+// DatadogStatus returns the Datadog status of level,
+// the name of its syslog severity.
+func DatadogStatus(level slog.Level) string {
+	return level.Severity().SyslogName()
+}
+
+type datadogFormat struct {
+	source   string
+	service  string
+	hostname string
+	tags     string
+}
+
+func (f datadogFormat) ContentType() string {
+	return "application/json"
+}
+
+func (f datadogFormat) Append(b []byte, ent slog.SinkEntry) []byte {
+	m := slog.M(
+		slog.F("message", ent.Message),
+		slog.F("status", DatadogStatus(ent.Level)),
+		slog.F("timestamp", ent.Time.UnixNano()/1e6),
+	)
+	if f.source != "" {
+		m = append(m, slog.F("ddsource", f.source))
+	}
+	if f.service != "" {
+		m = append(m, slog.F("service", f.service))
+	}
+	if f.hostname != "" {
+		m = append(m, slog.F("hostname", f.hostname))
+	}
+	if tags := f.entryTags(ent); tags != "" {
+		m = append(m, slog.F("ddtags", tags))
+	}
+
+	logger := slog.M(
+		slog.F("method_name", ent.Func),
+	)
+	if len(ent.LoggerNames) > 0 {
+		logger = append(logger, slog.F("name", strings.Join(ent.LoggerNames, ".")))
+	}
+	m = append(m,
+		slog.F("logger", logger),
+		slog.F("caller", ent.File+":"+strconv.Itoa(ent.Line)),
+	)
+
+	if ent.SpanContext != (trace.SpanContext{}) {
+		m = append(m,
+			slog.F("dd.trace_id", strconv.FormatUint(binary.BigEndian.Uint64(ent.SpanContext.TraceID[8:]), 10)),
+			slog.F("dd.span_id", strconv.FormatUint(binary.BigEndian.Uint64(ent.SpanContext.SpanID[:]), 10)),
+		)
+	}
+	if len(ent.Fields) > 0 {
+		m = append(m, slog.F("fields", ent.Fields))
+	}
+
+	if len(b) == 0 {
+		b = append(b, '[')
+	} else {
+		b = append(b, ',')
+	}
+	return appendJSON(b, m, 0)
+}
+
+func (f datadogFormat) Finish(b []byte) ([]byte, error) {
+	return append(b, ']'), nil
+}
+
+// entryTags returns the static tags followed by the tags of ent.
+func (f datadogFormat) entryTags(ent slog.SinkEntry) string {
+	if len(ent.Tags) == 0 {
+		return f.tags
+	}
+	var sb strings.Builder
+	sb.WriteString(f.tags)
+	for _, t := range ent.Tags {
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(t.Name)
+		sb.WriteByte(':')
+		sb.WriteString(labelValue(t.Value))
+	}
+	return sb.String()
+}
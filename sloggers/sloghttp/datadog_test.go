@@ -0,0 +1,64 @@
+package sloghttp_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.opencensus.io/trace"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghttp"
+)
+
+func TestDatadog(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	s := sloghttp.Datadog(srv.URL, &sloghttp.DatadogOptions{
+		APIKey:  "key",
+		Source:  "go",
+		Service: "api",
+		Tags:    []string{"env:prod", "team:core"},
+	})
+
+	ent := slog.SinkEntry{
+		Level:   slog.LevelWarn,
+		Message: "hello",
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{15: 1},
+			SpanID:  trace.SpanID{7: 2},
+		},
+		Fields: slog.M(slog.F("user", "bob")),
+		Tags:   slog.M(slog.F("region", "us-east-1")),
+	}
+	err := s.LogEntryErr(bg, ent)
+	assert.Success(t, "log entry", err)
+	err = s.Close()
+	assert.Success(t, "close", err)
+
+	assert.Equal(t, "api key", "key", srv.Requests()[0].Header.Get("DD-API-KEY"))
+
+	var logs []map[string]interface{}
+	err = json.Unmarshal([]byte(srv.Bodies()[0]), &logs)
+	assert.Success(t, "unmarshal", err)
+	assert.Len(t, "logs", 1, logs)
+
+	l := logs[0]
+	assert.Equal(t, "message", "hello", l["message"])
+	assert.Equal(t, "status", "warning", l["status"])
+	assert.Equal(t, "ddsource", "go", l["ddsource"])
+	assert.Equal(t, "service", "api", l["service"])
+	assert.Equal(t, "ddtags", "env:prod,team:core,region:us-east-1", l["ddtags"])
+	assert.Equal(t, "trace id", "1", l["dd.trace_id"])
+	assert.Equal(t, "span id", "2", l["dd.span_id"])
+	assert.Equal(t, "fields", map[string]interface{}{"user": "bob"}, l["fields"])
+}
+
+func TestDatadogStatus(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "warn", "warning", sloghttp.DatadogStatus(slog.LevelWarn))
+	assert.Equal(t, "fatal", "alert", sloghttp.DatadogStatus(slog.LevelFatal))
+	assert.Equal(t, "custom", "alert", sloghttp.DatadogStatus(slog.Level(42)))
+}
@@ -0,0 +1,3 @@
+package sloghttp
+
+var ClickHouseFormat Format = clickHouseFormat{}
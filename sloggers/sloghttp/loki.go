@@ -0,0 +1,204 @@
+package sloghttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cdr.dev/slog"
+)
+
+// LokiOptions represents the options for the sink returned by Loki.
+type LokiOptions struct {
+	// Options configures the underlying sink.
+	// The Format is always the Loki push format.
+	Options
+
+	// Labels are the names of the fields that are sent as stream labels
+	// instead of in the line. "level" is the level of the entry and
+	// "logger" is the logger names joined with a period.
+	// Invalid characters in label names are replaced with underscores.
+	//
+	// The tags of entries are always sent as labels. See slog.Tag.
+	Labels []string
+	// StaticLabels are sent with every entry. e.g. {"job": "api", "host": "a1"}
+	StaticLabels map[string]string
+	// MaxLabelValues is the maximum number of distinct values of a label.
+	// Once it is reached, entries with new values keep the field in the
+	// line instead. Defaults to 100.
+	MaxLabelValues int
+	// TenantID is sent in the X-Scope-OrgID header if set.
+	TenantID string
+}
+
+// Loki creates a sink that pushes entries to the Loki push API at url.
+// e.g. "http://loki:3100/loki/api/v1/push"
+//
+// Every line is the entry in the slogjson format without the
+// fields and tags that were sent as labels.
+//
+// If opts is nil, the defaults are used.
+func Loki(url string, opts *LokiOptions) *BatchSink {
+	if opts == nil {
+		opts = &LokiOptions{}
+	}
+	o := opts.Options
+	o.Format = newLokiFormat(opts)
+	if opts.TenantID != "" {
+		o.Header = cloneHeader(o.Header)
+		o.Header.Set("X-Scope-OrgID", opts.TenantID)
+	}
+	return newBatchSink("sloghttp.Loki", url, &o)
+}
+
+func cloneHeader(h http.Header) http.Header {
+	h2 := make(http.Header, len(h)+1)
+	for k, v := range h {
+		h2[k] = v
+	}
+	return h2
+}
+
+type lokiFormat struct {
+	labels    map[string]string
+	static    map[string]string
+	maxValues int
+	// seen is the set of values of every label.
+	// Append is only called with the sink lock held.
+	seen map[string]map[string]struct{}
+}
+
+func newLokiFormat(opts *LokiOptions) *lokiFormat {
+	f := &lokiFormat{
+		labels:    make(map[string]string, len(opts.Labels)),
+		static:    make(map[string]string, len(opts.StaticLabels)),
+		maxValues: opts.MaxLabelValues,
+		seen:      make(map[string]map[string]struct{}),
+	}
+	if f.maxValues <= 0 {
+		f.maxValues = 100
+	}
+	for _, name := range opts.Labels {
+		f.labels[name] = lokiLabelName(name)
+	}
+	for k, v := range opts.StaticLabels {
+		f.static[lokiLabelName(k)] = v
+	}
+	return f
+}
+
+// lokiLabelName replaces the characters not allowed in
+// label names with underscores.
+func lokiLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func (f *lokiFormat) ContentType() string {
+	return "application/json"
+}
+
+func (f *lokiFormat) Append(b []byte, ent slog.SinkEntry) []byte {
+	stream := make(map[string]string, len(f.static)+len(f.labels))
+	for k, v := range f.static {
+		stream[k] = v
+	}
+
+	if label, ok := f.labels["level"]; ok {
+		if v := strings.ToLower(ent.Level.String()); f.allow(label, v) {
+			stream[label] = v
+		}
+	}
+	if label, ok := f.labels["logger"]; ok && len(ent.LoggerNames) > 0 {
+		name := strings.Join(ent.LoggerNames, ".")
+		if f.allow(label, name) {
+			stream[label] = name
+		}
+	}
+
+	fields := make(slog.Map, 0, len(ent.Fields))
+	for _, field := range ent.Fields {
+		label, ok := f.labels[field.Name]
+		if ok {
+			v := labelValue(field.Value)
+			if f.allow(label, v) {
+				stream[label] = v
+				continue
+			}
+		}
+		fields = append(fields, field)
+	}
+	ent.Fields = fields
+
+	// Tags are always labels unless they exceed the cardinality limit.
+	var tags slog.Map
+	for _, tag := range ent.Tags {
+		label := lokiLabelName(tag.Name)
+		v := labelValue(tag.Value)
+		if f.allow(label, v) {
+			stream[label] = v
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	ent.Tags = tags
+
+	line, _ := json.Marshal(ent)
+	ts := strconv.FormatInt(ent.Time.UnixNano(), 10)
+
+	if len(b) == 0 {
+		b = append(b, `{"streams":[`...)
+	} else {
+		b = append(b, ',')
+	}
+	b = append(b, `{"stream":`...)
+	b = appendJSON(b, stream, 0)
+	b = append(b, `,"values":[[`...)
+	b = appendJSON(b, ts, ',')
+	b = appendJSON(b, string(line), 0)
+	b = append(b, "]]}"...)
+	return b
+}
+
+// allow reports whether v can be a value of label without
+// exceeding the cardinality limit.
+func (f *lokiFormat) allow(label, v string) bool {
+	values, ok := f.seen[label]
+	if !ok {
+		values = make(map[string]struct{})
+		f.seen[label] = values
+	}
+	if _, ok := values[v]; ok {
+		return true
+	}
+	if len(values) >= f.maxValues {
+		return false
+	}
+	values[v] = struct{}{}
+	return true
+}
+
+func labelValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (f *lokiFormat) Finish(b []byte) ([]byte, error) {
+	return append(b, "]}"...), nil
+}
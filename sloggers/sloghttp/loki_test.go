@@ -0,0 +1,69 @@
+package sloghttp_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghttp"
+)
+
+func TestLoki(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	s := sloghttp.Loki(srv.URL, &sloghttp.LokiOptions{
+		Options: sloghttp.Options{
+			FlushInterval: time.Hour,
+		},
+		Labels:         []string{"level", "logger", "component"},
+		StaticLabels:   map[string]string{"host": "a1"},
+		MaxLabelValues: 1,
+		TenantID:       "team",
+	})
+
+	l := slog.Make(s).Named("api")
+	l.Info(bg, "1", slog.F("component", "db"), slog.F("user", "bob"), slog.Tag("region", "us-east-1"))
+	// The level label counts the value that is sent.
+	l.Info(bg, "2", slog.F("component", "cache"), slog.Tag("level", "info"))
+	err := s.Close()
+	assert.Success(t, "close", err)
+
+	assert.Equal(t, "tenant", "team", srv.Requests()[0].Header.Get("X-Scope-OrgID"))
+
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	err = json.Unmarshal([]byte(srv.Bodies()[0]), &push)
+	assert.Success(t, "unmarshal", err)
+	assert.Len(t, "streams", 2, push.Streams)
+
+	assert.Equal(t, "stream 1", map[string]string{
+		"host":      "a1",
+		"level":     "info",
+		"logger":    "api",
+		"component": "db",
+		"region":    "us-east-1",
+	}, push.Streams[0].Stream)
+	var ent slog.SinkEntry
+	err = ent.UnmarshalJSON([]byte(push.Streams[0].Values[0][1]))
+	assert.Success(t, "unmarshal line", err)
+	assert.Equal(t, "fields", slog.M(slog.F("user", "bob")), ent.Fields)
+	assert.Len(t, "tags", 0, ent.Tags)
+
+	// Only one component value is allowed as a label.
+	assert.Equal(t, "stream 2", map[string]string{
+		"host":   "a1",
+		"level":  "info",
+		"logger": "api",
+	}, push.Streams[1].Stream)
+	err = ent.UnmarshalJSON([]byte(push.Streams[1].Values[0][1]))
+	assert.Success(t, "unmarshal line", err)
+	assert.Equal(t, "fields", slog.M(slog.F("component", "cache")), ent.Fields)
+	assert.Len(t, "tags", 0, ent.Tags)
+}
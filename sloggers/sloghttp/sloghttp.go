@@ -0,0 +1,522 @@
+// Package sloghttp contains the slogger that POSTs batches
+// of entries to an HTTP endpoint.
+//
+// Entries are batched in memory and sent by a background goroutine.
+// Failed requests are retried with exponential backoff. Responses
+// with status 429 or 503 are retried after the time in their
+// Retry-After header.
+//
+// The body of a request is determined by a Format. NDJSON and
+// ElasticBulk are provided and the other files in this package
+// contain presets for specific services.
+package sloghttp // import "cdr.dev/slog/sloggers/sloghttp"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/exportctx"
+)
+
+// Format encodes batches of entries into request bodies.
+type Format interface {
+	// ContentType returns the Content-Type of request bodies.
+	ContentType() string
+	// Append appends ent to the body b of the batch.
+	// b is empty for the first entry of a batch.
+	Append(b []byte, ent slog.SinkEntry) []byte
+	// Finish completes the body b of a batch before it is sent.
+	// If it returns an error, e.g. because b is corrupt,
+	// the batch is dropped.
+	Finish(b []byte) ([]byte, error)
+}
+
+// NDJSON returns a Format that writes every entry in
+// the slogjson format on its own line.
+func NDJSON() Format {
+	return ndjson{}
+}
+
+type ndjson struct{}
+
+func (ndjson) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjson) Append(b []byte, ent slog.SinkEntry) []byte {
+	return appendJSON(b, ent, '\n')
+}
+
+func (ndjson) Finish(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// ElasticBulk returns a Format for the Elasticsearch _bulk API
+// that indexes every entry in the slogjson format into index.
+func ElasticBulk(index string) Format {
+	action, _ := json.Marshal(map[string]interface{}{
+		"index": map[string]string{
+			"_index": index,
+		},
+	})
+	return elasticBulk{
+		action: append(action, '\n'),
+	}
+}
+
+type elasticBulk struct {
+	action []byte
+}
+
+func (elasticBulk) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (f elasticBulk) Append(b []byte, ent slog.SinkEntry) []byte {
+	b = append(b, f.action...)
+	return appendJSON(b, ent, '\n')
+}
+
+func (elasticBulk) Finish(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// appendJSON appends the JSON encoding of v followed by sep if non zero.
+func appendJSON(b []byte, v interface{}, sep byte) []byte {
+	// No error is guaranteed due to slog.Map handling errors itself.
+	buf, _ := json.Marshal(v)
+	b = append(b, buf...)
+	if sep != 0 {
+		b = append(b, sep)
+	}
+	return b
+}
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Format encodes the request bodies. Defaults to NDJSON.
+	Format Format
+	// Client sends the requests. Defaults to a client
+	// with a 30s timeout.
+	Client *http.Client
+	// Header is added to every request. e.g. for authorization.
+	Header http.Header
+	// Prepare is called with every request and its body
+	// before it is sent. It can be used to sign requests.
+	Prepare func(req *http.Request, body []byte) error
+	// Gzip enables compressing request bodies.
+	Gzip bool
+	// Sanitize enables escaping or stripping CR, LF and NUL characters
+	// in entries before they are encoded. See slog.SanitizeEntry.
+	// Even though JSON escapes them, receivers may not once decoded.
+	Sanitize *slog.SanitizeOptions
+
+	// BatchSize is the maximum number of entries in a request.
+	// Defaults to 1000.
+	BatchSize int
+	// BatchBytes is the size of a request body in bytes after
+	// which it is sent. Defaults to 1 MiB.
+	BatchBytes int
+	// FlushInterval is the maximum time an entry waits in
+	// a batch before it is sent. Defaults to 1s.
+	FlushInterval time.Duration
+	// MaxPending is the maximum number of batches waiting to be sent.
+	// When it is reached, the oldest batch is dropped. Defaults to 8.
+	MaxPending int
+
+	// MaxRetries is the number of times a failed request is retried.
+	// Defaults to 5. Set to a negative value to disable retries.
+	MaxRetries int
+	// MinBackoff is the time to wait before the first retry.
+	// It is doubled on every retry. Defaults to 500ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum time to wait before a retry.
+	// Defaults to 30s.
+	MaxBackoff time.Duration
+	// Timeout is the maximum time to send a batch, including the
+	// retries, after which it is dropped so that a slow endpoint does
+	// not hold up the batches behind it. Defaults to 1m. Set to a
+	// negative value to disable it.
+	Timeout time.Duration
+}
+
+func (opts *Options) withDefaults() *Options {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Format == nil {
+		o.Format = NDJSON()
+	}
+	if o.Client == nil {
+		o.Client = &http.Client{
+			Timeout: 30 * time.Second,
+		}
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 1000
+	}
+	if o.BatchBytes <= 0 {
+		o.BatchBytes = 1 << 20
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.MaxPending <= 0 {
+		o.MaxPending = 8
+	}
+	if o.MaxRetries == 0 {
+		o.MaxRetries = 5
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 500 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 30 * time.Second
+	}
+	if o.Timeout == 0 {
+		o.Timeout = time.Minute
+	}
+	return &o
+}
+
+// BatchSink POSTs batches of entries to an HTTP endpoint.
+//
+// See Make.
+type BatchSink struct {
+	// dropped is first for 64 bit alignment.
+	dropped uint64
+
+	name  string
+	url   string
+	opts  *Options
+	queue chan batch
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	// verify is called with the context of the request and the body of
+	// successful responses by presets that must check it. An error
+	// causes a retry.
+	verify func(ctx context.Context, resp []byte) error
+
+	mu      sync.Mutex
+	drained *sync.Cond
+	body    []byte
+	count   int
+	pending int
+	err     error
+	closed  bool
+
+	errorf func(f string, v ...interface{})
+}
+
+type batch struct {
+	body  []byte
+	count int
+}
+
+var _ slog.ErrorSink = &BatchSink{}
+
+// Make creates a sink that POSTs batches of entries to url.
+//
+// Sync sends the current batch and waits until every batch has
+// been sent. Call Close to send the remaining entries
+// and stop the background goroutines.
+//
+// If opts is nil, the defaults are used.
+func Make(url string, opts *Options) *BatchSink {
+	return newBatchSink("sloghttp", url, opts)
+}
+
+func newBatchSink(name, url string, opts *Options) *BatchSink {
+	s := &BatchSink{
+		name: name,
+		url:  url,
+		opts: opts.withDefaults(),
+		done: make(chan struct{}),
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+	s.queue = make(chan batch, s.opts.MaxPending)
+	s.drained = sync.NewCond(&s.mu)
+
+	s.wg.Add(2)
+	go s.sendLoop()
+	go s.flushLoop()
+	return s
+}
+
+// LogEntry implements slog.Sink.
+func (s *BatchSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = s.LogEntryErr(ctx, ent)
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// It only returns an error if the sink is closed.
+// Failures to send batches are returned by SyncErr.
+func (s *BatchSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return xerrors.Errorf("%v: sink is closed", s.name)
+	}
+
+	if s.opts.Sanitize != nil {
+		ent = slog.SanitizeEntry(ent, s.opts.Sanitize)
+	}
+	s.body = s.opts.Format.Append(s.body, ent)
+	s.count++
+	if s.count >= s.opts.BatchSize || len(s.body) >= s.opts.BatchBytes {
+		s.flushLocked()
+	}
+	return nil
+}
+
+// flushLocked queues the current batch.
+func (s *BatchSink) flushLocked() {
+	if s.count == 0 {
+		return
+	}
+	body, err := s.opts.Format.Finish(s.body)
+	b := batch{
+		body:  body,
+		count: s.count,
+	}
+	s.body = nil
+	s.count = 0
+	if err != nil {
+		atomic.AddUint64(&s.dropped, uint64(b.count))
+		if s.err == nil {
+			s.err = err
+		}
+		s.errorf("%v: failed to finish batch of %v entries: %+v", s.name, b.count, err)
+		return
+	}
+
+	s.pending++
+	select {
+	case s.queue <- b:
+	default:
+		select {
+		case old := <-s.queue:
+			s.pending--
+			atomic.AddUint64(&s.dropped, uint64(old.count))
+		default:
+		}
+		// Only flushLocked sends and it holds the lock so there is room now.
+		s.queue <- b
+	}
+}
+
+func (s *BatchSink) flushLoop() {
+	defer s.wg.Done()
+
+	t := time.NewTicker(s.opts.FlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+			s.mu.Lock()
+			s.flushLocked()
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *BatchSink) sendLoop() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case b := <-s.queue:
+			err := s.send(b.body)
+			s.mu.Lock()
+			if err != nil {
+				atomic.AddUint64(&s.dropped, uint64(b.count))
+				if s.err == nil {
+					s.err = err
+				}
+				s.errorf("%v: failed to send %v entries: %+v", s.name, b.count, err)
+			}
+			s.pending--
+			if s.pending == 0 {
+				s.drained.Broadcast()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *BatchSink) send(body []byte) error {
+	if s.opts.Gzip {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(body)
+		w.Close()
+		body = buf.Bytes()
+	}
+
+	ctx, cancel := exportctx.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+
+	backoff := s.opts.MinBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if retryAfter < 0 || attempt >= s.opts.MaxRetries {
+			return err
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return xerrors.Errorf("timed out after %v: %w", s.opts.Timeout, err)
+		}
+
+		backoff *= 2
+		if backoff > s.opts.MaxBackoff {
+			backoff = s.opts.MaxBackoff
+		}
+	}
+}
+
+// post sends a single request. If the request failed, retryAfter is
+// negative if it must not be retried, zero if it should be retried
+// with backoff or the time to wait before retrying.
+func (s *BatchSink) post(ctx context.Context, body []byte) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, xerrors.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range s.opts.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", s.opts.Format.ContentType())
+	if s.opts.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if s.opts.Prepare != nil {
+		err = s.opts.Prepare(req, body)
+		if err != nil {
+			return -1, xerrors.Errorf("failed to prepare request: %w", err)
+		}
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if s.verify != nil {
+			return 0, s.verify(ctx, msg)
+		}
+		return 0, nil
+	}
+	err = xerrors.Errorf("unexpected status %v: %s", resp.Status, bytes.TrimSpace(msg))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return parseRetryAfter(resp.Header.Get("Retry-After")), err
+	case resp.StatusCode >= 500:
+		return 0, err
+	default:
+		return -1, err
+	}
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Sync implements slog.Sink.
+func (s *BatchSink) Sync() {
+	_ = s.SyncErr()
+}
+
+// SyncErr implements slog.ErrorSink.
+//
+// It sends the current batch, waits until every batch has been sent and
+// returns the first error sending a batch since the last call.
+func (s *BatchSink) SyncErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flushLocked()
+	for s.pending > 0 {
+		s.drained.Wait()
+	}
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Dropped returns the number of entries that were dropped because
+// too many batches were pending or they could not be sent.
+func (s *BatchSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close sends the remaining entries and stops the background goroutines.
+// It returns the first error sending a batch since the last Sync.
+func (s *BatchSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.flushLocked()
+	for s.pending > 0 {
+		s.drained.Wait()
+	}
+	err := s.err
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+	return err
+}
@@ -0,0 +1,206 @@
+package sloghttp_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghttp"
+)
+
+var bg = context.Background()
+
+// server records the bodies of requests and responds
+// with the queued statuses before succeeding.
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	statuses []int
+}
+
+func newServer(t *testing.T, statuses ...int) *server {
+	s := &server{
+		statuses: statuses,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		var err error
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			var gr *gzip.Reader
+			gr, err = gzip.NewReader(r.Body)
+			if err == nil {
+				body, err = ioutil.ReadAll(gr)
+			}
+		} else {
+			body, err = ioutil.ReadAll(r.Body)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r)
+		s.bodies = append(s.bodies, string(body))
+		if len(s.statuses) > 0 {
+			status := s.statuses[0]
+			s.statuses = s.statuses[1:]
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) Bodies() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func (s *server) Requests() []*http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*http.Request(nil), s.requests...)
+}
+
+func messages(t *testing.T, body string) []string {
+	t.Helper()
+
+	var msgs []string
+	sc := bufio.NewScanner(strings.NewReader(body))
+	for sc.Scan() {
+		var ent slog.SinkEntry
+		err := ent.UnmarshalJSON(sc.Bytes())
+		assert.Success(t, "unmarshal", err)
+		msgs = append(msgs, ent.Message)
+	}
+	return msgs
+}
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	s := sloghttp.Make(srv.URL, &sloghttp.Options{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Gzip:          true,
+		Header: http.Header{
+			"Authorization": []string{"Bearer token"},
+		},
+	})
+
+	l := slog.Make(s)
+	l.Info(bg, "1")
+	l.Info(bg, "2")
+	l.Info(bg, "3")
+	err := s.Close()
+	assert.Success(t, "close", err)
+
+	bodies := srv.Bodies()
+	assert.Len(t, "bodies", 2, bodies)
+	assert.Equal(t, "batch 1", []string{"1", "2"}, messages(t, bodies[0]))
+	assert.Equal(t, "batch 2", []string{"3"}, messages(t, bodies[1]))
+
+	r := srv.Requests()[0]
+	assert.Equal(t, "authorization", "Bearer token", r.Header.Get("Authorization"))
+	assert.Equal(t, "content type", "application/x-ndjson", r.Header.Get("Content-Type"))
+}
+
+func TestMake_Sanitize(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t)
+	s := sloghttp.Make(srv.URL, &sloghttp.Options{
+		Sanitize: &slog.SanitizeOptions{Strip: true},
+	})
+	slog.Make(s).Info(bg, "a\r\nb")
+	err := s.Close()
+	assert.Success(t, "close", err)
+
+	bodies := srv.Bodies()
+	assert.Len(t, "bodies", 1, bodies)
+	assert.Equal(t, "messages", []string{"ab"}, messages(t, bodies[0]))
+}
+
+func TestMake_Retry(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, http.StatusTooManyRequests, http.StatusInternalServerError)
+	s := sloghttp.Make(srv.URL, &sloghttp.Options{
+		MinBackoff: time.Millisecond,
+	})
+	defer s.Close()
+
+	slog.Make(s).Info(bg, "hello")
+	err := s.SyncErr()
+	assert.Success(t, "sync", err)
+	assert.Len(t, "bodies", 3, srv.Bodies())
+	assert.Equal(t, "dropped", uint64(0), s.Dropped())
+}
+
+func TestMake_Timeout(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	s := sloghttp.Make(srv.URL, &sloghttp.Options{
+		MinBackoff: time.Hour,
+		Timeout:    10 * time.Millisecond,
+	})
+	defer s.Close()
+
+	slog.Make(s).Info(bg, "hello")
+	err := s.SyncErr()
+	assert.Error(t, "sync", err)
+	assert.True(t, "timed out", strings.Contains(err.Error(), "timed out after 10ms"))
+	assert.Equal(t, "dropped", uint64(1), s.Dropped())
+}
+
+func TestMake_BadRequest(t *testing.T) {
+	t.Parallel()
+
+	srv := newServer(t, http.StatusBadRequest)
+	s := sloghttp.Make(srv.URL, &sloghttp.Options{
+		MinBackoff: time.Millisecond,
+	})
+	defer s.Close()
+
+	slog.Make(s).Info(bg, "hello")
+	err := s.SyncErr()
+	assert.Error(t, "sync", err)
+	assert.Len(t, "bodies", 1, srv.Bodies())
+	assert.Equal(t, "dropped", uint64(1), s.Dropped())
+
+	err = s.SyncErr()
+	assert.Success(t, "sync", err)
+}
+
+func TestElasticBulk(t *testing.T) {
+	t.Parallel()
+
+	f := sloghttp.ElasticBulk("logs")
+	b := f.Append(nil, slog.SinkEntry{Message: "hello"})
+	b, err := f.Finish(b)
+	assert.Success(t, "finish", err)
+
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	assert.Len(t, "lines", 2, lines)
+	assert.Equal(t, "action", `{"index":{"_index":"logs"}}`, string(lines[0]))
+	assert.Equal(t, "msgs", []string{"hello"}, messages(t, string(lines[1])))
+}
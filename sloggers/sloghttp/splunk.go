@@ -0,0 +1,231 @@
+package sloghttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// SplunkOptions represents the options for the sink returned by Splunk.
+type SplunkOptions struct {
+	// Options configures the underlying sink.
+	// The Format is always the HEC event format.
+	Options
+
+	// Token is the HEC token.
+	Token string
+	// Index, Source, SourceType and Host set the metadata of every
+	// event. The defaults configured for the token are used if empty.
+	Index      string
+	Source     string
+	SourceType string
+	Host       string
+	// Fields are the names of the entry fields that are also sent as
+	// indexed fields. "level" is the level of the entry.
+	Fields []string
+
+	// Channel is sent in the X-Splunk-Request-Channel header
+	// and is required if indexer acknowledgment is enabled
+	// for the token. It must be a GUID.
+	Channel string
+	// Ack enables waiting for indexer acknowledgment of every
+	// batch. Batches that are not acknowledged within AckTimeout
+	// are sent again. Requires Channel. The wait counts towards
+	// Options.Timeout.
+	Ack bool
+	// AckPollInterval is how often the acknowledgment status
+	// is polled. Defaults to 1s.
+	AckPollInterval time.Duration
+	// AckTimeout defaults to 1m.
+	AckTimeout time.Duration
+}
+
+// Splunk creates a sink that sends entries to the Splunk HTTP Event
+// Collector at url. e.g. "https://splunk:8088"
+//
+// The event of every entry is the entry in the slogjson format.
+//
+// If opts is nil, the defaults are used.
+func Splunk(url string, opts *SplunkOptions) *BatchSink {
+	if opts == nil {
+		opts = &SplunkOptions{}
+	}
+	url = strings.TrimSuffix(url, "/")
+
+	o := opts.Options
+	o.Format = newSplunkFormat(opts)
+	o.Header = cloneHeader(o.Header)
+	o.Header.Set("Authorization", "Splunk "+opts.Token)
+	if opts.Channel != "" {
+		o.Header.Set("X-Splunk-Request-Channel", opts.Channel)
+	}
+
+	s := newBatchSink("sloghttp.Splunk", url+"/services/collector/event", &o)
+	if opts.Ack {
+		a := &splunkAck{
+			s:            s,
+			url:          url + "/services/collector/ack",
+			pollInterval: opts.AckPollInterval,
+			timeout:      opts.AckTimeout,
+		}
+		if a.pollInterval <= 0 {
+			a.pollInterval = time.Second
+		}
+		if a.timeout <= 0 {
+			a.timeout = time.Minute
+		}
+		s.verify = a.wait
+	}
+	return s
+}
+
+type splunkFormat struct {
+	index      string
+	source     string
+	sourceType string
+	host       string
+	fields     map[string]struct{}
+}
+
+func newSplunkFormat(opts *SplunkOptions) *splunkFormat {
+	f := &splunkFormat{
+		index:      opts.Index,
+		source:     opts.Source,
+		sourceType: opts.SourceType,
+		host:       opts.Host,
+		fields:     make(map[string]struct{}, len(opts.Fields)),
+	}
+	for _, name := range opts.Fields {
+		f.fields[name] = struct{}{}
+	}
+	return f
+}
+
+func (f *splunkFormat) ContentType() string {
+	return "application/json"
+}
+
+type splunkEvent struct {
+	Time       json.Number       `json:"time"`
+	Host       string            `json:"host,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	SourceType string            `json:"sourcetype,omitempty"`
+	Index      string            `json:"index,omitempty"`
+	Event      slog.SinkEntry    `json:"event"`
+	Fields     map[string]string `json:"fields,omitempty"`
+}
+
+func (f *splunkFormat) Append(b []byte, ent slog.SinkEntry) []byte {
+	ev := splunkEvent{
+		// Seconds since the epoch with millisecond precision.
+		Time:       json.Number(strconv.FormatFloat(float64(ent.Time.UnixNano()/1e6)/1e3, 'f', 3, 64)),
+		Host:       f.host,
+		Source:     f.source,
+		SourceType: f.sourceType,
+		Index:      f.index,
+		Event:      ent,
+	}
+
+	if len(f.fields) > 0 {
+		ev.Fields = make(map[string]string)
+		if _, ok := f.fields["level"]; ok {
+			ev.Fields["level"] = ent.Level.String()
+		}
+		for _, field := range ent.Fields {
+			if _, ok := f.fields[field.Name]; ok {
+				ev.Fields[field.Name] = labelValue(field.Value)
+			}
+		}
+	}
+
+	// Events are concatenated without a separator but
+	// a newline makes the body easier to read.
+	return appendJSON(b, ev, '\n')
+}
+
+func (f *splunkFormat) Finish(b []byte) ([]byte, error) {
+	return b, nil
+}
+
+// splunkAck waits for indexer acknowledgment.
+type splunkAck struct {
+	s            *BatchSink
+	url          string
+	pollInterval time.Duration
+	timeout      time.Duration
+}
+
+func (a *splunkAck) wait(ctx context.Context, resp []byte) error {
+	var r struct {
+		AckID *int64 `json:"ackId"`
+	}
+	err := json.Unmarshal(resp, &r)
+	if err != nil {
+		return xerrors.Errorf("failed to parse response %q: %w", resp, err)
+	}
+	if r.AckID == nil {
+		return xerrors.Errorf("response %q has no ackId, is indexer acknowledgment enabled?", resp)
+	}
+
+	deadline := time.Now().Add(a.timeout)
+	for {
+		ok, err := a.poll(ctx, *r.AckID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return xerrors.Errorf("ack %v timed out after %v", *r.AckID, a.timeout)
+		}
+
+		t := time.NewTimer(a.pollInterval)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return xerrors.Errorf("failed to wait for ack %v: %w", *r.AckID, ctx.Err())
+		}
+	}
+}
+
+func (a *splunkAck) poll(ctx context.Context, id int64) (bool, error) {
+	body, _ := json.Marshal(map[string][]int64{
+		"acks": {id},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+	if err != nil {
+		return false, xerrors.Errorf("failed to create ack request: %w", err)
+	}
+	for k, v := range a.s.opts.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.s.opts.Client.Do(req)
+	if err != nil {
+		return false, xerrors.Errorf("failed to poll ack: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, xerrors.Errorf("unexpected ack status %v", resp.Status)
+	}
+
+	var r struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return false, xerrors.Errorf("failed to decode ack response: %w", err)
+	}
+	return r.Acks[strconv.FormatInt(id, 10)], nil
+}
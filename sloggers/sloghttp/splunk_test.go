@@ -0,0 +1,124 @@
+package sloghttp_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghttp"
+)
+
+func TestSplunk(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var events []byte
+	var header http.Header
+	polls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/collector/event", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		events = b
+		header = r.Header
+		mu.Unlock()
+		w.Write([]byte(`{"text":"Success","code":0,"ackId":7}`))
+	})
+	mux.HandleFunc("/services/collector/ack", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		done := polls > 1
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"acks": map[string]bool{"7": done},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := sloghttp.Splunk(srv.URL, &sloghttp.SplunkOptions{
+		Token:           "token",
+		Index:           "main",
+		SourceType:      "_json",
+		Fields:          []string{"level", "user"},
+		Channel:         "a0b11c3f-e2a4-4efc-9c1d-8f7d6c5b4a39",
+		Ack:             true,
+		AckPollInterval: time.Millisecond,
+	})
+
+	slog.Make(s).Warn(bg, "hello", slog.F("user", "bob"))
+	err := s.Close()
+	assert.Success(t, "close", err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "polls", 2, polls)
+	assert.Equal(t, "authorization", "Splunk token", header.Get("Authorization"))
+	assert.Equal(t, "channel", "a0b11c3f-e2a4-4efc-9c1d-8f7d6c5b4a39", header.Get("X-Splunk-Request-Channel"))
+
+	var ev struct {
+		Time       float64           `json:"time"`
+		Index      string            `json:"index"`
+		SourceType string            `json:"sourcetype"`
+		Event      slog.SinkEntry    `json:"event"`
+		Fields     map[string]string `json:"fields"`
+	}
+	err = json.Unmarshal(events, &ev)
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "index", "main", ev.Index)
+	assert.Equal(t, "sourcetype", "_json", ev.SourceType)
+	assert.Equal(t, "msg", "hello", ev.Event.Message)
+	assert.Equal(t, "fields", map[string]string{"level": "WARN", "user": "bob"}, ev.Fields)
+	assert.True(t, "time", time.Since(time.Unix(int64(ev.Time), 0)) < time.Minute)
+}
+
+func TestSplunk_AckTimeout(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	polls := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/services/collector/event", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"text":"Success","code":0,"ackId":7}`))
+	})
+	mux.HandleFunc("/services/collector/ack", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		polls++
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"acks": map[string]bool{"7": false},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := sloghttp.Splunk(srv.URL, &sloghttp.SplunkOptions{
+		Options: sloghttp.Options{
+			Timeout: 50 * time.Millisecond,
+		},
+		Channel:         "a0b11c3f-e2a4-4efc-9c1d-8f7d6c5b4a39",
+		Ack:             true,
+		AckPollInterval: time.Hour,
+	})
+	defer s.Close()
+
+	start := time.Now()
+	slog.Make(s).Info(bg, "hello")
+	err := s.SyncErr()
+	assert.Error(t, "sync", err)
+	assert.True(t, "timed out", strings.Contains(err.Error(), "timed out after 50ms"))
+	assert.True(t, "bounded by timeout", time.Since(start) < 10*time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "polls", 1, polls)
+}
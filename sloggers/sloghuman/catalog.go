@@ -0,0 +1,56 @@
+package sloghuman
+
+import (
+	"fmt"
+	"strings"
+
+	"cdr.dev/slog"
+)
+
+// Catalog returns a function for Options.Translate that looks up the
+// translation of every message in messages, keyed by the message as
+// logged. Placeholders such as {disk} in the translation are replaced
+// with the value of the field of the same name. Messages without a
+// translation are written as is.
+//
+//	sloghuman.Catalog(map[string]string{
+//		"disk full": "Datenträger {disk} ist voll",
+//	})
+func Catalog(messages map[string]string) func(msg string, fields slog.Map) string {
+	return func(msg string, fields slog.Map) string {
+		tr, ok := messages[msg]
+		if !ok {
+			return msg
+		}
+		if !strings.Contains(tr, "{") {
+			return tr
+		}
+		return expand(tr, fields)
+	}
+}
+
+// expand replaces the placeholders in s with the values of fields.
+// Unknown placeholders are left as is.
+func expand(s string, fields slog.Map) string {
+	var sb strings.Builder
+	for {
+		i := strings.IndexByte(s, '{')
+		if i < 0 {
+			break
+		}
+		j := strings.IndexByte(s[i:], '}')
+		if j < 0 {
+			break
+		}
+		j += i
+		sb.WriteString(s[:i])
+		if v, ok := fields.Get(s[i+1 : j]); ok {
+			fmt.Fprint(&sb, v)
+		} else {
+			sb.WriteString(s[i : j+1])
+		}
+		s = s[j+1:]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
@@ -0,0 +1,39 @@
+package sloghuman_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Parallel()
+
+	human := &bytes.Buffer{}
+	json := &bytes.Buffer{}
+	l := slog.Make(
+		sloghuman.Make(human, &sloghuman.Options{
+			Translate: sloghuman.Catalog(map[string]string{
+				"disk full": "Datenträger {disk} ist voll {unknown}",
+			}),
+		}),
+		slogjson.Sink(json),
+	)
+	l.Info(bg, "disk full", slog.F("disk", "sda"))
+	l.Info(bg, "untranslated")
+
+	lines := strings.Split(strings.TrimSuffix(human.String(), "\n"), "\n")
+	assert.Len(t, "lines", 2, lines)
+	assert.True(t, "translated", strings.Contains(lines[0], "\tDatenträger sda ist voll {unknown}\t"))
+	assert.True(t, "untranslated", strings.Contains(lines[1], "\tuntranslated"))
+
+	var ent slog.SinkEntry
+	err := ent.UnmarshalJSON([]byte(strings.SplitN(json.String(), "\n", 2)[0]))
+	assert.Success(t, "unmarshal JSON", err)
+	assert.Equal(t, "JSON message", "disk full", ent.Message)
+}
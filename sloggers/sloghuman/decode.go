@@ -0,0 +1,287 @@
+package sloghuman
+
+import (
+	"bufio"
+	"encoding/hex"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/entryhuman"
+)
+
+// Decoder reads entries written in the human readable format,
+// e.g. from captured terminal output, so that tests and tools
+// can inspect them without matching the format with regexps.
+//
+// The file and function of the location are as written, i.e. relative
+// to the module and the package. Multiline values written with
+// MultilineIndent become the message or a string field appended to the
+// fields. Entries written with MultilineSplit are decoded as separate
+// entries. Colors are removed and lines that are not part of an entry
+// are skipped.
+type Decoder struct {
+	r    *bufio.Reader
+	opts *Options
+
+	// next is a line read ahead.
+	next    string
+	hasNext bool
+}
+
+// NewDecoder returns a decoder that reads entries from r.
+//
+// opts must match the options of the sink that wrote them.
+// If opts is nil, the defaults are used.
+func NewDecoder(r io.Reader, opts *Options) *Decoder {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &Decoder{
+		r:    bufio.NewReader(r),
+		opts: opts,
+	}
+}
+
+// splitField matches the lines of multiline fields
+// written with MultilineSplit.
+var splitField = regexp.MustCompile(`^"([^"]*)": (.*)$`)
+
+// ansi matches the color escape sequences.
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func (d *Decoder) readLine() (string, error) {
+	if d.hasNext {
+		d.hasNext = false
+		return d.next, nil
+	}
+
+	line, err := d.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	line = ansi.ReplaceAllString(line, "")
+	if d.opts.SDPriority {
+		line = stripPriority(line)
+	}
+	return line, nil
+}
+
+func (d *Decoder) unreadLine(line string) {
+	d.next = line
+	d.hasNext = true
+}
+
+// stripPriority removes a sd-daemon(3) prefix such as <6>.
+func stripPriority(line string) string {
+	if len(line) >= 3 && line[0] == '<' && line[2] == '>' && line[1] >= '0' && line[1] <= '7' {
+		return line[3:]
+	}
+	return line
+}
+
+// Decode reads the next entry into ent.
+//
+// It returns io.EOF when there are no more entries.
+func (d *Decoder) Decode(ent *slog.SinkEntry) error {
+	var e slog.SinkEntry
+	var more bool
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return err
+		}
+		e, more, err = parseHeader(line)
+		if err == nil {
+			break
+		}
+	}
+
+	key, val, err := d.readMultiline()
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		switch {
+		case key == "msg" && e.Message == "...":
+			e.Message = val
+		default:
+			e.Fields = append(e.Fields, slog.F(key, val))
+		}
+	} else if more {
+		// The header did not end with the ellipsis of
+		// a multiline value after all.
+		e.Message += " ..."
+	}
+
+	*ent = e
+	return nil
+}
+
+// readMultiline reads the multiline value written after a header
+// with MultilineIndent, if any.
+func (d *Decoder) readMultiline() (key, val string, err error) {
+	line, err := d.readLine()
+	if err == io.EOF {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// The first line is an optional indentation, the marker and
+	// then the key in quotes and the first line of the value.
+	i := strings.Index(line, `"`)
+	if i < 0 || strings.TrimLeft(line[:i], " ") != d.opts.ContinuationMarker {
+		d.unreadLine(line)
+		return "", "", nil
+	}
+	j := strings.Index(line[i+1:], `": `)
+	if j < 0 {
+		d.unreadLine(line)
+		return "", "", nil
+	}
+	prefix := line[:i]
+	key = line[i+1 : i+1+j]
+	lines := []string{line[i+1+j+3:]}
+	indent := prefix + strings.Repeat(" ", len(key)+4)
+
+	// Continuation lines are indented to the start of the value
+	// except for empty lines which are not indented.
+	var empty int
+	for {
+		line, err := d.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if line == "" {
+			empty++
+			continue
+		}
+		if !strings.HasPrefix(line, indent) {
+			d.unreadLine(line)
+			break
+		}
+		for ; empty > 0; empty-- {
+			lines = append(lines, "")
+		}
+		lines = append(lines, line[len(indent):])
+	}
+	return key, strings.Join(lines, "\n"), nil
+}
+
+// parseHeader parses the first line of an entry. more reports whether
+// the line ends with the ellipsis of a multiline value.
+func parseHeader(line string) (ent slog.SinkEntry, more bool, err error) {
+	if len(line) < len(entryhuman.TimeFormat)+1 {
+		return ent, false, xerrors.New("line too short")
+	}
+	ent.Time, err = time.Parse(entryhuman.TimeFormat, line[:len(entryhuman.TimeFormat)])
+	if err != nil {
+		return ent, false, xerrors.Errorf("failed to parse time: %w", err)
+	}
+	line = line[len(entryhuman.TimeFormat)+1:]
+
+	parts := strings.Split(line, "\t")
+	if len(parts) < 4 {
+		return ent, false, xerrors.New("missing parts")
+	}
+
+	level := parts[0]
+	if !strings.HasPrefix(level, "[") || !strings.HasSuffix(level, "]") {
+		return ent, false, xerrors.Errorf("invalid level %q", level)
+	}
+	err = ent.Level.UnmarshalText([]byte(level[1 : len(level)-1]))
+	if err != nil {
+		return ent, false, err
+	}
+	parts = parts[1:]
+
+	if name := parts[0]; strings.HasPrefix(name, "(") && strings.HasSuffix(name, ")") && strings.HasPrefix(parts[1], "<") {
+		ent.LoggerNames = strings.Split(name[1:len(name)-1], ".")
+		parts = parts[1:]
+	}
+
+	loc := parts[0]
+	if !strings.HasPrefix(loc, "<") || !strings.HasSuffix(loc, ">") {
+		return ent, false, xerrors.Errorf("invalid location %q", loc)
+	}
+	loc = loc[1 : len(loc)-1]
+	i := strings.LastIndexByte(loc, ':')
+	if i < 0 {
+		return ent, false, xerrors.Errorf("invalid location %q", loc)
+	}
+	ent.File = loc[:i]
+	ent.Line, err = strconv.Atoi(loc[i+1:])
+	if err != nil {
+		return ent, false, xerrors.Errorf("invalid line in location %q: %w", loc, err)
+	}
+	ent.Func = parts[1]
+	parts = parts[2:]
+
+	if len(parts) == 0 {
+		return ent, false, xerrors.New("missing message")
+	}
+	last := &parts[len(parts)-1]
+	if strings.HasSuffix(*last, " ...") {
+		*last = strings.TrimSuffix(*last, " ...")
+		more = true
+	}
+
+	ent.Message = parts[0]
+	if strings.HasPrefix(ent.Message, `"`) {
+		if m := splitField.FindStringSubmatch(ent.Message); m != nil && len(parts) == 1 {
+			// A line of a multiline field written with MultilineSplit.
+			ent.Message = ""
+			ent.Fields = slog.M(slog.F(m[1], m[2]))
+			return ent, false, nil
+		}
+		ent.Message, err = strconv.Unquote(ent.Message)
+		if err != nil {
+			return ent, false, xerrors.Errorf("failed to unquote message: %w", err)
+		}
+	}
+
+	if len(parts) > 1 {
+		err = ent.Fields.UnmarshalJSON([]byte(strings.Join(parts[1:], "\t")))
+		if err != nil {
+			return ent, false, xerrors.Errorf("failed to parse fields: %w", err)
+		}
+		ent.SpanContext, ent.Fields = spanContext(ent.Fields)
+	}
+	return ent, more, nil
+}
+
+// spanContext removes the trace and span fields
+// and returns the span context they describe.
+func spanContext(fields slog.Map) (trace.SpanContext, slog.Map) {
+	var sc trace.SpanContext
+	if len(fields) < 2 || fields[0].Name != "trace" || fields[1].Name != "span" {
+		return sc, fields
+	}
+	traceID, _ := fields[0].Value.(string)
+	spanID, _ := fields[1].Value.(string)
+	if hex.DecodedLen(len(traceID)) != len(sc.TraceID) || hex.DecodedLen(len(spanID)) != len(sc.SpanID) {
+		return sc, fields
+	}
+	_, err := hex.Decode(sc.TraceID[:], []byte(traceID))
+	if err != nil {
+		return trace.SpanContext{}, fields
+	}
+	_, err = hex.Decode(sc.SpanID[:], []byte(spanID))
+	if err != nil {
+		return trace.SpanContext{}, fields
+	}
+	return sc, fields[2:]
+}
@@ -0,0 +1,110 @@
+package sloghuman_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+func decodeAll(t *testing.T, r io.Reader, opts *sloghuman.Options) []slog.SinkEntry {
+	t.Helper()
+
+	d := sloghuman.NewDecoder(r, opts)
+	var ents []slog.SinkEntry
+	for {
+		var ent slog.SinkEntry
+		err := d.Decode(&ent)
+		if xerrors.Is(err, io.EOF) {
+			return ents
+		}
+		assert.Success(t, "decode", err)
+		ents = append(ents, ent)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	opts := &sloghuman.Options{
+		ContinuationMarker: "| ",
+		SDPriority:         true,
+	}
+	sc := trace.SpanContext{
+		TraceID: trace.TraceID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		SpanID:  trace.SpanID{0, 1, 2, 3, 4, 5, 6, 7},
+	}
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC)
+
+	ents := []slog.SinkEntry{{
+		Time:        ts,
+		Level:       slog.LevelInfo,
+		Message:     "hello\tworld",
+		LoggerNames: []string{"a", "b"},
+		Line:        12,
+		SpanContext: sc,
+		Fields:      slog.M(slog.F("n", "1"), slog.F("m", slog.M(slog.F("k", true)))),
+	}, {
+		Time:    ts,
+		Level:   slog.LevelError,
+		Message: "line1\n\nline2",
+		Line:    13,
+	}, {
+		Time:    ts,
+		Level:   slog.LevelWarn,
+		Message: "failed",
+		Line:    14,
+		Fields:  slog.M(slog.F("a", "b"), slog.F("stack", "frame1\n  frame2")),
+	}}
+
+	b := &bytes.Buffer{}
+	b.WriteString("not an entry\n")
+	s := sloghuman.Make(b, opts)
+	for _, ent := range ents {
+		s.LogEntry(bg, ent)
+	}
+
+	got := decodeAll(t, b, opts)
+	for i := range got {
+		// The location is made relative to the module
+		// which depends on how the test is run.
+		got[i].File = ""
+	}
+	assert.Equal(t, "entries", ents, got)
+}
+
+func TestDecoder_Color(t *testing.T) {
+	t.Parallel()
+
+	in := "\x1b[0m\x1b[0m2020-01-02 03:04:05.000 \x1b[91m[CRITICAL]\x1b[0m\t\x1b[36m<.:0>\t\x1b[0m\t\"\"\t{\x1b[34m\"hey\"\x1b[0m: \x1b[32m\"hi\"\x1b[0m}\n"
+	ents := decodeAll(t, strings.NewReader(in), nil)
+	assert.Len(t, "entries", 1, ents)
+	assert.Equal(t, "level", slog.LevelCritical, ents[0].Level)
+	assert.Equal(t, "file", ".", ents[0].File)
+	assert.Equal(t, "fields", slog.M(slog.F("hey", "hi")), ents[0].Fields)
+}
+
+func TestDecoder_Split(t *testing.T) {
+	t.Parallel()
+
+	opts := &sloghuman.Options{
+		Multiline: sloghuman.MultilineSplit,
+	}
+	b := &bytes.Buffer{}
+	l := slog.Make(sloghuman.Make(b, opts))
+	l.Info(bg, "msg", slog.F("stack", "frame1\nframe2"))
+
+	ents := decodeAll(t, b, opts)
+	assert.Len(t, "entries", 3, ents)
+	assert.Equal(t, "message", "msg", ents[0].Message)
+	assert.Equal(t, "line 1", slog.M(slog.F("stack", "frame1")), ents[1].Fields)
+	assert.Equal(t, "line 2", slog.M(slog.F("stack", "frame2")), ents[2].Fields)
+}
@@ -0,0 +1,12 @@
+package sloghuman
+
+import (
+	"io"
+)
+
+// NewTerminalStatus is like NewStatus but treats w as a terminal.
+func NewTerminalStatus(w io.Writer) *Status {
+	s := NewStatus(w)
+	s.tty = true
+	return s
+}
@@ -3,52 +3,305 @@
 package sloghuman // import "cdr.dev/slog/sloggers/sloghuman"
 
 import (
-	"context"
 	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"golang.org/x/xerrors"
+
 	"cdr.dev/slog"
+	"cdr.dev/slog/internal/dedup"
 	"cdr.dev/slog/internal/entryhuman"
-	"cdr.dev/slog/internal/syncwriter"
 )
 
+// Multiline controls how messages and string or error fields
+// that contain newlines are written.
+type Multiline int
+
+const (
+	// MultilineIndent writes the multiline value after the entry with
+	// every continuation line indented. This is the default.
+	//
+	// Only the message or the first multiline field is written this way.
+	MultilineIndent Multiline = iota
+
+	// MultilineEscape escapes newlines so that every entry is
+	// on a single line.
+	MultilineEscape
+
+	// MultilineSplit writes every line of the multiline value as a
+	// separate entry with the same timestamp, level, names and location.
+	// Use it with log collectors that treat every line as an entry.
+	//
+	// Only the message or the first multiline field is written this way.
+	MultilineSplit
+)
+
+// TimeStyle controls how the timestamps of entries are written.
+type TimeStyle int
+
+const (
+	// TimeWall writes the wall clock time. This is the default.
+	TimeWall TimeStyle = iota
+
+	// TimeSinceStart writes the time since the process started,
+	// e.g. +12.345s, which is easier to read while iterating locally.
+	TimeSinceStart
+
+	// TimeSincePrevious writes the time since the previous entry
+	// written by the sink, e.g. +0.012s.
+	TimeSincePrevious
+)
+
+// TableOptions configures the tables of slices of structs and maps.
+// See Options.Table.
+type TableOptions struct {
+	// MaxRows is the number of rows after which the rest are
+	// summarized. Defaults to 20.
+	MaxRows int
+	// MaxWidth is the width after which the values of a column
+	// are truncated. Defaults to 40.
+	MaxWidth int
+}
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Multiline controls how values with newlines are written.
+	Multiline Multiline
+	// ContinuationMarker is written at the start of every line of a
+	// multiline value written with MultilineIndent. e.g. "| "
+	ContinuationMarker string
+	// DedupMinSize enables replacing field values of at least this
+	// many bytes that have already been written with a reference
+	// to their first occurrence. Disabled if zero.
+	//
+	// The first occurrence gets an extra field with the original name
+	// and a _sha256 suffix. e.g. {"stack_sha256": "1b4f0e9851971998"}
+	// Later occurrences become {"sha256_ref": "1b4f0e9851971998"}.
+	DedupMinSize int
+	// SDPriority prefixes every line with the sd-daemon(3) priority of
+	// the level. e.g. "<6>" for INFO. See slog.Level.Severity.
+	// journald parses the prefix from the stdout and stderr of services
+	// so that entries get the correct priority without the native
+	// journal protocol.
+	SDPriority bool
+	// Raw disables escaping control characters, ANSI sequences and
+	// invalid UTF-8 in user provided strings. Only enable it if
+	// every message, name and field value is trusted as otherwise
+	// they can forge entries or control the terminal.
+	Raw bool
+	// Translate is called with the message and fields of every entry
+	// and returns the message to write, e.g. a translation from a
+	// message catalog keyed by the message. Only the human readable
+	// output is translated so other sinks keep the original message.
+	// See Catalog.
+	Translate func(msg string, fields slog.Map) string
+	// OmitTime and OmitLocation omit the timestamp and the location
+	// from the entries, e.g. for the output of command line tools.
+	OmitTime     bool
+	OmitLocation bool
+	// Time controls how timestamps are written.
+	Time TimeStyle
+	// Path and Func are the styles of the file and the function of the
+	// location. Default to slog.PathModule and slog.FuncShort.
+	Path slog.PathStyle
+	Func slog.FuncStyle
+	// Table writes fields that are slices or arrays of structs or maps
+	// as aligned tables with a column per field instead of JSON. e.g.
+	//
+	//	"peers": name   addr      up
+	//	         alice  10.0.0.1  true
+	//	         bob    10.0.0.2  false
+	//
+	// Tables are written like multiline values so only the first
+	// multiline field is written this way. Disabled if nil.
+	Table *TableOptions
+	// Width is the width in columns after which lines are truncated
+	// with an ellipsis and the number of columns cut, e.g. "…(+42)", so
+	// that long entries do not scroll horizontally. The full entries are
+	// only written by other sinks.
+	//
+	// If zero, it is the width of the terminal written to, overridden
+	// by the COLUMNS environment variable, and lines are not truncated
+	// unless the writer is a terminal. Negative disables truncation.
+	Width int
+	// Wrap wraps lines wider than Width onto indented lines
+	// instead of truncating them.
+	Wrap bool
+}
+
+func (opts *Options) entryhuman() *entryhuman.Options {
+	eopts := &entryhuman.Options{
+		Multiline:          entryhuman.Multiline(opts.Multiline),
+		ContinuationMarker: opts.ContinuationMarker,
+		Raw:                opts.Raw,
+		OmitTime:           opts.OmitTime,
+		OmitLocation:       opts.OmitLocation,
+		Path:               opts.Path,
+		Func:               opts.Func,
+	}
+	if opts.Table != nil {
+		eopts.Table = &entryhuman.TableOptions{
+			MaxRows:  opts.Table.MaxRows,
+			MaxWidth: opts.Table.MaxWidth,
+		}
+	}
+	return eopts
+}
+
 // Sink creates a slog.Sink that writes logs in a human
 // readable YAML like format to the given writer.
 //
 // If the writer implements Sync() error then
 // it will be called when syncing.
 func Sink(w io.Writer) slog.Sink {
-	return &humanSink{
-		w:  syncwriter.New(w),
-		w2: w,
+	return Make(w, nil)
+}
+
+// Make is like Sink but configures the format with opts.
+//
+// If opts is nil, the defaults are used.
+func Make(w io.Writer, opts *Options) slog.Sink {
+	return slog.WriterSink(w, newEncoder(w, opts))
+}
+
+// Encoder returns an encoder for the format of the package.
+// It can be used with any sink that accepts a slog.Encoder.
+//
+// The output is only colored if the FORCE_COLOR
+// environment variable is set.
+//
+// If opts is nil, the defaults are used.
+func Encoder(opts *Options) slog.Encoder {
+	return newEncoder(nil, opts)
+}
+
+// newEncoder returns an encoder that colors the output
+// if w is a terminal.
+func newEncoder(w io.Writer, opts *Options) humanEncoder {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if w == nil {
+		w = ioutil.Discard
+	}
+
+	e := humanEncoder{
+		w:    w,
+		opts: opts,
+	}
+	if opts.Time != TimeWall {
+		e.relative = &entryhuman.Relative{Previous: opts.Time == TimeSincePrevious}
+	}
+	if opts.DedupMinSize > 0 {
+		e.dedup = dedup.New(opts.DedupMinSize)
 	}
+	return e
 }
 
-type humanSink struct {
-	w  *syncwriter.Writer
-	w2 io.Writer
+type humanEncoder struct {
+	w     io.Writer
+	opts  *Options
+	dedup *dedup.Cache
+	// relative is nil for wall clock timestamps.
+	relative *entryhuman.Relative
 }
 
-func (s humanSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
-	str := entryhuman.Fmt(s.w2, ent)
-	lines := strings.Split(str, "\n")
+func (e humanEncoder) Encode(buf []byte, ent slog.SinkEntry) []byte {
+	if e.opts.Translate != nil {
+		ent.Message = e.opts.Translate(ent.Message, ent.Fields)
+	}
+	if e.dedup != nil {
+		e.dedup.Lock()
+		ent.Fields = e.dedup.Fields(ent.Fields)
+		e.dedup.Unlock()
+	}
+
+	eopts := e.opts.entryhuman()
+	if e.relative != nil {
+		eopts.Since = e.relative.Since(ent.Time)
+	}
+	str := entryhuman.FmtOpts(e.w, ent, eopts)
+
+	if e.opts.Multiline == MultilineIndent {
+		lines := strings.Split(str, "\n")
 
-	// We need to add 4 spaces before every field line for readability.
-	// humanfmt doesn't do it for us because the testSink doesn't want
-	// it as *testing.T automatically does it.
-	fieldsLines := lines[1:]
-	for i, line := range fieldsLines {
-		if line == "" {
-			continue
+		// We need to add 4 spaces before every field line for readability.
+		// humanfmt doesn't do it for us because the testSink doesn't want
+		// it as *testing.T automatically does it.
+		fieldsLines := lines[1:]
+		for i, line := range fieldsLines {
+			if line == "" {
+				continue
+			}
+			fieldsLines[i] = strings.Repeat(" ", 2) + line
 		}
-		fieldsLines[i] = strings.Repeat(" ", 2) + line
+
+		str = strings.Join(lines, "\n")
 	}
 
-	str = strings.Join(lines, "\n")
+	width := e.opts.Width
+	if width == 0 {
+		width = entryhuman.TerminalWidth(e.w)
+	}
+	str = entryhuman.FitWidth(str, width, e.opts.Wrap)
+
+	if e.opts.SDPriority {
+		prefix := sdPriority(ent.Level)
+		str = prefix + strings.Replace(str, "\n", "\n"+prefix, -1)
+	}
+
+	return append(buf, str...)
+}
+
+// sdPriority returns the sd-daemon(3) prefix for level.
+func sdPriority(level slog.Level) string {
+	return "<" + strconv.Itoa(level.Severity().Syslog) + ">"
+}
 
-	s.w.Write("sloghuman", []byte(str+"\n"))
+func init() {
+	slog.RegisterFormat("human", openFormat, "multiline", "dedup", "sdpriority", "time")
 }
 
-func (s humanSink) Sync() {
-	s.w.Sync("sloghuman")
+// openFormat opens the format for slog.Open.
+// The query parameters multiline (indent, escape or split),
+// dedup, sdpriority and time (wall, start or previous) set the options.
+func openFormat(u *url.URL, w io.Writer) (slog.Encoder, error) {
+	q := u.Query()
+	opts := &Options{}
+	switch v := q.Get("multiline"); v {
+	case "", "indent":
+	case "escape":
+		opts.Multiline = MultilineEscape
+	case "split":
+		opts.Multiline = MultilineSplit
+	default:
+		return nil, xerrors.Errorf("invalid multiline %q", v)
+	}
+	if v := q.Get("dedup"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid dedup %q: %w", v, err)
+		}
+		opts.DedupMinSize = n
+	}
+	if v := q.Get("sdpriority"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid sdpriority %q: %w", v, err)
+		}
+		opts.SDPriority = b
+	}
+	switch v := q.Get("time"); v {
+	case "", "wall":
+	case "start":
+		opts.Time = TimeSinceStart
+	case "previous":
+		opts.Time = TimeSincePrevious
+	default:
+		return nil, xerrors.Errorf("invalid time %q", v)
+	}
+	return newEncoder(w, opts), nil
 }
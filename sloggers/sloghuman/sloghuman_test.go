@@ -3,7 +3,9 @@ package sloghuman_test
 import (
 	"bytes"
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/internal/assert"
@@ -24,5 +26,102 @@ func TestMake(t *testing.T) {
 	et, rest, err := entryhuman.StripTimestamp(b.String())
 	assert.Success(t, "strip timestamp", err)
 	assert.False(t, "timestamp", et.IsZero())
-	assert.Equal(t, "entry", " [INFO]\t<cdr.dev/slog/sloggers/sloghuman_test/sloghuman_test.go:21>\tTestMake\t...\t{\"wowow\": \"me\\nyou\"}\n  \"msg\": line1\n\n         line2\n", rest)
+	assert.Equal(t, "entry", " [INFO]\t<./sloggers/sloghuman/sloghuman_test.go:23>\tTestMake\t...\t{\"wowow\": \"me\\nyou\"}\n  \"msg\": line1\n\n         line2\n", rest)
+}
+
+func TestMultilineSplit(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(sloghuman.Make(b, &sloghuman.Options{
+		Multiline: sloghuman.MultilineSplit,
+	}))
+	l.Info(bg, "line1\nline2")
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Len(t, "lines", 2, lines)
+	for _, line := range lines {
+		// Split entries must not be indented like continuation lines.
+		_, _, err := entryhuman.StripTimestamp(line)
+		assert.Success(t, "strip timestamp", err)
+	}
+}
+
+func TestSDPriority(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(sloghuman.Make(b, &sloghuman.Options{
+		SDPriority: true,
+	}))
+	l.Warn(bg, "line1\nline2")
+	l.Error(bg, "failed")
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Len(t, "lines", 4, lines)
+	// Continuation lines of multiline messages are prefixed too.
+	for i, prefix := range []string{"<4>", "<4>", "<4>", "<3>"} {
+		assert.True(t, "prefix", strings.HasPrefix(lines[i], prefix))
+	}
+	assert.True(t, "timestamp", strings.HasPrefix(lines[0], "<4>20"))
+}
+
+func TestTable(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(sloghuman.Make(b, &sloghuman.Options{
+		Table: &sloghuman.TableOptions{MaxRows: 1},
+	}))
+	l.Info(bg, "peers", slog.F("peers", []slog.Map{
+		slog.M(slog.F("name", "alice"), slog.F("up", true)),
+		slog.M(slog.F("name", "bob")),
+	}))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	assert.Equal(t, "lines", []string{
+		`  "peers": name   up`,
+		"           alice  true",
+		"           ... 1 more rows",
+	}, lines[1:])
+}
+
+func TestWidth(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(sloghuman.Make(b, &sloghuman.Options{
+		Width: 40,
+	}))
+	l.Info(bg, strings.Repeat("x", 100))
+
+	line := strings.TrimSuffix(b.String(), "\n")
+	assert.True(t, "truncated", strings.HasSuffix(line, ")") && strings.Contains(line, "…(+"))
+	assert.True(t, "width", len([]rune(line)) <= 40)
+}
+
+func TestTime(t *testing.T) {
+	t.Parallel()
+
+	e := sloghuman.Encoder(&sloghuman.Options{
+		Time:         sloghuman.TimeSincePrevious,
+		OmitLocation: true,
+	})
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var lines []string
+	for _, d := range []time.Duration{0, 12345 * time.Millisecond, 12500 * time.Millisecond} {
+		lines = append(lines, string(e.Encode(nil, slog.SinkEntry{
+			Time:    start.Add(d),
+			Message: "step",
+		})))
+	}
+	assert.Equal(t, "lines", []string{
+		"+0.000s [DEBUG]\tstep",
+		"+12.345s [DEBUG]\tstep",
+		"+0.155s [DEBUG]\tstep",
+	}, lines)
+
+	e = sloghuman.Encoder(&sloghuman.Options{Time: sloghuman.TimeSinceStart})
+	line := string(e.Encode(nil, slog.SinkEntry{Time: time.Now()}))
+	assert.True(t, "since start", strings.HasPrefix(line, "+"))
 }
@@ -0,0 +1,113 @@
+package sloghuman
+
+import (
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// clearLine returns the cursor to the start of the line and clears it.
+const clearLine = "\r\x1b[2K"
+
+// Status is a transient status line at the bottom of a terminal, e.g.
+// a spinner or a progress bar, that entries written through it are
+// printed above so that they do not garble each other.
+//
+// Pass the status line to Sink or Make in place of the terminal:
+//
+//	status := sloghuman.NewStatus(os.Stderr)
+//	log := slog.Make(sloghuman.Sink(status))
+//	status.Set("downloading 3/10")
+//	log.Info(ctx, "downloaded", slog.F("file", name))
+//	status.Clear()
+//
+// Status is safe for concurrent use.
+type Status struct {
+	mu   sync.Mutex
+	w    io.Writer
+	tty  bool
+	line string
+}
+
+var _ io.Writer = &Status{}
+
+// NewStatus returns a status line at the bottom of w. If w is not
+// a terminal, the status line is not written and entries are written
+// to w as is.
+func NewStatus(w io.Writer) *Status {
+	return &Status{
+		w:   w,
+		tty: fdOf(w) >= 0 && terminal.IsTerminal(fdOf(w)),
+	}
+}
+
+func fdOf(w io.Writer) int {
+	f, ok := w.(interface {
+		Fd() uintptr
+	})
+	if !ok {
+		return -1
+	}
+	return int(f.Fd())
+}
+
+// Set replaces the status line with line. Only the first line
+// of line is written.
+func (s *Status) Set(line string) {
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.tty {
+		return
+	}
+	s.line = line
+	io.WriteString(s.w, clearLine+line)
+}
+
+// Clear removes the status line.
+func (s *Status) Clear() {
+	s.Set("")
+}
+
+// Write writes p above the status line and redraws it.
+// p should end with a newline, as the entries of Sink do.
+func (s *Status) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.tty || s.line == "" {
+		return s.w.Write(p)
+	}
+
+	_, err := io.WriteString(s.w, clearLine)
+	if err != nil {
+		return 0, err
+	}
+	n, err := s.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	_, err = io.WriteString(s.w, s.line)
+	return n, err
+}
+
+// Fd returns the file descriptor of the terminal so that the
+// entries are colored like those written to it directly.
+// It returns ^uintptr(0) if the writer has none.
+func (s *Status) Fd() uintptr {
+	return uintptr(fdOf(s.w))
+}
+
+// Sync syncs the writer if it implements Sync() error.
+func (s *Status) Sync() error {
+	if sw, ok := s.w.(interface {
+		Sync() error
+	}); ok {
+		return sw.Sync()
+	}
+	return nil
+}
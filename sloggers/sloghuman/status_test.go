@@ -0,0 +1,50 @@
+package sloghuman_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	s := sloghuman.NewTerminalStatus(b)
+	write := func(p string) {
+		_, err := s.Write([]byte(p))
+		assert.Success(t, "write", err)
+	}
+	write("a\n")
+	s.Set("1/2\nignored")
+	write("b\n")
+	s.Clear()
+	write("c\n")
+	assert.Equal(t, "output", "a\n"+
+		"\r\x1b[2K1/2"+
+		"\r\x1b[2Kb\n1/2"+
+		"\r\x1b[2K"+
+		"c\n", b.String())
+
+	b.Reset()
+	s.Set("2/2")
+	slog.Make(sloghuman.Sink(s)).Info(bg, "entry")
+	out := b.String()
+	assert.True(t, "entry above", strings.Contains(out, "entry") && strings.HasSuffix(out, "\n2/2"))
+}
+
+func TestStatus_NotTerminal(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	s := sloghuman.NewStatus(b)
+	s.Set("progress")
+	_, err := s.Write([]byte("entry\n"))
+	assert.Success(t, "write", err)
+	s.Clear()
+	assert.Equal(t, "output", "entry\n", b.String())
+}
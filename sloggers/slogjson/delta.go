@@ -0,0 +1,176 @@
+package slogjson
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// deltaState remembers the contexts written to an output stream.
+// See Options.DeltaSegment.
+type deltaState struct {
+	mu sync.Mutex
+
+	segment int
+	n       int
+	// last contains the encoded fields of the previous entry
+	// of every logger.
+	last    map[string][][]byte
+	defined map[string]struct{}
+}
+
+func newDeltaState(segment int) *deltaState {
+	return &deltaState{
+		segment: segment,
+		last:    make(map[string][][]byte),
+		defined: make(map[string]struct{}),
+	}
+}
+
+// split returns the fields of ent after its context and the JSON to
+// write as the ctx key, either the ID of the context or its definition
+// if it has not been written in the segment yet. ctx is nil if ent shares
+// no leading fields with the previous entry of its logger.
+//
+// Callers must hold mu. References are only resolved if the entries
+// are written in the order they were split, see slog.Encoder.
+func (d *deltaState) split(ent slog.SinkEntry) (rest slog.Map, ctx []byte) {
+	if d.n >= d.segment {
+		d.n = 0
+		d.last = make(map[string][][]byte)
+		d.defined = make(map[string]struct{})
+	}
+	d.n++
+
+	encoded := make([][]byte, len(ent.Fields))
+	for i, f := range ent.Fields {
+		// No error is guaranteed due to slog.Map handling errors itself.
+		encoded[i], _ = json.Marshal(slog.M(f))
+	}
+
+	logger := strings.Join(ent.LoggerNames, ".")
+	prev := d.last[logger]
+	d.last[logger] = encoded
+
+	k := 0
+	for k < len(encoded) && k < len(prev) && bytes.Equal(encoded[k], prev[k]) {
+		k++
+	}
+	if k == 0 {
+		return ent.Fields, nil
+	}
+
+	h := sha256.New()
+	for _, p := range encoded[:k] {
+		h.Write(p)
+	}
+	id := hex.EncodeToString(h.Sum(nil)[:8])
+	rest = ent.Fields[k:]
+
+	if _, ok := d.defined[id]; ok {
+		ctx, _ = json.Marshal(id)
+		return rest, ctx
+	}
+	d.defined[id] = struct{}{}
+	ctx, _ = json.Marshal(slog.M(
+		slog.F("id", id),
+		slog.F("fields", ent.Fields[:k]),
+	))
+	return rest, ctx
+}
+
+// Decoder reads the entries written by the sink and encoder of the
+// package and restores the fields of contexts referenced by ID.
+// See Options.DeltaSegment.
+type Decoder struct {
+	d        *json.Decoder
+	contexts map[string]slog.Map
+}
+
+// NewDecoder returns a Decoder that reads the entries from r.
+// r must contain the output stream from its start so that the
+// contexts are defined before they are referenced.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		d:        json.NewDecoder(bufio.NewReader(r)),
+		contexts: make(map[string]slog.Map),
+	}
+}
+
+// deltaContext is the definition of a context.
+type deltaContext struct {
+	ID     string   `json:"id"`
+	Fields slog.Map `json:"fields"`
+}
+
+// Decode reads the next entry. It returns io.EOF at the end of the input.
+func (d *Decoder) Decode() (slog.SinkEntry, error) {
+	var raw map[string]json.RawMessage
+	err := d.d.Decode(&raw)
+	if err == io.EOF {
+		return slog.SinkEntry{}, err
+	}
+	if err != nil {
+		return slog.SinkEntry{}, xerrors.Errorf("failed to decode entry: %w", err)
+	}
+	return d.decode(raw)
+}
+
+// DecodeLine decodes the entry of a single line, e.g. of a file
+// that may contain invalid lines that Decode cannot skip. r may be
+// nil if only DecodeLine is used.
+func (d *Decoder) DecodeLine(line []byte) (slog.SinkEntry, error) {
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal(line, &raw)
+	if err != nil {
+		return slog.SinkEntry{}, xerrors.Errorf("failed to decode entry: %w", err)
+	}
+	return d.decode(raw)
+}
+
+// decode decodes the fields of an entry and restores its context.
+func (d *Decoder) decode(raw map[string]json.RawMessage) (slog.SinkEntry, error) {
+	var ctx slog.Map
+	if p, ok := raw["ctx"]; ok {
+		delete(raw, "ctx")
+
+		var id string
+		if json.Unmarshal(p, &id) == nil {
+			ctx, ok = d.contexts[id]
+			if !ok {
+				return slog.SinkEntry{}, xerrors.Errorf("unknown context %q", id)
+			}
+		} else {
+			var def deltaContext
+			err := json.Unmarshal(p, &def)
+			if err != nil {
+				return slog.SinkEntry{}, xerrors.Errorf("failed to decode context: %w", err)
+			}
+			d.contexts[def.ID] = def.Fields
+			ctx = def.Fields
+		}
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return slog.SinkEntry{}, xerrors.Errorf("failed to encode entry: %w", err)
+	}
+	var ent slog.SinkEntry
+	err = ent.UnmarshalJSON(b)
+	if err != nil {
+		return slog.SinkEntry{}, err
+	}
+	if len(ctx) > 0 {
+		ent.Fields = append(ctx[:len(ctx):len(ctx)], ent.Fields...)
+	}
+	return ent, nil
+}
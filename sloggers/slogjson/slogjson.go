@@ -18,64 +18,173 @@
 package slogjson // import "cdr.dev/slog/sloggers/slogjson"
 
 import (
-	"context"
 	"encoding/json"
-	"fmt"
 	"io"
+	"net/url"
+	"strconv"
 
-	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
-	"cdr.dev/slog/internal/syncwriter"
+	"cdr.dev/slog/internal/dedup"
 )
 
 // Sink creates a slog.Sink that writes JSON logs
 // to the given writer. See package level docs
 // for the format.
+//
+// Entries can be parsed back with slog.SinkEntry.UnmarshalJSON.
 // If the writer implements Sync() error then
 // it will be called when syncing.
 func Sink(w io.Writer) slog.Sink {
-	return jsonSink{
-		w: syncwriter.New(w),
-	}
+	return Make(w, nil)
 }
 
-type jsonSink struct {
-	w *syncwriter.Writer
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// DedupMinSize enables replacing field values of at least this
+	// many bytes that have already been written with a reference
+	// to their first occurrence. Disabled if zero.
+	//
+	// The first occurrence gets an extra field with the original name
+	// and a _sha256 suffix. e.g. {"stack_sha256": "1b4f0e9851971998"}
+	// Later occurrences become {"sha256_ref": "1b4f0e9851971998"}.
+	DedupMinSize int
+
+	// DeltaSegment enables writing the leading fields that an entry
+	// shares with the previous entry of its logger, usually the fields
+	// of slog.Logger.With, once as a context and referencing it by ID in
+	// later entries. Disabled if zero.
+	//
+	// The first entry of a context gets an extra key with its definition,
+	// e.g. "ctx": {"id": "1b4f0e9851971998", "fields": {"svc": "api"}},
+	// and the fields of later entries of the context are replaced with
+	// "ctx": "1b4f0e9851971998". The contexts are written in full again
+	// after every DeltaSegment entries so that readers can start at any
+	// segment. Use Decoder to read the entries with their fields.
+	DeltaSegment int
+
+	// Path is the style of the file in the caller and Func of the
+	// function in func. Default to slog.PathFull and slog.FuncFull.
+	Path slog.PathStyle
+	Func slog.FuncStyle
+}
+
+// Make is like Sink but configures the format with opts.
+//
+// If opts is nil, the defaults are used.
+func Make(w io.Writer, opts *Options) slog.Sink {
+	return slog.WriterSink(w, Encoder(opts))
 }
 
-func (s jsonSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
-	m := slog.M(
-		slog.F("ts", ent.Time),
-		slog.F("level", ent.Level),
-		slog.F("msg", ent.Message),
-		slog.F("caller", fmt.Sprintf("%v:%v", ent.File, ent.Line)),
-		slog.F("func", ent.Func),
-	)
-
-	if len(ent.LoggerNames) > 0 {
-		m = append(m, slog.F("logger_names", ent.LoggerNames))
+// Encoder returns an encoder for the format of the package.
+// It can be used with any sink that accepts a slog.Encoder.
+//
+// If opts is nil, the defaults are used.
+func Encoder(opts *Options) slog.Encoder {
+	if opts == nil {
+		opts = &Options{}
 	}
 
-	if ent.SpanContext != (trace.SpanContext{}) {
-		m = append(m,
-			slog.F("trace", ent.SpanContext.TraceID),
-			slog.F("span", ent.SpanContext.SpanID),
-		)
+	e := jsonEncoder{
+		path: opts.Path,
+		fn:   opts.Func,
+	}
+	if opts.DedupMinSize > 0 {
+		e.dedup = dedup.New(opts.DedupMinSize)
+	}
+	if opts.DeltaSegment > 0 {
+		e.delta = newDeltaState(opts.DeltaSegment)
 	}
+	return e
+}
+
+type jsonEncoder struct {
+	dedup *dedup.Cache
+	delta *deltaState
+	path  slog.PathStyle
+	fn    slog.FuncStyle
+}
 
-	if len(ent.Fields) > 0 {
-		m = append(m,
-			slog.F("fields", ent.Fields),
-		)
+func (e jsonEncoder) Encode(buf []byte, ent slog.SinkEntry) []byte {
+	ent.File = slog.TrimPath(ent.File, ent.Func, e.path)
+	ent.Func = slog.TrimFunc(ent.Func, e.fn)
+
+	var ctx []byte
+	if e.delta != nil {
+		// The contexts are split off before the values are de-duplicated
+		// as references would differ from the previous entry.
+		e.delta.mu.Lock()
+		defer e.delta.mu.Unlock()
+		ent.Fields, ctx = e.delta.split(ent)
+	}
+	if e.dedup != nil {
+		e.dedup.Lock()
+		ent.Fields = e.dedup.Fields(ent.Fields)
+		e.dedup.Unlock()
+	}
+
+	// No error is guaranteed due to slog.Map handling errors itself.
+	b, _ := json.Marshal(ent)
+	if ctx != nil {
+		b = append(b[:len(b)-1], `,"ctx":`...)
+		b = append(b, ctx...)
+		b = append(b, '}')
 	}
+	return append(buf, b...)
+}
+
+func init() {
+	slog.RegisterFormat("json", openFormat, "dedup", "delta", "path", "func")
+}
 
-	buf, _ := json.Marshal(m)
+// openFormat opens the format for slog.Open.
+// The dedup, delta, path and func query parameters set Options.DedupMinSize,
+// Options.DeltaSegment, Options.Path, which is one of full, module, package
+// or base, and Options.Func, which is one of full, package, short or omit.
+func openFormat(u *url.URL, w io.Writer) (slog.Encoder, error) {
+	opts := &Options{}
+	if v := u.Query().Get("dedup"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid dedup %q: %w", v, err)
+		}
+		opts.DedupMinSize = n
+	}
+	if v := u.Query().Get("delta"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid delta %q: %w", v, err)
+		}
+		opts.DeltaSegment = n
+	}
+	if v := u.Query().Get("path"); v != "" {
+		style, ok := pathStyles[v]
+		if !ok {
+			return nil, xerrors.Errorf("invalid path %q", v)
+		}
+		opts.Path = style
+	}
+	if v := u.Query().Get("func"); v != "" {
+		style, ok := funcStyles[v]
+		if !ok {
+			return nil, xerrors.Errorf("invalid func %q", v)
+		}
+		opts.Func = style
+	}
+	return Encoder(opts), nil
+}
 
-	buf = append(buf, '\n')
-	s.w.Write("slogjson", buf)
+var pathStyles = map[string]slog.PathStyle{
+	"full":    slog.PathFull,
+	"module":  slog.PathModule,
+	"package": slog.PathPackage,
+	"base":    slog.PathBase,
 }
 
-func (s jsonSink) Sync() {
-	s.w.Sync("slogjson")
+var funcStyles = map[string]slog.FuncStyle{
+	"full":    slog.FuncFull,
+	"package": slog.FuncPackage,
+	"short":   slog.FuncShort,
+	"omit":    slog.FuncOmit,
 }
@@ -3,7 +3,9 @@ package slogjson_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"runtime"
 	"testing"
 
@@ -29,7 +31,112 @@ func TestMake(t *testing.T) {
 	l.Error(ctx, "line1\n\nline2", slog.F("wowow", "me\nyou"))
 
 	j := entryjson.Filter(b.String(), "ts")
-	exp := fmt.Sprintf(`{"level":"ERROR","msg":"line1\n\nline2","caller":"%v:29","func":"cdr.dev/slog/sloggers/slogjson_test.TestMake","logger_names":["named"],"trace":"%v","span":"%v","fields":{"wowow":"me\nyou"}}
+	exp := fmt.Sprintf(`{"level":"ERROR","msg":"line1\n\nline2","caller":"%v:31","func":"cdr.dev/slog/sloggers/slogjson_test.TestMake","logger_names":["named"],"trace":"%v","span":"%v","fields":{"wowow":"me\nyou"}}
 `, slogjsonTestFile, s.SpanContext().TraceID, s.SpanContext().SpanID)
 	assert.Equal(t, "entry", exp, j)
 }
+
+func TestDedup(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(slogjson.Make(b, &slogjson.Options{
+		DedupMinSize: 4,
+	}))
+	l.Info(bg, "first", slog.F("stack", "big stack"))
+	l.Info(bg, "second", slog.F("stack", "big stack"))
+
+	d := json.NewDecoder(b)
+	var ents [2]slog.SinkEntry
+	for i := range ents {
+		err := d.Decode(&ents[i])
+		assert.Success(t, "decode", err)
+	}
+
+	id := ents[0].Fields[1].Value
+	assert.Equal(t, "first", slog.M(
+		slog.F("stack", "big stack"),
+		slog.F("stack_sha256", id),
+	), ents[0].Fields)
+	assert.Equal(t, "second", slog.M(
+		slog.F("stack", slog.M(slog.F("sha256_ref", id))),
+	), ents[1].Fields)
+}
+
+func TestEncoder(t *testing.T) {
+	t.Parallel()
+
+	enc := slogjson.Encoder(nil)
+	b := enc.Encode([]byte("prefix "), slog.SinkEntry{Message: "hello"})
+
+	var ent slog.SinkEntry
+	err := json.Unmarshal(bytes.TrimPrefix(b, []byte("prefix ")), &ent)
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "msg", "hello", ent.Message)
+}
+
+func TestDelta(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(slogjson.Make(b, &slogjson.Options{
+		DeltaSegment: 3,
+	})).With(slog.F("svc", "api"), slog.F("region", "us-east-1"))
+	for i := 0; i < 4; i++ {
+		l.Info(bg, "req", slog.F("i", i))
+	}
+	l.Named("db").Info(bg, "query")
+
+	lines := bytes.Split(bytes.TrimSpace(b.Bytes()), []byte("\n"))
+	assert.Equal(t, "lines", 5, len(lines))
+	assert.True(t, "first in full", bytes.Contains(lines[0], []byte(`"fields":{"svc":"api","region":"us-east-1","i":0}`)))
+	assert.True(t, "context defined", bytes.Contains(lines[1], []byte(`"fields":{"i":1},"ctx":{"id":`)))
+	assert.True(t, "context referenced", !bytes.Contains(lines[2], []byte(`"svc"`)) && bytes.Contains(lines[2], []byte(`"ctx":"`)))
+	assert.True(t, "new segment", bytes.Contains(lines[3], []byte(`"svc":"api"`)) && !bytes.Contains(lines[3], []byte(`"ctx"`)))
+	assert.True(t, "other logger", !bytes.Contains(lines[4], []byte(`"ctx"`)))
+
+	d := slogjson.NewDecoder(b)
+	for i := 0; i < 4; i++ {
+		ent, err := d.Decode()
+		assert.Success(t, "decode", err)
+		assert.Equal(t, "fields", slog.M(
+			slog.F("svc", "api"),
+			slog.F("region", "us-east-1"),
+			slog.F("i", json.Number(fmt.Sprint(i))),
+		), ent.Fields)
+	}
+	ent, err := d.Decode()
+	assert.Success(t, "decode", err)
+	assert.Equal(t, "logger", []string{"db"}, ent.LoggerNames)
+	_, err = d.Decode()
+	assert.Equal(t, "eof", io.EOF, err)
+
+	_, err = slogjson.NewDecoder(bytes.NewReader(lines[2])).Decode()
+	assert.Error(t, "unknown context", err)
+
+	d = slogjson.NewDecoder(nil)
+	for _, line := range lines[1:3] {
+		ent, err = d.DecodeLine(line)
+		assert.Success(t, "decode line", err)
+		assert.Equal(t, "svc", "api", ent.Fields[0].Value)
+	}
+	_, err = d.DecodeLine([]byte(`{"ts":`))
+	assert.Error(t, "invalid line", err)
+}
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	b := &bytes.Buffer{}
+	l := slog.Make(slogjson.Make(b, &slogjson.Options{
+		Path: slog.PathModule,
+		Func: slog.FuncShort,
+	}))
+	l.Info(bg, "hi")
+
+	var ent slog.SinkEntry
+	err := json.Unmarshal(b.Bytes(), &ent)
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "file", "./sloggers/slogjson/slogjson_test.go", ent.File)
+	assert.Equal(t, "func", "TestPath", ent.Func)
+}
@@ -0,0 +1,12 @@
+package slognet
+
+import (
+	"context"
+	"net"
+)
+
+func SetDial(s *TCPSink, dial func(ctx context.Context, network, addr string) (net.Conn, error)) {
+	for _, c := range s.conns {
+		c.dial = dial
+	}
+}
@@ -0,0 +1,136 @@
+package slognet
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+func init() {
+	slog.RegisterSink("tcp", openTCP(false), "framing", "sanitize")
+	slog.RegisterSink("tls", openTCP(true), "framing", "sanitize")
+	for _, network := range []string{"unix", "unixgram", "unixpacket"} {
+		slog.RegisterSink(network, openUnix(network), "framing", "sanitize")
+	}
+	slog.RegisterSink("syslog", openSyslog, "transport", "facility", "app", "sanitize")
+}
+
+// openOptions parses the framing and sanitize (escape or strip)
+// query parameters.
+func openOptions(u *url.URL, newEncoder func(w io.Writer) (slog.Encoder, error)) (*Options, error) {
+	opts := &Options{}
+	switch v := u.Query().Get("framing"); v {
+	case "", "newline":
+	case "length":
+		opts.Framing = FrameLength
+	default:
+		return nil, xerrors.Errorf("unknown framing %q", v)
+	}
+
+	switch v := u.Query().Get("sanitize"); v {
+	case "":
+	case "escape":
+		opts.Sanitize = &slog.SanitizeOptions{}
+	case "strip":
+		opts.Sanitize = &slog.SanitizeOptions{Strip: true}
+	default:
+		return nil, xerrors.Errorf("unknown sanitize %q", v)
+	}
+
+	enc, err := newEncoder(nil)
+	if err != nil {
+		return nil, err
+	}
+	opts.Encoder = enc
+	return opts, nil
+}
+
+// openTCP opens the TCP sink for slog.Open. e.g.
+//
+//	json+tcp://logs.internal:5170?framing=length
+//	json+tls://logs.internal:5171
+func openTCP(useTLS bool) slog.SinkOpener {
+	return func(u *url.URL, newEncoder func(w io.Writer) (slog.Encoder, error)) (slog.Sink, error) {
+		if u.Host == "" {
+			return nil, xerrors.New("no address")
+		}
+		opts, err := openOptions(u, newEncoder)
+		if err != nil {
+			return nil, err
+		}
+
+		tcpOpts := &TCPOptions{
+			Options: *opts,
+		}
+		if useTLS {
+			tcpOpts.TLS = &tls.Config{}
+		}
+		return TCP(u.Host, tcpOpts), nil
+	}
+}
+
+// openUnix opens the Unix sink for slog.Open. e.g.
+//
+//	json+unixgram:///run/app/log.sock
+func openUnix(network string) slog.SinkOpener {
+	return func(u *url.URL, newEncoder func(w io.Writer) (slog.Encoder, error)) (slog.Sink, error) {
+		if u.Path == "" {
+			return nil, xerrors.New("no path")
+		}
+		opts, err := openOptions(u, newEncoder)
+		if err != nil {
+			return nil, err
+		}
+		return Unix(network, u.Path, opts), nil
+	}
+}
+
+// openSyslog opens the syslog sink for slog.Open. e.g.
+//
+//	syslog://logs.internal:514
+//	json+syslog://logs.internal?transport=tcp&facility=16&app=api
+//
+// The port defaults to 514. The transport (udp or tcp), facility and app
+// query parameters set SyslogOptions.Network, Facility and AppName.
+func openSyslog(u *url.URL, newEncoder func(w io.Writer) (slog.Encoder, error)) (slog.Sink, error) {
+	if u.Host == "" {
+		return nil, xerrors.New("no address")
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "514")
+	}
+
+	q := u.Query()
+	opts := &SyslogOptions{
+		AppName: q.Get("app"),
+	}
+	switch v := q.Get("transport"); v {
+	case "", "udp":
+		opts.Network = "udp"
+	case "tcp":
+		opts.Network = "tcp"
+	default:
+		return nil, xerrors.Errorf("unknown transport %q", v)
+	}
+	if v := q.Get("facility"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 23 {
+			return nil, xerrors.Errorf("invalid facility %q", v)
+		}
+		opts.Facility = n
+	}
+
+	netOpts, err := openOptions(u, newEncoder)
+	if err != nil {
+		return nil, err
+	}
+	opts.Options = *netOpts
+	return Syslog(addr, opts), nil
+}
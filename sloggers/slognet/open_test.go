@@ -0,0 +1,45 @@
+package slognet_test
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	_ "cdr.dev/slog/sloggers/slogjson"
+)
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s, err := slog.Open("json+unix://" + addr + "?framing=length&sanitize=strip")
+	assert.Success(t, "open", err)
+	err = s.(slog.ErrorSink).LogEntryErr(bg, slog.SinkEntry{Message: "hel\nlo"})
+	assert.Success(t, "log entry", err)
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	var n uint32
+	err = binary.Read(c, binary.BigEndian, &n)
+	assert.Success(t, "read length", err)
+	p := make([]byte, n)
+	_, err = io.ReadFull(c, p)
+	assert.Success(t, "read", err)
+
+	var ent slog.SinkEntry
+	err = ent.UnmarshalJSON(p)
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "msg", "hello", ent.Message)
+
+	_, err = slog.Open("json+tcp://")
+	assert.Error(t, "no address", err)
+	_, err = slog.Open("json+unix://" + addr + "?sanitize=maybe")
+	assert.Error(t, "invalid sanitize", err)
+}
@@ -0,0 +1,297 @@
+// Package slognet contains sloggers that write JSON logs
+// to network sockets.
+//
+// Entries are encoded in the slogjson format by default and framed with
+// either a trailing newline or a length prefix. Connections are
+// dialed lazily and re-dialed with exponential backoff after a failure.
+// Entries logged while the sink is disconnected are dropped.
+//
+// Importing the package registers the tcp, tls, unix, unixgram,
+// unixpacket and syslog sinks with slog.Open.
+package slognet // import "cdr.dev/slog/sloggers/slognet"
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/exportctx"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+// Framing controls how entries are delimited.
+type Framing int
+
+const (
+	// FrameNewline terminates every entry with a newline.
+	// This is the default.
+	FrameNewline Framing = iota
+
+	// FrameLength prefixes every entry with its length
+	// as a 4 byte big endian integer.
+	FrameLength
+)
+
+// Options represents the options for the sinks in this package.
+type Options struct {
+	// Framing controls how entries are delimited.
+	Framing Framing
+	// Encoder encodes the entries. Defaults to the slogjson format.
+	// Use FrameLength with formats that span multiple lines.
+	Encoder slog.Encoder
+	// Sanitize enables escaping or stripping CR, LF and NUL characters
+	// in entries before they are encoded so that user provided strings
+	// cannot forge records at receivers that split on them.
+	// See slog.SanitizeEntry.
+	Sanitize *slog.SanitizeOptions
+	// DialTimeout is the maximum time to wait for a connection.
+	// Defaults to 1s.
+	DialTimeout time.Duration
+	// WriteTimeout is the maximum time to wait for an entry to be written.
+	// Defaults to 1s.
+	//
+	// Both timeouts are shortened to the deadline of the context of the
+	// entry unless it is already done.
+	WriteTimeout time.Duration
+	// MinBackoff is the time to wait before re-dialing after the
+	// first failure. It is doubled on every consecutive failure.
+	// Defaults to 100ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum time to wait before re-dialing.
+	// Defaults to 10s.
+	MaxBackoff time.Duration
+}
+
+func (opts *Options) withDefaults() *Options {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Encoder == nil {
+		o.Encoder = slogjson.Encoder(nil)
+	}
+	if o.DialTimeout == 0 {
+		o.DialTimeout = time.Second
+	}
+	if o.WriteTimeout == 0 {
+		o.WriteTimeout = time.Second
+	}
+	if o.MinBackoff == 0 {
+		o.MinBackoff = 100 * time.Millisecond
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = 10 * time.Second
+	}
+	return &o
+}
+
+// Unix creates a slog.Sink that writes entries to the Unix domain socket
+// at addr. The network must be "unix", "unixgram" or "unixpacket".
+//
+// With "unixgram" and "unixpacket" every entry is written as a single datagram.
+// Call Close to close the connection.
+//
+// If opts is nil, the defaults are used.
+func Unix(network, addr string, opts *Options) *UnixSink {
+	switch network {
+	case "unix", "unixgram", "unixpacket":
+	default:
+		panic(fmt.Sprintf("slognet: unknown unix network %q", network))
+	}
+	return &UnixSink{
+		c: newConnSink("slognet.Unix", network, addr, (&net.Dialer{}).DialContext, opts),
+	}
+}
+
+// UnixSink writes entries to a Unix domain socket.
+//
+// See Unix.
+type UnixSink struct {
+	c *connSink
+}
+
+var _ slog.ErrorSink = &UnixSink{}
+
+// LogEntry implements slog.Sink.
+//
+// Failures are printed to stderr except for entries
+// dropped while waiting to re-dial.
+func (s *UnixSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	s.c.LogEntry(ctx, ent)
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// It returns an error if the sink is closed.
+func (s *UnixSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	return s.c.LogEntryErr(ctx, ent)
+}
+
+// Sync implements slog.Sink.
+func (s *UnixSink) Sync() {}
+
+// SyncErr implements slog.ErrorSink.
+//
+// Entries are written before LogEntry returns
+// so there is nothing to sync.
+func (s *UnixSink) SyncErr() error {
+	return nil
+}
+
+// Close closes the connection.
+func (s *UnixSink) Close() error {
+	return s.c.close()
+}
+
+// errBackoff is returned when an entry is dropped because
+// the sink is waiting to re-dial.
+var errBackoff = xerrors.New("disconnected, waiting to re-dial")
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// connSink writes entries to a single connection.
+type connSink struct {
+	name    string
+	network string
+	addr    string
+	dial    dialFunc
+	opts    *Options
+
+	mu       sync.Mutex
+	c        net.Conn
+	failures int
+	retryAt  time.Time
+	closed   bool
+
+	errorf func(f string, v ...interface{})
+}
+
+func newConnSink(name, network, addr string, dial dialFunc, opts *Options) *connSink {
+	return &connSink{
+		name:    name,
+		network: network,
+		addr:    addr,
+		dial:    dial,
+		opts:    opts.withDefaults(),
+
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+}
+
+// LogEntry implements slog.Sink.
+//
+// Failures are printed to stderr except for entries
+// dropped while waiting to re-dial.
+func (s *connSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	err := s.LogEntryErr(ctx, ent)
+	if err != nil && !xerrors.Is(err, errBackoff) {
+		s.errorf("%v: %+v", s.name, err)
+	}
+}
+
+// LogEntryErr implements slog.ErrorSink.
+func (s *connSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return xerrors.Errorf("%v: sink is closed", s.name)
+	}
+
+	// The entry is encoded while holding the lock so that entries are
+	// written in the order they were encoded. See slog.Encoder.
+	p := s.opts.encode(ent)
+
+	// An existing connection may have been closed by the peer,
+	// so a failed write is retried once on a new connection.
+	for attempt := 0; ; attempt++ {
+		redialed := s.c == nil
+		err := s.connect(ctx)
+		if err != nil {
+			return err
+		}
+
+		err = s.c.SetWriteDeadline(exportctx.Deadline(ctx, s.opts.WriteTimeout))
+		if err == nil {
+			_, err = s.c.Write(p)
+		}
+		if err == nil {
+			s.failures = 0
+			return nil
+		}
+
+		s.c.Close()
+		s.c = nil
+		if redialed || attempt > 0 {
+			s.fail()
+			return xerrors.Errorf("failed to write entry to %v: %w", s.addr, err)
+		}
+	}
+}
+
+// connect dials a new connection if there is none.
+func (s *connSink) connect(ctx context.Context) error {
+	if s.c != nil {
+		return nil
+	}
+	if time.Now().Before(s.retryAt) {
+		return errBackoff
+	}
+
+	ctx, cancel := exportctx.WithTimeout(ctx, s.opts.DialTimeout)
+	defer cancel()
+	c, err := s.dial(ctx, s.network, s.addr)
+	if err != nil {
+		s.fail()
+		return xerrors.Errorf("failed to dial %v: %w", s.addr, err)
+	}
+	s.c = c
+	return nil
+}
+
+// fail schedules the next dial with exponential backoff.
+func (s *connSink) fail() {
+	backoff := s.opts.MinBackoff << uint(s.failures)
+	if backoff > s.opts.MaxBackoff || backoff <= 0 {
+		backoff = s.opts.MaxBackoff
+	} else {
+		s.failures++
+	}
+	s.retryAt = time.Now().Add(backoff)
+}
+
+// close closes the connection. Entries logged afterwards are not written.
+func (s *connSink) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	if s.c == nil {
+		return nil
+	}
+	err := s.c.Close()
+	s.c = nil
+	return err
+}
+
+func (opts *Options) encode(ent slog.SinkEntry) []byte {
+	if opts.Sanitize != nil {
+		ent = slog.SanitizeEntry(ent, opts.Sanitize)
+	}
+	switch opts.Framing {
+	case FrameLength:
+		p := opts.Encoder.Encode(make([]byte, 4, 512), ent)
+		binary.BigEndian.PutUint32(p, uint32(len(p)-4))
+		return p
+	default:
+		p := opts.Encoder.Encode(make([]byte, 0, 512), ent)
+		return append(p, '\n')
+	}
+}
@@ -0,0 +1,331 @@
+package slognet_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogjson"
+	"cdr.dev/slog/sloggers/slognet"
+)
+
+var bg = context.Background()
+
+func tempSocket(t *testing.T) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "slognet")
+	assert.Success(t, "temp dir", err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return filepath.Join(dir, "sock")
+}
+
+func TestUnix(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.Unix("unix", addr, nil)
+	ent := slog.SinkEntry{Message: "hello"}
+	err = s.LogEntryErr(bg, ent)
+	assert.Success(t, "log entry", err)
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	line, err := bufio.NewReader(c).ReadString('\n')
+	assert.Success(t, "read", err)
+
+	var got slog.SinkEntry
+	err = got.UnmarshalJSON([]byte(line))
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "msg", "hello", got.Message)
+
+	// Closing the sink closes the connection.
+	err = s.Close()
+	assert.Success(t, "close", err)
+	_, err = c.Read(make([]byte, 1))
+	assert.Equal(t, "read after close", io.EOF, err)
+	err = s.LogEntryErr(bg, ent)
+	assert.Error(t, "log after close", err)
+}
+
+func TestUnix_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+
+	s := slognet.Unix("unix", addr, &slognet.Options{
+		Framing:    slognet.FrameLength,
+		MinBackoff: time.Millisecond,
+	})
+	defer s.Close()
+	err = s.LogEntryErr(bg, slog.SinkEntry{Message: "1"})
+	assert.Success(t, "log entry", err)
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	c.Close()
+	ln.Close()
+
+	// The collector is down.
+	err = s.LogEntryErr(bg, slog.SinkEntry{Message: "2"})
+	assert.Error(t, "log entry", err)
+
+	ln, err = net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	// Wait out the backoff.
+	time.Sleep(10 * time.Millisecond)
+	err = s.LogEntryErr(bg, slog.SinkEntry{Message: "3"})
+	assert.Success(t, "log entry", err)
+
+	c, err = ln.Accept()
+	assert.Success(t, "accept", err)
+	defer c.Close()
+
+	var size [4]byte
+	_, err = io.ReadFull(c, size[:])
+	assert.Success(t, "read size", err)
+	b := make([]byte, binary.BigEndian.Uint32(size[:]))
+	_, err = io.ReadFull(c, b)
+	assert.Success(t, "read entry", err)
+
+	var got slog.SinkEntry
+	err = got.UnmarshalJSON(b)
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "msg", "3", got.Message)
+}
+
+func TestUnix_Datagram(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	pc, err := net.ListenPacket("unixgram", addr)
+	assert.Success(t, "listen", err)
+	defer pc.Close()
+
+	s := slognet.Unix("unixgram", addr, nil)
+	defer s.Close()
+	l := slog.Make(s)
+	l.Info(bg, "one")
+	l.Info(bg, "two")
+
+	for _, msg := range []string{"one", "two"} {
+		b := make([]byte, 4096)
+		n, _, err := pc.ReadFrom(b)
+		assert.Success(t, "read", err)
+
+		var got slog.SinkEntry
+		err = got.UnmarshalJSON(b[:n])
+		assert.Success(t, "unmarshal", err)
+		assert.Equal(t, "msg", msg, got.Message)
+	}
+}
+
+func TestUnix_Encoder(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.Unix("unix", addr, &slognet.Options{
+		Framing: slognet.FrameLength,
+		Encoder: slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+			return append(buf, ent.Message...)
+		}),
+	})
+	defer s.Close()
+	err = s.LogEntryErr(bg, slog.SinkEntry{Message: "line1\nline2"})
+	assert.Success(t, "log entry", err)
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	var n uint32
+	err = binary.Read(c, binary.BigEndian, &n)
+	assert.Success(t, "read length", err)
+	p := make([]byte, n)
+	_, err = io.ReadFull(c, p)
+	assert.Success(t, "read", err)
+	assert.Equal(t, "entry", "line1\nline2", string(p))
+}
+
+func TestUnix_Sanitize(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.Unix("unix", addr, &slognet.Options{
+		Sanitize: &slog.SanitizeOptions{},
+		Encoder: slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+			return append(buf, ent.Message...)
+		}),
+	})
+	defer s.Close()
+	err = s.LogEntryErr(bg, slog.SinkEntry{Message: "line1\n2020-01-01 [INFO] forged"})
+	assert.Success(t, "log entry", err)
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	line, err := bufio.NewReader(c).ReadString('\n')
+	assert.Success(t, "read", err)
+	assert.Equal(t, "entry", "line1\\n2020-01-01 [INFO] forged\n", line)
+}
+
+func TestUnix_Delta(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.Unix("unix", addr, &slognet.Options{
+		Encoder: delayDefinitions(slogjson.Encoder(&slogjson.Options{
+			DeltaSegment: 5,
+		}), `"ctx":{`),
+	})
+	defer s.Close()
+	logConcurrently(t, s, ln)
+}
+
+// delayDefinitions returns an encoder that delays the entries encoded
+// by enc that contain def, the definition of a context or value.
+func delayDefinitions(enc slog.Encoder, def string) slog.Encoder {
+	return slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+		buf = enc.Encode(buf, ent)
+		if bytes.Contains(buf, []byte(def)) {
+			// Give other writers the chance to reference
+			// the definition before it is written.
+			time.Sleep(time.Millisecond)
+		}
+		return buf
+	})
+}
+
+// logConcurrently logs entries to s from several goroutines and checks
+// that the entries written to the connection accepted from ln decode.
+func logConcurrently(t *testing.T, s slog.Sink, ln net.Listener) {
+	t.Helper()
+
+	// The writers share a context so that it is often
+	// defined by one writer and referenced by another.
+	l := slog.Make(s).With(slog.F("svc", "api"))
+
+	const writers, entries = 8, 200
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < entries; j++ {
+				l.Info(bg, "entry", slog.F("writer", i))
+			}
+		}(i)
+	}
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	defer c.Close()
+	d := slogjson.NewDecoder(c)
+	counts := make(map[string]int)
+	for i := 0; i < writers*entries; i++ {
+		ent, err := d.Decode()
+		assert.Success(t, "decode", err)
+		svc, _ := ent.Fields.Get("svc")
+		assert.Equal(t, "svc", "api", svc)
+		writer, _ := ent.Fields.Get("writer")
+		counts[fmt.Sprint(writer)]++
+	}
+	wg.Wait()
+	assert.Equal(t, "writers", writers, len(counts))
+	for _, n := range counts {
+		assert.Equal(t, "entries", entries, n)
+	}
+}
+
+func TestUnix_Dedup(t *testing.T) {
+	t.Parallel()
+
+	addr := tempSocket(t)
+	ln, err := net.Listen("unix", addr)
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.Unix("unix", addr, &slognet.Options{
+		Encoder: delayDefinitions(slogjson.Encoder(&slogjson.Options{
+			DedupMinSize: 64,
+		}), `_sha256"`),
+	})
+	defer s.Close()
+	l := slog.Make(s)
+
+	const writers, entries = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < entries; j++ {
+				// The values are shared by the writers.
+				l.Info(bg, "entry", slog.F("stack", strings.Repeat("x", 64+j%5)))
+			}
+		}(i)
+	}
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	defer c.Close()
+	r := bufio.NewReader(c)
+	defined := make(map[string]bool)
+	for i := 0; i < writers*entries; i++ {
+		line, err := r.ReadBytes('\n')
+		assert.Success(t, "read", err)
+		var ent struct {
+			Fields struct {
+				Stack  json.RawMessage `json:"stack"`
+				SHA256 string          `json:"stack_sha256"`
+			} `json:"fields"`
+		}
+		err = json.Unmarshal(line, &ent)
+		assert.Success(t, "unmarshal", err)
+
+		if ent.Fields.SHA256 != "" {
+			defined[ent.Fields.SHA256] = true
+			continue
+		}
+		var ref struct {
+			ID string `json:"sha256_ref"`
+		}
+		err = json.Unmarshal(ent.Fields.Stack, &ref)
+		assert.Success(t, "unmarshal ref", err)
+		assert.True(t, "reference to written value", defined[ref.ID])
+	}
+	wg.Wait()
+	assert.Equal(t, "values", 5, len(defined))
+}
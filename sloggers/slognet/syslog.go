@@ -0,0 +1,174 @@
+package slognet
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"cdr.dev/slog"
+)
+
+// SyslogOptions represents the options for the sink returned by Syslog.
+type SyslogOptions struct {
+	// Options configures the connection. The Encoder encodes the MSG
+	// of every message after the syslog header. Framing is ignored as
+	// every message is terminated with a newline.
+	Options
+
+	// Network is "udp" or "tcp". Defaults to "udp".
+	Network string
+	// Facility is the syslog facility from 1 to 23.
+	// Defaults to 1 for user-level messages.
+	Facility int
+	// AppName is the APP-NAME of the messages.
+	// Defaults to the base name of the executable.
+	AppName string
+}
+
+// Syslog creates a sink that writes every entry as an RFC 5424 message
+// to the syslog server at addr such as "logs.internal:514". The severity
+// of a message is the syslog severity of the level of its entry, see
+// slog.Level.Severity, and the MSG is the entry in the slogjson format
+// by default.
+//
+// With "udp" every entry is written as a single datagram. With "tcp"
+// messages are framed with a trailing newline as in RFC 6587, so use
+// an Encoder that does not write newlines.
+// Call Close to close the connection.
+//
+// It panics if the network or facility is invalid.
+//
+// If opts is nil, the defaults are used.
+func Syslog(addr string, opts *SyslogOptions) *SyslogSink {
+	o := SyslogOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Network == "" {
+		o.Network = "udp"
+	}
+	switch o.Network {
+	case "udp", "tcp":
+	default:
+		panic(fmt.Sprintf("slognet: unknown syslog network %q", o.Network))
+	}
+	if o.Facility == 0 {
+		o.Facility = 1
+	}
+	if o.Facility < 1 || o.Facility > 23 {
+		panic(fmt.Sprintf("slognet: invalid syslog facility %v", o.Facility))
+	}
+	if o.AppName == "" {
+		o.AppName = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+
+	netOpts := o.Options.withDefaults()
+	netOpts.Framing = FrameNewline
+	netOpts.Encoder = syslogEncoder{
+		enc:      netOpts.Encoder,
+		facility: o.Facility,
+		hostname: syslogHeaderField(hostname, 255),
+		appName:  syslogHeaderField(o.AppName, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+	}
+	return &SyslogSink{
+		c: newConnSink("slognet.Syslog", o.Network, addr, (&net.Dialer{}).DialContext, netOpts),
+	}
+}
+
+// SyslogSink writes entries to a syslog server.
+//
+// See Syslog.
+type SyslogSink struct {
+	c *connSink
+}
+
+var _ slog.ErrorSink = &SyslogSink{}
+
+// LogEntry implements slog.Sink.
+//
+// Failures are printed to stderr except for entries
+// dropped while waiting to re-dial.
+func (s *SyslogSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	s.c.LogEntry(ctx, ent)
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// It returns an error if the sink is closed.
+func (s *SyslogSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	return s.c.LogEntryErr(ctx, ent)
+}
+
+// Sync implements slog.Sink.
+func (s *SyslogSink) Sync() {}
+
+// SyncErr implements slog.ErrorSink.
+//
+// Entries are written before LogEntry returns
+// so there is nothing to sync.
+func (s *SyslogSink) SyncErr() error {
+	return nil
+}
+
+// Close closes the connection.
+func (s *SyslogSink) Close() error {
+	return s.c.close()
+}
+
+// syslogEncoder prefixes the encoding of every entry with the
+// RFC 5424 header without structured data.
+type syslogEncoder struct {
+	enc      slog.Encoder
+	facility int
+	hostname string
+	appName  string
+	procID   string
+}
+
+func (e syslogEncoder) Encode(buf []byte, ent slog.SinkEntry) []byte {
+	severity := ent.Level.Severity().Syslog
+	if severity < 0 || severity > 7 {
+		severity = 6
+	}
+	buf = append(buf, '<')
+	buf = strconv.AppendInt(buf, int64(e.facility*8+severity), 10)
+	buf = append(buf, ">1 "...)
+	if ent.Time.IsZero() {
+		buf = append(buf, '-')
+	} else {
+		buf = ent.Time.UTC().AppendFormat(buf, "2006-01-02T15:04:05.000000Z07:00")
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, e.hostname...)
+	buf = append(buf, ' ')
+	buf = append(buf, e.appName...)
+	buf = append(buf, ' ')
+	buf = append(buf, e.procID...)
+	// There is no MSGID and no structured data.
+	buf = append(buf, " - - "...)
+	return e.enc.Encode(buf, ent)
+}
+
+// syslogHeaderField returns s with the characters not allowed in
+// header fields replaced with underscores and cut to max bytes,
+// or "-" if s is empty.
+func syslogHeaderField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	b := []byte(s)
+	if len(b) > max {
+		b = b[:max]
+	}
+	for i, c := range b {
+		if c <= ' ' || c > '~' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
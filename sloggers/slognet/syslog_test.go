@@ -0,0 +1,81 @@
+package slognet_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slognet"
+)
+
+func TestSyslog(t *testing.T) {
+	t.Parallel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Success(t, "listen", err)
+	defer pc.Close()
+
+	s := slognet.Syslog(pc.LocalAddr().String(), &slognet.SyslogOptions{
+		Facility: 16,
+		AppName:  "my app",
+	})
+	defer s.Close()
+	err = s.LogEntryErr(bg, slog.SinkEntry{
+		Time:    time.Date(2020, 5, 17, 15, 30, 0, 12345000, time.UTC),
+		Level:   slog.LevelWarn,
+		Message: "hello",
+	})
+	assert.Success(t, "log entry", err)
+
+	b := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(b)
+	assert.Success(t, "read", err)
+
+	// local0 is 16 and warning is 4.
+	hostname, _ := os.Hostname()
+	header := "<132>1 2020-05-17T15:30:00.012345Z " + hostname + " my_app " + strconv.Itoa(os.Getpid()) + " - - "
+	msg := string(b[:n])
+	assert.True(t, "header", strings.HasPrefix(msg, header))
+
+	var ent slog.SinkEntry
+	err = ent.UnmarshalJSON([]byte(strings.TrimPrefix(msg, header)))
+	assert.Success(t, "unmarshal", err)
+	assert.Equal(t, "msg", "hello", ent.Message)
+
+	assert.Success(t, "close", s.Close())
+	assert.Error(t, "log after close", s.LogEntryErr(bg, slog.SinkEntry{}))
+}
+
+func TestOpen_Syslog(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s, err := slog.Open("syslog://" + ln.Addr().String() + "?transport=tcp&app=api")
+	assert.Success(t, "open", err)
+	defer s.(io.Closer).Close()
+	slog.Make(s).Error(bg, "failed")
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	defer c.Close()
+	line, err := bufio.NewReader(c).ReadString('\n')
+	assert.Success(t, "read", err)
+	assert.True(t, "priority", strings.HasPrefix(line, "<11>1 "))
+	assert.True(t, "app", strings.Contains(line, " api "))
+	assert.True(t, "msg", strings.Contains(line, `"msg":"failed"`))
+
+	_, err = slog.Open("syslog://" + ln.Addr().String() + "?facility=24")
+	assert.Error(t, "invalid facility", err)
+	_, err = slog.Open("syslog://" + ln.Addr().String() + "?framing=length")
+	assert.Error(t, "unknown parameter", err)
+}
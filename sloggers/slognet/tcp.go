@@ -0,0 +1,291 @@
+package slognet
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// QueuePolicy controls what happens when an entry
+// is logged while the queue is full.
+type QueuePolicy int
+
+const (
+	// QueueBlock blocks until there is room in the queue
+	// or the context is done. This is the default.
+	QueueBlock QueuePolicy = iota
+
+	// QueueDropOldest drops the oldest queued entry.
+	QueueDropOldest
+)
+
+// TCPOptions represents the options for the sink returned by TCP.
+type TCPOptions struct {
+	Options
+
+	// TLS enables TLS if set. Set Certificates for client
+	// certificate authentication. If ServerName is empty,
+	// the host of the address is used.
+	TLS *tls.Config
+	// Conns is the number of connections entries are written on.
+	// Entries are only ordered if there is a single connection, which
+	// encoders that reference earlier entries such as slogjson with
+	// DeltaSegment require. Defaults to 1.
+	Conns int
+	// QueueSize is the maximum number of entries waiting
+	// to be written. Defaults to 1024.
+	QueueSize int
+	// QueuePolicy controls what happens when the queue is full.
+	QueuePolicy QueuePolicy
+}
+
+// TCPSink writes entries to a TCP address from a bounded queue.
+//
+// See TCP.
+type TCPSink struct {
+	// dropped is first for 64 bit alignment.
+	dropped uint64
+
+	opts  *TCPOptions
+	conns []*connSink
+	queue chan slog.SinkEntry
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	drained *sync.Cond
+	pending int
+	err     error
+	closed  bool
+
+	errorf func(f string, v ...interface{})
+}
+
+var (
+	_ slog.ErrorSink = &TCPSink{}
+	_ slog.Loader    = &TCPSink{}
+)
+
+// TCP creates a sink that writes entries to the TCP address addr
+// such as "logstash:5000", optionally over TLS.
+//
+// LogEntry only queues the entry. It is written by a background goroutine
+// per connection. Sync waits until the queue is empty.
+// Call Close to write the remaining entries and close the connections.
+//
+// If opts is nil, the defaults are used.
+func TCP(addr string, opts *TCPOptions) *TCPSink {
+	o := TCPOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.Options = *o.Options.withDefaults()
+	if o.Conns <= 0 {
+		o.Conns = 1
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1024
+	}
+
+	s := &TCPSink{
+		opts:  &o,
+		queue: make(chan slog.SinkEntry, o.QueueSize),
+		done:  make(chan struct{}),
+	}
+	s.drained = sync.NewCond(&s.mu)
+
+	dial := (&net.Dialer{}).DialContext
+	if o.TLS != nil {
+		dial = tlsDial(o.TLS)
+	}
+	for i := 0; i < o.Conns; i++ {
+		c := newConnSink("slognet.TCP", "tcp", addr, dial, &o.Options)
+		s.conns = append(s.conns, c)
+		s.errorf = c.errorf
+	}
+
+	s.wg.Add(len(s.conns))
+	for _, c := range s.conns {
+		go s.writeLoop(c)
+	}
+	return s
+}
+
+func tlsDial(config *tls.Config) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := config
+		if cfg.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				c.Close()
+				return nil, err
+			}
+			cfg = cfg.Clone()
+			cfg.ServerName = host
+		}
+
+		tc := tls.Client(c, cfg)
+		if deadline, ok := ctx.Deadline(); ok {
+			tc.SetDeadline(deadline)
+		}
+		err = tc.Handshake()
+		if err != nil {
+			c.Close()
+			return nil, xerrors.Errorf("TLS handshake failed: %w", err)
+		}
+		tc.SetDeadline(time.Time{})
+		return tc, nil
+	}
+}
+
+func (s *TCPSink) writeLoop(c *connSink) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case ent := <-s.queue:
+			err := c.LogEntryErr(context.Background(), ent)
+			s.mu.Lock()
+			if err != nil {
+				atomic.AddUint64(&s.dropped, 1)
+				if s.err == nil {
+					s.err = err
+				}
+				if !xerrors.Is(err, errBackoff) {
+					s.errorf("%v: %+v", c.name, err)
+				}
+			}
+			s.pending--
+			if s.pending == 0 {
+				s.drained.Broadcast()
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// LogEntry implements slog.Sink.
+func (s *TCPSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = s.LogEntryErr(ctx, ent)
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// It returns an error if the sink is closed or, with QueueBlock,
+// if ctx is done before there is room in the queue.
+// Failures to write queued entries are returned by SyncErr.
+func (s *TCPSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	// Entries are encoded when they are written, not when they are
+	// queued, so that an entry dropped from the queue is never referenced
+	// by a later entry. See slog.Encoder.
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return xerrors.New("slognet.TCP: sink is closed")
+	}
+	s.pending++
+
+	if s.opts.QueuePolicy == QueueDropOldest {
+		defer s.mu.Unlock()
+		select {
+		case s.queue <- ent:
+		default:
+			select {
+			case <-s.queue:
+				s.pending--
+				atomic.AddUint64(&s.dropped, 1)
+			default:
+			}
+			// Only senders hold the lock so there is room now.
+			s.queue <- ent
+		}
+		return nil
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.queue <- ent:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		s.pending--
+		s.mu.Unlock()
+		atomic.AddUint64(&s.dropped, 1)
+		return xerrors.Errorf("slognet.TCP: failed to queue entry: %w", ctx.Err())
+	case <-s.done:
+		return xerrors.New("slognet.TCP: sink is closed")
+	}
+}
+
+// Sync implements slog.Sink.
+func (s *TCPSink) Sync() {
+	_ = s.SyncErr()
+}
+
+// SyncErr implements slog.ErrorSink.
+//
+// It waits until every queued entry has been written and returns the first
+// error writing an entry since the last call.
+func (s *TCPSink) SyncErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.pending > 0 {
+		s.drained.Wait()
+	}
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Dropped returns the number of entries that were dropped because
+// the queue was full or they could not be written.
+func (s *TCPSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Load returns the fill of the queue from 0 for empty to 1 for full.
+// It implements slog.Loader for slog.Adaptive.
+func (s *TCPSink) Load() float64 {
+	return float64(len(s.queue)) / float64(cap(s.queue))
+}
+
+// Close writes the queued entries and closes the connections.
+func (s *TCPSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	for s.pending > 0 {
+		s.drained.Wait()
+	}
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+
+	var err error
+	for _, c := range s.conns {
+		err2 := c.close()
+		if err == nil {
+			err = err2
+		}
+	}
+	return err
+}
@@ -0,0 +1,176 @@
+package slognet_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogjson"
+	"cdr.dev/slog/sloggers/slognet"
+)
+
+func readMessages(t *testing.T, c net.Conn, n int) []string {
+	t.Helper()
+
+	r := bufio.NewReader(c)
+	var msgs []string
+	for i := 0; i < n; i++ {
+		line, err := r.ReadString('\n')
+		assert.Success(t, "read", err)
+
+		var ent slog.SinkEntry
+		err = ent.UnmarshalJSON([]byte(line))
+		assert.Success(t, "unmarshal", err)
+		msgs = append(msgs, ent.Message)
+	}
+	return msgs
+}
+
+func TestTCP(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.TCP(ln.Addr().String(), nil)
+	l := slog.Make(s)
+	l.Info(bg, "one")
+	l.Info(bg, "two")
+	err = s.SyncErr()
+	assert.Success(t, "sync", err)
+
+	c, err := ln.Accept()
+	assert.Success(t, "accept", err)
+	defer c.Close()
+	assert.Equal(t, "msgs", []string{"one", "two"}, readMessages(t, c, 2))
+
+	err = s.Close()
+	assert.Success(t, "close", err)
+	err = s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.Error(t, "log after close", err)
+}
+
+func TestTCP_Delta(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.TCP(ln.Addr().String(), &slognet.TCPOptions{
+		Options: slognet.Options{
+			Encoder: delayDefinitions(slogjson.Encoder(&slogjson.Options{
+				DeltaSegment: 5,
+			}), `"ctx":{`),
+		},
+	})
+	defer s.Close()
+	logConcurrently(t, s, ln)
+}
+
+func TestTCP_TLS(t *testing.T) {
+	t.Parallel()
+
+	cert, pool := selfSigned(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	})
+	assert.Success(t, "listen", err)
+	defer ln.Close()
+
+	s := slognet.TCP(ln.Addr().String(), &slognet.TCPOptions{
+		TLS: &tls.Config{
+			RootCAs: pool,
+		},
+	})
+	defer s.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err == nil {
+			// The handshake happens on the first read otherwise.
+			c.(*tls.Conn).Handshake()
+		}
+		accepted <- c
+	}()
+
+	slog.Make(s).Info(bg, "secret")
+	err = s.SyncErr()
+	assert.Success(t, "sync", err)
+
+	c := <-accepted
+	defer c.Close()
+	assert.Equal(t, "msgs", []string{"secret"}, readMessages(t, c, 1))
+}
+
+func TestTCP_DropOldest(t *testing.T) {
+	t.Parallel()
+
+	s := slognet.TCP("collector:5000", &slognet.TCPOptions{
+		Options: slognet.Options{
+			WriteTimeout: time.Minute,
+		},
+		QueueSize:   2,
+		QueuePolicy: slognet.QueueDropOldest,
+	})
+	defer s.Close()
+
+	client, server := net.Pipe()
+	defer server.Close()
+	dialed := make(chan struct{})
+	slognet.SetDial(s, func(ctx context.Context, network, addr string) (net.Conn, error) {
+		close(dialed)
+		return client, nil
+	})
+
+	l := slog.Make(s)
+	l.Info(bg, "1")
+	// The first entry is being written to the blocked pipe.
+	<-dialed
+	for _, msg := range []string{"2", "3", "4", "5"} {
+		l.Info(bg, msg)
+	}
+	assert.Equal(t, "dropped", uint64(2), s.Dropped())
+	assert.Equal(t, "load", 1.0, s.Load())
+
+	assert.Equal(t, "msgs", []string{"1", "4", "5"}, readMessages(t, server, 3))
+}
+
+func selfSigned(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Success(t, "generate key", err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Success(t, "create certificate", err)
+	leaf, err := x509.ParseCertificate(der)
+	assert.Success(t, "parse certificate", err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, pool
+}
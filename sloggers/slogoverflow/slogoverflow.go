@@ -0,0 +1,192 @@
+// Package slogoverflow contains a slog.Sink wrapper that moves
+// oversized field values out of entries and into a blob store.
+//
+// A request body of a few megabytes makes an entry unreadable and
+// can exceed the ingestion limits of log aggregators. With this
+// package the field is replaced with a reference to the stored value:
+//
+//	{"body": {"ref": "file:///var/log/blobs/7d86...", "size": 3145728, "sha256": "7d86..."}}
+package slogoverflow // import "cdr.dev/slog/sloggers/slogoverflow"
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// DefaultMaxSize is the default maximum size of a field value.
+const DefaultMaxSize = 16 << 10
+
+// BlobStore stores oversized field values.
+//
+// Implement it to store values in e.g. S3.
+type BlobStore interface {
+	// Put stores p and returns a reference to it such as a URL.
+	Put(ctx context.Context, p []byte) (ref string, err error)
+}
+
+// DirStore returns a BlobStore that stores every value in a file
+// in dir named by the SHA-256 of the value.
+//
+// References are file URLs.
+func DirStore(dir string) BlobStore {
+	return dirStore{
+		dir: dir,
+	}
+}
+
+type dirStore struct {
+	dir string
+}
+
+func (s dirStore) Put(ctx context.Context, p []byte) (string, error) {
+	sum := sha256.Sum256(p)
+	path, err := filepath.Abs(filepath.Join(s.dir, hex.EncodeToString(sum[:])))
+	if err != nil {
+		return "", xerrors.Errorf("failed to get absolute path: %w", err)
+	}
+
+	// Identical values are stored only once.
+	_, err = os.Stat(path)
+	if err == nil {
+		return "file://" + filepath.ToSlash(path), nil
+	}
+
+	err = os.MkdirAll(s.dir, 0700)
+	if err != nil {
+		return "", xerrors.Errorf("failed to create blob directory: %w", err)
+	}
+	err = ioutil.WriteFile(path, p, 0600)
+	if err != nil {
+		return "", xerrors.Errorf("failed to write blob: %w", err)
+	}
+	return "file://" + filepath.ToSlash(path), nil
+}
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Store stores the oversized values. It is required.
+	Store BlobStore
+	// MaxSize is the maximum size in bytes of a field value.
+	// Strings and byte slices are measured by their length
+	// and all other values by the length of their JSON encoding.
+	// Defaults to DefaultMaxSize.
+	MaxSize int
+}
+
+// Make returns a slog.Sink that replaces every field value in
+// entries larger than opts.MaxSize with a reference to it in opts.Store
+// before logging the entry to s.
+//
+// Strings and byte slices are stored as is and all other values
+// are stored as JSON. If storing a value fails, it is replaced with
+// the error and at most the first opts.MaxSize bytes of the value,
+// cut at the start of a UTF-8 character.
+//
+// It panics if opts.Store is nil.
+func Make(s slog.Sink, opts *Options) slog.Sink {
+	if opts == nil || opts.Store == nil {
+		panic("slogoverflow: Store is required")
+	}
+	o := *opts
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultMaxSize
+	}
+	return &overflowSink{
+		s:    s,
+		opts: o,
+	}
+}
+
+type overflowSink struct {
+	s    slog.Sink
+	opts Options
+}
+
+func (s *overflowSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	var fields slog.Map
+	for i, f := range ent.Fields {
+		p, ok := s.oversized(f.Value)
+		if !ok {
+			continue
+		}
+
+		if fields == nil {
+			// Copy the fields as other sinks share them.
+			fields = append(slog.Map(nil), ent.Fields...)
+		}
+		fields[i] = slog.F(f.Name, s.store(ctx, p))
+	}
+	if fields != nil {
+		ent.Fields = fields
+	}
+
+	s.s.LogEntry(ctx, ent)
+}
+
+func (s *overflowSink) Sync() {
+	s.s.Sync()
+}
+
+func (s *overflowSink) Flush(ctx context.Context) error {
+	return slog.Flush(ctx, s.s)
+}
+
+// oversized returns the value to store if v is too large.
+func (s *overflowSink) oversized(v interface{}) ([]byte, bool) {
+	var p []byte
+	switch v := v.(type) {
+	case string:
+		if len(v) <= s.opts.MaxSize {
+			return nil, false
+		}
+		p = []byte(v)
+	case []byte:
+		p = v
+	default:
+		// No error is guaranteed due to slog.Map handling errors itself.
+		p, _ = json.Marshal(slog.M(slog.F("v", v)))
+		// Strip {"v": and }.
+		p = p[len(`{"v":`) : len(p)-1]
+	}
+	return p, len(p) > s.opts.MaxSize
+}
+
+func (s *overflowSink) store(ctx context.Context, p []byte) slog.Map {
+	sum := sha256.Sum256(p)
+
+	ref, err := s.opts.Store.Put(ctx, p)
+	if err != nil {
+		return slog.M(
+			slog.Error(xerrors.Errorf("failed to store oversized value: %w", err)),
+			slog.F("size", len(p)),
+			slog.F("truncated", string(truncate(p, s.opts.MaxSize))),
+		)
+	}
+
+	return slog.M(
+		slog.F("ref", ref),
+		slog.F("size", len(p)),
+		slog.F("sha256", hex.EncodeToString(sum[:])),
+	)
+}
+
+// truncate returns the first n bytes of p without splitting
+// the last UTF-8 character. p must be longer than n.
+func truncate(p []byte, n int) []byte {
+	for i := n; i > 0 && i > n-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			return p[:i]
+		}
+	}
+	return p[:n]
+}
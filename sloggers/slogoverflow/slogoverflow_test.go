@@ -0,0 +1,107 @@
+package slogoverflow_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogoverflow"
+	"cdr.dev/slog/sloggers/slogtest"
+)
+
+var bg = context.Background()
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dir", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		spy := slogtest.Spy()
+		l := slog.Make(slogoverflow.Make(spy, &slogoverflow.Options{
+			Store:   slogoverflow.DirStore(dir),
+			MaxSize: 8,
+		}))
+
+		body := strings.Repeat("a", 9)
+		fields := slog.M(
+			slog.F("small", "hi"),
+			slog.F("body", body),
+			slog.F("list", []int{1, 2, 3, 4, 5}),
+		)
+		l.Info(bg, "request", fields...)
+
+		ref := func(blob string) slog.Map {
+			sum := sha256.Sum256([]byte(blob))
+			path := filepath.Join(dir, hex.EncodeToString(sum[:]))
+
+			b, err := ioutil.ReadFile(path)
+			assert.Success(t, "read blob", err)
+			assert.Equal(t, "blob", blob, string(b))
+
+			return slog.M(
+				slog.F("ref", "file://"+filepath.ToSlash(path)),
+				slog.F("size", len(blob)),
+				slog.F("sha256", hex.EncodeToString(sum[:])),
+			)
+		}
+
+		assert.Len(t, "entries", 1, spy.Entries())
+		assert.Equal(t, "fields", slog.M(
+			slog.F("small", "hi"),
+			slog.F("body", ref(body)),
+			slog.F("list", ref("[1,2,3,4,5]")),
+		), spy.Entries()[0].Fields)
+
+		// The original fields must not be modified.
+		assert.Equal(t, "original", body, fields[1].Value)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		spy := slogtest.Spy()
+		l := slog.Make(slogoverflow.Make(spy, &slogoverflow.Options{
+			Store:   failStore{},
+			MaxSize: 2,
+		}))
+		l.Info(bg, "request", slog.F("body", "hello"))
+
+		v := spy.Entries()[0].Fields[0].Value.(slog.Map)
+		assert.Equal(t, "truncated", slog.F("truncated", "he"), v[2])
+
+		// Characters are not split.
+		l.Info(bg, "request", slog.F("body", "hé!"))
+		v = spy.Entries()[1].Fields[0].Value.(slog.Map)
+		assert.Equal(t, "truncated", slog.F("truncated", "h"), v[2])
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+
+		opts := &slogoverflow.Options{
+			Store: failStore{},
+		}
+		slogoverflow.Make(slogtest.Spy(), opts)
+		assert.Equal(t, "max size", 0, opts.MaxSize)
+
+		defer func() {
+			assert.Equal(t, "panic", "slogoverflow: Store is required", recover())
+		}()
+		slogoverflow.Make(slogtest.Spy(), nil)
+	})
+}
+
+type failStore struct{}
+
+func (failStore) Put(context.Context, []byte) (string, error) {
+	return "", io.ErrClosedPipe
+}
@@ -0,0 +1,399 @@
+// Package slogpg contains the slogger that writes logs
+// to a PostgreSQL table.
+//
+// Entries are batched in memory and inserted with COPY. The table has
+// a column for every part of the entry and the fields in a jsonb column:
+//
+//	CREATE TABLE logs (
+//		ts       timestamptz NOT NULL,
+//		level    text NOT NULL,
+//		logger   text NOT NULL,
+//		msg      text NOT NULL,
+//		caller   text NOT NULL,
+//		func     text NOT NULL,
+//		trace_id text,
+//		span_id  text,
+//		fields   jsonb NOT NULL
+//	);
+//
+// It is meant for small deployments where the database is the only
+// infrastructure. Use DeleteBefore to limit the size of the table.
+package slogpg // import "cdr.dev/slog/sloggers/slogpg"
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/exportctx"
+)
+
+// Columns are the columns of the table in the order of the rows
+// passed to Copier.
+var Columns = []string{"ts", "level", "logger", "msg", "caller", "func", "trace_id", "span_id", "fields"}
+
+// Copier copies rows into a table with COPY.
+//
+// To adapt a pool from github.com/jackc/pgx, use:
+//
+//	slogpg.CopierFunc(func(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+//		_, err := pool.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+//		return err
+//	})
+type Copier interface {
+	CopyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) error
+}
+
+// CopierFunc implements Copier with a function.
+type CopierFunc func(ctx context.Context, table string, columns []string, rows [][]interface{}) error
+
+// CopyFrom implements Copier.
+func (f CopierFunc) CopyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+	return f(ctx, table, columns, rows)
+}
+
+// SQLCopier returns a Copier that uses COPY FROM STDIN in a transaction
+// on db as supported by github.com/lib/pq.
+func SQLCopier(db *sql.DB) Copier {
+	return CopierFunc(func(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return xerrors.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		quoted := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = quoteIdent(c)
+		}
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf("COPY %v (%v) FROM STDIN", quoteIdent(table), strings.Join(quoted, ", ")))
+		if err != nil {
+			return xerrors.Errorf("failed to prepare COPY: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, row := range rows {
+			_, err = stmt.ExecContext(ctx, row...)
+			if err != nil {
+				return xerrors.Errorf("failed to copy row: %w", err)
+			}
+		}
+		// An Exec without arguments completes the COPY.
+		_, err = stmt.ExecContext(ctx)
+		if err != nil {
+			return xerrors.Errorf("failed to complete COPY: %w", err)
+		}
+		return tx.Commit()
+	})
+}
+
+// Execer executes SQL statements. It is implemented by *sql.DB.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// CreateTable creates table and an index on its ts column
+// if they do not exist.
+func CreateTable(ctx context.Context, db Execer, table string) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %v (
+	ts       timestamptz NOT NULL,
+	level    text NOT NULL,
+	logger   text NOT NULL,
+	msg      text NOT NULL,
+	caller   text NOT NULL,
+	func     text NOT NULL,
+	trace_id text,
+	span_id  text,
+	fields   jsonb NOT NULL
+)`, quoteIdent(table)))
+	if err != nil {
+		return xerrors.Errorf("failed to create table: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %v ON %v (ts)",
+		quoteIdent(lastIdent(table)+"_ts_idx"), quoteIdent(table)))
+	if err != nil {
+		return xerrors.Errorf("failed to create index: %w", err)
+	}
+	return nil
+}
+
+// DeleteBefore deletes the entries in table logged before t
+// and returns the number of deleted entries.
+//
+// Call it periodically to retain e.g. a week of logs:
+//
+//	slogpg.DeleteBefore(ctx, db, "logs", time.Now().AddDate(0, 0, -7))
+func DeleteBefore(ctx context.Context, db Execer, table string, t time.Time) (int64, error) {
+	res, err := db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %v WHERE ts < $1", quoteIdent(table)), t)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to delete entries: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// quoteIdent quotes every part of a possibly schema
+// qualified identifier.
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.Replace(p, `"`, `""`, -1) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+func lastIdent(name string) string {
+	return name[strings.LastIndexByte(name, '.')+1:]
+}
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Table is the name of the table. Defaults to "logs".
+	Table string
+	// BatchSize is the number of entries after which they are copied.
+	// Defaults to 500.
+	BatchSize int
+	// FlushInterval is the maximum time an entry waits
+	// before it is copied. Defaults to 1s.
+	FlushInterval time.Duration
+	// Timeout is the maximum time to copy a batch so that a slow
+	// database does not block the caller of LogEntry that filled it.
+	// That caller also stops waiting for the copy when the context of
+	// its entry is done, unless it already was, but the copy continues
+	// as the batch contains the entries of other callers. Defaults to
+	// 10s. Set to a negative value to disable it.
+	Timeout time.Duration
+}
+
+// Sink copies batches of entries into a table.
+//
+// See Make.
+type Sink struct {
+	c     Copier
+	table string
+	opts  *Options
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu     sync.Mutex
+	rows   [][]interface{}
+	closed bool
+
+	// flushMu serializes copies so that entries are ordered.
+	flushMu sync.Mutex
+
+	errorf func(f string, v ...interface{})
+}
+
+var _ slog.ErrorSink = &Sink{}
+
+// Make creates a sink that copies batches of entries into
+// a table with c. Call CreateTable first to create the table.
+//
+// A batch is copied by the entry that fills it and periodically
+// by a background goroutine. Call Close to copy the remaining
+// entries and stop the goroutine.
+//
+// If opts is nil, the defaults are used.
+func Make(c Copier, opts *Options) *Sink {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Table == "" {
+		o.Table = "logs"
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 500
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 10 * time.Second
+	}
+
+	s := &Sink{
+		c:     c,
+		table: o.Table,
+		opts:  &o,
+		done:  make(chan struct{}),
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+	s.wg.Add(1)
+	go s.flushLoop()
+	return s
+}
+
+func row(ent slog.SinkEntry) []interface{} {
+	var traceID, spanID interface{}
+	if ent.SpanContext != (trace.SpanContext{}) {
+		traceID = ent.SpanContext.TraceID.String()
+		spanID = ent.SpanContext.SpanID.String()
+	}
+
+	fields := "{}"
+	if len(ent.Fields) > 0 {
+		// No error is guaranteed due to slog.Map handling errors itself.
+		b, _ := json.Marshal(ent.Fields)
+		fields = string(b)
+	}
+
+	return []interface{}{
+		ent.Time,
+		ent.Level.String(),
+		strings.Join(ent.LoggerNames, "."),
+		ent.Message,
+		ent.File + ":" + strconv.Itoa(ent.Line),
+		ent.Func,
+		traceID,
+		spanID,
+		fields,
+	}
+}
+
+// LogEntry implements slog.Sink.
+//
+// Failures are printed to stderr.
+func (s *Sink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	err := s.LogEntryErr(ctx, ent)
+	if err != nil {
+		s.errorf("slogpg: %+v", err)
+	}
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// It returns the error copying the batch if ent filled it.
+func (s *Sink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return xerrors.New("sink is closed")
+	}
+	s.rows = append(s.rows, row(ent))
+	full := len(s.rows) >= s.opts.BatchSize
+	s.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return s.flush(ctx)
+}
+
+// flush copies the current batch and waits until it has been copied
+// or ctx is done.
+func (s *Sink) flush(ctx context.Context) error {
+	s.flushMu.Lock()
+
+	s.mu.Lock()
+	rows := s.rows
+	s.rows = nil
+	s.mu.Unlock()
+
+	if len(rows) == 0 {
+		s.flushMu.Unlock()
+		return nil
+	}
+
+	// The copy is not canceled with ctx as the batch
+	// contains the entries of other callers.
+	copied := make(chan error, 1)
+	go func() {
+		defer s.flushMu.Unlock()
+		copied <- s.copy(rows)
+	}()
+
+	ctx, cancel := exportctx.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+	select {
+	case err := <-copied:
+		return err
+	case <-ctx.Done():
+		go func() {
+			err := <-copied
+			if err != nil {
+				s.errorf("slogpg: %+v", err)
+			}
+		}()
+		return xerrors.Errorf("stopped waiting for the copy of %v entries: %w", len(rows), ctx.Err())
+	}
+}
+
+func (s *Sink) copy(rows [][]interface{}) error {
+	ctx, cancel := exportctx.WithTimeout(context.Background(), s.opts.Timeout)
+	defer cancel()
+	err := s.c.CopyFrom(ctx, s.table, Columns, rows)
+	if err != nil {
+		return xerrors.Errorf("failed to copy %v entries: %w", len(rows), err)
+	}
+	return nil
+}
+
+func (s *Sink) flushLoop() {
+	defer s.wg.Done()
+
+	t := time.NewTicker(s.opts.FlushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-t.C:
+			err := s.flush(context.Background())
+			if err != nil {
+				s.errorf("slogpg: %+v", err)
+			}
+		}
+	}
+}
+
+// Sync implements slog.Sink.
+func (s *Sink) Sync() {
+	err := s.SyncErr()
+	if err != nil {
+		s.errorf("slogpg: %+v", err)
+	}
+}
+
+// SyncErr implements slog.ErrorSink.
+//
+// It copies the current batch.
+func (s *Sink) SyncErr() error {
+	return s.flush(context.Background())
+}
+
+// Flush implements slog.Flusher.
+//
+// Like SyncErr, but the batch is copied with ctx.
+func (s *Sink) Flush(ctx context.Context) error {
+	return s.flush(ctx)
+}
+
+// Close copies the remaining entries and stops the background goroutine.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.done)
+	s.wg.Wait()
+	return s.flush(context.Background())
+}
@@ -0,0 +1,148 @@
+package slogpg_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogpg"
+)
+
+var bg = context.Background()
+
+type fakeCopier struct {
+	mu      sync.Mutex
+	batches [][][]interface{}
+}
+
+func (c *fakeCopier) CopyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if table != "app.logs" || len(columns) != 9 {
+		panic("unexpected table or columns")
+	}
+	c.batches = append(c.batches, rows)
+	return nil
+}
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+
+	c := &fakeCopier{}
+	s := slogpg.Make(c, &slogpg.Options{
+		Table:         "app.logs",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	l := slog.Make(s).Named("api")
+	l.Info(bg, "1", slog.F("user", "bob"))
+	l.Info(bg, "2")
+	l.Info(bg, "3")
+	err := s.Close()
+	assert.Success(t, "close", err)
+
+	assert.Len(t, "batches", 2, c.batches)
+	assert.Len(t, "batch 1", 2, c.batches[0])
+	assert.Len(t, "batch 2", 1, c.batches[1])
+
+	row := c.batches[0][0]
+	assert.Equal(t, "level", "INFO", row[1])
+	assert.Equal(t, "logger", "api", row[2])
+	assert.Equal(t, "msg", "1", row[3])
+	assert.Equal(t, "trace id", nil, row[6])
+	assert.Equal(t, "fields", `{"user":"bob"}`, row[8])
+	assert.Equal(t, "fields", "{}", c.batches[0][1][8])
+
+	err = s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.Error(t, "log after close", err)
+}
+
+func TestMake_Timeout(t *testing.T) {
+	t.Parallel()
+
+	c := slogpg.CopierFunc(func(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s := slogpg.Make(c, &slogpg.Options{
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Timeout:       10 * time.Millisecond,
+	})
+	defer s.Close()
+
+	err := s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+}
+
+func TestMake_Detached(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	var copied []int
+	var mu sync.Mutex
+	c := slogpg.CopierFunc(func(ctx context.Context, table string, columns []string, rows [][]interface{}) error {
+		<-release
+		mu.Lock()
+		defer mu.Unlock()
+		copied = append(copied, len(rows))
+		return ctx.Err()
+	})
+	s := slogpg.Make(c, &slogpg.Options{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	err := s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.Success(t, "log", err)
+
+	// The caller that fills the batch stops waiting when its
+	// context is done but the batch is still copied.
+	ctx, cancel := context.WithTimeout(bg, 10*time.Millisecond)
+	defer cancel()
+	err = s.LogEntryErr(ctx, slog.SinkEntry{})
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+
+	close(release)
+	err = s.Close()
+	assert.Success(t, "close", err)
+	assert.Equal(t, "copied", []int{2}, copied)
+}
+
+type fakeExecer struct {
+	queries []string
+}
+
+func (e *fakeExecer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	return driverResult(3), nil
+}
+
+type driverResult int64
+
+func (r driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (r driverResult) RowsAffected() (int64, error) { return int64(r), nil }
+
+func TestCreateTable(t *testing.T) {
+	t.Parallel()
+
+	e := &fakeExecer{}
+	err := slogpg.CreateTable(bg, e, "app.logs")
+	assert.Success(t, "create table", err)
+	assert.Len(t, "queries", 2, e.queries)
+	assert.True(t, "create table", strings.HasPrefix(e.queries[0], `CREATE TABLE IF NOT EXISTS "app"."logs" (`))
+	assert.Equal(t, "create index", `CREATE INDEX IF NOT EXISTS "logs_ts_idx" ON "app"."logs" (ts)`, e.queries[1])
+
+	n, err := slogpg.DeleteBefore(bg, e, "logs", time.Now())
+	assert.Success(t, "delete", err)
+	assert.Equal(t, "deleted", int64(3), n)
+	assert.Equal(t, "delete", `DELETE FROM "logs" WHERE ts < $1`, e.queries[2])
+}
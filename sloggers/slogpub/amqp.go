@@ -0,0 +1,148 @@
+package slogpub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// AMQPChannel publishes AMQP messages.
+//
+// To adapt a channel from github.com/rabbitmq/amqp091-go
+// in confirm mode, use:
+//
+//	func (c amqpChannel) Publish(ctx context.Context, exchange, key string, body []byte) error {
+//		dc, err := c.ch.PublishWithDeferredConfirmWithContext(ctx, exchange, key, false, false, amqp.Publishing{
+//			ContentType: "application/json",
+//			Body:        body,
+//		})
+//		if err != nil {
+//			return err
+//		}
+//		ok, err := dc.WaitContext(ctx)
+//		if err == nil && !ok {
+//			err = errors.New("nacked")
+//		}
+//		return err
+//	}
+type AMQPChannel interface {
+	// Publish publishes body and waits for the publisher confirm.
+	Publish(ctx context.Context, exchange, routingKey string, body []byte) error
+	Close() error
+}
+
+// AMQPOptions represents the options for the sink returned by AMQP.
+type AMQPOptions struct {
+	// Exchange is the template of the exchange. See Options.Topic.
+	Exchange string
+	// RoutingKey is the template of the routing key. See Options.Topic.
+	// e.g. "{component}.{level}"
+	RoutingKey string
+	// BufferSize is the number of entries buffered while the broker
+	// is unreachable. See Options.BufferSize.
+	BufferSize int
+	// MinBackoff is the time to wait before redialing after the first
+	// failure. It is doubled on every consecutive failure.
+	// Defaults to 100ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum time to wait before redialing.
+	// Defaults to 10s.
+	MaxBackoff time.Duration
+	// Encoder encodes the messages. Defaults to the slogjson format.
+	Encoder slog.Encoder
+	// Timeout is the maximum time to publish a message.
+	// See Options.Timeout.
+	Timeout time.Duration
+}
+
+// AMQP creates a sink that publishes every entry
+// to a channel returned by dial.
+//
+// dial is called for the first entry and again after a failure
+// to publish, with exponential backoff if it fails.
+//
+// If opts is nil, the defaults are used.
+func AMQP(dial func(ctx context.Context) (AMQPChannel, error), opts *AMQPOptions) slog.ErrorSink {
+	if opts == nil {
+		opts = &AMQPOptions{}
+	}
+
+	p := &amqpPublisher{
+		dial:       dial,
+		minBackoff: opts.MinBackoff,
+		maxBackoff: opts.MaxBackoff,
+	}
+	if p.minBackoff <= 0 {
+		p.minBackoff = 100 * time.Millisecond
+	}
+	if p.maxBackoff <= 0 {
+		p.maxBackoff = 10 * time.Second
+	}
+
+	s := newSink("slogpub.AMQP", nil, &Options{
+		Topic:      opts.RoutingKey,
+		BufferSize: opts.BufferSize,
+		Encoder:    opts.Encoder,
+		Timeout:    opts.Timeout,
+	})
+	exchange := parseTopic(opts.Exchange)
+	s.send = func(ctx context.Context, routingKey string, ent slog.SinkEntry) error {
+		return p.publish(ctx, s.expand(exchange, ent), routingKey, s.encoder.Encode(nil, ent))
+	}
+	s.flush = func(context.Context) error { return nil }
+	return s
+}
+
+type amqpPublisher struct {
+	dial       func(ctx context.Context) (AMQPChannel, error)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu       sync.Mutex
+	ch       AMQPChannel
+	failures int
+	retryAt  time.Time
+}
+
+func (p *amqpPublisher) publish(ctx context.Context, exchange, routingKey string, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ch == nil {
+		if time.Now().Before(p.retryAt) {
+			return errBackoff
+		}
+		ch, err := p.dial(ctx)
+		if err != nil {
+			p.fail()
+			return xerrors.Errorf("failed to dial: %w", err)
+		}
+		p.ch = ch
+	}
+
+	err := p.ch.Publish(ctx, exchange, routingKey, body)
+	if err != nil {
+		// The channel is closed by the broker on most errors
+		// so a new one is dialed for the next entry.
+		p.ch.Close()
+		p.ch = nil
+		return err
+	}
+	p.failures = 0
+	return nil
+}
+
+// fail schedules the next dial with exponential backoff.
+func (p *amqpPublisher) fail() {
+	backoff := p.minBackoff << uint(p.failures)
+	if backoff > p.maxBackoff || backoff <= 0 {
+		backoff = p.maxBackoff
+	} else {
+		p.failures++
+	}
+	p.retryAt = time.Now().Add(backoff)
+}
@@ -0,0 +1,78 @@
+package slogpub_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogpub"
+)
+
+type fakeChannel struct {
+	published []string
+	fail      bool
+	closed    bool
+}
+
+func (c *fakeChannel) Publish(ctx context.Context, exchange, routingKey string, body []byte) error {
+	if c.fail {
+		return io.EOF
+	}
+	c.published = append(c.published, exchange+" "+routingKey)
+	return nil
+}
+
+func (c *fakeChannel) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestAMQP(t *testing.T) {
+	t.Parallel()
+
+	var channels []*fakeChannel
+	dialErr := io.ErrUnexpectedEOF
+	s := slogpub.AMQP(func(ctx context.Context) (slogpub.AMQPChannel, error) {
+		if dialErr != nil {
+			return nil, dialErr
+		}
+		c := &fakeChannel{}
+		channels = append(channels, c)
+		return c, nil
+	}, &slogpub.AMQPOptions{
+		Exchange:   "logs-{level}",
+		RoutingKey: "{component}.{level}",
+		MinBackoff: time.Millisecond,
+	})
+
+	ent := slog.SinkEntry{
+		Level:  slog.LevelError,
+		Fields: slog.M(slog.F("component", "db")),
+	}
+	err := s.LogEntryErr(bg, ent)
+	assert.Error(t, "dial failure", err)
+
+	// Waiting to redial.
+	dialErr = nil
+	err = s.LogEntryErr(bg, ent)
+	assert.Error(t, "backoff", err)
+
+	time.Sleep(5 * time.Millisecond)
+	err = s.LogEntryErr(bg, ent)
+	assert.Success(t, "publish", err)
+	assert.Len(t, "channels", 1, channels)
+	assert.Equal(t, "published", []string{"logs-error db.error"}, channels[0].published)
+
+	// A failed publish closes the channel and a new one is dialed.
+	channels[0].fail = true
+	err = s.LogEntryErr(bg, ent)
+	assert.Error(t, "publish failure", err)
+	assert.True(t, "closed", channels[0].closed)
+
+	err = s.LogEntryErr(bg, ent)
+	assert.Success(t, "publish", err)
+	assert.Len(t, "channels", 2, channels)
+}
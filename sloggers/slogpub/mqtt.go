@@ -0,0 +1,102 @@
+package slogpub
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cdr.dev/slog"
+)
+
+// MQTTClient publishes MQTT messages.
+//
+// To adapt a client from github.com/eclipse/paho.mqtt.golang, use:
+//
+//	slogpub.MQTTClientFunc(func(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+//		t := c.Publish(topic, qos, retained, payload)
+//		select {
+//		case <-t.Done():
+//			return t.Error()
+//		case <-ctx.Done():
+//			return ctx.Err()
+//		}
+//	})
+type MQTTClient interface {
+	// Publish publishes payload and waits until it has been sent
+	// for the QoS or ctx is done.
+	Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+}
+
+// MQTTClientFunc implements MQTTClient with a function.
+type MQTTClientFunc func(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+
+// Publish implements MQTTClient.
+func (f MQTTClientFunc) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	return f(ctx, topic, qos, retained, payload)
+}
+
+// MQTTOptions represents the options for the sink returned by MQTT.
+type MQTTOptions struct {
+	// Topic is the topic template. See Options.Topic.
+	// e.g. "devices/{device}/logs/{level}"
+	Topic string
+	// QoS is the quality of service of every message.
+	QoS byte
+	// Retained sets the retained flag of every message.
+	Retained bool
+	// BufferSize is the number of messages buffered while the broker
+	// is unreachable. See Options.BufferSize. Defaults to 1000.
+	// Set to a negative value to disable buffering.
+	BufferSize int
+	// Encoder encodes the messages. Defaults to the slogjson format.
+	Encoder slog.Encoder
+	// Sanitize enables escaping or stripping CR, LF and NUL characters
+	// in entries. See Options.Sanitize.
+	Sanitize *slog.SanitizeOptions
+	// Timeout is the maximum time to publish a message.
+	// See Options.Timeout.
+	Timeout time.Duration
+}
+
+// MQTT creates a sink that publishes every entry with c.
+//
+// Slashes and wildcards in placeholder values are replaced with
+// underscores.
+//
+// If opts is nil, the defaults are used.
+func MQTT(c MQTTClient, opts *MQTTOptions) slog.ErrorSink {
+	if opts == nil {
+		opts = &MQTTOptions{}
+	}
+	bufferSize := opts.BufferSize
+	if bufferSize == 0 {
+		bufferSize = 1000
+	}
+
+	s := newSink("slogpub.MQTT", mqttPublisher{c, opts.QoS, opts.Retained}, &Options{
+		Topic:         opts.Topic,
+		SanitizeTopic: mqttSanitizer.Replace,
+		Sanitize:      opts.Sanitize,
+		BufferSize:    bufferSize,
+		Encoder:       opts.Encoder,
+		Timeout:       opts.Timeout,
+	})
+	s.nameSep = "/"
+	return s
+}
+
+var mqttSanitizer = strings.NewReplacer(
+	"/", "_",
+	"+", "_",
+	"#", "_",
+)
+
+type mqttPublisher struct {
+	c        MQTTClient
+	qos      byte
+	retained bool
+}
+
+func (p mqttPublisher) Publish(ctx context.Context, topic string, msg []byte) error {
+	return p.c.Publish(ctx, topic, p.qos, p.retained, msg)
+}
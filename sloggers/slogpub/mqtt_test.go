@@ -0,0 +1,66 @@
+package slogpub_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogpub"
+)
+
+func TestMQTT(t *testing.T) {
+	t.Parallel()
+
+	var topics []string
+	var qos []byte
+	offline := true
+	c := slogpub.MQTTClientFunc(func(ctx context.Context, topic string, q byte, retained bool, payload []byte) error {
+		if offline {
+			return io.EOF
+		}
+		topics = append(topics, topic)
+		qos = append(qos, q)
+		return nil
+	})
+
+	s := slogpub.MQTT(c, &slogpub.MQTTOptions{
+		Topic:      "devices/{device}/{logger}",
+		QoS:        1,
+		BufferSize: 2,
+	})
+	l := slog.Make(s).Named("a").Named("b")
+	l.Info(bg, "1", slog.F("device", "x/y"))
+	l.Info(bg, "2", slog.F("device", "x/y"))
+	l.Info(bg, "3", slog.F("device", "x/y"))
+
+	err := s.SyncErr()
+	assert.Error(t, "sync while offline", err)
+
+	// The oldest message was dropped.
+	offline = false
+	err = s.SyncErr()
+	assert.Success(t, "sync", err)
+	assert.Equal(t, "topics", []string{"devices/x_y/a/b", "devices/x_y/a/b"}, topics)
+	assert.Equal(t, "qos", []byte{1, 1}, qos)
+}
+
+func TestMQTT_Timeout(t *testing.T) {
+	t.Parallel()
+
+	c := slogpub.MQTTClientFunc(func(ctx context.Context, topic string, q byte, retained bool, payload []byte) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s := slogpub.MQTT(c, &slogpub.MQTTOptions{
+		Topic:      "logs",
+		BufferSize: -1,
+		Timeout:    10 * time.Millisecond,
+	})
+	err := s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,83 @@
+package slogpub
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cdr.dev/slog"
+)
+
+// NATSConn is implemented by *nats.Conn from github.com/nats-io/nats.go.
+type NATSConn interface {
+	Publish(subject string, data []byte) error
+	FlushWithContext(ctx context.Context) error
+}
+
+// NATSOptions represents the options for the sink returned by NATS.
+type NATSOptions struct {
+	// Subject is the subject template. See Options.Topic.
+	// e.g. "logs.{component}.{level}"
+	Subject string
+	// Encoder encodes the messages. Defaults to the slogjson format.
+	Encoder slog.Encoder
+	// Sanitize enables escaping or stripping CR, LF and NUL characters
+	// in entries. See Options.Sanitize.
+	Sanitize *slog.SanitizeOptions
+	// Timeout is the maximum time to flush the connection.
+	// See Options.Timeout.
+	Timeout time.Duration
+}
+
+// NATS creates a sink that publishes every entry with nc.
+//
+// Periods, spaces and wildcards in placeholder values are replaced with
+// underscores. Publishing only queues the message in the connection
+// and Sync flushes the connection.
+//
+// To publish to JetStream and wait for the ack, use Make with:
+//
+//	slogpub.PublisherFunc(func(ctx context.Context, subject string, msg []byte) error {
+//		_, err := js.Publish(subject, msg, nats.Context(ctx))
+//		return err
+//	})
+//
+// If opts is nil, the defaults are used.
+func NATS(nc NATSConn, opts *NATSOptions) slog.ErrorSink {
+	if opts == nil {
+		opts = &NATSOptions{}
+	}
+	return newSink("slogpub.NATS", natsPublisher{nc}, &Options{
+		Topic:         opts.Subject,
+		SanitizeTopic: natsSanitizer.Replace,
+		Sanitize:      opts.Sanitize,
+		Encoder:       opts.Encoder,
+		Timeout:       opts.Timeout,
+	})
+}
+
+var natsSanitizer = strings.NewReplacer(
+	".", "_",
+	" ", "_",
+	"\t", "_",
+	"*", "_",
+	">", "_",
+)
+
+type natsPublisher struct {
+	nc NATSConn
+}
+
+func (p natsPublisher) Publish(ctx context.Context, subject string, msg []byte) error {
+	// Publishing does not wait for the server so the
+	// context is only checked before the message is queued.
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+	return p.nc.Publish(subject, msg)
+}
+
+func (p natsPublisher) FlushWithContext(ctx context.Context) error {
+	return p.nc.FlushWithContext(ctx)
+}
@@ -0,0 +1,156 @@
+package slogpub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/trace"
+
+	"cdr.dev/slog"
+)
+
+// RedisConn is implemented by redis.Conn from github.com/gomodule/redigo.
+//
+// To adapt a client from github.com/go-redis/redis, use:
+//
+//	slogpub.RedisConnFunc(func(cmd string, args ...interface{}) (interface{}, error) {
+//		return rdb.Do(ctx, append([]interface{}{cmd}, args...)...).Result()
+//	})
+type RedisConn interface {
+	Do(cmd string, args ...interface{}) (reply interface{}, err error)
+}
+
+// RedisConnFunc implements RedisConn with a function.
+type RedisConnFunc func(cmd string, args ...interface{}) (interface{}, error)
+
+// Do implements RedisConn.
+func (f RedisConnFunc) Do(cmd string, args ...interface{}) (interface{}, error) {
+	return f(cmd, args...)
+}
+
+// RedisOptions represents the options for the sink returned by Redis.
+type RedisOptions struct {
+	// Stream is the template of the key of the stream.
+	// See Options.Topic. Defaults to "logs".
+	Stream string
+	// MaxLen trims the stream to about this many entries
+	// on every XADD. Disabled if zero.
+	MaxLen int64
+	// ExactMaxLen trims the stream to exactly MaxLen entries
+	// instead of letting Redis trim whole nodes, which is faster.
+	ExactMaxLen bool
+	// BufferSize is the number of entries buffered while Redis
+	// is unreachable. See Options.BufferSize.
+	BufferSize int
+}
+
+// Redis creates a sink that adds every entry to a Redis stream with XADD.
+//
+// The entry is flattened into the fields ts, level, msg, caller, func,
+// logger, trace, span and then the fields of the entry. Fields of
+// nested maps are joined with a period. e.g. "req.method"
+// Strings and errors are added as is and other values as JSON.
+//
+// Calls to c are serialized.
+//
+// If opts is nil, the defaults are used.
+func Redis(c RedisConn, opts *RedisOptions) slog.ErrorSink {
+	if opts == nil {
+		opts = &RedisOptions{}
+	}
+	stream := opts.Stream
+	if stream == "" {
+		stream = "logs"
+	}
+
+	p := &redisPublisher{
+		c: c,
+	}
+	if opts.MaxLen > 0 {
+		p.trim = []interface{}{"MAXLEN", "~", opts.MaxLen}
+		if opts.ExactMaxLen {
+			p.trim = []interface{}{"MAXLEN", opts.MaxLen}
+		}
+	}
+
+	s := newSink("slogpub.Redis", nil, &Options{
+		Topic:      stream,
+		BufferSize: opts.BufferSize,
+	})
+	s.send = p.send
+	s.flush = func(context.Context) error { return nil }
+	return s
+}
+
+type redisPublisher struct {
+	mu   sync.Mutex
+	c    RedisConn
+	trim []interface{}
+}
+
+func (p *redisPublisher) send(ctx context.Context, stream string, ent slog.SinkEntry) error {
+	args := make([]interface{}, 0, 2+len(p.trim)+16+2*len(ent.Fields))
+	args = append(args, stream)
+	args = append(args, p.trim...)
+	args = append(args, "*",
+		"ts", ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		"level", ent.Level.String(),
+		"msg", ent.Message,
+		"caller", ent.File+":"+strconv.Itoa(ent.Line),
+		"func", ent.Func,
+	)
+	if len(ent.LoggerNames) > 0 {
+		args = append(args, "logger", strings.Join(ent.LoggerNames, "."))
+	}
+	if ent.SpanContext != (trace.SpanContext{}) {
+		args = append(args,
+			"trace", ent.SpanContext.TraceID.String(),
+			"span", ent.SpanContext.SpanID.String(),
+		)
+	}
+	args = appendFlat(args, "", ent.Fields)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := p.c.Do("XADD", args...)
+	return err
+}
+
+// appendFlat appends the name and value of every field of m
+// with nested maps flattened.
+func appendFlat(args []interface{}, prefix string, m slog.Map) []interface{} {
+	for _, f := range m {
+		name := prefix + f.Name
+		switch v := f.Value.(type) {
+		case slog.Map:
+			args = appendFlat(args, name+".", v)
+		case string:
+			args = append(args, name, v)
+		case error:
+			args = append(args, name, v.Error())
+		default:
+			args = append(args, name, jsonValue(v))
+		}
+	}
+	return args
+}
+
+// jsonValue returns the JSON encoding of v as encoded in a slog.Map.
+// Strings are not quoted.
+func jsonValue(v interface{}) string {
+	b, err := json.Marshal(slog.M(slog.F("v", v)))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	b = b[len(`{"v":`) : len(b)-1]
+
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return s
+	}
+	return string(b)
+}
@@ -0,0 +1,59 @@
+package slogpub_test
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogpub"
+)
+
+func TestRedis(t *testing.T) {
+	t.Parallel()
+
+	var cmds [][]interface{}
+	c := slogpub.RedisConnFunc(func(cmd string, args ...interface{}) (interface{}, error) {
+		cmds = append(cmds, append([]interface{}{cmd}, args...))
+		return "1-0", nil
+	})
+
+	s := slogpub.Redis(c, &slogpub.RedisOptions{
+		Stream: "logs:{level}",
+		MaxLen: 1000,
+	})
+	err := s.LogEntryErr(bg, slog.SinkEntry{
+		Time:        time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+		Level:       slog.LevelWarn,
+		Message:     "hello",
+		LoggerNames: []string{"api"},
+		File:        "main.go",
+		Line:        42,
+		Func:        "main.main",
+		Fields: slog.M(
+			slog.F("req", slog.M(
+				slog.F("method", "GET"),
+				slog.F("size", 12),
+			)),
+			slog.Error(xerrors.New("boom")),
+			slog.F("tags", []string{"a"}),
+		),
+	})
+	assert.Success(t, "log entry", err)
+
+	assert.Equal(t, "cmds", [][]interface{}{{
+		"XADD", "logs:warn", "MAXLEN", "~", int64(1000), "*",
+		"ts", "2020-01-02T03:04:05.000006Z",
+		"level", "WARN",
+		"msg", "hello",
+		"caller", "main.go:42",
+		"func", "main.main",
+		"logger", "api",
+		"req.method", "GET",
+		"req.size", "12",
+		"error", "boom",
+		"tags", `["a"]`,
+	}}, cmds)
+}
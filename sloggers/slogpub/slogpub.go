@@ -0,0 +1,345 @@
+// Package slogpub contains sloggers that publish logs
+// to message brokers and streams.
+//
+// The broker clients are not dependencies of this package. Instead
+// every preset accepts a small interface that the client of the broker
+// already implements or that can be implemented with a PublisherFunc.
+package slogpub // import "cdr.dev/slog/sloggers/slogpub"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/exportctx"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+// Publisher publishes messages to a broker.
+type Publisher interface {
+	// Publish publishes msg to topic and returns after the broker has
+	// accepted it if the broker supports acknowledgments.
+	Publish(ctx context.Context, topic string, msg []byte) error
+}
+
+// PublisherFunc implements Publisher with a function.
+type PublisherFunc func(ctx context.Context, topic string, msg []byte) error
+
+// Publish implements Publisher.
+func (f PublisherFunc) Publish(ctx context.Context, topic string, msg []byte) error {
+	return f(ctx, topic, msg)
+}
+
+type flusher interface {
+	Flush() error
+}
+
+type contextFlusher interface {
+	FlushWithContext(ctx context.Context) error
+}
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Topic is the template of the topic of every entry.
+	//
+	// {level} is replaced with the lowercase level, {logger} with the
+	// logger names joined with a period, or a slash for MQTT, and any
+	// other name in braces with the value of the field with that name.
+	// e.g. "logs.{logger}.{level}"
+	Topic string
+	// Missing replaces placeholders without a value.
+	// Defaults to "none".
+	Missing string
+	// SanitizeTopic replaces the characters of placeholder values
+	// that are not allowed in topics. Defaults to none.
+	SanitizeTopic func(s string) string
+	// Sanitize enables escaping or stripping CR, LF and NUL characters
+	// in entries before they are encoded. See slog.SanitizeEntry.
+	// Even though JSON escapes them, consumers may not once decoded.
+	Sanitize *slog.SanitizeOptions
+	// BufferSize enables buffering up to this many messages
+	// that failed to publish, e.g. while the broker is offline.
+	// They are published again before the next message and on Sync.
+	// When the buffer is full, the oldest message is dropped.
+	BufferSize int
+	// Encoder encodes the messages. Defaults to the slogjson format.
+	Encoder slog.Encoder
+	// Timeout is the maximum time to publish a message so that a slow
+	// broker does not block the callers of LogEntry. It is the deadline
+	// of the context passed to the Publisher, which is also done with
+	// the context of the entry unless that is already done. It also
+	// bounds flushing the Publisher on Sync.
+	// Defaults to 5s. Set to a negative value to disable it.
+	Timeout time.Duration
+}
+
+// Make creates a sink that publishes every entry with p.
+//
+// If p implements FlushWithContext(ctx context.Context) error or
+// Flush() error, it is called by Sync.
+func Make(p Publisher, opts *Options) slog.ErrorSink {
+	return newSink("slogpub", p, opts)
+}
+
+func newSink(name string, p Publisher, opts *Options) *pubSink {
+	if opts == nil {
+		opts = &Options{}
+	}
+	enc := opts.Encoder
+	if enc == nil {
+		enc = slogjson.Encoder(nil)
+	}
+	s := &pubSink{
+		name:    name,
+		topic:   parseTopic(opts.Topic),
+		encoder: enc,
+		send: func(ctx context.Context, topic string, ent slog.SinkEntry) error {
+			return p.Publish(ctx, topic, enc.Encode(nil, ent))
+		},
+		flush: func(ctx context.Context) error {
+			switch f := p.(type) {
+			case contextFlusher:
+				return f.FlushWithContext(ctx)
+			case flusher:
+				return f.Flush()
+			}
+			return nil
+		},
+
+		missing:       opts.Missing,
+		sanitizeTopic: opts.SanitizeTopic,
+		sanitize:      opts.Sanitize,
+		nameSep:       ".",
+		bufferSize:    opts.BufferSize,
+		timeout:       opts.Timeout,
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+	if s.timeout == 0 {
+		s.timeout = 5 * time.Second
+	}
+	if s.missing == "" {
+		s.missing = "none"
+	}
+	if s.sanitizeTopic == nil {
+		s.sanitizeTopic = func(s string) string { return s }
+	}
+	return s
+}
+
+type pubSink struct {
+	name    string
+	encoder slog.Encoder
+	// send encodes and publishes an entry.
+	send func(ctx context.Context, topic string, ent slog.SinkEntry) error
+	// flush is called by Sync.
+	flush         func(ctx context.Context) error
+	topic         []topicPart
+	missing       string
+	sanitizeTopic func(string) string
+	sanitize      *slog.SanitizeOptions
+	// nameSep joins the logger names in topics.
+	nameSep string
+
+	bufferSize int
+	timeout    time.Duration
+	// mu is held while publishing so that entries are published in
+	// the order they were encoded. See slog.Encoder.
+	mu     sync.Mutex
+	buffer []message
+
+	errorf func(f string, v ...interface{})
+}
+
+// LogEntry implements slog.Sink.
+//
+// Failures are printed to stderr except for entries
+// not published while waiting to reconnect.
+func (s *pubSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	err := s.LogEntryErr(ctx, ent)
+	if err != nil && !xerrors.Is(err, errBackoff) {
+		s.errorf("%v: %+v", s.name, err)
+	}
+}
+
+// errBackoff is returned when an entry is not published because
+// the sink is waiting to reconnect.
+var errBackoff = xerrors.New("disconnected, waiting to reconnect")
+
+// LogEntryErr implements slog.ErrorSink.
+func (s *pubSink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	if s.sanitize != nil {
+		ent = slog.SanitizeEntry(ent, s.sanitize)
+	}
+	m := message{
+		topic: s.topicOf(ent),
+		ent:   ent,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bufferSize <= 0 {
+		return s.publish(ctx, m)
+	}
+
+	err := s.drainLocked(ctx)
+	if err == nil {
+		err = s.publish(ctx, m)
+	}
+	if err != nil {
+		s.buffer = append(s.buffer, m)
+		if len(s.buffer) > s.bufferSize {
+			s.buffer = s.buffer[1:]
+		}
+	}
+	return err
+}
+
+type message struct {
+	topic string
+	ent   slog.SinkEntry
+}
+
+func (s *pubSink) publish(ctx context.Context, m message) error {
+	ctx, cancel := exportctx.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	err := s.send(ctx, m.topic, m.ent)
+	if err != nil {
+		return xerrors.Errorf("failed to publish entry to %v: %w", m.topic, err)
+	}
+	return nil
+}
+
+// drainLocked publishes the buffered messages in order
+// until one fails.
+func (s *pubSink) drainLocked(ctx context.Context) error {
+	for len(s.buffer) > 0 {
+		err := s.publish(ctx, s.buffer[0])
+		if err != nil {
+			return err
+		}
+		s.buffer[0] = message{}
+		s.buffer = s.buffer[1:]
+	}
+	// Release the backing array.
+	s.buffer = nil
+	return nil
+}
+
+// Sync implements slog.Sink.
+func (s *pubSink) Sync() {
+	err := s.SyncErr()
+	if err != nil {
+		s.errorf("%v: %+v", s.name, err)
+	}
+}
+
+// SyncErr implements slog.ErrorSink.
+//
+// It publishes the buffered messages first.
+func (s *pubSink) SyncErr() error {
+	return s.Flush(context.Background())
+}
+
+// Flush implements slog.Flusher.
+//
+// Like SyncErr, but the buffered messages are published with ctx.
+func (s *pubSink) Flush(ctx context.Context) error {
+	if s.bufferSize > 0 {
+		s.mu.Lock()
+		err := s.drainLocked(ctx)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := exportctx.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	err := s.flush(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to flush: %w", err)
+	}
+	return nil
+}
+
+// topicPart is a literal or, if placeholder is set, the
+// name of a placeholder.
+type topicPart struct {
+	s           string
+	placeholder bool
+}
+
+func parseTopic(t string) []topicPart {
+	var parts []topicPart
+	for t != "" {
+		i := strings.IndexByte(t, '{')
+		j := -1
+		if i >= 0 {
+			j = strings.IndexByte(t[i+1:], '}')
+		}
+		if j < 0 {
+			parts = append(parts, topicPart{s: t})
+			break
+		}
+		j += i + 1
+		if i > 0 {
+			parts = append(parts, topicPart{s: t[:i]})
+		}
+		parts = append(parts, topicPart{s: t[i+1 : j], placeholder: true})
+		t = t[j+1:]
+	}
+	return parts
+}
+
+// topicOf returns the topic of ent.
+func (s *pubSink) topicOf(ent slog.SinkEntry) string {
+	return s.expand(s.topic, ent)
+}
+
+// expand returns the template parts with the placeholders
+// replaced with their values for ent.
+func (s *pubSink) expand(parts []topicPart, ent slog.SinkEntry) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		if !p.placeholder {
+			sb.WriteString(p.s)
+			continue
+		}
+
+		v := s.placeholder(p.s, ent)
+		if v == "" {
+			v = s.missing
+		}
+		sb.WriteString(v)
+	}
+	return sb.String()
+}
+
+func (s *pubSink) placeholder(name string, ent slog.SinkEntry) string {
+	switch name {
+	case "level":
+		return s.sanitizeTopic(strings.ToLower(ent.Level.String()))
+	case "logger":
+		names := make([]string, len(ent.LoggerNames))
+		for i, n := range ent.LoggerNames {
+			names[i] = s.sanitizeTopic(n)
+		}
+		return strings.Join(names, s.nameSep)
+	}
+
+	for i := len(ent.Fields) - 1; i >= 0; i-- {
+		f := ent.Fields[i]
+		if f.Name == name {
+			return s.sanitizeTopic(fmt.Sprint(f.Value))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package slogpub_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogpub"
+)
+
+var bg = context.Background()
+
+type message struct {
+	topic string
+	ent   slog.SinkEntry
+}
+
+type fakeNATS struct {
+	msgs    []message
+	flushes int
+	err     error
+	// block makes flushes wait until their context is done.
+	block bool
+}
+
+func (c *fakeNATS) Publish(subject string, data []byte) error {
+	if c.err != nil {
+		return c.err
+	}
+	var ent slog.SinkEntry
+	err := ent.UnmarshalJSON(data)
+	if err != nil {
+		return err
+	}
+	c.msgs = append(c.msgs, message{subject, ent})
+	return nil
+}
+
+func (c *fakeNATS) FlushWithContext(ctx context.Context) error {
+	c.flushes++
+	if c.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestNATS(t *testing.T) {
+	t.Parallel()
+
+	nc := &fakeNATS{}
+	s := slogpub.NATS(nc, &slogpub.NATSOptions{
+		Subject: "logs.{component}.{level}",
+	})
+	l := slog.Make(s)
+	l.Info(bg, "hello", slog.F("component", "api.v1"))
+	l.Warn(bg, "world")
+	l.Sync()
+
+	assert.Len(t, "msgs", 2, nc.msgs)
+	assert.Equal(t, "topic", "logs.api_v1.info", nc.msgs[0].topic)
+	assert.Equal(t, "msg", "hello", nc.msgs[0].ent.Message)
+	assert.Equal(t, "topic", "logs.none.warn", nc.msgs[1].topic)
+	assert.Equal(t, "flushes", 1, nc.flushes)
+
+	nc.err = io.EOF
+	err := s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.True(t, "EOF", xerrors.Is(err, io.EOF))
+}
+
+func TestNATS_Timeout(t *testing.T) {
+	t.Parallel()
+
+	nc := &fakeNATS{block: true}
+	s := slogpub.NATS(nc, &slogpub.NATSOptions{
+		Subject: "logs",
+		Timeout: 10 * time.Millisecond,
+	})
+	err := s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.Success(t, "log entry", err)
+	err = s.SyncErr()
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, "flushes", 1, nc.flushes)
+}
+
+func TestMake(t *testing.T) {
+	t.Parallel()
+
+	var topics []string
+	s := slogpub.Make(slogpub.PublisherFunc(func(ctx context.Context, topic string, msg []byte) error {
+		topics = append(topics, topic)
+		return nil
+	}), &slogpub.Options{
+		Topic:   "{logger}/{user}/{",
+		Missing: "-",
+	})
+
+	l := slog.Make(s).Named("a").Named("b")
+	l.Info(bg, "hello", slog.F("user", 1), slog.F("user", 2))
+	l.Info(bg, "hello")
+
+	assert.Equal(t, "topics", []string{"a.b/2/{", "a.b/-/{"}, topics)
+}
+
+func TestMake_Timeout(t *testing.T) {
+	t.Parallel()
+
+	var done int
+	var deadline time.Time
+	s := slogpub.Make(slogpub.PublisherFunc(func(ctx context.Context, topic string, msg []byte) error {
+		if ctx.Err() != nil {
+			done++
+		}
+		deadline, _ = ctx.Deadline()
+		<-ctx.Done()
+		return ctx.Err()
+	}), &slogpub.Options{
+		Timeout: 10 * time.Millisecond,
+	})
+
+	err := s.LogEntryErr(bg, slog.SinkEntry{})
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+
+	// Entries logged with a done context are still published.
+	ctx, cancel := context.WithCancel(bg)
+	cancel()
+	err = s.LogEntryErr(ctx, slog.SinkEntry{})
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, "done", 0, done)
+
+	// The earlier deadline of the caller is used.
+	ctx, cancel = context.WithTimeout(bg, time.Millisecond)
+	defer cancel()
+	callerDeadline, _ := ctx.Deadline()
+	err = s.LogEntryErr(ctx, slog.SinkEntry{})
+	assert.True(t, "deadline", xerrors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, "caller deadline", callerDeadline, deadline)
+}
+
+func TestMake_Sanitize(t *testing.T) {
+	t.Parallel()
+
+	var topics, msgs []string
+	s := slogpub.Make(slogpub.PublisherFunc(func(ctx context.Context, topic string, msg []byte) error {
+		topics = append(topics, topic)
+		msgs = append(msgs, string(msg))
+		return nil
+	}), &slogpub.Options{
+		Topic:         "logs.{user}",
+		SanitizeTopic: strings.NewReplacer(".", "_").Replace,
+		Sanitize:      &slog.SanitizeOptions{},
+		Encoder: slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+			return append(buf, ent.Message...)
+		}),
+	})
+
+	slog.Make(s).Info(bg, "a\nb", slog.F("user", "j.doe"))
+	assert.Equal(t, "topics", []string{"logs.j_doe"}, topics)
+	assert.Equal(t, "msgs", []string{`a\nb`}, msgs)
+}
+
+func TestMake_Encoder(t *testing.T) {
+	t.Parallel()
+
+	var msgs []string
+	s := slogpub.Make(slogpub.PublisherFunc(func(ctx context.Context, topic string, msg []byte) error {
+		msgs = append(msgs, string(msg))
+		return nil
+	}), &slogpub.Options{
+		Encoder: slog.EncoderFunc(func(buf []byte, ent slog.SinkEntry) []byte {
+			return append(buf, ent.Message...)
+		}),
+	})
+
+	slog.Make(s).Info(bg, "hello")
+	assert.Equal(t, "msgs", []string{"hello"}, msgs)
+}
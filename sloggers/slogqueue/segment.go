@@ -0,0 +1,179 @@
+package slogqueue
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Every segment file is named by the sequence number of its first
+// record in hex with the .seg extension. Every record is the length of
+// its data and then the CRC-32C of its data as little endian uint32s,
+// followed by the data, the binary encoding of the entry.
+//
+// The ack file contains the ID of the queue, the sequence number of the
+// next entry to deliver as a little endian uint64 and the CRC-32C of
+// both. It is replaced atomically.
+const (
+	segmentExt       = ".seg"
+	recordHeaderSize = 8
+	maxRecordSize    = 64 << 20
+
+	ackFile = "ack"
+	idSize  = 8
+	ackSize = idSize + 8 + 4
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// errCorrupt is returned for records with an invalid length or CRC.
+var errCorrupt = xerrors.New("corrupt record")
+
+func appendRecord(b, data []byte) []byte {
+	var hdr [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(hdr[:4], uint32(len(data)))
+	binary.LittleEndian.PutUint32(hdr[4:], crc32.Checksum(data, crcTable))
+	b = append(b, hdr[:]...)
+	return append(b, data...)
+}
+
+// readRecord reads the data of the next record. It returns io.EOF at the
+// end of r and errCorrupt if the record is incomplete or invalid.
+func readRecord(r io.Reader) ([]byte, error) {
+	var hdr [recordHeaderSize]byte
+	_, err := io.ReadFull(r, hdr[:])
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err == io.ErrUnexpectedEOF {
+		return nil, errCorrupt
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	n := binary.LittleEndian.Uint32(hdr[:4])
+	if n > maxRecordSize {
+		return nil, errCorrupt
+	}
+	data := make([]byte, n)
+	_, err = io.ReadFull(r, data)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, errCorrupt
+	}
+	if err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(data, crcTable) != binary.LittleEndian.Uint32(hdr[4:]) {
+		return nil, errCorrupt
+	}
+	return data, nil
+}
+
+// segment is a segment file of the queue.
+type segment struct {
+	// base is the sequence number of the first record.
+	base uint64
+	size int64
+}
+
+func segmentPath(dir string, base uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%016x", base)+segmentExt)
+}
+
+// listSegments returns the segments in dir sorted by their base.
+// Their sizes are not set.
+func listSegments(dir string) ([]segment, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read queue directory: %w", err)
+	}
+	var segments []segment
+	for _, fi := range infos {
+		name := fi.Name()
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		base, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 16, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{base: base, size: fi.Size()})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].base < segments[j].base
+	})
+	return segments, nil
+}
+
+// scanSegment returns the number of valid records at the start of the
+// segment at path and their size.
+func scanSegment(path string) (n uint64, size int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("failed to open segment: %w", err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	for {
+		data, err := readRecord(br)
+		if err == io.EOF || err == errCorrupt {
+			return n, size, nil
+		}
+		if err != nil {
+			return 0, 0, xerrors.Errorf("failed to read segment: %w", err)
+		}
+		n++
+		size += int64(recordHeaderSize + len(data))
+	}
+}
+
+// readAck reads the ack file in dir. ok is false if it does not exist
+// or is invalid.
+func readAck(dir string) (id string, ack uint64, ok bool, err error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, ackFile))
+	if os.IsNotExist(err) {
+		return "", 0, false, nil
+	}
+	if err != nil {
+		return "", 0, false, xerrors.Errorf("failed to read ack: %w", err)
+	}
+	if len(b) != ackSize || crc32.Checksum(b[:ackSize-4], crcTable) != binary.LittleEndian.Uint32(b[ackSize-4:]) {
+		return "", 0, false, nil
+	}
+	return hex.EncodeToString(b[:idSize]), binary.LittleEndian.Uint64(b[idSize:]), true, nil
+}
+
+// writeAck replaces the ack file in dir.
+func writeAck(dir, id string, ack uint64) error {
+	b := make([]byte, ackSize)
+	_, err := hex.Decode(b[:idSize], []byte(id))
+	if err != nil {
+		return xerrors.Errorf("invalid queue id %q: %w", id, err)
+	}
+	binary.LittleEndian.PutUint64(b[idSize:], ack)
+	binary.LittleEndian.PutUint32(b[ackSize-4:], crc32.Checksum(b[:ackSize-4], crcTable))
+
+	tmp := filepath.Join(dir, ackFile+".tmp")
+	err = ioutil.WriteFile(tmp, b, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to write ack: %w", err)
+	}
+	err = os.Rename(tmp, filepath.Join(dir, ackFile))
+	if err != nil {
+		return xerrors.Errorf("failed to replace ack: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,677 @@
+// Package slogqueue contains a sink that queues entries in segment files
+// on disk before they are delivered to another sink, usually a network
+// exporter, so that they survive crashes of the process and outages of
+// the backend:
+//
+//	q, err := slogqueue.Open("/var/lib/app/logs", sloghttp.Make(url, nil), nil)
+//	...
+//	defer q.Close()
+//	log := slog.Make(q)
+//
+// Entries are delivered at least once. A batch of entries is only
+// acknowledged once the sink is flushed without an error, so entries are
+// delivered again after a failure or a crash during delivery. Every
+// entry has a dedup_key field that is unique per entry and stays the
+// same when it is delivered again so that the backend can drop the
+// duplicates.
+package slogqueue // import "cdr.dev/slog/sloggers/slogqueue"
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// Options represents the options for the queue returned by Open.
+type Options struct {
+	// SegmentSize is the size in bytes after which a new segment file
+	// is started. Segments are deleted once all their entries have been
+	// delivered. Defaults to 16 MiB.
+	SegmentSize int64
+	// MaxSize is the maximum size in bytes of the segments. When it is
+	// exceeded, the oldest segment is deleted even if its entries have
+	// not been delivered. Defaults to 1 GiB.
+	MaxSize int64
+	// BatchSize is the maximum number of entries delivered before the
+	// sink is flushed and they are acknowledged. Defaults to 100.
+	BatchSize int
+	// FlushTimeout is the maximum time to flush the sink after a batch.
+	// Defaults to 30s.
+	FlushTimeout time.Duration
+	// MinBackoff is the time to wait before delivering a batch again
+	// after the first failure. It is doubled on every consecutive
+	// failure. Defaults to 100ms.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum time to wait before delivering a batch
+	// again. Defaults to 30s.
+	MaxBackoff time.Duration
+	// DedupField is the name of the field of the dedup key of the
+	// entries, the ID of the queue and the sequence number of the entry.
+	// Defaults to "dedup_key".
+	DedupField string
+	// OmitDedupKey omits the dedup key from the delivered entries.
+	OmitDedupKey bool
+}
+
+func (opts *Options) withDefaults() *Options {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.SegmentSize <= 0 {
+		o.SegmentSize = 16 << 20
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = 1 << 30
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.FlushTimeout <= 0 {
+		o.FlushTimeout = 30 * time.Second
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 100 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 30 * time.Second
+	}
+	if o.DedupField == "" {
+		o.DedupField = "dedup_key"
+	}
+	return &o
+}
+
+// Queue queues entries on disk and delivers them to a sink.
+//
+// See Open.
+type Queue struct {
+	// dropped is first for 64 bit alignment.
+	dropped uint64
+
+	dir  string
+	id   string
+	sink slog.Sink
+	opts *Options
+
+	mu sync.Mutex
+	// segments are sorted by their base. The last one is written to.
+	segments []segment
+	f        *os.File
+	// next is the sequence number of the next entry written
+	// and ack that of the next entry delivered.
+	next uint64
+	ack  uint64
+	// acked is closed and replaced when ack advances.
+	acked  chan struct{}
+	closed bool
+
+	notify chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	errorf func(f string, v ...interface{})
+}
+
+var _ slog.ErrorSink = &Queue{}
+
+// Open opens the queue in dir, creating it if necessary, and starts
+// delivering its entries to sink in a background goroutine, beginning
+// with the entries not delivered before the queue was last closed or the
+// process crashed. Only one Queue may use dir at a time.
+//
+// If opts is nil, the defaults are used.
+func Open(dir string, sink slog.Sink, opts *Options) (*Queue, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create queue directory: %w", err)
+	}
+
+	id, ack, ok, err := readAck(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		var b [idSize]byte
+		_, err = io.ReadFull(rand.Reader, b[:])
+		if err != nil {
+			return nil, xerrors.Errorf("failed to generate queue id: %w", err)
+		}
+		id = hex.EncodeToString(b[:])
+	}
+
+	q := &Queue{
+		dir:    dir,
+		id:     id,
+		sink:   sink,
+		opts:   opts.withDefaults(),
+		acked:  make(chan struct{}),
+		notify: make(chan struct{}, 1),
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+	err = q.recover(ack)
+	if err != nil {
+		return nil, err
+	}
+	err = writeAck(dir, q.id, q.ack)
+	if err != nil {
+		q.f.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+	q.wg.Add(1)
+	go q.deliverLoop(ctx)
+	return q, nil
+}
+
+// recover restores the segments in the directory, truncates a record of
+// the last segment that was not completely written and opens the last
+// segment for writing.
+func (q *Queue) recover(ack uint64) error {
+	segments, err := listSegments(q.dir)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		q.ack, q.next = ack, ack
+		return q.createSegmentLocked()
+	}
+
+	last := &segments[len(segments)-1]
+	path := segmentPath(q.dir, last.base)
+	n, size, err := scanSegment(path)
+	if err != nil {
+		return err
+	}
+	if size != last.size {
+		err = os.Truncate(path, size)
+		if err != nil {
+			return xerrors.Errorf("failed to truncate segment: %w", err)
+		}
+		last.size = size
+	}
+	q.f, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to open segment: %w", err)
+	}
+
+	q.segments = segments
+	q.next = last.base + n
+	q.ack = ack
+	if q.ack < segments[0].base {
+		q.ack = segments[0].base
+	}
+	if q.ack > q.next {
+		q.ack = q.next
+	}
+	return nil
+}
+
+// createSegmentLocked starts a new segment at q.next.
+func (q *Queue) createSegmentLocked() error {
+	f, err := os.OpenFile(segmentPath(q.dir, q.next), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return xerrors.Errorf("failed to create segment: %w", err)
+	}
+	if q.f != nil {
+		err = q.f.Close()
+		if err != nil {
+			q.errorf("slogqueue: failed to close segment: %+v", err)
+		}
+	}
+	q.f = f
+	q.segments = append(q.segments, segment{base: q.next})
+	return nil
+}
+
+// LogEntry implements slog.Sink.
+//
+// Failures are printed to stderr.
+func (q *Queue) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	err := q.LogEntryErr(ctx, ent)
+	if err != nil {
+		q.errorf("slogqueue: %+v", err)
+	}
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// The entry is written to the current segment before it returns so that
+// it survives a crash of the process. Use Sync to also write it to disk.
+func (q *Queue) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	data, err := ent.MarshalBinary()
+	if err != nil {
+		return xerrors.Errorf("failed to encode entry: %w", err)
+	}
+	if len(data) > maxRecordSize {
+		return xerrors.Errorf("entry of %v bytes exceeds the maximum of %v", len(data), maxRecordSize)
+	}
+	rec := appendRecord(nil, data)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return xerrors.New("queue is closed")
+	}
+
+	cur := &q.segments[len(q.segments)-1]
+	if cur.size > 0 && cur.size+int64(len(rec)) > q.opts.SegmentSize {
+		err = q.createSegmentLocked()
+		if err != nil {
+			return err
+		}
+		cur = &q.segments[len(q.segments)-1]
+	}
+
+	_, err = q.f.Write(rec)
+	if err != nil {
+		// Remove a partially written record.
+		_ = q.f.Truncate(cur.size)
+		return xerrors.Errorf("failed to write entry: %w", err)
+	}
+	cur.size += int64(len(rec))
+	q.next++
+	q.trimLocked()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// trimLocked deletes the oldest segments while the segments
+// exceed opts.MaxSize.
+func (q *Queue) trimLocked() {
+	var total int64
+	for _, s := range q.segments {
+		total += s.size
+	}
+	for total > q.opts.MaxSize && len(q.segments) > 1 {
+		oldest := q.segments[0]
+		err := os.Remove(segmentPath(q.dir, oldest.base))
+		if err != nil {
+			q.errorf("slogqueue: failed to delete segment: %+v", err)
+		}
+		total -= oldest.size
+		q.segments = q.segments[1:]
+
+		if base := q.segments[0].base; q.ack < base {
+			atomic.AddUint64(&q.dropped, base-q.ack)
+			q.advanceLocked(base)
+		}
+	}
+}
+
+// advanceLocked sets ack and wakes up the callers of Flush.
+func (q *Queue) advanceLocked(ack uint64) {
+	q.ack = ack
+	close(q.acked)
+	q.acked = make(chan struct{})
+}
+
+// deleteAckedLocked deletes the segments whose entries have all been
+// delivered.
+func (q *Queue) deleteAckedLocked() {
+	for len(q.segments) > 1 && q.segments[1].base <= q.ack {
+		err := os.Remove(segmentPath(q.dir, q.segments[0].base))
+		if err != nil && !os.IsNotExist(err) {
+			q.errorf("slogqueue: failed to delete segment: %+v", err)
+		}
+		q.segments = q.segments[1:]
+	}
+}
+
+func (q *Queue) deliverLoop(ctx context.Context) {
+	defer q.wg.Done()
+
+	r := &segmentReader{q: q}
+	defer r.close()
+
+	var failures uint
+	for {
+		q.mu.Lock()
+		pending := q.ack < q.next
+		q.mu.Unlock()
+		if !pending {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.notify:
+				continue
+			}
+		}
+
+		err := q.deliver(ctx, r)
+		if err == nil {
+			failures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		q.errorf("slogqueue: failed to deliver entries: %+v", err)
+		// Deliver the batch again from its first entry.
+		r.close()
+		r.positioned = false
+
+		backoff := q.opts.MinBackoff << failures
+		if backoff > q.opts.MaxBackoff || backoff <= 0 {
+			backoff = q.opts.MaxBackoff
+		} else {
+			failures++
+		}
+		t := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// deliver delivers the next batch of entries and acknowledges them.
+func (q *Queue) deliver(ctx context.Context, r *segmentReader) error {
+	es, isErrorSink := q.sink.(slog.ErrorSink)
+	delivered := false
+	for i := 0; i < q.opts.BatchSize; i++ {
+		ent, seq, ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		delivered = true
+
+		if !q.opts.OmitDedupKey {
+			// The entry is decoded from its record so its fields are not shared.
+			ent.Fields = append(ent.Fields, slog.F(q.opts.DedupField, q.id+"-"+strconv.FormatUint(seq, 10)))
+		}
+		if isErrorSink {
+			err = es.LogEntryErr(ctx, ent)
+			if err != nil {
+				return xerrors.Errorf("failed to deliver entry: %w", err)
+			}
+		} else {
+			q.sink.LogEntry(ctx, ent)
+		}
+	}
+	if !delivered {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		// Entries may have been dropped while reading.
+		if r.seq > q.ack {
+			q.advanceLocked(r.seq)
+		}
+		return nil
+	}
+
+	flushCtx, cancel := context.WithTimeout(ctx, q.opts.FlushTimeout)
+	defer cancel()
+	err := slog.Flush(flushCtx, q.sink)
+	if err != nil {
+		return xerrors.Errorf("failed to flush sink: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if r.seq > q.ack {
+		q.advanceLocked(r.seq)
+	}
+	q.deleteAckedLocked()
+	return writeAck(q.dir, q.id, q.ack)
+}
+
+// segmentReader reads the entries of the queue for delivery.
+type segmentReader struct {
+	q *Queue
+	// positioned is false if the reader must start at q.ack.
+	positioned bool
+	// f is nil if the segment of seq must be opened.
+	f  *os.File
+	br *bufio.Reader
+	// seq is the sequence number of the next entry
+	// and base that of the first entry of f.
+	seq  uint64
+	base uint64
+}
+
+func (r *segmentReader) close() {
+	if r.f != nil {
+		r.f.Close()
+		r.f = nil
+	}
+}
+
+// next reads the next entry. ok is false if there is none.
+func (r *segmentReader) next() (ent slog.SinkEntry, seq uint64, ok bool, err error) {
+	for {
+		r.q.mu.Lock()
+		if !r.positioned {
+			r.seq = r.q.ack
+			r.positioned = true
+		}
+		if len(r.q.segments) > 0 && r.seq < r.q.segments[0].base {
+			// The segment was deleted to stay within MaxSize.
+			atomic.AddUint64(&r.q.dropped, r.q.segments[0].base-r.seq)
+			r.seq = r.q.segments[0].base
+			r.close()
+		}
+		if r.seq >= r.q.next {
+			r.q.mu.Unlock()
+			return slog.SinkEntry{}, 0, false, nil
+		}
+		// Find the segment of the next entry.
+		i := len(r.q.segments) - 1
+		for i > 0 && r.q.segments[i].base > r.seq {
+			i--
+		}
+		s := r.q.segments[i]
+		r.q.mu.Unlock()
+
+		if r.f != nil && r.base != s.base {
+			r.close()
+		}
+		var data []byte
+		if r.f == nil {
+			err = r.open(s.base)
+		}
+		if err == nil {
+			data, err = readRecord(r.br)
+		}
+		if err == io.EOF {
+			err = errCorrupt
+		}
+		if xerrors.Is(err, errCorrupt) {
+			err = r.skipSegment(s.base)
+			if err != nil {
+				return slog.SinkEntry{}, 0, false, err
+			}
+			continue
+		}
+		if err != nil {
+			return slog.SinkEntry{}, 0, false, xerrors.Errorf("failed to read segment: %w", err)
+		}
+
+		seq = r.seq
+		r.seq++
+		err = ent.UnmarshalBinary(data)
+		if err != nil {
+			r.q.errorf("slogqueue: skipping entry %v that cannot be decoded: %+v", seq, err)
+			atomic.AddUint64(&r.q.dropped, 1)
+			continue
+		}
+		return ent, seq, true, nil
+	}
+}
+
+// skipSegment skips the rest of the corrupt segment at base.
+func (r *segmentReader) skipSegment(base uint64) error {
+	r.close()
+
+	r.q.mu.Lock()
+	defer r.q.mu.Unlock()
+
+	var end uint64
+	for i, s := range r.q.segments {
+		if s.base != base {
+			continue
+		}
+		if i+1 < len(r.q.segments) {
+			end = r.q.segments[i+1].base
+			break
+		}
+		// Later entries are written to a new segment
+		// as they cannot be found after the corruption.
+		end = r.q.next
+		err := r.q.createSegmentLocked()
+		if err != nil {
+			return err
+		}
+	}
+	if end > r.seq {
+		r.q.errorf("slogqueue: skipping %v entries of corrupt segment %v", end-r.seq, segmentPath(r.q.dir, base))
+		atomic.AddUint64(&r.q.dropped, end-r.seq)
+		r.seq = end
+	}
+	return nil
+}
+
+// open opens the segment at base and skips to r.seq.
+// It returns errCorrupt if a skipped record is corrupt.
+func (r *segmentReader) open(base uint64) error {
+	f, err := os.Open(segmentPath(r.q.dir, base))
+	if err != nil {
+		return xerrors.Errorf("failed to open segment: %w", err)
+	}
+	r.f = f
+	r.br = bufio.NewReader(f)
+	r.base = base
+	for seq := base; seq < r.seq; seq++ {
+		_, err = readRecord(r.br)
+		if err == io.EOF {
+			err = errCorrupt
+		}
+		if err != nil {
+			r.close()
+			return xerrors.Errorf("failed to skip to entry %v: %w", r.seq, err)
+		}
+	}
+	return nil
+}
+
+// Sync implements slog.Sink.
+func (q *Queue) Sync() {
+	err := q.SyncErr()
+	if err != nil {
+		q.errorf("slogqueue: %+v", err)
+	}
+}
+
+// SyncErr implements slog.ErrorSink.
+//
+// It writes the current segment to disk so that its entries also
+// survive a crash of the machine. It does not wait for the entries to
+// be delivered, see Flush.
+func (q *Queue) SyncErr() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil
+	}
+	err := q.f.Sync()
+	if err != nil {
+		return xerrors.Errorf("failed to sync segment: %w", err)
+	}
+	return nil
+}
+
+// Flush implements slog.Flusher.
+//
+// It waits until the entries logged before it have been delivered
+// or ctx is done.
+func (q *Queue) Flush(ctx context.Context) error {
+	q.mu.Lock()
+	target := q.next
+	for q.ack < target {
+		if q.closed {
+			pending := target - q.ack
+			q.mu.Unlock()
+			return xerrors.Errorf("queue closed with %v entries pending", pending)
+		}
+		acked := q.acked
+		q.mu.Unlock()
+
+		select {
+		case <-acked:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		q.mu.Lock()
+	}
+	q.mu.Unlock()
+	return nil
+}
+
+// Pending returns the number of entries that have not been delivered.
+func (q *Queue) Pending() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.next - q.ack
+}
+
+// Dropped returns the number of entries that were dropped before
+// they were delivered because the queue exceeded MaxSize or they
+// were corrupt.
+func (q *Queue) Dropped() uint64 {
+	return atomic.LoadUint64(&q.dropped)
+}
+
+// Close stops delivering entries, canceling the delivery of the current
+// batch, and closes the queue. The entries that have not been delivered
+// are delivered after the queue is opened again. Call Flush first to
+// deliver them.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	// Wake up the callers of Flush.
+	q.advanceLocked(q.ack)
+	q.mu.Unlock()
+
+	q.cancel()
+	q.wg.Wait()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	err := q.f.Sync()
+	if err == nil {
+		err = q.f.Close()
+	}
+	if err != nil {
+		return xerrors.Errorf("failed to close segment: %w", err)
+	}
+	return writeAck(q.dir, q.id, q.ack)
+}
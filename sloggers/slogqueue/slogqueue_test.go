@@ -0,0 +1,292 @@
+package slogqueue_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogqueue"
+)
+
+var bg = context.Background()
+
+// sink records the delivered entries. It fails to log and flush
+// while fail is set and only to flush while failFlush is set.
+type sink struct {
+	mu        sync.Mutex
+	entries   []slog.SinkEntry
+	fail      bool
+	failFlush bool
+	flushes   int
+}
+
+func (s *sink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	s.LogEntryErr(ctx, ent)
+}
+
+func (s *sink) LogEntryErr(_ context.Context, ent slog.SinkEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return xerrors.New("backend unavailable")
+	}
+	s.entries = append(s.entries, ent)
+	return nil
+}
+
+func (s *sink) Sync() {}
+
+func (s *sink) SyncErr() error {
+	return nil
+}
+
+func (s *sink) Flush(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flushes++
+	if s.fail || s.failFlush {
+		return xerrors.New("backend unavailable")
+	}
+	return nil
+}
+
+func (s *sink) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+func (s *sink) setFailFlush(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failFlush = fail
+}
+
+func (s *sink) flushCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flushes
+}
+
+// field returns the value of a field of the i-th delivered entry
+// formatted with fmt.Sprint.
+func (s *sink) field(i int, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.entries[i].Fields.Get(name)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// messages returns the messages and dedup keys of the delivered entries.
+func (s *sink) messages() (msgs, keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ent := range s.entries {
+		msgs = append(msgs, ent.Message)
+		key, _ := ent.Fields.Get("dedup_key")
+		k, _ := key.(string)
+		keys = append(keys, k)
+	}
+	return msgs, keys
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "slogqueue")
+	assert.Success(t, "temp dir", err)
+	return dir
+}
+
+func flush(t *testing.T, q *slogqueue.Queue) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(bg, 10*time.Second)
+	defer cancel()
+	assert.Success(t, "flush", q.Flush(ctx))
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := &sink{}
+	q, err := slogqueue.Open(dir, s, &slogqueue.Options{
+		BatchSize: 2,
+	})
+	assert.Success(t, "open", err)
+	l := slog.Make(q)
+	l.Info(bg, "a", slog.F("i", 1))
+	l.Info(bg, "b")
+	l.Info(bg, "c")
+	flush(t, q)
+
+	msgs, keys := s.messages()
+	assert.Equal(t, "msgs", []string{"a", "b", "c"}, msgs)
+	assert.Equal(t, "pending", uint64(0), q.Pending())
+	assert.Equal(t, "field", "1", s.field(0, "i"))
+	for i, key := range keys {
+		assert.True(t, "dedup key", strings.HasSuffix(key, fmt.Sprintf("-%v", i)))
+	}
+	assert.Success(t, "close", q.Close())
+	assert.Error(t, "log after close", q.LogEntryErr(bg, slog.SinkEntry{Message: "d"}))
+
+	// The ID of the queue is kept and the
+	// entries are not delivered again.
+	s2 := &sink{}
+	q, err = slogqueue.Open(dir, s2, nil)
+	assert.Success(t, "reopen", err)
+	l = slog.Make(q)
+	l.Info(bg, "d")
+	flush(t, q)
+	assert.Success(t, "close", q.Close())
+	msgs, keys2 := s2.messages()
+	assert.Equal(t, "msgs", []string{"d"}, msgs)
+	assert.Equal(t, "dedup key", strings.TrimSuffix(keys[0], "0")+"3", keys2[0])
+}
+
+func TestQueue_Redeliver(t *testing.T) {
+	t.Parallel()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := &sink{fail: true}
+	q, err := slogqueue.Open(dir, s, &slogqueue.Options{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 10 * time.Millisecond,
+	})
+	assert.Success(t, "open", err)
+	l := slog.Make(q)
+	l.Info(bg, "a")
+	l.Info(bg, "b")
+
+	ctx, cancel := context.WithTimeout(bg, 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, "flush while failing", context.DeadlineExceeded, q.Flush(ctx))
+	assert.Equal(t, "pending", uint64(2), q.Pending())
+
+	s.setFail(false)
+	flush(t, q)
+	msgs, keys := s.messages()
+	assert.Equal(t, "msgs", []string{"a", "b"}, msgs)
+	assert.Success(t, "close", q.Close())
+
+	// Entries delivered before a failed flush are delivered
+	// again with the same dedup keys.
+	s2 := &sink{}
+	q, err = slogqueue.Open(dir, s2, &slogqueue.Options{
+		MinBackoff: time.Millisecond,
+	})
+	assert.Success(t, "reopen", err)
+	s2.setFailFlush(true)
+	q.LogEntry(bg, slog.SinkEntry{Message: "c"})
+	for s2.flushCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	s2.setFailFlush(false)
+	flush(t, q)
+	assert.Success(t, "close", q.Close())
+	msgs, keys2 := s2.messages()
+	assert.True(t, "delivered again", len(msgs) >= 2)
+	assert.Equal(t, "msgs", "c", msgs[len(msgs)-1])
+	assert.Equal(t, "dedup key", strings.TrimSuffix(keys[0], "0")+"2", keys2[0])
+	assert.Equal(t, "same dedup key", keys2[0], keys2[len(keys2)-1])
+}
+
+func TestQueue_Persist(t *testing.T) {
+	t.Parallel()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := &sink{fail: true}
+	q, err := slogqueue.Open(dir, s, &slogqueue.Options{
+		SegmentSize: 200,
+	})
+	assert.Success(t, "open", err)
+	for i := 0; i < 10; i++ {
+		q.LogEntry(bg, slog.SinkEntry{Message: "entry", Fields: slog.M(slog.F("i", i))})
+	}
+	assert.Success(t, "sync", q.SyncErr())
+	assert.Success(t, "close", q.Close())
+	assert.Error(t, "flush after close", q.Flush(bg))
+
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	assert.Success(t, "glob", err)
+	assert.True(t, "segments", len(segments) > 1)
+
+	// Simulate a crash while the last record was written.
+	last := segments[len(segments)-1]
+	fi, err := os.Stat(last)
+	assert.Success(t, "stat", err)
+	err = os.Truncate(last, fi.Size()-3)
+	assert.Success(t, "truncate", err)
+
+	s2 := &sink{}
+	q, err = slogqueue.Open(dir, s2, nil)
+	assert.Success(t, "reopen", err)
+	assert.Equal(t, "pending", uint64(9), q.Pending())
+	q.LogEntry(bg, slog.SinkEntry{Message: "after"})
+	flush(t, q)
+	assert.Success(t, "close", q.Close())
+
+	msgs, _ := s2.messages()
+	assert.Equal(t, "delivered", 10, len(msgs))
+	assert.Equal(t, "last", "after", msgs[9])
+	for i := 0; i < 9; i++ {
+		assert.Equal(t, "i", fmt.Sprint(i), s2.field(i, "i"))
+	}
+
+	segments, err = filepath.Glob(filepath.Join(dir, "*.seg"))
+	assert.Success(t, "glob", err)
+	assert.Equal(t, "acked segments deleted", 1, len(segments))
+}
+
+func TestQueue_MaxSize(t *testing.T) {
+	t.Parallel()
+
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	s := &sink{fail: true}
+	q, err := slogqueue.Open(dir, s, &slogqueue.Options{
+		SegmentSize:  200,
+		MaxSize:      400,
+		OmitDedupKey: true,
+	})
+	assert.Success(t, "open", err)
+	for i := 0; i < 50; i++ {
+		q.LogEntry(bg, slog.SinkEntry{Message: "entry", Fields: slog.M(slog.F("i", i))})
+	}
+	dropped := q.Dropped()
+	assert.True(t, "dropped", dropped > 0)
+	assert.Equal(t, "pending", 50-dropped, q.Pending())
+	assert.Success(t, "close", q.Close())
+
+	s2 := &sink{}
+	q, err = slogqueue.Open(dir, s2, &slogqueue.Options{
+		OmitDedupKey: true,
+	})
+	assert.Success(t, "reopen", err)
+	flush(t, q)
+	assert.Success(t, "close", q.Close())
+
+	msgs, _ := s2.messages()
+	assert.Equal(t, "delivered", 50-int(dropped), len(msgs))
+	assert.Equal(t, "newest kept", "49", s2.field(len(msgs)-1, "i"))
+	assert.Equal(t, "dedup key", "", s2.field(0, "dedup_key"))
+}
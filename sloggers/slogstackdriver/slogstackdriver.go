@@ -2,10 +2,10 @@
 package slogstackdriver // import "cdr.dev/slog/sloggers/slogstackdriver"
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 
 	"cloud.google.com/go/compute/metadata"
@@ -14,7 +14,6 @@ import (
 	logpb "google.golang.org/genproto/googleapis/logging/v2"
 
 	"cdr.dev/slog"
-	"cdr.dev/slog/internal/syncwriter"
 )
 
 // Sink creates a slog.Sink configured to write JSON logs
@@ -22,20 +21,24 @@ import (
 //
 // See https://cloud.google.com/logging/docs/agent
 func Sink(w io.Writer) slog.Sink {
+	return slog.WriterSink(w, Encoder())
+}
+
+// Encoder returns an encoder for the format of the package.
+// It can be used with any sink that accepts a slog.Encoder.
+func Encoder() slog.Encoder {
 	projectID, _ := metadata.ProjectID()
 
-	return stackdriverSink{
+	return stackdriverEncoder{
 		projectID: projectID,
-		w:         syncwriter.New(w),
 	}
 }
 
-type stackdriverSink struct {
+type stackdriverEncoder struct {
 	projectID string
-	w         *syncwriter.Writer
 }
 
-func (s stackdriverSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+func (s stackdriverEncoder) Encode(buf []byte, ent slog.SinkEntry) []byte {
 	// https://cloud.google.com/logging/docs/agent/configuration#special-fields
 	e := slog.M(
 		slog.F("severity", sev(ent.Level)),
@@ -62,33 +65,26 @@ func (s stackdriverSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
 		)
 	}
 
-	e = append(e, ent.Fields...)
-
-	buf, _ := json.Marshal(e)
+	if len(ent.Tags) > 0 {
+		e = append(e, slog.F("logging.googleapis.com/labels", ent.Tags))
+	}
 
-	buf = append(buf, '\n')
-	s.w.Write("slogstackdriver", buf)
-}
+	e = append(e, ent.Fields...)
 
-func (s stackdriverSink) Sync() {
-	s.w.Sync("stackdriverSink")
+	b, _ := json.Marshal(e)
+	return append(buf, b...)
 }
 
 func sev(level slog.Level) logpbtype.LogSeverity {
-	switch level {
-	case slog.LevelDebug:
-		return logpbtype.LogSeverity_DEBUG
-	case slog.LevelInfo:
-		return logpbtype.LogSeverity_INFO
-	case slog.LevelWarn:
-		return logpbtype.LogSeverity_WARNING
-	case slog.LevelError:
-		return logpbtype.LogSeverity_ERROR
-	default:
-		return logpbtype.LogSeverity_CRITICAL
-	}
+	return logpbtype.LogSeverity(logpbtype.LogSeverity_value[level.Severity().Stackdriver])
 }
 
-func (s stackdriverSink) traceField(tID trace.TraceID) string {
+func (s stackdriverEncoder) traceField(tID trace.TraceID) string {
 	return fmt.Sprintf("projects/%v/traces/%v", s.projectID, tID)
 }
+
+func init() {
+	slog.RegisterFormat("stackdriver", func(u *url.URL, w io.Writer) (slog.Encoder, error) {
+		return Encoder(), nil
+	})
+}
@@ -45,4 +45,5 @@ func TestSevMapping(t *testing.T) {
 	assert.Equal(t, "level", logpbtype.LogSeverity_WARNING, slogstackdriver.Sev(slog.LevelWarn))
 	assert.Equal(t, "level", logpbtype.LogSeverity_ERROR, slogstackdriver.Sev(slog.LevelError))
 	assert.Equal(t, "level", logpbtype.LogSeverity_CRITICAL, slogstackdriver.Sev(slog.LevelCritical))
+	assert.Equal(t, "fatal level", logpbtype.LogSeverity_CRITICAL, slogstackdriver.Sev(slog.LevelFatal))
 }
@@ -0,0 +1,9 @@
+package slogtenant
+
+import (
+	"time"
+)
+
+func SetNow(r *Router, now func() time.Time) {
+	r.now = now
+}
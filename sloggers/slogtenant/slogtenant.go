@@ -0,0 +1,394 @@
+// Package slogtenant contains a slog.Sink that routes entries
+// to a separate sink for every tenant.
+//
+// The tenant of an entry is the value of a designated field, usually
+// added to the context of a request with slog.With:
+//
+//	ctx = slog.With(ctx, slog.F("tenant", tenantID))
+//
+// so that e.g. every tenant's entries are written to their own file
+// or Loki stream and never mixed with those of another tenant.
+package slogtenant // import "cdr.dev/slog/sloggers/slogtenant"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// Options represents the options for the sink returned by Make.
+type Options struct {
+	// Field is the name of the field with the tenant.
+	// If the field appears more than once, the last one is used.
+	// Defaults to "tenant".
+	Field string
+	// New creates the sink of a tenant when the first entry of the
+	// tenant is logged. It must validate the tenant before using it
+	// in e.g. a file path as it comes from the logged fields.
+	//
+	// It is called without holding the lock of the Router, so when
+	// the first entries of a tenant are logged concurrently it may be
+	// called more than once for the tenant. Only one of the sinks is
+	// used and the others are closed right away.
+	New func(tenant string) (slog.Sink, error)
+	// Default receives the entries without a tenant, those of
+	// tenants whose sink could not be created and those of new
+	// tenants while MaxTenants sinks are open.
+	// If nil, the entries are dropped.
+	Default slog.Sink
+	// MaxTenants is the maximum number of tenants with an open sink
+	// so that entries with many distinct tenants, e.g. from a bug or
+	// an attacker, do not exhaust file descriptors or memory.
+	// Defaults to no limit.
+	MaxTenants int
+	// IdleTimeout is the time after which the sink of a tenant
+	// without entries is closed. It is created again if another
+	// entry of the tenant is logged. Defaults to 10m.
+	IdleTimeout time.Duration
+}
+
+// Router routes entries to the sinks of their tenants.
+//
+// See Make.
+type Router struct {
+	opts *Options
+	now  func() time.Time
+
+	mu        sync.RWMutex
+	tenants   map[string]*tenant
+	lastSweep time.Time
+
+	errorf func(f string, v ...interface{})
+}
+
+type tenant struct {
+	s slog.Sink
+	// lastUsed is the time of the last entry in unix nanoseconds.
+	lastUsed int64
+
+	// The sink is used without holding the lock of the Router, so a
+	// tenant removed from the Router is closed by the last of its users.
+	mu      sync.Mutex
+	refs    int
+	removed bool
+	// report enables printing the error of closing the sink.
+	report bool
+	// closed is closed once the sink is closed with err.
+	closed chan struct{}
+	err    error
+}
+
+func newTenant(s slog.Sink) *tenant {
+	return &tenant{
+		s:      s,
+		closed: make(chan struct{}),
+	}
+}
+
+// acquire marks the sink as in use.
+// Callers must hold the lock of the Router and t must not be removed.
+func (t *tenant) acquire() {
+	t.mu.Lock()
+	t.refs++
+	t.mu.Unlock()
+}
+
+// release marks the sink as no longer in use by the caller
+// and closes it if it was removed in the meantime.
+func (r *Router) release(t *tenant) {
+	t.mu.Lock()
+	t.refs--
+	last := t.removed && t.refs == 0
+	t.mu.Unlock()
+	if last {
+		r.closeTenant(t)
+	}
+}
+
+// remove closes the sink of t once it is no longer in use. t must already
+// have been deleted from the tenants of the Router. If report is set,
+// the error of closing the sink is printed.
+func (r *Router) remove(t *tenant, report bool) {
+	t.mu.Lock()
+	t.removed = true
+	t.report = report
+	unused := t.refs == 0
+	t.mu.Unlock()
+	if unused {
+		r.closeTenant(t)
+	}
+}
+
+func (r *Router) closeTenant(t *tenant) {
+	t.err = closeSink(t.s)
+	close(t.closed)
+	if t.report && t.err != nil {
+		r.errorf("slogtenant: failed to close idle sink: %+v", t.err)
+	}
+}
+
+// Make creates a Router that creates the sinks of tenants with opts.New.
+//
+// Idle sinks are closed while logging other entries, i.e. there is no
+// background goroutine. The sinks of tenants are closed by calling
+// Close if they implement io.Closer and Sync otherwise.
+//
+// It panics if opts.New is nil.
+func Make(opts *Options) *Router {
+	if opts == nil || opts.New == nil {
+		panic("slogtenant: New is required")
+	}
+	o := *opts
+	if o.Field == "" {
+		o.Field = "tenant"
+	}
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = 10 * time.Minute
+	}
+
+	return &Router{
+		opts:    &o,
+		now:     time.Now,
+		tenants: make(map[string]*tenant),
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+}
+
+// tenantOf returns the tenant of ent.
+func (r *Router) tenantOf(ent slog.SinkEntry) (string, bool) {
+	for i := len(ent.Fields) - 1; i >= 0; i-- {
+		f := ent.Fields[i]
+		if f.Name != r.opts.Field {
+			continue
+		}
+		switch v := f.Value.(type) {
+		case string:
+			return v, v != ""
+		case fmt.Stringer:
+			s := v.String()
+			return s, s != ""
+		default:
+			s := fmt.Sprint(v)
+			return s, s != ""
+		}
+	}
+	return "", false
+}
+
+// LogEntry implements slog.Sink.
+func (r *Router) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	id, ok := r.tenantOf(ent)
+	if !ok {
+		r.logDefault(ctx, ent)
+		return
+	}
+
+	// The sink of the tenant is called without holding the lock so that
+	// a slow sink does not block the entries of other tenants.
+	now := r.now()
+	r.mu.RLock()
+	t := r.tenants[id]
+	if t != nil {
+		atomic.StoreInt64(&t.lastUsed, now.UnixNano())
+		t.acquire()
+		r.mu.RUnlock()
+		t.s.LogEntry(ctx, ent)
+		r.release(t)
+		r.maybeSweep(now)
+		return
+	}
+	full := r.full()
+	r.mu.RUnlock()
+	if full {
+		r.logDefault(ctx, ent)
+		return
+	}
+
+	// The sink is created without the lock held so that a slow New
+	// does not block the entries of other tenants.
+	s, err := r.opts.New(id)
+	if err != nil {
+		r.errorf("slogtenant: failed to create sink of tenant %q: %+v", id, err)
+		r.logDefault(ctx, ent)
+		return
+	}
+
+	r.mu.Lock()
+	t = r.tenants[id]
+	unused := s
+	if t == nil && !r.full() {
+		t = newTenant(s)
+		r.tenants[id] = t
+		unused = nil
+	}
+	if t != nil {
+		atomic.StoreInt64(&t.lastUsed, now.UnixNano())
+		t.acquire()
+	}
+	r.mu.Unlock()
+
+	if unused != nil {
+		// Another entry of the tenant created its sink first
+		// or the limit was reached in the meantime.
+		err := closeSink(unused)
+		if err != nil {
+			r.errorf("slogtenant: failed to close unused sink of tenant %q: %+v", id, err)
+		}
+	}
+	if t == nil {
+		r.logDefault(ctx, ent)
+		return
+	}
+	t.s.LogEntry(ctx, ent)
+	r.release(t)
+	r.maybeSweep(now)
+}
+
+// full reports whether MaxTenants sinks are open.
+// Callers must hold mu.
+func (r *Router) full() bool {
+	return r.opts.MaxTenants > 0 && len(r.tenants) >= r.opts.MaxTenants
+}
+
+func (r *Router) logDefault(ctx context.Context, ent slog.SinkEntry) {
+	if r.opts.Default != nil {
+		r.opts.Default.LogEntry(ctx, ent)
+	}
+}
+
+// maybeSweep closes the idle sinks at most twice per IdleTimeout.
+func (r *Router) maybeSweep(now time.Time) {
+	r.mu.RLock()
+	due := now.Sub(r.lastSweep) >= r.opts.IdleTimeout/2
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	var idle []*tenant
+	r.mu.Lock()
+	if now.Sub(r.lastSweep) < r.opts.IdleTimeout/2 {
+		r.mu.Unlock()
+		return
+	}
+	r.lastSweep = now
+	for id, t := range r.tenants {
+		if now.Sub(time.Unix(0, atomic.LoadInt64(&t.lastUsed))) >= r.opts.IdleTimeout {
+			delete(r.tenants, id)
+			idle = append(idle, t)
+		}
+	}
+	r.mu.Unlock()
+
+	// Sinks still in use by other entries
+	// are closed once those are logged.
+	for _, t := range idle {
+		r.remove(t, true)
+	}
+}
+
+func closeSink(s slog.Sink) error {
+	if c, ok := s.(io.Closer); ok {
+		return c.Close()
+	}
+	s.Sync()
+	return nil
+}
+
+// Sync implements slog.Sink.
+//
+// It syncs the sinks of every tenant and the default sink.
+func (r *Router) Sync() {
+	for _, t := range r.acquireAll() {
+		t.s.Sync()
+		r.release(t)
+	}
+
+	if r.opts.Default != nil {
+		r.opts.Default.Sync()
+	}
+}
+
+// Flush implements slog.Flusher.
+//
+// It flushes the sinks of every tenant and the default sink
+// and returns the first error.
+func (r *Router) Flush(ctx context.Context) error {
+	var err error
+	for id, t := range r.acquireAll() {
+		err2 := slog.Flush(ctx, t.s)
+		r.release(t)
+		if err == nil && err2 != nil {
+			err = xerrors.Errorf("failed to flush sink of tenant %q: %w", id, err2)
+		}
+	}
+	if r.opts.Default != nil {
+		err2 := slog.Flush(ctx, r.opts.Default)
+		if err == nil && err2 != nil {
+			err = xerrors.Errorf("failed to flush default sink: %w", err2)
+		}
+	}
+	return err
+}
+
+// acquireAll marks the sinks of every tenant as in use
+// so that they can be called without holding the lock.
+func (r *Router) acquireAll() map[string]*tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenants := make(map[string]*tenant, len(r.tenants))
+	for id, t := range r.tenants {
+		t.acquire()
+		tenants[id] = t
+	}
+	return tenants
+}
+
+// Tenants returns the sorted tenants with an open sink.
+func (r *Router) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.tenants))
+	for id := range r.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Close closes the sinks of every tenant and returns the first error.
+// It waits for entries that are being logged to those sinks.
+// Entries logged afterwards create new sinks.
+//
+// The default sink is not closed.
+func (r *Router) Close() error {
+	r.mu.Lock()
+	tenants := r.tenants
+	r.tenants = make(map[string]*tenant)
+	r.mu.Unlock()
+
+	for _, t := range tenants {
+		r.remove(t, false)
+	}
+	var err error
+	for id, t := range tenants {
+		<-t.closed
+		err2 := t.err
+		if err == nil && err2 != nil {
+			err = xerrors.Errorf("failed to close sink of tenant %q: %w", id, err2)
+		}
+	}
+	return err
+}
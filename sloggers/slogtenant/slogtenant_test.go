@@ -0,0 +1,269 @@
+package slogtenant_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogtenant"
+)
+
+var bg = context.Background()
+
+type fakeSink struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+	closed  bool
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, ent slog.SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, ent)
+}
+
+func (s *fakeSink) Sync() {}
+
+func (s *fakeSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var msgs []string
+	for _, ent := range s.entries {
+		msgs = append(msgs, ent.Message)
+	}
+	return msgs
+}
+
+func TestRouter(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	sinks := map[string][]*fakeSink{}
+	def := &fakeSink{}
+	r := slogtenant.Make(&slogtenant.Options{
+		New: func(tenant string) (slog.Sink, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			s := &fakeSink{}
+			sinks[tenant] = append(sinks[tenant], s)
+			return s, nil
+		},
+		Default:     def,
+		IdleTimeout: time.Minute,
+	})
+	now := time.Unix(0, 0)
+	slogtenant.SetNow(r, func() time.Time {
+		return now
+	})
+
+	l := slog.Make(r)
+	a := slog.With(bg, slog.F("tenant", "a"))
+	b := slog.With(bg, slog.F("tenant", "b"))
+	l.Info(a, "a1")
+	l.Info(b, "b1")
+	l.Info(a, "a2")
+	l.Info(bg, "none")
+	// The last tenant field wins.
+	l.Info(b, "a3", slog.F("tenant", "a"))
+
+	assert.Equal(t, "tenants", []string{"a", "b"}, r.Tenants())
+	assert.Equal(t, "a", []string{"a1", "a2", "a3"}, sinks["a"][0].messages())
+	assert.Equal(t, "b", []string{"b1"}, sinks["b"][0].messages())
+	assert.Equal(t, "default", []string{"none"}, def.messages())
+
+	// b is closed after being idle.
+	now = now.Add(40 * time.Second)
+	l.Info(a, "a4")
+	now = now.Add(40 * time.Second)
+	l.Info(a, "a5")
+	assert.Equal(t, "tenants", []string{"a"}, r.Tenants())
+	assert.True(t, "b closed", sinks["b"][0].closed)
+
+	// And recreated for its next entry.
+	l.Info(b, "b2")
+	assert.Len(t, "b sinks", 2, sinks["b"])
+	assert.Equal(t, "b", []string{"b2"}, sinks["b"][1].messages())
+
+	err := r.Close()
+	assert.Success(t, "close", err)
+	assert.True(t, "a closed", sinks["a"][0].closed)
+	assert.Equal(t, "tenants", []string{}, r.Tenants())
+	assert.False(t, "default closed", def.closed)
+}
+
+func TestRouter_NewError(t *testing.T) {
+	t.Parallel()
+
+	def := &fakeSink{}
+	r := slogtenant.Make(&slogtenant.Options{
+		Field: "org",
+		New: func(tenant string) (slog.Sink, error) {
+			return nil, xerrors.New("invalid tenant")
+		},
+		Default: def,
+	})
+	slog.Make(r).Info(bg, "msg", slog.F("org", 42))
+
+	assert.Equal(t, "default", []string{"msg"}, def.messages())
+	assert.Len(t, "tenants", 0, r.Tenants())
+}
+
+func TestRouter_MaxTenants(t *testing.T) {
+	t.Parallel()
+
+	def := &fakeSink{}
+	r := slogtenant.Make(&slogtenant.Options{
+		New: func(tenant string) (slog.Sink, error) {
+			return &fakeSink{}, nil
+		},
+		Default:    def,
+		MaxTenants: 2,
+	})
+	l := slog.Make(r)
+	l.Info(bg, "a", slog.F("tenant", "a"))
+	l.Info(bg, "b", slog.F("tenant", "b"))
+	l.Info(bg, "c", slog.F("tenant", "c"))
+	l.Info(bg, "a", slog.F("tenant", "a"))
+
+	assert.Equal(t, "tenants", []string{"a", "b"}, r.Tenants())
+	assert.Equal(t, "default", []string{"c"}, def.messages())
+}
+
+func TestRouter_SlowNew(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	var mu sync.Mutex
+	var sinks []*fakeSink
+	r := slogtenant.Make(&slogtenant.Options{
+		New: func(tenant string) (slog.Sink, error) {
+			if tenant == "slow" {
+				started <- struct{}{}
+				<-release
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			s := &fakeSink{}
+			sinks = append(sinks, s)
+			return s, nil
+		},
+	})
+	l := slog.Make(r)
+
+	var wg sync.WaitGroup
+	for _, msg := range []string{"1", "2"} {
+		msg := msg
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info(bg, msg, slog.F("tenant", "slow"))
+		}()
+	}
+	<-started
+	<-started
+
+	// Other tenants are not blocked by the creation of a sink.
+	l.Info(bg, "fast", slog.F("tenant", "fast"))
+	assert.Equal(t, "tenants", []string{"fast"}, r.Tenants())
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, "tenants", []string{"fast", "slow"}, r.Tenants())
+
+	// Only one of the sinks of the slow tenant is used
+	// and the other one is closed.
+	var used, closed int
+	for _, s := range sinks[1:] {
+		if s.closed {
+			closed++
+			assert.Len(t, "closed entries", 0, s.messages())
+		} else {
+			used++
+			assert.Len(t, "entries", 2, s.messages())
+		}
+	}
+	assert.Equal(t, "used", 1, used)
+	assert.Equal(t, "closed", 1, closed)
+}
+
+// blockingSink blocks logging until release is closed.
+type blockingSink struct {
+	fakeSink
+	logging chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	s.logging <- struct{}{}
+	<-s.release
+	s.fakeSink.LogEntry(ctx, ent)
+}
+
+func (s *blockingSink) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func TestRouter_BlockedSink(t *testing.T) {
+	t.Parallel()
+
+	blocked := &blockingSink{
+		logging: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	fast := &fakeSink{}
+	r := slogtenant.Make(&slogtenant.Options{
+		New: func(tenant string) (slog.Sink, error) {
+			if tenant == "blocked" {
+				return blocked, nil
+			}
+			return fast, nil
+		},
+		IdleTimeout: time.Minute,
+	})
+	now := time.Unix(0, 0)
+	var nowMu sync.Mutex
+	slogtenant.SetNow(r, func() time.Time {
+		nowMu.Lock()
+		defer nowMu.Unlock()
+		return now
+	})
+	l := slog.Make(r)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Info(bg, "blocked", slog.F("tenant", "blocked"))
+	}()
+	<-blocked.logging
+
+	// Other tenants are neither blocked by the sink nor by
+	// creating a sink or closing idle sinks while it is in use.
+	nowMu.Lock()
+	now = now.Add(2 * time.Minute)
+	nowMu.Unlock()
+	l.Info(bg, "fast", slog.F("tenant", "fast"))
+	assert.Equal(t, "fast", []string{"fast"}, fast.messages())
+	assert.Equal(t, "tenants", []string{"fast"}, r.Tenants())
+
+	// The idle sink is closed once it is no longer in use.
+	assert.False(t, "closed while in use", blocked.isClosed())
+	close(blocked.release)
+	<-done
+	assert.True(t, "closed", blocked.isClosed())
+	assert.Equal(t, "blocked", []string{"blocked"}, blocked.messages())
+}
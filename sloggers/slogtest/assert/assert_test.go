@@ -108,6 +108,10 @@ type fakeTB struct {
 	fatals int
 }
 
+func (tb *fakeTB) Name() string {
+	return "TestFake"
+}
+
 func (tb *fakeTB) Helper() {}
 
 func (tb *fakeTB) Log(v ...interface{}) {}
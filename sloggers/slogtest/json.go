@@ -0,0 +1,82 @@
+package slogtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// TestEntry is an entry logged by a test.
+type TestEntry struct {
+	// Package is the import path of the package of the test.
+	Package string
+	// Test is the name of the test, including the path of the subtest.
+	Test  string
+	Entry slog.SinkEntry
+}
+
+// testEvent is an event of go test -json. See go doc test2json.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// logPrefix matches the location that testing prefixes t.Log with.
+var logPrefix = regexp.MustCompile(`^\s*[^\s:]+:\d+: `)
+
+// ReadTestJSON reads the events of go test -json from r and returns the
+// entries logged by loggers with Options.JSON in the order they were
+// logged. Other output is ignored.
+func ReadTestJSON(r io.Reader) ([]TestEntry, error) {
+	var ents []TestEntry
+	// partial contains the output of tests that does not end
+	// with a newline yet as long lines are split into events.
+	partial := make(map[[2]string]string)
+
+	d := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var ev testEvent
+		err := d.Decode(&ev)
+		if err == io.EOF {
+			return ents, nil
+		}
+		if err != nil {
+			return ents, xerrors.Errorf("failed to decode test event: %w", err)
+		}
+		if ev.Action != "output" {
+			continue
+		}
+
+		key := [2]string{ev.Package, ev.Test}
+		out := partial[key] + ev.Output
+		if !strings.HasSuffix(out, "\n") {
+			partial[key] = out
+			continue
+		}
+		delete(partial, key)
+
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+			line = logPrefix.ReplaceAllString(line, "")
+			if !strings.HasPrefix(line, "{") {
+				continue
+			}
+			var ent slog.SinkEntry
+			if ent.UnmarshalJSON([]byte(line)) != nil {
+				continue
+			}
+			ents = append(ents, TestEntry{
+				Package: ev.Package,
+				Test:    ev.Test,
+				Entry:   ent,
+			})
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package slogtest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogtest"
+)
+
+func TestReadTestJSON(t *testing.T) {
+	t.Parallel()
+
+	tb := &fakeTB{}
+	l := slogtest.Make(tb, &slogtest.Options{JSON: true})
+	l.Info(bg, "hello", slog.F("a", 1))
+
+	// go test -json splits long lines into multiple events.
+	output := "    t_test.go:10: " + tb.lastLog + "\n"
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, ev := range []map[string]string{
+		{"Action": "run", "Package": "pkg", "Test": "TestFake"},
+		{"Action": "output", "Package": "pkg", "Test": "TestFake", "Output": "=== RUN   TestFake\n"},
+		{"Action": "output", "Package": "pkg", "Test": "TestFake", "Output": output[:20]},
+		{"Action": "output", "Package": "pkg", "Test": "TestOther", "Output": "    other_test.go:5: not JSON\n"},
+		{"Action": "output", "Package": "pkg", "Test": "TestFake", "Output": output[20:]},
+		{"Action": "pass", "Package": "pkg", "Test": "TestFake"},
+	} {
+		err := enc.Encode(ev)
+		assert.Success(t, "encode event", err)
+	}
+
+	ents, err := slogtest.ReadTestJSON(&b)
+	assert.Success(t, "read", err)
+	assert.Len(t, "entries", 1, ents)
+	assert.Equal(t, "package", "pkg", ents[0].Package)
+	assert.Equal(t, "test", "TestFake", ents[0].Test)
+	assert.Equal(t, "msg", "hello", ents[0].Entry.Message)
+	assert.Equal(t, "level", slog.LevelInfo, ents[0].Entry.Level)
+
+	v, _ := ents[0].Entry.Fields.Get("test")
+	assert.Equal(t, "test field", "TestFake", v)
+}
@@ -0,0 +1,209 @@
+package slogtest
+
+import (
+	"context"
+	"sync"
+
+	"cdr.dev/slog"
+)
+
+// SpyCall is a single call to SpySink.LogEntry.
+type SpyCall struct {
+	Entry slog.SinkEntry
+	// Err is the error the call returned.
+	Err error
+	// Panic is the value the call panicked with.
+	Panic interface{}
+}
+
+// SpySink is a slog.Sink that records every call and can be
+// scripted to fail, block or panic.
+//
+// It is meant for testing sinks that wrap other sinks.
+// It implements slog.ErrorSink to report scripted failures.
+type SpySink struct {
+	mu      sync.Mutex
+	calls   []SpyCall
+	started int
+	syncs   int
+	// changed is closed and replaced whenever a call starts.
+	changed chan struct{}
+
+	failN   int
+	failErr error
+	panicN  int
+	panicV  interface{}
+	block   chan struct{}
+	syncErr error
+}
+
+var _ slog.ErrorSink = &SpySink{}
+
+// Spy returns a SpySink that records every call.
+//
+// By default every call succeeds.
+func Spy() *SpySink {
+	return &SpySink{
+		changed: make(chan struct{}),
+	}
+}
+
+// LogEntry implements slog.Sink.
+func (s *SpySink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = s.LogEntryErr(ctx, ent)
+}
+
+// LogEntryErr implements slog.ErrorSink.
+//
+// If the sink is blocked, it returns ctx.Err() if ctx is done
+// before the sink is released.
+func (s *SpySink) LogEntryErr(ctx context.Context, ent slog.SinkEntry) error {
+	s.mu.Lock()
+	s.started++
+	close(s.changed)
+	s.changed = make(chan struct{})
+	block := s.block
+	s.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			s.mu.Lock()
+			s.calls = append(s.calls, SpyCall{Entry: ent, Err: ctx.Err()})
+			s.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.panicN > 0 {
+		s.panicN--
+		s.calls = append(s.calls, SpyCall{Entry: ent, Panic: s.panicV})
+		panic(s.panicV)
+	}
+
+	var err error
+	if s.failN != 0 {
+		if s.failN > 0 {
+			s.failN--
+		}
+		err = s.failErr
+	}
+	s.calls = append(s.calls, SpyCall{Entry: ent, Err: err})
+	return err
+}
+
+// Sync implements slog.Sink.
+func (s *SpySink) Sync() {
+	_ = s.SyncErr()
+}
+
+// SyncErr implements slog.ErrorSink.
+func (s *SpySink) SyncErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncs++
+	return s.syncErr
+}
+
+// Fail causes the next n calls to LogEntry to fail with err.
+//
+// If n is negative, all calls fail until Fail is called again.
+// Fail(0, nil) causes calls to succeed again.
+func (s *SpySink) Fail(n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failN = n
+	s.failErr = err
+}
+
+// FailSync causes all calls to Sync to fail with err.
+func (s *SpySink) FailSync(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncErr = err
+}
+
+// Panic causes the next n calls to LogEntry to panic with v.
+func (s *SpySink) Panic(n int, v interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panicN = n
+	s.panicV = v
+}
+
+// Block causes calls to LogEntry to block until the returned
+// function is called.
+func (s *SpySink) Block() (release func()) {
+	block := make(chan struct{})
+
+	s.mu.Lock()
+	s.block = block
+	s.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			if s.block == block {
+				s.block = nil
+			}
+			s.mu.Unlock()
+			close(block)
+		})
+	}
+}
+
+// Wait waits until at least n calls to LogEntry have started.
+//
+// It returns ctx.Err() if ctx is done first.
+func (s *SpySink) Wait(ctx context.Context, n int) error {
+	for {
+		s.mu.Lock()
+		started := s.started
+		changed := s.changed
+		s.mu.Unlock()
+
+		if started >= n {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Calls returns every completed call to LogEntry.
+func (s *SpySink) Calls() []SpyCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SpyCall(nil), s.calls...)
+}
+
+// Entries returns the entries of every call to LogEntry
+// that neither failed nor panicked.
+func (s *SpySink) Entries() []slog.SinkEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []slog.SinkEntry
+	for _, c := range s.calls {
+		if c.Err == nil && c.Panic == nil {
+			entries = append(entries, c.Entry)
+		}
+	}
+	return entries
+}
+
+// Syncs returns the number of calls to Sync.
+func (s *SpySink) Syncs() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.syncs
+}
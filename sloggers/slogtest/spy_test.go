@@ -0,0 +1,92 @@
+package slogtest_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogtest"
+)
+
+func TestSpy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("record", func(t *testing.T) {
+		t.Parallel()
+
+		s := slogtest.Spy()
+		l := slog.Make(s)
+		l.Info(bg, "hello")
+		l.Error(bg, "world")
+
+		assert.Len(t, "entries", 2, s.Entries())
+		assert.Equal(t, "msg", "world", s.Entries()[1].Message)
+		assert.Equal(t, "syncs", 1, s.Syncs())
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		t.Parallel()
+
+		s := slogtest.Spy()
+		s.Fail(2, io.EOF)
+		assert.Equal(t, "err", io.EOF, s.LogEntryErr(bg, slog.SinkEntry{}))
+		assert.Equal(t, "err", io.EOF, s.LogEntryErr(bg, slog.SinkEntry{}))
+		assert.Success(t, "log", s.LogEntryErr(bg, slog.SinkEntry{}))
+		assert.Len(t, "calls", 3, s.Calls())
+		assert.Len(t, "entries", 1, s.Entries())
+
+		s.FailSync(io.ErrClosedPipe)
+		assert.Equal(t, "err", io.ErrClosedPipe, s.SyncErr())
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		t.Parallel()
+
+		s := slogtest.Spy()
+		s.Panic(1, "boom")
+		func() {
+			defer func() {
+				assert.Equal(t, "recovered", "boom", recover())
+			}()
+			s.LogEntry(bg, slog.SinkEntry{})
+		}()
+		s.LogEntry(bg, slog.SinkEntry{})
+		assert.Len(t, "calls", 2, s.Calls())
+		assert.Len(t, "entries", 1, s.Entries())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(bg, time.Minute)
+		defer cancel()
+
+		s := slogtest.Spy()
+		release := s.Block()
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- s.LogEntryErr(ctx, slog.SinkEntry{})
+		}()
+
+		assert.Success(t, "wait", s.Wait(ctx, 1))
+		assert.Len(t, "calls", 0, s.Calls())
+		release()
+		assert.Success(t, "log", <-errs)
+		assert.Len(t, "calls", 1, s.Calls())
+	})
+
+	t.Run("blockCanceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(bg)
+		s := slogtest.Spy()
+		defer s.Block()()
+
+		cancel()
+		assert.Equal(t, "err", context.Canceled, s.LogEntryErr(ctx, slog.SinkEntry{}))
+	})
+}
@@ -9,12 +9,14 @@ import (
 	"context"
 	"log"
 	"os"
+	"regexp"
 	"sync"
 	"testing"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/internal/entryhuman"
 	"cdr.dev/slog/sloggers/sloghuman"
+	"cdr.dev/slog/sloggers/slogjson"
 )
 
 // Ensure all stdlib logs go through slog.
@@ -34,60 +36,199 @@ type Options struct {
 	// conditions exist when t.Log is called concurrently of a test exiting. Set
 	// to true if you don't need this behavior.
 	SkipCleanup bool
+	// AllowDuplicateKeys disables failing the test on entries with
+	// duplicate field keys. See slog.Strict.
+	AllowDuplicateKeys bool
+	// KeyPattern enables failing the test on entries with field keys
+	// that do not match it, e.g. slog.SnakeCasePattern. See slog.CheckKeys.
+	KeyPattern *regexp.Regexp
+	// OmitTestName omits the test field of the name of the test,
+	// including the path of the subtest, from the entries.
+	OmitTestName bool
+	// Seed is written in the seed field of the entries if not zero,
+	// e.g. the seed of a randomized test so that it can be reproduced.
+	Seed int64
+	// JSON logs the entries as JSON objects in the format of slogjson
+	// instead of the human readable format so that CI systems can index
+	// them from the output of go test -json. See ReadTestJSON.
+	JSON bool
+	// Time controls how the timestamps of human readable entries are
+	// written, e.g. sloghuman.TimeSincePrevious to see the time taken
+	// between the steps of a test.
+	Time sloghuman.TimeStyle
 }
 
 // Make creates a Logger that writes logs to tb in a human readable format.
+//
+// The returned Logger is safe to share between parallel subtests.
+// Use WithT to direct entries to the subtest's testing.TB.
 func Make(tb testing.TB, opts *Options) slog.Logger {
 	if opts == nil {
 		opts = &Options{}
 	}
 
 	sink := &testSink{
-		tb:   tb,
-		opts: opts,
+		tb:    tb,
+		opts:  opts,
+		tests: make(map[testing.TB]bool),
 	}
-	if !opts.SkipCleanup {
-		tb.Cleanup(func() {
-			sink.mu.Lock()
-			defer sink.mu.Unlock()
-			sink.testDone = true
-		})
+	if opts.JSON {
+		sink.json = slogjson.Encoder(nil)
 	}
+	if opts.Time != sloghuman.TimeWall {
+		sink.relative = &entryhuman.Relative{Previous: opts.Time == sloghuman.TimeSincePrevious}
+	}
+	sink.register(tb)
 
 	return slog.Make(sink)
 }
 
+type tbKey struct{}
+
+// WithT returns a context that causes loggers created by Make
+// to log entries with it to tb instead of the testing.TB passed
+// to Make.
+//
+// Use it to attribute entries to the correct subtest when a logger
+// is shared between subtests.
+func WithT(ctx context.Context, tb testing.TB) context.Context {
+	return context.WithValue(ctx, tbKey{}, tb)
+}
+
+func tbFromContext(ctx context.Context) (testing.TB, bool) {
+	tb, ok := ctx.Value(tbKey{}).(testing.TB)
+	return tb, ok
+}
+
+type iterationKey struct{}
+
+// WithIteration returns a context that causes loggers created by Make
+// to write i in the iteration field of the entries logged with it,
+// e.g. the iteration of a test that runs the same case repeatedly.
+func WithIteration(ctx context.Context, i int) context.Context {
+	return context.WithValue(ctx, iterationKey{}, i)
+}
+
 type testSink struct {
-	tb       testing.TB
-	opts     *Options
+	tb   testing.TB
+	opts *Options
+	// json encodes the entries if Options.JSON is set.
+	json slog.Encoder
+	// relative is nil for wall clock timestamps.
+	relative *entryhuman.Relative
 	mu       sync.RWMutex
-	testDone bool
+	// tests contains every test the sink has logged to.
+	// The value is true once the test has finished.
+	tests map[testing.TB]bool
+}
+
+// register adds a cleanup to tb that prevents logging
+// to it once it has finished.
+func (ts *testSink) register(tb testing.TB) {
+	ts.mu.RLock()
+	_, ok := ts.tests[tb]
+	ts.mu.RUnlock()
+	if ok {
+		return
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if _, ok := ts.tests[tb]; ok {
+		return
+	}
+	ts.tests[tb] = false
+	if !ts.opts.SkipCleanup {
+		tb.Cleanup(func() {
+			ts.mu.Lock()
+			defer ts.mu.Unlock()
+			ts.tests[tb] = true
+		})
+	}
+}
+
+// TestHelper returns the test that entries logged with ctx are logged to
+// so that slog.Logger marks its frames as helpers of the test.
+func (ts *testSink) TestHelper(ctx context.Context) interface{ Helper() } {
+	if tb, ok := tbFromContext(ctx); ok {
+		return tb
+	}
+	return ts.tb
 }
 
 func (ts *testSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	tb := ts.tb
+	if ctxTB, ok := tbFromContext(ctx); ok {
+		tb = ctxTB
+		ts.register(tb)
+	}
+
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 
-	// Don't log after the test this sink was created in has finished.
-	if ts.testDone {
+	// Don't log after the test this sink is logging to has finished.
+	if ts.tests[tb] {
 		return
 	}
 
-	// The testing package logs to stdout and not stderr.
-	s := entryhuman.Fmt(os.Stdout, ent)
+	tb.Helper()
+
+	var s string
+	if ts.json != nil {
+		s = string(ts.json.Encode(nil, ts.withTestFields(ctx, tb, ent)))
+	} else {
+		// The testing package logs to stdout and not stderr.
+		var eopts entryhuman.Options
+		if ts.relative != nil {
+			eopts.Since = ts.relative.Since(ent.Time)
+		}
+		s = entryhuman.FmtOpts(os.Stdout, ts.withTestFields(ctx, tb, ent), &eopts)
+	}
 
 	switch ent.Level {
 	case slog.LevelDebug, slog.LevelInfo, slog.LevelWarn:
-		ts.tb.Log(s)
+		tb.Log(s)
 	case slog.LevelError, slog.LevelCritical:
 		if ts.opts.IgnoreErrors {
-			ts.tb.Log(s)
+			tb.Log(s)
 		} else {
-			ts.tb.Error(s)
+			tb.Error(s)
 		}
 	case slog.LevelFatal:
-		ts.tb.Fatal(s)
+		tb.Fatal(s)
+	}
+
+	if !ts.opts.AllowDuplicateKeys {
+		if dups := ent.Fields.DuplicateKeys(); len(dups) > 0 {
+			tb.Errorf("slogtest: duplicate field keys %q in entry logged at %v:%v", dups, ent.File, ent.Line)
+		}
+	}
+	if ts.opts.KeyPattern != nil {
+		if invalid := slog.InvalidKeys(ent.Fields, ts.opts.KeyPattern); len(invalid) > 0 {
+			tb.Errorf("slogtest: field keys %q do not match %v in entry logged at %v:%v", invalid, ts.opts.KeyPattern, ent.File, ent.Line)
+		}
+	}
+}
+
+// withTestFields returns ent with the fields that attribute it to
+// the test prepended so that entries of parallel tests can be told
+// apart in captured output.
+func (ts *testSink) withTestFields(ctx context.Context, tb testing.TB, ent slog.SinkEntry) slog.SinkEntry {
+	var fields slog.Map
+	if !ts.opts.OmitTestName {
+		fields = append(fields, slog.F("test", tb.Name()))
+	}
+	if ts.opts.Seed != 0 {
+		fields = append(fields, slog.F("seed", ts.opts.Seed))
+	}
+	if i, ok := ctx.Value(iterationKey{}).(int); ok {
+		fields = append(fields, slog.F("iteration", i))
+	}
+	if len(fields) > 0 {
+		// The fields of ent are shared with other sinks.
+		ent.Fields = append(fields, ent.Fields...)
 	}
+	return ent
 }
 
 func (ts *testSink) Sync() {}
@@ -101,23 +242,27 @@ func l(t testing.TB) slog.Logger {
 // Debug logs the given msg and fields to t via t.Log at the debug level.
 func Debug(t testing.TB, msg string, fields ...slog.Field) {
 	slog.Helper()
+	t.Helper()
 	l(t).Debug(ctx, msg, fields...)
 }
 
 // Info logs the given msg and fields to t via t.Log at the info level.
 func Info(t testing.TB, msg string, fields ...slog.Field) {
 	slog.Helper()
+	t.Helper()
 	l(t).Info(ctx, msg, fields...)
 }
 
 // Error logs the given msg and fields to t via t.Error at the error level.
 func Error(t testing.TB, msg string, fields ...slog.Field) {
 	slog.Helper()
+	t.Helper()
 	l(t).Error(ctx, msg, fields...)
 }
 
 // Fatal logs the given msg and fields to t via t.Fatal at the fatal level.
 func Fatal(t testing.TB, msg string, fields ...slog.Field) {
 	slog.Helper()
+	t.Helper()
 	l(t).Fatal(ctx, msg, fields...)
 }
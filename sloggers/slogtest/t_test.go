@@ -2,9 +2,17 @@ package slogtest_test
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
+	"cdr.dev/slog"
 	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/sloghuman"
 	"cdr.dev/slog/sloggers/slogtest"
 )
 
@@ -71,27 +79,158 @@ func TestSkipCleanup(t *testing.T) {
 	assert.Len(t, "no cleanups", 0, tb.cleanups)
 }
 
+func TestWithT(t *testing.T) {
+	t.Parallel()
+
+	tb := &fakeTB{}
+	subtb := &fakeTB{}
+	l := slogtest.Make(tb, &slogtest.Options{})
+
+	l.Info(slogtest.WithT(bg, subtb), "hello")
+	assert.Equal(t, "logs", 0, tb.logs)
+	assert.Equal(t, "subtest logs", 1, subtb.logs)
+	assert.Len(t, "subtest cleanups", 1, subtb.cleanups)
+
+	for _, fn := range subtb.cleanups {
+		fn()
+	}
+
+	// This should not log since the subtest has finished.
+	l.Info(slogtest.WithT(bg, subtb), "hello")
+	assert.Equal(t, "subtest logs", 1, subtb.logs)
+
+	// The parent test has not finished.
+	l.Info(bg, "hello")
+	assert.Equal(t, "logs", 1, tb.logs)
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	tb := &fakeTB{}
+	l := slogtest.Make(tb, &slogtest.Options{}).With(slog.F("a", 1))
+	l.Info(bg, "hello", slog.F("a", 2))
+	assert.Equal(t, "errors", 1, tb.errors)
+
+	tb = &fakeTB{}
+	l = slogtest.Make(tb, &slogtest.Options{
+		AllowDuplicateKeys: true,
+	}).With(slog.F("a", 1))
+	l.Info(bg, "hello", slog.F("a", 2))
+	assert.Equal(t, "errors", 0, tb.errors)
+}
+
+func TestKeyPattern(t *testing.T) {
+	t.Parallel()
+
+	tb := &fakeTB{}
+	l := slogtest.Make(tb, &slogtest.Options{
+		KeyPattern: slog.SnakeCasePattern,
+	})
+	l.Info(bg, "hello", slog.F("snake_case", 1))
+	assert.Equal(t, "errors", 0, tb.errors)
+	l.Info(bg, "hello", slog.F("camelCase", 1))
+	assert.Equal(t, "errors", 1, tb.errors)
+}
+
+func TestTestFields(t *testing.T) {
+	t.Parallel()
+
+	tb := &fakeTB{}
+	l := slogtest.Make(tb, &slogtest.Options{Seed: 42})
+	l.Info(slogtest.WithIteration(bg, 3), "hello", slog.F("a", 1))
+	assert.True(t, "fields", strings.HasSuffix(tb.lastLog, `{"test": "TestFake", "seed": 42, "iteration": 3, "a": 1}`))
+
+	l = slogtest.Make(tb, &slogtest.Options{OmitTestName: true})
+	l.Info(bg, "hello")
+	assert.False(t, "omitted", strings.Contains(tb.lastLog, "TestFake"))
+}
+
+func TestTime(t *testing.T) {
+	t.Parallel()
+
+	tb := &fakeTB{}
+	l := slogtest.Make(tb, &slogtest.Options{Time: sloghuman.TimeSincePrevious})
+	l.Info(bg, "hello")
+	assert.True(t, "relative", strings.HasPrefix(tb.lastLog, "+0.000s [INFO]"))
+}
+
+func TestHelper(t *testing.T) {
+	t.Parallel()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$", "-test.v")
+	cmd.Env = append(os.Environ(), "SLOGTEST_HELPER_PROCESS=1")
+	out, err := cmd.CombinedOutput()
+	assert.Success(t, "run", err)
+
+	var wants int
+	for _, line := range strings.Split(string(out), "\n") {
+		if !strings.HasPrefix(line, "want ") {
+			continue
+		}
+		wants++
+		loc := strings.TrimPrefix(line, "want ")
+		assert.True(t, "logged at "+loc, strings.Contains(string(out), " "+loc+": "))
+	}
+	assert.Equal(t, "wants", 4, wants)
+}
+
+// TestHelperProcess logs with slogtest for TestHelper, which checks
+// that the testing package reports the location of the callers.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("SLOGTEST_HELPER_PROCESS") != "1" {
+		t.Skip("run by TestHelper")
+	}
+
+	want := func() {
+		_, file, line, _ := runtime.Caller(1)
+		fmt.Printf("want %v:%v\n", filepath.Base(file), line+1)
+	}
+
+	l := slogtest.Make(t, nil)
+	want()
+	l.Info(bg, "info")
+	want()
+	l.Audit(bg, "audit")
+	want()
+	slogtest.Info(t, "stateless")
+	t.Run("sub", func(t *testing.T) {
+		want()
+		l.Warn(slogtest.WithT(bg, t), "warn")
+	})
+}
+
 var bg = context.Background()
 
 type fakeTB struct {
 	testing.TB
 
 	logs     int
+	lastLog  string
 	errors   int
 	fatals   int
 	cleanups []func()
 }
 
+func (tb *fakeTB) Name() string {
+	return "TestFake"
+}
+
 func (tb *fakeTB) Helper() {}
 
 func (tb *fakeTB) Log(v ...interface{}) {
 	tb.logs++
+	tb.lastLog = fmt.Sprint(v...)
 }
 
 func (tb *fakeTB) Error(v ...interface{}) {
 	tb.errors++
 }
 
+func (tb *fakeTB) Errorf(f string, v ...interface{}) {
+	tb.errors++
+}
+
 func (tb *fakeTB) Fatal(v ...interface{}) {
 	tb.fatals++
 	panic("")
@@ -0,0 +1,202 @@
+// Package slogtraceevent contains the slogger that writes entries
+// in the Chrome trace event format.
+//
+// The output can be opened in chrome://tracing and https://ui.perfetto.dev
+// to view logs on the same timeline as traces during profiling.
+//
+// Entries with a time.Duration field named "duration", as logged by Timed,
+// become slices that end at the time of the entry. All other entries
+// become instant events. Every logger name gets its own track.
+//
+// See https://docs.google.com/document/d/1CvAClvFfyA5R-PhYUmn5OOQtYMH4h6I0nSsKchNAySU
+package slogtraceevent // import "cdr.dev/slog/sloggers/slogtraceevent"
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cdr.dev/slog"
+)
+
+// Sink writes entries as trace events.
+//
+// See Make.
+type Sink struct {
+	w   io.Writer
+	pid int
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+	tids    map[string]int
+}
+
+var _ slog.Sink = &Sink{}
+
+// Make creates a sink that writes a JSON array of trace events to w.
+//
+// The array is only terminated by Close but trace viewers accept
+// unterminated arrays so the output of a crashed process can be opened.
+func Make(w io.Writer) *Sink {
+	return &Sink{
+		w:    w,
+		pid:  os.Getpid(),
+		tids: make(map[string]int),
+	}
+}
+
+type event struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Ph    string                 `json:"ph"`
+	Ts    float64                `json:"ts"`
+	Dur   *float64               `json:"dur,omitempty"`
+	Scope string                 `json:"s,omitempty"`
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+func micros(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e3
+}
+
+// LogEntry implements slog.Sink.
+func (s *Sink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	name := strings.Join(ent.LoggerNames, ".")
+
+	ev := event{
+		Name: ent.Message,
+		Cat:  name,
+		Ph:   "i",
+		Ts:   micros(ent.Time),
+		Pid:  s.pid,
+		Args: map[string]interface{}{
+			"level": ent.Level.String(),
+		},
+	}
+
+	var fields slog.Map
+	for _, f := range ent.Fields {
+		if d, ok := f.Value.(time.Duration); ok && f.Name == "duration" && ev.Ph == "i" {
+			dur := float64(d) / 1e3
+			ev.Ph = "X"
+			ev.Dur = &dur
+			ev.Ts = micros(ent.Time.Add(-d))
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if ev.Ph == "i" {
+		// Instant events are drawn on their track.
+		ev.Scope = "t"
+	}
+	if len(fields) > 0 {
+		// Map preserves the order of the fields and encodes their values
+		// like every other sink.
+		b, _ := json.Marshal(fields)
+		ev.Args["fields"] = json.RawMessage(b)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	tid, ok := s.tids[name]
+	if !ok {
+		tid = len(s.tids) + 1
+		s.tids[name] = tid
+
+		// Name the track of the logger.
+		trackName := name
+		if trackName == "" {
+			trackName = "main"
+		}
+		s.write(event{
+			Name: "thread_name",
+			Ph:   "M",
+			Pid:  s.pid,
+			Tid:  tid,
+			Args: map[string]interface{}{
+				"name": trackName,
+			},
+		})
+	}
+	ev.Tid = tid
+	s.write(ev)
+}
+
+func (s *Sink) write(ev event) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	if !s.started {
+		s.started = true
+		b = append([]byte("[\n"), b...)
+	} else {
+		b = append([]byte(",\n"), b...)
+	}
+	// Trace events are best effort.
+	_, _ = s.w.Write(b)
+}
+
+// Sync implements slog.Sink.
+//
+// It calls Sync on the writer if it implements Sync() error.
+func (s *Sink) Sync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sw, ok := s.w.(interface{ Sync() error }); ok {
+		_ = sw.Sync()
+	}
+}
+
+// Close terminates the array. Entries logged after
+// Close are dropped. It does not close the writer.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	end := "\n]\n"
+	if !s.started {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(s.w, end)
+	return err
+}
+
+// Timed logs msg with the fields and the elapsed time as a
+// "duration" field when the returned function is called.
+// The fields passed to the returned function are appended.
+//
+//	done := slogtraceevent.Timed(ctx, log, "load config")
+//	// ...
+//	done(slog.F("entries", n))
+func Timed(ctx context.Context, l slog.Logger, msg string, fields ...slog.Field) (done func(fields ...slog.Field)) {
+	start := time.Now()
+	return func(fields2 ...slog.Field) {
+		slog.Helper()
+
+		fs := make([]slog.Field, 0, len(fields)+len(fields2)+1)
+		fs = append(fs, fields...)
+		fs = append(fs, fields2...)
+		fs = append(fs, slog.F("duration", time.Since(start)))
+		l.Info(ctx, msg, fs...)
+	}
+}
@@ -0,0 +1,119 @@
+package slogtraceevent_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogtraceevent"
+)
+
+var bg = context.Background()
+
+type event struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat"`
+	Ph    string                 `json:"ph"`
+	Ts    float64                `json:"ts"`
+	Dur   float64                `json:"dur"`
+	Scope string                 `json:"s"`
+	Tid   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args"`
+}
+
+func TestSink(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	s := slogtraceevent.Make(&b)
+
+	end := time.Unix(100, 0)
+	s.LogEntry(bg, slog.SinkEntry{
+		Time:        end,
+		Level:       slog.LevelInfo,
+		Message:     "query",
+		LoggerNames: []string{"db"},
+		Fields: slog.M(
+			slog.F("rows", 3),
+			slog.F("duration", 2*time.Millisecond),
+		),
+	})
+	s.LogEntry(bg, slog.SinkEntry{
+		Time:    end,
+		Level:   slog.LevelWarn,
+		Message: "slow",
+	})
+
+	var unterminated bytes.Buffer
+	unterminated.Write(b.Bytes())
+	unterminated.WriteString("]")
+	var events []event
+	err := json.Unmarshal(unterminated.Bytes(), &events)
+	assert.Success(t, "unmarshal unterminated", err)
+
+	assert.Success(t, "close", s.Close())
+	events = nil
+	err = json.Unmarshal(b.Bytes(), &events)
+	assert.Success(t, "unmarshal", err)
+
+	assert.Len(t, "events", 4, events)
+	assert.Equal(t, "db track", event{
+		Name: "thread_name",
+		Ph:   "M",
+		Tid:  1,
+		Args: map[string]interface{}{"name": "db"},
+	}, events[0])
+	assert.Equal(t, "slice", event{
+		Name: "query",
+		Cat:  "db",
+		Ph:   "X",
+		Ts:   100e6 - 2000,
+		Dur:  2000,
+		Tid:  1,
+		Args: map[string]interface{}{
+			"level":  "INFO",
+			"fields": map[string]interface{}{"rows": 3.0},
+		},
+	}, events[1])
+	assert.Equal(t, "main track", "main", events[2].Args["name"])
+	assert.Equal(t, "instant", event{
+		Name:  "slow",
+		Ph:    "i",
+		Ts:    100e6,
+		Scope: "t",
+		Tid:   2,
+		Args:  map[string]interface{}{"level": "WARN"},
+	}, events[3])
+}
+
+func TestTimed(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	s := slogtraceevent.Make(&b)
+	l := slog.Make(s)
+
+	done := slogtraceevent.Timed(bg, l, "work", slog.F("a", 1))
+	done(slog.F("b", 2))
+	assert.Success(t, "close", s.Close())
+
+	var events []event
+	err := json.Unmarshal(b.Bytes(), &events)
+	assert.Success(t, "unmarshal", err)
+	assert.Len(t, "events", 2, events)
+	assert.Equal(t, "ph", "X", events[1].Ph)
+	assert.Equal(t, "fields", map[string]interface{}{"a": 1.0, "b": 2.0}, events[1].Args["fields"])
+}
+
+func TestEmpty(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	s := slogtraceevent.Make(&b)
+	assert.Success(t, "close", s.Close())
+	assert.Equal(t, "output", "[]\n", b.String())
+}
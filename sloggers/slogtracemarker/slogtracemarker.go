@@ -0,0 +1,137 @@
+// Package slogtracemarker contains the slogger that writes terse
+// entries to the ftrace trace_marker file on Linux.
+//
+// Entries show up in the kernel trace next to the scheduler and
+// syscall events recorded by tools such as trace-cmd and Perfetto,
+// which helps correlate application events with kernel activity:
+//
+//	myapp-4120 [003] ..... 1234.567890: tracing_mark_write: slog INFO http: request done status=200 dur=1.2ms
+//
+// ftrace must be enabled and the process must be allowed to write
+// to the file, usually by running as root.
+package slogtracemarker // import "cdr.dev/slog/sloggers/slogtracemarker"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// Paths are the locations of trace_marker tried by Open in order.
+var Paths = []string{
+	"/sys/kernel/tracing/trace_marker",
+	"/sys/kernel/debug/tracing/trace_marker",
+}
+
+// MaxSize is the maximum size of an entry. Longer entries are truncated.
+// The kernel splits or truncates larger writes.
+const MaxSize = 1024
+
+// Open opens the trace_marker file and returns a sink that writes to it.
+//
+// It returns an error if ftrace is not available. The file is never closed.
+func Open() (slog.Sink, error) {
+	var errs []string
+	for _, path := range Paths {
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err == nil {
+			return Sink(f), nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, xerrors.Errorf("failed to open trace_marker: %v", strings.Join(errs, "; "))
+}
+
+// Sink creates a sink that writes every entry to w on a single line
+// with a single write.
+//
+// The format is the level, the logger names, the message and then
+// the fields as name=value with values that contain spaces quoted.
+func Sink(w io.Writer) slog.Sink {
+	return &markerSink{
+		w: w,
+		pool: sync.Pool{
+			New: func() interface{} {
+				return &bytes.Buffer{}
+			},
+		},
+	}
+}
+
+type markerSink struct {
+	w    io.Writer
+	pool sync.Pool
+}
+
+func (s *markerSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	buf := s.pool.Get().(*bytes.Buffer)
+	defer s.pool.Put(buf)
+	buf.Reset()
+
+	buf.WriteString("slog ")
+	buf.WriteString(ent.Level.String())
+	buf.WriteByte(' ')
+	if len(ent.LoggerNames) > 0 {
+		buf.WriteString(strings.Join(ent.LoggerNames, "."))
+		buf.WriteString(": ")
+	}
+	buf.WriteString(oneLine(ent.Message))
+	for _, f := range ent.Fields {
+		buf.WriteByte(' ')
+		buf.WriteString(oneLine(f.Name))
+		buf.WriteByte('=')
+		buf.WriteString(value(f.Value))
+	}
+
+	b := buf.Bytes()
+	if len(b) > MaxSize-1 {
+		b = b[:MaxSize-1]
+	}
+	b = append(b, '\n')
+
+	// Errors are ignored as tracing is best effort and
+	// writes fail while tracing is off.
+	_, _ = s.w.Write(b)
+}
+
+func (s *markerSink) Sync() {}
+
+var newlines = strings.NewReplacer("\n", `\n`, "\r", `\r`)
+
+func oneLine(s string) string {
+	return newlines.Replace(s)
+}
+
+func value(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		b, err := json.Marshal(slog.M(slog.F("v", v)))
+		if err != nil {
+			s = fmt.Sprint(v)
+		} else {
+			s = string(b[len(`{"v":`) : len(b)-1])
+		}
+	}
+
+	if strings.ContainsAny(s, " \t\n\r\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package slogtracemarker_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogtracemarker"
+)
+
+var bg = context.Background()
+
+func TestSink(t *testing.T) {
+	t.Parallel()
+
+	var b bytes.Buffer
+	l := slog.Make(slogtracemarker.Sink(&b)).Named("http")
+	l.Info(bg, "request\ndone",
+		slog.F("status", 200),
+		slog.F("dur", 1200*time.Microsecond),
+		slog.F("path", "/a b"),
+		slog.Error(xerrors.New("boom")),
+	)
+	assert.Equal(t, "entry", "slog INFO http: request\\ndone status=200 dur=1.2ms path=\"/a b\" error=boom\n", b.String())
+
+	b.Reset()
+	l.Info(bg, strings.Repeat("x", 2*slogtracemarker.MaxSize))
+	assert.Equal(t, "size", slogtracemarker.MaxSize, b.Len())
+	assert.True(t, "newline", strings.HasSuffix(b.String(), "x\n"))
+}
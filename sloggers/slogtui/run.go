@@ -0,0 +1,194 @@
+package slogtui
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/entryhuman"
+)
+
+// help is the list of keys in the status line.
+const help = "space pause  / search  l level  n logger  f field  c clear  q quit"
+
+// Run shows the entries in the terminal out and reads the keys from in
+// until q is pressed or ctx is done. If in is a terminal, it is put in
+// raw mode so that keys are read as they are pressed.
+//
+// The keys are:
+//
+//	space  pause or resume showing new entries
+//	/      search the messages and fields
+//	l      cycle the minimum level
+//	n      filter by a prefix of the logger names
+//	f      filter by a field, e.g. "user" or "user=alice"
+//	c      clear the filters
+//	q      stop the viewer
+//
+// Only one Run may be in progress at a time. When ctx is done, Run
+// returns while a read from in may continue in the background.
+func (v *Viewer) Run(ctx context.Context, in io.Reader, out io.Writer) error {
+	if f, ok := in.(*os.File); ok && terminal.IsTerminal(int(f.Fd())) {
+		state, err := terminal.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return xerrors.Errorf("failed to put terminal in raw mode: %w", err)
+		}
+		defer terminal.Restore(int(f.Fd()), state)
+	}
+
+	keys := make(chan byte)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(keys)
+		r := bufio.NewReader(in)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			select {
+			case keys <- b:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	s := &screen{v: v, out: out}
+	s.draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-v.changed:
+			if !s.paused {
+				s.draw()
+			}
+		case b, ok := <-keys:
+			if !ok || !s.key(b) {
+				// Leave the last screen and move below it.
+				io.WriteString(out, "\r\n")
+				return nil
+			}
+			s.draw()
+		}
+	}
+}
+
+// screen is the state of a Run.
+type screen struct {
+	v      *Viewer
+	out    io.Writer
+	filter Filter
+	paused bool
+
+	// prompt is the name of the filter being typed, if any.
+	prompt string
+	input  string
+}
+
+// key handles a key and reports whether to continue.
+func (s *screen) key(b byte) bool {
+	if s.prompt != "" {
+		switch b {
+		case '\r', '\n':
+			s.apply()
+			s.prompt = ""
+		case 0x1b:
+			s.prompt = ""
+		case 0x7f, '\b':
+			if s.input != "" {
+				s.input = s.input[:len(s.input)-1]
+			}
+		default:
+			if b >= ' ' {
+				s.input += string(b)
+			}
+		}
+		return true
+	}
+
+	switch b {
+	case 'q', 0x03:
+		// Ctrl-C is a key in raw mode.
+		return false
+	case ' ', 'p':
+		s.paused = !s.paused
+	case 'l':
+		s.filter.Level = (s.filter.Level + 1) % (slog.LevelFatal + 1)
+	case 'c':
+		s.filter = Filter{}
+	case '/':
+		s.prompt, s.input = "search", s.filter.Search
+	case 'n':
+		s.prompt, s.input = "logger", s.filter.Logger
+	case 'f':
+		s.prompt, s.input = "field", s.filter.Field
+		if s.filter.Value != "" {
+			s.input += "=" + s.filter.Value
+		}
+	}
+	return true
+}
+
+// apply sets the filter of the prompt to its input.
+func (s *screen) apply() {
+	switch s.prompt {
+	case "search":
+		s.filter.Search = s.input
+	case "logger":
+		s.filter.Logger = s.input
+	case "field":
+		s.filter.Field, s.filter.Value = s.input, ""
+		if i := strings.IndexByte(s.input, '='); i >= 0 {
+			s.filter.Field, s.filter.Value = s.input[:i], s.input[i+1:]
+		}
+	}
+}
+
+// draw redraws the newest entries that match the filter
+// and fit above the status line.
+func (s *screen) draw() {
+	// Lines are not truncated if out is not a terminal.
+	width, height := 0, 24
+	if f, ok := s.out.(*os.File); ok {
+		if w, h, err := terminal.GetSize(int(f.Fd())); err == nil {
+			width, height = w, h
+		}
+	}
+
+	var lines []string
+	ents := s.v.Entries(s.filter)
+	for i := len(ents) - 1; i >= 0 && len(lines) < height-1; i-- {
+		ent := entryhuman.FitWidth(entryhuman.Fmt(s.out, ents[i]), width, false)
+		entLines := strings.Split(ent, "\n")
+		lines = append(entLines, lines...)
+	}
+	if len(lines) > height-1 {
+		lines = lines[len(lines)-(height-1):]
+	}
+
+	status := s.filter.String() + "  |  " + help
+	if s.paused {
+		status = "[paused] " + status
+	}
+	if s.prompt != "" {
+		status = s.prompt + ": " + s.input
+	}
+
+	var b strings.Builder
+	// Move home and clear the screen.
+	b.WriteString("\x1b[H\x1b[2J")
+	for _, line := range lines {
+		b.WriteString(line + "\r\n")
+	}
+	b.WriteString("\x1b[7m" + entryhuman.FitWidth(status, width, false) + "\x1b[0m")
+	io.WriteString(s.out, b.String())
+}
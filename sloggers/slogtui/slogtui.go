@@ -0,0 +1,162 @@
+// Package slogtui contains a sink that keeps the recent entries for an
+// interactive viewer in the terminal, e.g. for a local development mode:
+//
+//	v := slogtui.Make(nil)
+//	log := slog.Make(v)
+//	if *dev {
+//		go v.Run(ctx, os.Stdin, os.Stdout)
+//	}
+//
+// The viewer filters the entries by level, logger name, field and text
+// and can pause while entries keep being kept.
+package slogtui // import "cdr.dev/slog/sloggers/slogtui"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"cdr.dev/slog"
+)
+
+// Options represents the options for the viewer returned by Make.
+type Options struct {
+	// Size is the number of entries kept. Defaults to 10000.
+	Size int
+}
+
+// Viewer is a sink that keeps the last entries in a ring buffer
+// and shows them in the terminal with Run.
+//
+// See Make.
+type Viewer struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+	// next is the index of the oldest entry once entries is full.
+	next int
+
+	// changed receives a value when an entry is logged.
+	changed chan struct{}
+}
+
+var _ slog.Sink = &Viewer{}
+
+// Make returns a viewer that keeps the last entries logged to it.
+//
+// If opts is nil, the defaults are used.
+func Make(opts *Options) *Viewer {
+	if opts == nil {
+		opts = &Options{}
+	}
+	size := opts.Size
+	if size <= 0 {
+		size = 10000
+	}
+	return &Viewer{
+		entries: make([]slog.SinkEntry, 0, size),
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// LogEntry implements slog.Sink.
+func (v *Viewer) LogEntry(_ context.Context, ent slog.SinkEntry) {
+	v.mu.Lock()
+	if len(v.entries) < cap(v.entries) {
+		v.entries = append(v.entries, ent)
+	} else {
+		v.entries[v.next] = ent
+		v.next = (v.next + 1) % len(v.entries)
+	}
+	v.mu.Unlock()
+
+	select {
+	case v.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Sync implements slog.Sink.
+func (v *Viewer) Sync() {}
+
+// Entries returns the kept entries that match f from the oldest
+// to the newest.
+func (v *Viewer) Entries(f Filter) []slog.SinkEntry {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var ents []slog.SinkEntry
+	for i := range v.entries {
+		ent := v.entries[(v.next+i)%len(v.entries)]
+		if f.Match(ent) {
+			ents = append(ents, ent)
+		}
+	}
+	return ents
+}
+
+// Filter selects the entries shown by the viewer.
+// The zero value matches every entry.
+type Filter struct {
+	// Level is the minimum level.
+	Level slog.Level
+	// Logger is a prefix of the logger names joined with a period,
+	// e.g. "http" for the entries of http and http.client.
+	Logger string
+	// Field is the name of a field the entries must have. If Value
+	// is set, it is the value of the field formatted with fmt.Sprint.
+	Field string
+	Value string
+	// Search is text that the message or a field must contain,
+	// ignoring case.
+	Search string
+}
+
+// Match reports whether ent matches f.
+func (f Filter) Match(ent slog.SinkEntry) bool {
+	if ent.Level < f.Level {
+		return false
+	}
+	if f.Logger != "" && !strings.HasPrefix(strings.Join(ent.LoggerNames, "."), f.Logger) {
+		return false
+	}
+	if f.Field != "" {
+		v, ok := ent.Fields.Get(f.Field)
+		if !ok || (f.Value != "" && fmt.Sprint(v) != f.Value) {
+			return false
+		}
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if strings.Contains(strings.ToLower(ent.Message), search) {
+			return true
+		}
+		for _, field := range ent.Fields {
+			s := strings.ToLower(field.Name + "=" + fmt.Sprint(field.Value))
+			if strings.Contains(s, search) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// String returns a summary of the filter for the status line.
+func (f Filter) String() string {
+	parts := []string{"level>=" + strings.ToLower(f.Level.String())}
+	if f.Logger != "" {
+		parts = append(parts, "logger="+f.Logger)
+	}
+	if f.Field != "" {
+		field := "field=" + f.Field
+		if f.Value != "" {
+			field += "=" + f.Value
+		}
+		parts = append(parts, field)
+	}
+	if f.Search != "" {
+		parts = append(parts, "search="+f.Search)
+	}
+	return strings.Join(parts, " ")
+}
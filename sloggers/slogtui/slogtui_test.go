@@ -0,0 +1,66 @@
+package slogtui_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogtui"
+)
+
+var bg = context.Background()
+
+func TestViewer(t *testing.T) {
+	t.Parallel()
+
+	v := slogtui.Make(&slogtui.Options{Size: 3})
+	l := slog.Make(v)
+	l.Info(bg, "dropped")
+	l.Named("http").Info(bg, "request", slog.F("user", "alice"))
+	l.Named("http").Named("client").Warn(bg, "retry", slog.F("user", "bob"))
+	l.Named("db").Error(bg, "query failed", slog.F("table", "Users"))
+
+	messages := func(f slogtui.Filter) []string {
+		var msgs []string
+		for _, ent := range v.Entries(f) {
+			msgs = append(msgs, ent.Message)
+		}
+		return msgs
+	}
+	assert.Equal(t, "all", []string{"request", "retry", "query failed"}, messages(slogtui.Filter{}))
+	assert.Equal(t, "level", []string{"retry", "query failed"}, messages(slogtui.Filter{Level: slog.LevelWarn}))
+	assert.Equal(t, "logger", []string{"request", "retry"}, messages(slogtui.Filter{Logger: "http"}))
+	assert.Equal(t, "field", []string{"request", "retry"}, messages(slogtui.Filter{Field: "user"}))
+	assert.Equal(t, "value", []string{"retry"}, messages(slogtui.Filter{Field: "user", Value: "bob"}))
+	assert.Equal(t, "search", []string{"query failed"}, messages(slogtui.Filter{Search: "USERS"}))
+
+	assert.Equal(t, "string", "level>=warn logger=http field=user=bob search=x", slogtui.Filter{
+		Level:  slog.LevelWarn,
+		Logger: "http",
+		Field:  "user",
+		Value:  "bob",
+		Search: "x",
+	}.String())
+}
+
+func TestViewer_Run(t *testing.T) {
+	t.Parallel()
+
+	v := slogtui.Make(nil)
+	l := slog.Make(v)
+	l.Info(bg, "hello", slog.F("user", "alice"))
+	l.Info(bg, "bye", slog.F("user", "bob"))
+
+	var out bytes.Buffer
+	err := v.Run(bg, strings.NewReader("fuser=bob\r q"), &out)
+	assert.Success(t, "run", err)
+
+	frames := strings.Split(out.String(), "\x1b[H\x1b[2J")
+	last := frames[len(frames)-1]
+	assert.True(t, "bye", strings.Contains(last, "bye"))
+	assert.False(t, "hello", strings.Contains(last, "hello"))
+	assert.True(t, "status", strings.Contains(last, "[paused] level>=debug field=user=bob"))
+}
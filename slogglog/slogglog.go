@@ -0,0 +1,408 @@
+// Package slogglog implements a glog and klog compatible API on top of slog.
+//
+// It is meant to ease migrating a codebase from glog one package at a time.
+// Replace the glog import with this package, call InitFlags to register
+// the -v and -vmodule flags and SetLogger to choose where logs go.
+//
+// Verbose logs are logged at slog.LevelInfo with a "v" field containing
+// their verbosity as glog does not have a separate severity for them.
+package slogglog // import "cdr.dev/slog/slogglog"
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+var (
+	mu     sync.RWMutex
+	logger = slog.Make(sloghuman.Sink(os.Stderr)).Leveled(slog.LevelDebug)
+
+	verbosity Level
+	vmodule   moduleSpec
+
+	// vcache caches the verbosity for each V call site.
+	// Entries from an older vgen are stale and recomputed.
+	// vgen is incremented whenever -v or -vmodule change.
+	vcache sync.Map
+	vgen   uint32
+)
+
+type vcacheEntry struct {
+	gen   uint32
+	level Level
+}
+
+// SetLogger sets the logger used by all functions in this package.
+//
+// The default logger writes to os.Stderr in the human format.
+func SetLogger(l slog.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	logger = l
+}
+
+func getLogger() slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logger
+}
+
+// InitFlags registers the -v and -vmodule flags on fs.
+//
+// If fs is nil, flag.CommandLine is used.
+func InitFlags(fs *flag.FlagSet) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	fs.Var(&verbosity, "v", "log level for V logs")
+	fs.Var(&vmodule, "vmodule", "comma-separated list of pattern=N settings for file-filtered logging")
+}
+
+// SetVerbosity sets the global verbosity as with -v.
+func SetVerbosity(v Level) {
+	verbosity.set(v)
+}
+
+// SetVModule sets the per file verbosity as with -vmodule.
+func SetVModule(spec string) error {
+	return vmodule.Set(spec)
+}
+
+// Level is the verbosity of a V log.
+//
+// It implements flag.Value.
+type Level int32
+
+var _ flag.Getter = new(Level)
+
+func (l *Level) get() Level {
+	return Level(atomic.LoadInt32((*int32)(l)))
+}
+
+func (l *Level) set(v Level) {
+	atomic.StoreInt32((*int32)(l), int32(v))
+	atomic.AddUint32(&vgen, 1)
+}
+
+// String implements flag.Value.
+func (l *Level) String() string {
+	return strconv.Itoa(int(l.get()))
+}
+
+// Get implements flag.Getter.
+func (l *Level) Get() interface{} {
+	return l.get()
+}
+
+// Set implements flag.Value.
+func (l *Level) Set(s string) error {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return xerrors.Errorf("invalid verbosity %q: %w", s, err)
+	}
+	l.set(Level(v))
+	return nil
+}
+
+type modulePattern struct {
+	pattern string
+	// full is true when pattern should be matched
+	// against the full file path instead of the base name.
+	full  bool
+	level Level
+}
+
+// moduleSpec implements flag.Value for -vmodule.
+type moduleSpec struct {
+	mu       sync.RWMutex
+	spec     string
+	patterns []modulePattern
+}
+
+func (m *moduleSpec) String() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.spec
+}
+
+func (m *moduleSpec) Set(spec string) error {
+	var patterns []modulePattern
+	for _, p := range strings.Split(spec, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		eq := strings.LastIndexByte(p, '=')
+		if eq <= 0 {
+			return xerrors.Errorf("invalid vmodule pattern %q: expected pattern=N", p)
+		}
+		pattern := strings.TrimSuffix(p[:eq], ".go")
+		v, err := strconv.Atoi(p[eq+1:])
+		if err != nil {
+			return xerrors.Errorf("invalid vmodule pattern %q: %w", p, err)
+		}
+		_, err = filepath.Match(pattern, "")
+		if err != nil {
+			return xerrors.Errorf("invalid vmodule pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, modulePattern{
+			pattern: pattern,
+			full:    strings.Contains(pattern, "/"),
+			level:   Level(v),
+		})
+	}
+
+	m.mu.Lock()
+	m.spec = spec
+	m.patterns = patterns
+	m.mu.Unlock()
+
+	atomic.AddUint32(&vgen, 1)
+	return nil
+}
+
+// level returns the verbosity configured for file.
+func (m *moduleSpec) level(file string) (Level, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	file = strings.TrimSuffix(file, ".go")
+	base := filepath.Base(file)
+	for _, p := range m.patterns {
+		name := base
+		if p.full {
+			name = file
+		}
+		if ok, _ := filepath.Match(p.pattern, name); ok {
+			return p.level, true
+		}
+	}
+	return 0, false
+}
+
+// Verbose is returned by V and logs only if
+// the requested verbosity is enabled.
+type Verbose struct {
+	enabled bool
+	level   Level
+}
+
+// V reports whether verbosity at the call site is at least level.
+//
+// -vmodule takes precedence over -v for matching files.
+func V(level Level) Verbose {
+	return Verbose{
+		enabled: enabled(level),
+		level:   level,
+	}
+}
+
+func enabled(level Level) bool {
+	if verbosity.get() >= level {
+		return true
+	}
+
+	pc, file, _, ok := runtime.Caller(2)
+	if !ok {
+		return false
+	}
+	gen := atomic.LoadUint32(&vgen)
+	if e, ok := vcache.Load(pc); ok && e.(vcacheEntry).gen == gen {
+		return e.(vcacheEntry).level >= level
+	}
+	v, ok := vmodule.level(file)
+	if !ok {
+		v = verbosity.get()
+	}
+	vcache.Store(pc, vcacheEntry{gen: gen, level: v})
+	return v >= level
+}
+
+// Enabled reports whether logs at this verbosity will be written.
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Info is like fmt.Sprint but logs if v is enabled.
+func (v Verbose) Info(args ...interface{}) {
+	slog.Helper()
+	if v.enabled {
+		v.log(fmt.Sprint(args...))
+	}
+}
+
+// Infoln is like fmt.Sprintln but logs if v is enabled.
+func (v Verbose) Infoln(args ...interface{}) {
+	slog.Helper()
+	if v.enabled {
+		v.log(fmt.Sprintln(args...))
+	}
+}
+
+// Infof is like fmt.Sprintf but logs if v is enabled.
+func (v Verbose) Infof(format string, args ...interface{}) {
+	slog.Helper()
+	if v.enabled {
+		v.log(fmt.Sprintf(format, args...))
+	}
+}
+
+// InfoS logs msg with the key value pairs in kv as fields if v is enabled.
+func (v Verbose) InfoS(msg string, kv ...interface{}) {
+	slog.Helper()
+	if v.enabled {
+		v.log(msg, kvFields(kv)...)
+	}
+}
+
+func (v Verbose) log(msg string, fields ...slog.Field) {
+	slog.Helper()
+	fields = append([]slog.Field{slog.F("v", int(v.level))}, fields...)
+	log(slog.LevelInfo, msg, fields...)
+}
+
+// Info is like fmt.Sprint but logs at slog.LevelInfo.
+func Info(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelInfo, fmt.Sprint(args...))
+}
+
+// Infoln is like fmt.Sprintln but logs at slog.LevelInfo.
+func Infoln(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelInfo, fmt.Sprintln(args...))
+}
+
+// Infof is like fmt.Sprintf but logs at slog.LevelInfo.
+func Infof(format string, args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// InfoS logs msg with the key value pairs in kv as fields at slog.LevelInfo.
+func InfoS(msg string, kv ...interface{}) {
+	slog.Helper()
+	log(slog.LevelInfo, msg, kvFields(kv)...)
+}
+
+// Warning is like fmt.Sprint but logs at slog.LevelWarn.
+func Warning(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelWarn, fmt.Sprint(args...))
+}
+
+// Warningln is like fmt.Sprintln but logs at slog.LevelWarn.
+func Warningln(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelWarn, fmt.Sprintln(args...))
+}
+
+// Warningf is like fmt.Sprintf but logs at slog.LevelWarn.
+func Warningf(format string, args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelWarn, fmt.Sprintf(format, args...))
+}
+
+// Error is like fmt.Sprint but logs at slog.LevelError.
+func Error(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelError, fmt.Sprint(args...))
+}
+
+// Errorln is like fmt.Sprintln but logs at slog.LevelError.
+func Errorln(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelError, fmt.Sprintln(args...))
+}
+
+// Errorf is like fmt.Sprintf but logs at slog.LevelError.
+func Errorf(format string, args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelError, fmt.Sprintf(format, args...))
+}
+
+// ErrorS logs msg with err and the key value pairs in kv as fields
+// at slog.LevelError.
+func ErrorS(err error, msg string, kv ...interface{}) {
+	slog.Helper()
+	fields := kvFields(kv)
+	if err != nil {
+		fields = append([]slog.Field{slog.Error(err)}, fields...)
+	}
+	log(slog.LevelError, msg, fields...)
+}
+
+// Fatal is like fmt.Sprint but logs at slog.LevelFatal and exits.
+func Fatal(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelFatal, fmt.Sprint(args...))
+}
+
+// Fatalln is like fmt.Sprintln but logs at slog.LevelFatal and exits.
+func Fatalln(args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelFatal, fmt.Sprintln(args...))
+}
+
+// Fatalf is like fmt.Sprintf but logs at slog.LevelFatal and exits.
+func Fatalf(format string, args ...interface{}) {
+	slog.Helper()
+	log(slog.LevelFatal, fmt.Sprintf(format, args...))
+}
+
+// Flush syncs the logger.
+func Flush() {
+	getLogger().Sync()
+}
+
+var ctx = context.Background()
+
+func log(level slog.Level, msg string, fields ...slog.Field) {
+	slog.Helper()
+
+	// glog adds a trailing newline when missing
+	// so callers often include one themselves.
+	msg = strings.TrimSuffix(msg, "\n")
+
+	l := getLogger()
+	switch level {
+	case slog.LevelInfo:
+		l.Info(ctx, msg, fields...)
+	case slog.LevelWarn:
+		l.Warn(ctx, msg, fields...)
+	case slog.LevelError:
+		l.Error(ctx, msg, fields...)
+	case slog.LevelFatal:
+		l.Fatal(ctx, msg, fields...)
+	}
+}
+
+// kvFields converts klog style key value pairs into fields.
+func kvFields(kv []interface{}) []slog.Field {
+	fields := make([]slog.Field, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		k, ok := kv[i].(string)
+		if !ok {
+			k = fmt.Sprint(kv[i])
+		}
+		if i+1 >= len(kv) {
+			fields = append(fields, slog.F(k, "(MISSING)"))
+			break
+		}
+		fields = append(fields, slog.F(k, kv[i+1]))
+	}
+	return fields
+}
@@ -0,0 +1,87 @@
+package slogglog_test
+
+import (
+	"context"
+	"flag"
+	"io"
+	"runtime"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogglog"
+)
+
+var _, slogglogTestFile, _, _ = runtime.Caller(0)
+
+type fakeSink struct {
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Sync() {}
+
+// These tests cannot be parallel as they modify global state.
+
+func TestGlog(t *testing.T) {
+	s := &fakeSink{}
+	slogglog.SetLogger(slog.Make(s))
+
+	slogglog.Infof("hello %v", "world")
+	slogglog.Warningln("warn")
+	slogglog.ErrorS(io.EOF, "failed", "attempt", 3, "dangling")
+
+	assert.Len(t, "entries", 3, s.entries)
+
+	assert.Equal(t, "msg", "hello world", s.entries[0].Message)
+	assert.Equal(t, "level", slog.LevelInfo, s.entries[0].Level)
+	assert.Equal(t, "file", slogglogTestFile, s.entries[0].File)
+	assert.Equal(t, "line", 33, s.entries[0].Line)
+
+	assert.Equal(t, "msg", "warn", s.entries[1].Message)
+	assert.Equal(t, "level", slog.LevelWarn, s.entries[1].Level)
+
+	assert.Equal(t, "level", slog.LevelError, s.entries[2].Level)
+	assert.Equal(t, "fields", slog.M(
+		slog.Error(io.EOF),
+		slog.F("attempt", 3),
+		slog.F("dangling", "(MISSING)"),
+	), s.entries[2].Fields)
+}
+
+func TestV(t *testing.T) {
+	s := &fakeSink{}
+	slogglog.SetLogger(slog.Make(s))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	slogglog.InitFlags(fs)
+	err := fs.Parse([]string{"-v=1"})
+	assert.Success(t, "parse flags", err)
+	t.Cleanup(func() {
+		slogglog.SetVerbosity(0)
+		slogglog.SetVModule("")
+	})
+
+	slogglog.V(1).Info("v1")
+	slogglog.V(2).Info("v2")
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "msg", "v1", s.entries[0].Message)
+	assert.Equal(t, "fields", slog.M(slog.F("v", 1)), s.entries[0].Fields)
+	assert.Equal(t, "line", 68, s.entries[0].Line)
+
+	err = fs.Parse([]string{"-vmodule=slogglog_t*=3"})
+	assert.Success(t, "parse flags", err)
+
+	assert.True(t, "v3 enabled", slogglog.V(3).Enabled())
+	assert.False(t, "v4 enabled", slogglog.V(4).Enabled())
+
+	err = slogglog.SetVModule("other=3")
+	assert.Success(t, "set vmodule", err)
+	assert.False(t, "v3 enabled", slogglog.V(3).Enabled())
+
+	err = slogglog.SetVModule("bad")
+	assert.Error(t, "set vmodule", err)
+}
@@ -0,0 +1,209 @@
+// Package slogproto encodes protobuf messages in fields with protojson.
+//
+// Without it, messages are encoded with reflection like any other struct
+// which exposes the internal state of the generated types, e.g. their
+// XXX_ and unexported fields, instead of the fields of the message.
+//
+// Importing the package registers an encoder with the defaults for every
+// proto.Message with slog.RegisterEncoder. Use Register to change the
+// options or Logger.WithEncoder and Encoder to use them for a single logger.
+package slogproto // import "cdr.dev/slog/slogproto"
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/slogval"
+)
+
+// Options represents the options for the encoder returned by Encoder.
+type Options struct {
+	// Marshal controls the protojson encoding, e.g. UseProtoNames.
+	// Multiline and Indent are ignored.
+	Marshal protojson.MarshalOptions
+	// Masks restricts the messages with the given full names to the
+	// paths of their field mask. Fields outside of the mask are removed
+	// wherever the message appears, including in other messages, unless
+	// it is within a field narrowed by the path of another mask.
+	Masks map[protoreflect.FullName]*fieldmaskpb.FieldMask
+	// Sensitive are bool extensions of google.protobuf.FieldOptions that
+	// mark fields as sensitive. Fields with any of them set to true are
+	// removed, e.g. with
+	//
+	//	extend google.protobuf.FieldOptions {
+	//	  bool sensitive = 50000;
+	//	}
+	//
+	//	string password = 2 [(sensitive) = true];
+	Sensitive []protoreflect.ExtensionType
+}
+
+// Encoder returns an encoder of proto.Message values to pass
+// to slog.RegisterEncoder or slog.Logger.WithEncoder.
+//
+// Nil messages are encoded as null.
+//
+// If opts is nil, the defaults are used.
+func Encoder(opts *Options) func(proto.Message) slog.Value {
+	if opts == nil {
+		opts = &Options{}
+	}
+	e := &encoder{
+		opts:  opts,
+		masks: make(map[protoreflect.FullName]map[string][]string, len(opts.Masks)),
+	}
+	for name, mask := range opts.Masks {
+		e.masks[name] = maskTree(mask.GetPaths())
+	}
+	return e.encode
+}
+
+// Register registers the encoder returned by Encoder with opts
+// for every proto.Message, replacing the defaults.
+func Register(opts *Options) {
+	slog.RegisterEncoder(Encoder(opts))
+}
+
+func init() {
+	Register(nil)
+}
+
+type encoder struct {
+	opts *Options
+	// masks maps message names to the field names in their mask
+	// and the paths within those fields.
+	masks map[protoreflect.FullName]map[string][]string
+}
+
+func (e *encoder) encode(m proto.Message) slog.Value {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return slogval.Nil{}
+	}
+
+	if len(e.masks) > 0 || len(e.opts.Sensitive) > 0 {
+		m = proto.Clone(m)
+		e.filter(m.ProtoReflect(), false)
+	}
+
+	mo := e.opts.Marshal
+	mo.Multiline = false
+	mo.Indent = ""
+	b, err := mo.Marshal(m)
+	if err != nil {
+		return slog.ValueOf(err)
+	}
+	// protojson randomizes its whitespace so the output
+	// is decoded instead of used as is.
+	return slog.ValueOf(json.RawMessage(b))
+}
+
+// filter removes the fields of m that are outside of its mask or
+// sensitive, recursively. masked reports whether m was already
+// narrowed by the path of a mask.
+func (e *encoder) filter(m protoreflect.Message, masked bool) {
+	var tree map[string][]string
+	if !masked {
+		tree = e.masks[m.Descriptor().FullName()]
+		if tree != nil {
+			applyMask(m, tree)
+		}
+	}
+
+	var remove []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if e.sensitive(fd) {
+			remove = append(remove, fd)
+			return true
+		}
+		switch {
+		case fd.IsList():
+			if fd.Message() == nil {
+				break
+			}
+			l := v.List()
+			for i := 0; i < l.Len(); i++ {
+				e.filter(l.Get(i).Message(), false)
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				break
+			}
+			v.Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+				e.filter(v.Message(), false)
+				return true
+			})
+		case fd.Message() != nil:
+			e.filter(v.Message(), masked || tree[string(fd.Name())] != nil)
+		}
+		return true
+	})
+	for _, fd := range remove {
+		m.Clear(fd)
+	}
+}
+
+func (e *encoder) sensitive(fd protoreflect.FieldDescriptor) bool {
+	if len(e.opts.Sensitive) == 0 {
+		return false
+	}
+	opts := fd.Options()
+	if opts == nil {
+		return false
+	}
+	for _, xt := range e.opts.Sensitive {
+		if !proto.HasExtension(opts, xt) {
+			continue
+		}
+		if b, ok := proto.GetExtension(opts, xt).(bool); ok && b {
+			return true
+		}
+	}
+	return false
+}
+
+// maskTree groups the paths of a field mask by their first field name.
+// A field with no remaining paths is kept as a whole.
+func maskTree(paths []string) map[string][]string {
+	tree := make(map[string][]string, len(paths))
+	for _, p := range paths {
+		name, rest := p, ""
+		if i := strings.IndexByte(p, '.'); i >= 0 {
+			name, rest = p[:i], p[i+1:]
+		}
+
+		sub, ok := tree[name]
+		switch {
+		case ok && sub == nil:
+			// Already kept as a whole.
+		case rest == "":
+			tree[name] = nil
+		default:
+			tree[name] = append(sub, rest)
+		}
+	}
+	return tree
+}
+
+// applyMask removes the fields of m that are not in tree.
+func applyMask(m protoreflect.Message, tree map[string][]string) {
+	var remove []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := tree[string(fd.Name())]
+		switch {
+		case !ok:
+			remove = append(remove, fd)
+		case sub != nil && fd.Message() != nil && !fd.IsList() && !fd.IsMap():
+			applyMask(v.Message(), maskTree(sub))
+		}
+		return true
+	})
+	for _, fd := range remove {
+		m.Clear(fd)
+	}
+}
@@ -0,0 +1,145 @@
+package slogproto_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogproto"
+)
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	var nilDuration *durationpb.Duration
+	assert.Equal(t, "JSON", `{"d":"1.500s","nil":null}`, marshalJSON(t, slog.M(
+		slog.F("d", durationpb.New(1500*time.Millisecond)),
+		slog.F("nil", nilDuration),
+	)))
+}
+
+func TestEncoder(t *testing.T) {
+	t.Parallel()
+
+	sensitive, user := testTypes(t)
+	fd := user.Descriptor().Fields()
+
+	newUser := func(name, password string) protoreflect.Message {
+		m := user.New()
+		m.Set(fd.ByName("name"), protoreflect.ValueOfString(name))
+		m.Set(fd.ByName("password"), protoreflect.ValueOfString(password))
+		m.Set(fd.ByName("age"), protoreflect.ValueOfInt32(42))
+		return m
+	}
+	m := newUser("alice", "hunter2")
+	m.Set(fd.ByName("friend"), protoreflect.ValueOfMessage(newUser("bob", "letmein")))
+
+	enc := slogproto.Encoder(nil)
+	assert.Equal(t, "defaults", `{"age":42,"friend":{"age":42,"name":"bob","password":"letmein"},"name":"alice","password":"hunter2"}`, marshalValue(t, enc(m.Interface())))
+
+	enc = slogproto.Encoder(&slogproto.Options{
+		Sensitive: []protoreflect.ExtensionType{sensitive},
+	})
+	assert.Equal(t, "sensitive", `{"age":42,"friend":{"age":42,"name":"bob"},"name":"alice"}`, marshalValue(t, enc(m.Interface())))
+	assert.Equal(t, "original", "hunter2", m.Get(fd.ByName("password")).String())
+
+	enc = slogproto.Encoder(&slogproto.Options{
+		Masks: map[protoreflect.FullName]*fieldmaskpb.FieldMask{
+			user.Descriptor().FullName(): {Paths: []string{"name", "friend.age"}},
+		},
+	})
+	assert.Equal(t, "masks", `{"friend":{"age":42},"name":"alice"}`, marshalValue(t, enc(m.Interface())))
+}
+
+// testTypes returns a sensitive extension of FieldOptions and a User
+// message type with a password field marked with it.
+func testTypes(t *testing.T) (protoreflect.ExtensionType, protoreflect.MessageType) {
+	files := new(protoregistry.Files)
+	err := files.RegisterFile(descriptorpb.File_google_protobuf_descriptor_proto)
+	assert.Success(t, "register descriptor.proto", err)
+
+	extFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("slogproto/ext.proto"),
+		Package:    proto.String("slogproto"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("sensitive"),
+			Number:   proto.Int32(50000),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+			Extendee: proto.String(".google.protobuf.FieldOptions"),
+		}},
+	}, files)
+	assert.Success(t, "new ext.proto", err)
+	err = files.RegisterFile(extFile)
+	assert.Success(t, "register ext.proto", err)
+	sensitive := dynamicpb.NewExtensionType(extFile.Extensions().Get(0))
+
+	passwordOpts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(passwordOpts, sensitive, true)
+
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+		}
+	}
+	password := field("password", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	password.Options = passwordOpts
+	friend := field("friend", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	friend.TypeName = proto.String(".slogproto.User")
+
+	userFile, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("slogproto/user.proto"),
+		Package:    proto.String("slogproto"),
+		Dependency: []string{"slogproto/ext.proto"},
+		Syntax:     proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				password,
+				field("age", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				friend,
+			},
+		}},
+	}, files)
+	assert.Success(t, "new user.proto", err)
+	return sensitive, dynamicpb.NewMessageType(userFile.Messages().Get(0))
+}
+
+func marshalJSON(t *testing.T, m slog.Map) string {
+	b, err := m.MarshalJSON()
+	assert.Success(t, "marshal map", err)
+	var buf bytes.Buffer
+	err = json.Compact(&buf, b)
+	assert.Success(t, "compact JSON", err)
+	return buf.String()
+}
+
+// marshalValue returns the JSON of v with the keys of objects sorted.
+func marshalValue(t *testing.T, v slog.Value) string {
+	b, err := json.Marshal(v)
+	assert.Success(t, "marshal value", err)
+	var i interface{}
+	err = json.Unmarshal(b, &i)
+	assert.Success(t, "unmarshal value", err)
+	b, err = json.Marshal(i)
+	assert.Success(t, "marshal sorted value", err)
+	return string(b)
+}
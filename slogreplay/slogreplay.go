@@ -0,0 +1,158 @@
+// Package slogreplay replays the entries of JSON logs to a sink, e.g. to
+// backfill an aggregator with the files written while it was down:
+//
+//	f, err := os.Open("app.log")
+//	...
+//	stats, err := slogreplay.Replay(f, sloghttp.Make(url, nil), &slogreplay.Options{
+//		Since: outageStart,
+//		Until: outageEnd,
+//	})
+//
+// The entries keep their original times, levels, names, locations and
+// fields. The input is the format of the slogjson sink, one entry per
+// line, including the delta encoding of slogjson.Options.DeltaSegment.
+package slogreplay // import "cdr.dev/slog/slogreplay"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogjson"
+)
+
+// Options represents the options for Replay.
+type Options struct {
+	// Since and Until limit the entries to those logged at or after
+	// Since and before Until. Zero values do not limit them.
+	Since time.Time
+	Until time.Time
+	// Filter returns whether to replay an entry. If nil,
+	// every entry is replayed.
+	Filter func(ent slog.SinkEntry) bool
+	// Rate is the maximum number of entries replayed per second so
+	// that the aggregator is not overwhelmed. Unlimited if zero.
+	Rate int
+	// OnError is called with the number of a line that cannot be
+	// decoded, starting at 1, and the error. If it returns an error,
+	// Replay stops with it. If nil, invalid lines are skipped, e.g. an
+	// entry truncated by a crash.
+	OnError func(line int, err error) error
+}
+
+// Stats are the numbers of lines handled by Replay.
+type Stats struct {
+	// Replayed is the number of entries logged to the sink.
+	Replayed int
+	// Skipped is the number of entries outside of the time range
+	// or rejected by the filter.
+	Skipped int
+	// Invalid is the number of lines that could not be decoded.
+	// Empty lines are not counted.
+	Invalid int
+}
+
+// Replay is like ReplayContext with context.Background.
+func Replay(r io.Reader, sink slog.Sink, opts *Options) (Stats, error) {
+	return ReplayContext(context.Background(), r, sink, opts)
+}
+
+// ReplayContext logs the entries read from r to sink with ctx until the
+// end of r and then flushes sink with slog.Flush. It stops early if ctx
+// is done.
+//
+// If opts is nil, the defaults are used.
+func ReplayContext(ctx context.Context, r io.Reader, sink slog.Sink, opts *Options) (Stats, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	var stats Stats
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Second / time.Duration(opts.Rate)
+	}
+	var next time.Time
+
+	d := slogjson.NewDecoder(nil)
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return stats, xerrors.Errorf("failed to read line %v: %w", n, err)
+		}
+		eof := err == io.EOF
+
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			ent, err := d.DecodeLine(line)
+			switch {
+			case err != nil:
+				stats.Invalid++
+				if opts.OnError != nil {
+					err = opts.OnError(n, err)
+					if err != nil {
+						return stats, err
+					}
+				}
+			case !opts.replays(ent):
+				stats.Skipped++
+			default:
+				if interval > 0 {
+					err = wait(ctx, next)
+					if err != nil {
+						return stats, err
+					}
+					next = time.Now().Add(interval)
+				}
+				if ctx.Err() != nil {
+					return stats, ctx.Err()
+				}
+				sink.LogEntry(ctx, ent)
+				stats.Replayed++
+			}
+		}
+
+		if eof {
+			break
+		}
+	}
+
+	err := slog.Flush(ctx, sink)
+	if err != nil {
+		return stats, xerrors.Errorf("failed to flush sink: %w", err)
+	}
+	return stats, nil
+}
+
+// replays reports whether ent is replayed.
+func (opts *Options) replays(ent slog.SinkEntry) bool {
+	if !opts.Since.IsZero() && ent.Time.Before(opts.Since) {
+		return false
+	}
+	if !opts.Until.IsZero() && !ent.Time.Before(opts.Until) {
+		return false
+	}
+	return opts.Filter == nil || opts.Filter(ent)
+}
+
+// wait waits until t or until ctx is done.
+func wait(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
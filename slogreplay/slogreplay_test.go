@@ -0,0 +1,106 @@
+package slogreplay_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/sloggers/slogjson"
+	"cdr.dev/slog/slogreplay"
+)
+
+var bg = context.Background()
+
+type fakeSink struct {
+	entries []slog.SinkEntry
+	syncs   int
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Sync() {
+	s.syncs++
+}
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// logs returns the slogjson output of an entry per message logged a
+// second apart, with a truncated line after the first.
+func logs(msgs ...string) string {
+	b := &bytes.Buffer{}
+	e := slogjson.Encoder(&slogjson.Options{DeltaSegment: 10})
+	for i, msg := range msgs {
+		b.Write(e.Encode(nil, slog.SinkEntry{
+			Time:        start.Add(time.Duration(i) * time.Second),
+			Level:       slog.LevelWarn,
+			Message:     msg,
+			LoggerNames: []string{"api"},
+			Fields:      slog.M(slog.F("svc", "api"), slog.F("i", i)),
+		}))
+		b.WriteByte('\n')
+		if i == 0 {
+			b.WriteString(`{"ts": "2020-01-01T00:`)
+			b.WriteString("\n\n")
+		}
+	}
+	return b.String()
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	stats, err := slogreplay.Replay(strings.NewReader(logs("a", "b", "c", "d")), s, &slogreplay.Options{
+		Since: start.Add(time.Second),
+		Filter: func(ent slog.SinkEntry) bool {
+			return ent.Message != "d"
+		},
+	})
+	assert.Success(t, "replay", err)
+	assert.Equal(t, "stats", slogreplay.Stats{Replayed: 2, Skipped: 2, Invalid: 1}, stats)
+	assert.Equal(t, "syncs", 1, s.syncs)
+
+	assert.Len(t, "entries", 2, s.entries)
+	ent := s.entries[0]
+	assert.Equal(t, "msg", "b", ent.Message)
+	assert.True(t, "time", start.Add(time.Second).Equal(ent.Time))
+	assert.Equal(t, "level", slog.LevelWarn, ent.Level)
+	assert.Equal(t, "names", []string{"api"}, ent.LoggerNames)
+	// The fields shared with the previous entry are restored.
+	assert.Equal(t, "svc", "api", s.entries[1].Fields[0].Value)
+}
+
+func TestReplay_OnError(t *testing.T) {
+	t.Parallel()
+
+	var lines []int
+	_, err := slogreplay.Replay(strings.NewReader(logs("a", "b")), &fakeSink{}, &slogreplay.Options{
+		OnError: func(line int, err error) error {
+			lines = append(lines, line)
+			return xerrors.Errorf("line %v: %w", line, err)
+		},
+	})
+	assert.Error(t, "replay", err)
+	assert.Equal(t, "lines", []int{2}, lines)
+}
+
+func TestReplay_Rate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(bg)
+	cancel()
+	s := &fakeSink{}
+	stats, err := slogreplay.ReplayContext(ctx, strings.NewReader(logs("a", "b")), s, &slogreplay.Options{
+		Rate: 1,
+	})
+	assert.True(t, "canceled", xerrors.Is(err, context.Canceled))
+	assert.Equal(t, "replayed", 0, stats.Replayed)
+}
@@ -0,0 +1,77 @@
+// Command slogschema generates typed logging functions for the events
+// of a schema. See package cdr.dev/slog/slogschema for the format.
+//
+//	//go:generate go run cdr.dev/slog/slogschema/cmd/slogschema -in events.json -out events_gen.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog/slogschema"
+)
+
+func main() {
+	err := run(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "slogschema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("slogschema", flag.ContinueOnError)
+	in := fs.String("in", "", "path of the JSON schema")
+	out := fs.String("out", "", "path of the generated Go file, stdout if empty")
+	pkg := fs.String("package", "", "package of the generated code, overrides the schema")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: slogschema -in events.json [-out events_gen.go] [-package name]\n\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nsupported field types: %v\n", strings.Join(slogschema.Types(), ", "))
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if *in == "" {
+		fs.Usage()
+		return xerrors.New("missing -in")
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var s slogschema.Schema
+	err = json.NewDecoder(f).Decode(&s)
+	if err != nil {
+		return xerrors.Errorf("failed to decode %v: %w", *in, err)
+	}
+	if *pkg != "" {
+		s.Package = *pkg
+	}
+	err = s.Validate()
+	if err != nil {
+		return xerrors.Errorf("invalid schema %v: %w", *in, err)
+	}
+
+	var b bytes.Buffer
+	err = slogschema.Generate(&b, &s)
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(b.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(*out, b.Bytes(), 0644)
+}
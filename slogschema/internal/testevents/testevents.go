@@ -0,0 +1,5 @@
+// Package testevents contains the code generated for the schema
+// in events.json to test slogschema.
+package testevents
+
+//go:generate go run cdr.dev/slog/slogschema/cmd/slogschema -in events.json -out events_gen.go
@@ -0,0 +1,339 @@
+// Package slogschema generates typed logging functions for events.
+//
+// The schema of the events is a JSON file:
+//
+//	{
+//	  "package": "logevents",
+//	  "events": [{
+//	    "name": "user_login",
+//	    "doc": "A user logged in.",
+//	    "level": "INFO",
+//	    "message": "user logged in",
+//	    "fields": [
+//	      {"name": "user_id", "type": "string", "required": true},
+//	      {"name": "method", "type": "string"},
+//	      {"name": "duration", "type": "time.Duration"}
+//	    ]
+//	  }]
+//	}
+//
+// For every event, the generated code registers its schema with
+// slog.RegisterEvent and has a function that logs it with slog.Emit.
+// The required fields are parameters of the function so that they
+// cannot be forgotten and the optional fields are in a struct:
+//
+//	logevents.UserLogin(ctx, log, userID, logevents.UserLoginEvent{
+//		Method: "password",
+//	})
+//
+// Optional fields are only logged if they are set, i.e. not the zero
+// value of their type. Generate the code with the slogschema command:
+//
+//	//go:generate go run cdr.dev/slog/slogschema/cmd/slogschema -in events.json -out events_gen.go
+package slogschema // import "cdr.dev/slog/slogschema"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// Schema is the schema of the events of a package.
+type Schema struct {
+	// Package is the name of the package of the generated code.
+	Package string             `json:"package"`
+	Events  []slog.EventSchema `json:"events"`
+}
+
+// isSet returns the expression that reports whether an optional
+// field of each supported type is set.
+var isSet = map[string]string{
+	"string":        "%v != \"\"",
+	"bool":          "%v",
+	"int":           "%v != 0",
+	"int32":         "%v != 0",
+	"int64":         "%v != 0",
+	"uint":          "%v != 0",
+	"uint32":        "%v != 0",
+	"uint64":        "%v != 0",
+	"float32":       "%v != 0",
+	"float64":       "%v != 0",
+	"time.Duration": "%v != 0",
+	"time.Time":     "!%v.IsZero()",
+	"error":         "%v != nil",
+	"interface{}":   "%v != nil",
+	"[]string":      "len(%v) > 0",
+	"[]int":         "len(%v) > 0",
+}
+
+// Parse reads and validates a schema.
+func Parse(r io.Reader) (*Schema, error) {
+	var s Schema
+	err := json.NewDecoder(r).Decode(&s)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to decode schema: %w", err)
+	}
+	err = s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Validate checks that the names of the package, events and fields are
+// valid and unique and that the types of the fields are supported.
+func (s *Schema) Validate() error {
+	if !token.IsIdentifier(s.Package) {
+		return xerrors.Errorf("invalid package name %q", s.Package)
+	}
+	names := make(map[string]bool)
+	for _, e := range s.Events {
+		if !slog.SnakeCasePattern.MatchString(e.Name) {
+			return xerrors.Errorf("event name %q is not snake_case", e.Name)
+		}
+		if names[e.Name] {
+			return xerrors.Errorf("duplicate event %q", e.Name)
+		}
+		names[e.Name] = true
+
+		fields := make(map[string]bool)
+		for _, f := range e.Fields {
+			if !slog.SnakeCasePattern.MatchString(f.Name) {
+				return xerrors.Errorf("field name %q of event %q is not snake_case", f.Name, e.Name)
+			}
+			if f.Name == "event" || fields[f.Name] {
+				return xerrors.Errorf("duplicate field %q of event %q", f.Name, e.Name)
+			}
+			fields[f.Name] = true
+			if _, ok := isSet[f.Type]; !ok {
+				return xerrors.Errorf("unsupported type %q of field %q of event %q", f.Type, f.Name, e.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Types returns the supported types of fields.
+func Types() []string {
+	types := make([]string, 0, len(isSet))
+	for t := range isSet {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Generate writes the code for s to w. s must be valid.
+func Generate(w io.Writer, s *Schema) error {
+	var b bytes.Buffer
+	g := &generator{b: &b}
+	g.file(s)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return xerrors.Errorf("failed to format generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+type generator struct {
+	b *bytes.Buffer
+}
+
+func (g *generator) p(format string, v ...interface{}) {
+	fmt.Fprintf(g.b, format+"\n", v...)
+}
+
+func (g *generator) file(s *Schema) {
+	g.p("// Code generated by slogschema. DO NOT EDIT.")
+	g.p("")
+	g.p("package %v", s.Package)
+	g.p("")
+	g.p("import (")
+	g.p("\t%q", "context")
+	if usesTime(s) {
+		g.p("\t%q", "time")
+	}
+	g.p("")
+	g.p("\t%q", "cdr.dev/slog")
+	g.p(")")
+	g.p("")
+
+	g.p("func init() {")
+	for _, e := range s.Events {
+		g.p("slog.RegisterEvent(slog.EventSchema{")
+		g.p("Name: %q,", e.Name)
+		if e.Doc != "" {
+			g.p("Doc: %q,", e.Doc)
+		}
+		g.p("Level: %v,", levelExpr(e.Level))
+		g.p("Message: %q,", e.Message)
+		if len(e.Fields) > 0 {
+			g.p("Fields: []slog.FieldSchema{")
+			for _, f := range e.Fields {
+				field := fmt.Sprintf("Name: %q, ", f.Name)
+				if f.Doc != "" {
+					field += fmt.Sprintf("Doc: %q, ", f.Doc)
+				}
+				field += fmt.Sprintf("Type: %q", f.Type)
+				if f.Required {
+					field += ", Required: true"
+				}
+				g.p("{%v},", field)
+			}
+			g.p("},")
+		}
+		g.p("})")
+	}
+	g.p("}")
+
+	for _, e := range s.Events {
+		g.event(e)
+	}
+}
+
+func (g *generator) event(e slog.EventSchema) {
+	name := goName(e.Name)
+	var required, optional []slog.FieldSchema
+	for _, f := range e.Fields {
+		if f.Required {
+			required = append(required, f)
+		} else {
+			optional = append(optional, f)
+		}
+	}
+
+	if len(optional) > 0 {
+		g.p("")
+		g.p("// %vEvent are the optional fields of the %v event.", name, e.Name)
+		g.p("// They are only logged if they are set.")
+		g.p("type %vEvent struct {", name)
+		for _, f := range optional {
+			g.doc(f.Doc)
+			g.p("%v %v", goName(f.Name), f.Type)
+		}
+		g.p("}")
+	}
+
+	g.p("")
+	g.p("// %v logs the %v event to l.", name, e.Name)
+	if e.Doc != "" {
+		g.p("//")
+		g.doc(e.Doc)
+	}
+	for _, f := range required {
+		if f.Doc != "" {
+			g.p("//")
+			g.doc(paramName(f.Name) + " is the " + f.Name + " field. " + f.Doc)
+		}
+	}
+	params := []string{"ctx context.Context", "l slog.Logger"}
+	for _, f := range required {
+		params = append(params, paramName(f.Name)+" "+f.Type)
+	}
+	if len(optional) > 0 {
+		params = append(params, "e "+name+"Event")
+	}
+	g.p("func %v(%v) {", name, strings.Join(params, ", "))
+	g.p("slog.Helper()")
+	if len(e.Fields) == 0 {
+		g.p("slog.Emit(ctx, l, %q)", e.Name)
+		g.p("}")
+		return
+	}
+	g.p("fields := make([]slog.Field, 0, %v)", len(e.Fields))
+	for _, f := range e.Fields {
+		if f.Required {
+			g.p("fields = append(fields, slog.F(%q, %v))", f.Name, paramName(f.Name))
+			continue
+		}
+		v := "e." + goName(f.Name)
+		g.p("if %v {", fmt.Sprintf(isSet[f.Type], v))
+		g.p("fields = append(fields, slog.F(%q, %v))", f.Name, v)
+		g.p("}")
+	}
+	g.p("slog.Emit(ctx, l, %q, fields...)", e.Name)
+	g.p("}")
+}
+
+func (g *generator) doc(doc string) {
+	if doc == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(doc), "\n") {
+		g.p("// %v", line)
+	}
+}
+
+func usesTime(s *Schema) bool {
+	for _, e := range s.Events {
+		for _, f := range e.Fields {
+			if strings.HasPrefix(f.Type, "time.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var levelExprs = map[slog.Level]string{
+	slog.LevelDebug:    "slog.LevelDebug",
+	slog.LevelInfo:     "slog.LevelInfo",
+	slog.LevelWarn:     "slog.LevelWarn",
+	slog.LevelError:    "slog.LevelError",
+	slog.LevelCritical: "slog.LevelCritical",
+	slog.LevelFatal:    "slog.LevelFatal",
+}
+
+func levelExpr(l slog.Level) string {
+	if s, ok := levelExprs[l]; ok {
+		return s
+	}
+	return fmt.Sprintf("slog.Level(%d)", l)
+}
+
+// initialisms are the parts of names written in upper case in Go.
+var initialisms = map[string]bool{
+	"api": true, "dns": true, "http": true, "https": true, "id": true,
+	"ip": true, "json": true, "sql": true, "tcp": true, "tls": true,
+	"ttl": true, "udp": true, "ui": true, "uri": true, "url": true,
+	"uuid": true,
+}
+
+// goName returns the exported Go name of a snake_case name.
+// e.g. user_id becomes UserID
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if initialisms[part] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// paramName returns the name of the parameter of a required field.
+// e.g. user_id becomes userID
+func paramName(name string) string {
+	p := name
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		p = name[:i] + goName(name[i+1:])
+	}
+	switch {
+	case token.IsKeyword(p), p == "ctx", p == "l", p == "e", p == "fields", p == "slog", p == "context", p == "time":
+		p += "Value"
+	}
+	return p
+}
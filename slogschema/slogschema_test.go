@@ -0,0 +1,93 @@
+package slogschema_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogschema"
+	"cdr.dev/slog/slogschema/internal/testevents"
+)
+
+var bg = context.Background()
+
+type fakeSink struct {
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Sync() {}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open(filepath.Join("internal", "testevents", "events.json"))
+	assert.Success(t, "open", err)
+	defer f.Close()
+	s, err := slogschema.Parse(f)
+	assert.Success(t, "parse", err)
+
+	var b bytes.Buffer
+	err = slogschema.Generate(&b, s)
+	assert.Success(t, "generate", err)
+
+	exp, err := ioutil.ReadFile(filepath.Join("internal", "testevents", "events_gen.go"))
+	assert.Success(t, "read generated", err)
+	// Run go generate ./... after changing the generator.
+	assert.Equal(t, "generated", string(exp), b.String())
+}
+
+func TestGenerated(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(s).Leveled(slog.LevelDebug)
+	testevents.UserLogin(bg, l, "bob", "web", testevents.UserLoginEvent{
+		Duration: time.Second,
+	})
+	testevents.CacheMiss(bg, l)
+
+	assert.Len(t, "entries", 2, s.entries)
+	login := s.entries[0]
+	assert.Equal(t, "level", slog.LevelInfo, login.Level)
+	assert.Equal(t, "msg", "user logged in", login.Message)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("event", "user_login"),
+		slog.F("user_id", "bob"),
+		slog.F("type", "web"),
+		slog.F("duration", time.Second),
+	), login.Fields)
+	assert.True(t, "location", strings.HasSuffix(login.File, "slogschema_test.go"))
+
+	assert.Equal(t, "no fields", slog.M(slog.F("event", "cache_miss")), s.entries[1].Fields)
+
+	e, ok := slog.LookupEvent("user_login")
+	assert.True(t, "registered", ok)
+	assert.Len(t, "schema fields", 5, e.Fields)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, schema := range []string{
+		`{"package": "x-y"}`,
+		`{"package": "x", "events": [{"name": "UserLogin"}]}`,
+		`{"package": "x", "events": [{"name": "a"}, {"name": "a"}]}`,
+		`{"package": "x", "events": [{"name": "a", "fields": [{"name": "b", "type": "chan int"}]}]}`,
+		`{"package": "x", "events": [{"name": "a", "fields": [{"name": "event", "type": "string"}]}]}`,
+		`{"package": "x", "events": [{"name": "a", "level": "LOUD"}]}`,
+	} {
+		_, err := slogschema.Parse(strings.NewReader(schema))
+		assert.Error(t, schema, err)
+	}
+}
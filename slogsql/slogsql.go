@@ -0,0 +1,168 @@
+// Package slogsql logs the queries of database/sql.
+//
+// Wrap a driver.Connector so that every query and statement executed
+// through the returned connector is logged with its duration, the
+// rows affected and its normalized text:
+//
+//	db := slogsql.OpenDB(connector, log.Named("sql"), nil)
+//	db.ExecContext(ctx, "UPDATE users SET name = $1 WHERE id = $2", name, id)
+//
+// The entries are logged with the context of the query so that the
+// fields added with slog.With reach them.
+package slogsql // import "cdr.dev/slog/slogsql"
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"cdr.dev/slog"
+)
+
+// Args is how the bind parameters of queries are logged.
+type Args int
+
+const (
+	// ArgsRedacted logs the number of parameters but not their values.
+	ArgsRedacted Args = iota
+	// ArgsOmitted does not log the parameters.
+	ArgsOmitted
+	// ArgsValues logs the values of the parameters except those
+	// for which Options.Redact returns true.
+	ArgsValues
+)
+
+// Options represents the options for Wrap and OpenDB.
+type Options struct {
+	// Level is the level of the entries of successful queries.
+	// Failed queries are logged at LevelError.
+	// Defaults to LevelDebug.
+	Level slog.Level
+	// SlowThreshold is the duration after which successful queries
+	// are logged at LevelWarn. Disabled if zero.
+	SlowThreshold time.Duration
+	// MaxQueryLength is the number of characters after which the
+	// normalized text of queries is truncated. Defaults to 1000.
+	MaxQueryLength int
+	// Args is how the bind parameters are logged.
+	// Defaults to ArgsRedacted.
+	Args Args
+	// Redact reports whether to redact the value of a parameter with
+	// ArgsValues. Its ordinal starts at 1 and its name is empty unless
+	// it is a named parameter.
+	Redact func(ordinal int, name string) bool
+}
+
+// Wrap returns a connector that logs the queries of the connections of c
+// to l. The connections only support the optional interfaces of
+// database/sql/driver that they need to log the queries and that are
+// shared by most drivers.
+//
+// If opts is nil, the defaults are used.
+func Wrap(c driver.Connector, l slog.Logger, opts *Options) driver.Connector {
+	if opts == nil {
+		opts = &Options{}
+	}
+	return &connector{
+		c: c,
+		log: &logger{
+			l:    l,
+			opts: opts,
+		},
+	}
+}
+
+// OpenDB is like sql.OpenDB but logs the queries of c as with Wrap.
+//
+// If opts is nil, the defaults are used.
+func OpenDB(c driver.Connector, l slog.Logger, opts *Options) *sql.DB {
+	return sql.OpenDB(Wrap(c, l, opts))
+}
+
+// logger logs queries.
+type logger struct {
+	l    slog.Logger
+	opts *Options
+}
+
+// log logs the query that took since start and returned err.
+// result is nil for queries that do not update rows.
+func (l *logger) log(ctx context.Context, msg, query string, args []driver.NamedValue, start time.Time, result driver.Result, err error) {
+	if err == driver.ErrSkip {
+		return
+	}
+	d := time.Since(start)
+
+	level := l.opts.Level
+	if l.opts.SlowThreshold > 0 && d > l.opts.SlowThreshold && level < slog.LevelWarn {
+		level = slog.LevelWarn
+	}
+	fields := slog.M(
+		slog.F("query", l.normalize(query)),
+		slog.F("duration", d),
+	)
+	if f, ok := l.args(args); ok {
+		fields = append(fields, f)
+	}
+	if result != nil {
+		if n, err := result.RowsAffected(); err == nil {
+			fields = append(fields, slog.F("rows_affected", n))
+		}
+	}
+	if err != nil {
+		level = slog.LevelError
+		fields = append(fields, slog.Error(err))
+	}
+	l.l.Log(ctx, l.l.Entry(ctx, level, msg, fields...))
+}
+
+// normalize collapses the white space of query and truncates it.
+func (l *logger) normalize(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	max := l.opts.MaxQueryLength
+	if max <= 0 {
+		max = 1000
+	}
+	if utf8.RuneCountInString(query) > max {
+		r := []rune(query)
+		query = string(r[:max]) + "…"
+	}
+	return query
+}
+
+func (l *logger) args(args []driver.NamedValue) (slog.Field, bool) {
+	switch l.opts.Args {
+	case ArgsOmitted:
+		return slog.Field{}, false
+	case ArgsValues:
+		values := make([]interface{}, len(args))
+		for i, a := range args {
+			values[i] = a.Value
+			if l.opts.Redact != nil && l.opts.Redact(a.Ordinal, a.Name) {
+				values[i] = slog.Redacted
+			}
+		}
+		return slog.F("args", values), true
+	}
+	return slog.F("args_count", len(args)), true
+}
+
+type connector struct {
+	c   driver.Connector
+	log *logger
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, log: c.log}, nil
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.c.Driver()
+}
@@ -0,0 +1,126 @@
+package slogsql_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogsql"
+)
+
+var bg = context.Background()
+
+type fakeSink struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Sync() {}
+
+// fakeConn is a connection that only supports ExecerContext, QueryerContext
+// and the required interfaces so that statements are also tested.
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, xerrors.New("unsupported") }
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if query == "fail" {
+		return nil, xerrors.New("syntax error")
+	}
+	return driver.RowsAffected(3), nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return []string{"n"} }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                        { return nil }
+
+func TestOpenDB(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	db := slogsql.OpenDB(fakeConnector{}, slog.Make(s).Leveled(slog.LevelDebug), &slogsql.Options{
+		MaxQueryLength: 40,
+		Args:           slogsql.ArgsValues,
+		Redact: func(ordinal int, _ string) bool {
+			return ordinal == 2
+		},
+	})
+	defer db.Close()
+
+	ctx := slog.With(bg, slog.F("request_id", "abc"))
+	_, err := db.ExecContext(ctx, "UPDATE users\n\tSET name = $1\n\tWHERE password = $2", "bob", "hunter2")
+	assert.Success(t, "exec", err)
+	_, err = db.ExecContext(ctx, "fail")
+	assert.Error(t, "exec", err)
+	rows, err := db.QueryContext(ctx, "SELECT n FROM numbers WHERE n > $1 AND n < $2 ORDER BY n", 1, 2)
+	assert.Success(t, "query", err)
+	rows.Close()
+
+	assert.Len(t, "entries", 3, s.entries)
+
+	exec := s.entries[0]
+	assert.Equal(t, "level", slog.LevelDebug, exec.Level)
+	assert.Equal(t, "msg", "sql exec", exec.Message)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("request_id", "abc"),
+		slog.F("query", "UPDATE users SET name = $1 WHERE passwor…"),
+		slog.F("duration", exec.Fields[2].Value),
+		slog.F("args", []interface{}{"bob", slog.Redacted}),
+		slog.F("rows_affected", int64(3)),
+	), exec.Fields)
+
+	fail := s.entries[1]
+	assert.Equal(t, "fail level", slog.LevelError, fail.Level)
+	assert.Equal(t, "fail error", "syntax error", fail.Fields[len(fail.Fields)-1].Value.(error).Error())
+
+	// The connection does not support QueryerContext
+	// so the query is prepared.
+	query := s.entries[2]
+	assert.Equal(t, "query msg", "sql query", query.Message)
+	assert.Equal(t, "query args", []interface{}{int64(1), slog.Redacted}, query.Fields[3].Value)
+}
+
+func TestSlowThreshold(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	db := slogsql.OpenDB(fakeConnector{}, slog.Make(s).Leveled(slog.LevelDebug), &slogsql.Options{
+		SlowThreshold: time.Nanosecond,
+	})
+	defer db.Close()
+
+	_, err := db.ExecContext(bg, "DELETE FROM sessions", 1)
+	assert.Success(t, "exec", err)
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "level", slog.LevelWarn, s.entries[0].Level)
+	assert.Equal(t, "args", slog.F("args_count", 1), s.entries[0].Fields[2])
+}
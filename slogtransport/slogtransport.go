@@ -0,0 +1,120 @@
+// Package slogtransport logs the requests of HTTP clients.
+//
+// Wrap the transport of a client so that every request is logged with
+// its response, latency and attempt:
+//
+//	client := &http.Client{
+//		Transport: slogtransport.Wrap(nil, log.Named("http"), nil),
+//	}
+//
+// The entries are logged with the context of the requests so that they
+// are correlated with the trace and fields of the caller.
+package slogtransport // import "cdr.dev/slog/slogtransport"
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"cdr.dev/slog"
+)
+
+// Options represents the options for Wrap.
+type Options struct {
+	// Level returns the level of the entry of a request. resp is nil
+	// if err is not. Defaults to DefaultLevel.
+	Level func(resp *http.Response, err error) slog.Level
+	// HTTP configures the request and response fields.
+	// If nil, the defaults of slog.HTTPOptions are used.
+	HTTP *slog.HTTPOptions
+}
+
+// DefaultLevel logs failed requests and server errors at LevelError,
+// client errors at LevelWarn and other responses at LevelDebug.
+func DefaultLevel(resp *http.Response, err error) slog.Level {
+	switch {
+	case err != nil || resp.StatusCode >= 500:
+		return slog.LevelError
+	case resp.StatusCode >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelDebug
+}
+
+// Wrap returns a transport that logs the requests sent with rt to l
+// with the request and response fields of slog.Request and slog.Response,
+// the duration until the response headers were received and the error.
+// Requests whose context is from WithAttempts also get an attempt field.
+//
+// If rt is nil, http.DefaultTransport is used.
+// If opts is nil, the defaults are used.
+func Wrap(rt http.RoundTripper, l slog.Logger, opts *Options) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	t := &transport{
+		rt:    rt,
+		l:     l,
+		level: opts.Level,
+		http:  opts.HTTP,
+	}
+	if t.level == nil {
+		t.level = DefaultLevel
+	}
+	return t
+}
+
+type transport struct {
+	rt    http.RoundTripper
+	l     slog.Logger
+	level func(*http.Response, error) slog.Level
+	http  *slog.HTTPOptions
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var attempt int32
+	if n, ok := ctx.Value(attemptsKey{}).(*int32); ok {
+		attempt = atomic.AddInt32(n, 1)
+	}
+
+	start := time.Now()
+	resp, err := t.rt.RoundTrip(req)
+	d := time.Since(start)
+
+	fields := slog.M(
+		t.http.Request(req),
+		slog.F("duration", d),
+	)
+	if attempt > 0 {
+		fields = append(fields, slog.F("attempt", attempt))
+	}
+	if err != nil {
+		fields = append(fields, slog.Error(err))
+	} else {
+		fields = append(fields, t.http.Response(resp))
+	}
+	t.l.Log(ctx, t.l.Entry(ctx, t.level(resp, err), "http request", fields...))
+	return resp, err
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped
+// transport if it supports it, as http.Client.CloseIdleConnections does.
+func (t *transport) CloseIdleConnections() {
+	if c, ok := t.rt.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+type attemptsKey struct{}
+
+// WithAttempts returns a context that counts the requests sent with it
+// or contexts derived from it, e.g. by a retry loop, so that their
+// entries have an attempt field starting at 1.
+func WithAttempts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptsKey{}, new(int32))
+}
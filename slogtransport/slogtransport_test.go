@@ -0,0 +1,102 @@
+package slogtransport_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogtransport"
+)
+
+type fakeSink struct {
+	mu      sync.Mutex
+	entries []slog.SinkEntry
+}
+
+func (s *fakeSink) LogEntry(_ context.Context, e slog.SinkEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, e)
+}
+
+func (s *fakeSink) Sync() {}
+
+func TestWrap(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	s := &fakeSink{}
+	client := &http.Client{
+		Transport: slogtransport.Wrap(nil, slog.Make(s).Leveled(slog.LevelDebug), nil),
+	}
+
+	ctx := slogtransport.WithAttempts(slog.With(context.Background(), slog.F("request_id", "abc")))
+	for i := 0; i < 2; i++ {
+		req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"/?token=secret", nil)
+		assert.Success(t, "new request", err)
+		resp, err := client.Do(req)
+		assert.Success(t, "do", err)
+		resp.Body.Close()
+	}
+
+	assert.Len(t, "entries", 2, s.entries)
+	first := s.entries[0]
+	assert.Equal(t, "first level", slog.LevelError, first.Level)
+	assert.Equal(t, "msg", "http request", first.Message)
+	assert.Equal(t, "request_id", "abc", first.Fields[0].Value)
+	req := first.Fields[1].Value.(slog.Map)
+	assert.Equal(t, "url", srv.URL+"/?token=REDACTED", req[1].Value)
+	assert.Equal(t, "duration", "duration", first.Fields[2].Name)
+	assert.Equal(t, "first attempt", slog.F("attempt", int32(1)), first.Fields[3])
+	resp := first.Fields[4].Value.(slog.Map)
+	assert.Equal(t, "status", http.StatusServiceUnavailable, resp[0].Value)
+
+	second := s.entries[1]
+	assert.Equal(t, "second level", slog.LevelDebug, second.Level)
+	assert.Equal(t, "second attempt", slog.F("attempt", int32(2)), second.Fields[3])
+}
+
+type failTransport struct{}
+
+func (failTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, xerrors.New("connection refused")
+}
+
+func TestWrapError(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	var levels []slog.Level
+	rt := slogtransport.Wrap(failTransport{}, slog.Make(s), &slogtransport.Options{
+		Level: func(resp *http.Response, err error) slog.Level {
+			level := slogtransport.DefaultLevel(resp, err)
+			levels = append(levels, level)
+			return level
+		},
+	})
+
+	req := httptest.NewRequest("GET", "http://example.com", nil)
+	_, err := rt.RoundTrip(req)
+	assert.Error(t, "round trip", err)
+
+	assert.Equal(t, "levels", []slog.Level{slog.LevelError}, levels)
+	assert.Len(t, "entries", 1, s.entries)
+	fields := s.entries[0].Fields
+	assert.Equal(t, "error", "error", fields[len(fields)-1].Name)
+}
@@ -0,0 +1,165 @@
+// Package slogval contains the typed representation of encoded
+// field values.
+//
+// slog.ValueOf and slog.Map.Value convert field values with the same
+// rules as JSON encoding, e.g. errors become their chain of messages and
+// structs with json tags become Maps. Sinks and encoders outside this
+// module can consume the result with a type switch instead of
+// reflecting on arbitrary interface{} values:
+//
+//	switch v := v.(type) {
+//	case slogval.String:
+//	case slogval.Int:
+//	case slogval.Float:
+//	case slogval.Bool:
+//	case slogval.Nil:
+//	case slogval.List:
+//	case slogval.Map:
+//	}
+//
+// The package does not depend on slog so that they can share types.
+package slogval // import "cdr.dev/slog/slogval"
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// Value is an encoded field value. It is one of
+// String, Int, Float, Bool, Nil, List and Map.
+type Value interface {
+	json.Marshaler
+
+	isValue()
+}
+
+// String is a string value.
+type String string
+
+// Int is an integer value.
+type Int int64
+
+// Float is a floating point value. Integers that do not
+// fit in an Int are Floats too.
+type Float float64
+
+// Bool is a boolean value.
+type Bool bool
+
+// Nil is the null value.
+type Nil struct{}
+
+// List is a list of values.
+type List []Value
+
+// Field is a named value of a Map.
+type Field struct {
+	Name  string
+	Value Value
+}
+
+// Map is an ordered map of values.
+//
+// Like slog.Map, it can have multiple fields with the same name.
+type Map []Field
+
+func (String) isValue() {}
+func (Int) isValue()    {}
+func (Float) isValue()  {}
+func (Bool) isValue()   {}
+func (Nil) isValue()    {}
+func (List) isValue()   {}
+func (Map) isValue()    {}
+
+// MarshalJSON implements json.Marshaler.
+func (v String) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(v))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Int) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(v), 10), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Float) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(v))
+}
+
+// MarshalJSON implements json.Marshaler.
+func (v Bool) MarshalJSON() ([]byte, error) {
+	return strconv.AppendBool(nil, bool(v)), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (Nil) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l List) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+	b := &bytes.Buffer{}
+	b.WriteByte('[')
+	for i, v := range l {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		err := marshal(b, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte(']')
+	return b.Bytes(), nil
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The order of the fields is preserved.
+func (m Map) MarshalJSON() ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteByte('{')
+	for i, f := range m {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		err = marshal(b, f.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func marshal(b *bytes.Buffer, v Value) error {
+	if v == nil {
+		v = Nil{}
+	}
+	p, err := v.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	b.Write(p)
+	return nil
+}
+
+// Get returns the value of the last field in m named name.
+func (m Map) Get(name string) (Value, bool) {
+	for i := len(m) - 1; i >= 0; i-- {
+		if m[i].Name == name {
+			return m[i].Value, true
+		}
+	}
+	return nil, false
+}
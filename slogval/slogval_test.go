@@ -0,0 +1,43 @@
+package slogval_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogval"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	v := slogval.Map{
+		{Name: "z", Value: slogval.String("a\"b")},
+		{Name: "a", Value: slogval.List{
+			slogval.Int(1),
+			slogval.Float(2.5),
+			slogval.Bool(false),
+			slogval.Nil{},
+			nil,
+		}},
+		{Name: "m", Value: slogval.Map{}},
+		{Name: "l", Value: slogval.List(nil)},
+	}
+	b, err := json.Marshal(v)
+	assert.Success(t, "marshal", err)
+	assert.Equal(t, "JSON", `{"z":"a\"b","a":[1,2.5,false,null,null],"m":{},"l":[]}`, string(b))
+}
+
+func TestMapGet(t *testing.T) {
+	t.Parallel()
+
+	m := slogval.Map{
+		{Name: "a", Value: slogval.Int(1)},
+		{Name: "a", Value: slogval.Int(2)},
+	}
+	v, ok := m.Get("a")
+	assert.True(t, "found", ok)
+	assert.Equal(t, "value", slogval.Int(2), v)
+	_, ok = m.Get("b")
+	assert.False(t, "not found", ok)
+}
@@ -0,0 +1,75 @@
+package slog
+
+import (
+	"context"
+)
+
+// DuplicateKeys returns the names of the fields that appear more than
+// once in m in the order of their first repeat. Nested maps are not
+// checked.
+//
+// Sinks that encode entries as JSON objects keep only one of the
+// duplicates even though Map preserves them all.
+func (m Map) DuplicateKeys() []string {
+	if len(m) < 2 {
+		return nil
+	}
+
+	var dups []string
+	seen := make(map[string]int, len(m))
+	for _, f := range m {
+		seen[f.Name]++
+		if seen[f.Name] == 2 {
+			dups = append(dups, f.Name)
+		}
+	}
+	return dups
+}
+
+// Strict returns a sink that logs entries to s and warns about
+// entries with duplicate field keys, whether they come from With,
+// the context or the call site.
+//
+// The warning is logged to s after the entry at LevelWarn with
+// the location of the entry and the duplicate keys in the keys field.
+func Strict(s Sink) Sink {
+	return strictSink{s}
+}
+
+type strictSink struct {
+	s Sink
+}
+
+func (s strictSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	s.s.LogEntry(ctx, ent)
+
+	dups := ent.Fields.DuplicateKeys()
+	if len(dups) == 0 {
+		return
+	}
+	s.s.LogEntry(ctx, warning(ent, "slog: duplicate field keys", F("keys", dups)))
+}
+
+func (s strictSink) Sync() {
+	s.s.Sync()
+}
+
+func (s strictSink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
+
+// warning returns a warning about ent with its location.
+func warning(ent SinkEntry, msg string, fields ...Field) SinkEntry {
+	return SinkEntry{
+		Time:        ent.Time,
+		Level:       LevelWarn,
+		Message:     msg,
+		LoggerNames: ent.LoggerNames,
+		Func:        ent.Func,
+		File:        ent.File,
+		Line:        ent.Line,
+		SpanContext: ent.SpanContext,
+		Fields:      fields,
+		Tags:        ent.Tags,
+	}
+}
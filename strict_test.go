@@ -0,0 +1,25 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestStrict(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Strict(s)).With(slog.F("a", 1), slog.F("b", 2))
+	l.Info(slog.With(bg, slog.F("b", 3)), "msg", slog.F("a", 4), slog.F("c", 5), slog.F("a", 6))
+
+	assert.Len(t, "entries", 2, s.entries)
+	warn := s.entries[1]
+	assert.Equal(t, "level", slog.LevelWarn, warn.Level)
+	assert.Equal(t, "keys", slog.M(slog.F("keys", []string{"b", "a"})), warn.Fields)
+	assert.Equal(t, "line", s.entries[0].Line, warn.Line)
+
+	l.Info(bg, "no dups", slog.F("c", 1))
+	assert.Len(t, "entries", 3, s.entries)
+}
@@ -0,0 +1,21 @@
+//go:build !slogdiscard && !slogdiscardinfo
+// +build !slogdiscard,!slogdiscardinfo
+
+package slog
+
+// DebugEnabled and InfoEnabled report whether Debug and Info entries
+// are compiled in. They are false when building with the slogdiscard
+// tag, which strips Debug, and the slogdiscardinfo tag, which strips
+// Debug and Info.
+//
+// The arguments of a stripped call are still evaluated as Go evaluates
+// them before the call. Guard expensive fields with the constants so
+// that the compiler removes them too:
+//
+//	if slog.DebugEnabled {
+//		l.Debug(ctx, "cache state", slog.F("keys", cache.Keys()))
+//	}
+const (
+	DebugEnabled = true
+	InfoEnabled  = true
+)
@@ -0,0 +1,11 @@
+//go:build slogdiscard && !slogdiscardinfo
+// +build slogdiscard,!slogdiscardinfo
+
+package slog
+
+// DebugEnabled and InfoEnabled report whether Debug and Info entries
+// are compiled in. Debug entries are stripped by the slogdiscard tag.
+const (
+	DebugEnabled = false
+	InfoEnabled  = true
+)
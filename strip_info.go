@@ -0,0 +1,11 @@
+//go:build slogdiscardinfo
+// +build slogdiscardinfo
+
+package slog
+
+// DebugEnabled and InfoEnabled report whether Debug and Info entries
+// are compiled in. Both are stripped by the slogdiscardinfo tag.
+const (
+	DebugEnabled = false
+	InfoEnabled  = false
+)
@@ -0,0 +1,28 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	l, c := slog.Discard()
+	l.Debug(bg, "debug")
+	l.Info(bg, "info")
+	l.Log(bg, slog.SinkEntry{Level: slog.LevelDebug})
+	l.Warn(bg, "warn")
+
+	count := func(enabled bool, n uint64) uint64 {
+		if enabled {
+			return n
+		}
+		return 0
+	}
+	assert.Equal(t, "debug", count(slog.DebugEnabled, 2), c.Count(slog.LevelDebug))
+	assert.Equal(t, "info", count(slog.InfoEnabled, 1), c.Count(slog.LevelInfo))
+	assert.Equal(t, "warn", uint64(1), c.Count(slog.LevelWarn))
+}
@@ -0,0 +1,59 @@
+package slog
+
+// tagValue is the value of the fields created by Tag.
+type tagValue string
+
+// Tag returns a field for a low-cardinality label of the entry such as
+// the region, environment or service. It can be used wherever fields
+// are, e.g. with Logger.With or at the call site.
+//
+// Loggers move tags from the fields of entries to SinkEntry.Tags so
+// that sinks can map them to labels of the log stream, e.g. Loki labels
+// or Datadog tags, instead of the payload. Use regular fields for high
+// cardinality values such as request IDs as every distinct set of tags
+// becomes a separate stream or time series downstream.
+//
+// A later tag replaces an earlier tag with the same name.
+func Tag(name, value string) Field {
+	return F(name, tagValue(value))
+}
+
+// extractTags moves the tags in the fields of ent to its tags.
+// The fields are only copied if there are tags as they are shared.
+func (ent SinkEntry) extractTags() SinkEntry {
+	i := 0
+	for ; i < len(ent.Fields); i++ {
+		if _, ok := ent.Fields[i].Value.(tagValue); ok {
+			break
+		}
+	}
+	if i == len(ent.Fields) {
+		return ent
+	}
+
+	fields := make(Map, i, len(ent.Fields))
+	copy(fields, ent.Fields[:i])
+	tags := append(Map(nil), ent.Tags...)
+	for _, f := range ent.Fields[i:] {
+		v, ok := f.Value.(tagValue)
+		if !ok {
+			fields = append(fields, f)
+			continue
+		}
+		tags = tags.setTag(f.Name, string(v))
+	}
+	ent.Fields = fields
+	ent.Tags = tags
+	return ent
+}
+
+// setTag sets the tag name of m to value in place.
+func (m Map) setTag(name, value string) Map {
+	for i := range m {
+		if m[i].Name == name {
+			m[i].Value = value
+			return m
+		}
+	}
+	return append(m, F(name, value))
+}
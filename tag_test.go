@@ -0,0 +1,46 @@
+package slog_test
+
+import (
+	"context"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestTag(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(s).With(slog.Tag("region", "us-east-1"), slog.F("user", "bob"))
+	ctx := slog.With(context.Background(), slog.Tag("env", "prod"))
+	l.Info(ctx, "hello", slog.Tag("region", "eu-west-1"), slog.F("id", 1))
+
+	assert.Len(t, "entries", 1, s.entries)
+	ent := s.entries[0]
+	assert.Equal(t, "fields", slog.M(
+		slog.F("user", "bob"),
+		slog.F("id", 1),
+	), ent.Fields)
+	assert.Equal(t, "tags", slog.M(
+		slog.F("region", "eu-west-1"),
+		slog.F("env", "prod"),
+	), ent.Tags)
+
+	b, err := ent.MarshalJSON()
+	assert.Success(t, "marshal JSON", err)
+	var ent2 slog.SinkEntry
+	err = ent2.UnmarshalJSON(b)
+	assert.Success(t, "unmarshal JSON", err)
+	assert.Equal(t, "JSON tags", ent.Tags, ent2.Tags)
+
+	b, err = ent.MarshalBinary()
+	assert.Success(t, "marshal binary", err)
+	ent2 = slog.SinkEntry{}
+	err = ent2.UnmarshalBinary(b)
+	assert.Success(t, "unmarshal binary", err)
+	assert.Equal(t, "binary tags", ent.Tags, ent2.Tags)
+
+	l.Info(bg, "no tags")
+	assert.Equal(t, "fields without tags", slog.M(slog.F("user", "bob")), s.entries[1].Fields)
+}
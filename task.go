@@ -0,0 +1,56 @@
+package slog
+
+import (
+	"context"
+
+	"cdr.dev/slog/internal/goid"
+)
+
+type taskKey struct{}
+
+// WithTaskID returns a context with the task ID id that entries logged
+// with it to a sink returned by Tasks are stamped with, e.g. the ID of
+// a job processed by a pool of goroutines.
+func WithTaskID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, taskKey{}, id)
+}
+
+// Tasks returns a sink that logs entries to s with a task field of the
+// task ID of their context set with WithTaskID or else a goroutine field
+// of the ID of the goroutine that logged them so that the entries of
+// concurrent work interleaved in the output can be told apart.
+//
+// It is opt-in as goroutine IDs are an implementation detail of the
+// runtime: they are only meant for reading logs, reading them parses
+// the header of the stack trace of the goroutine on every entry and
+// they identify the goroutine that logged an entry rather than the
+// work it is doing. Prefer WithTaskID where the work has an ID.
+//
+// s must be called on the goroutine that logged the entry so Tasks
+// must be in front of any sink that logs them asynchronously.
+func Tasks(s Sink) Sink {
+	return taskSink{s}
+}
+
+type taskSink struct {
+	s Sink
+}
+
+func (s taskSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	var f Field
+	if id, ok := ctx.Value(taskKey{}).(string); ok {
+		f = F("task", id)
+	} else {
+		f = F("goroutine", goid.ID())
+	}
+	ent.Fields = ent.Fields.append(M(f))
+	s.s.LogEntry(ctx, ent)
+}
+
+func (s taskSink) Sync() {
+	s.s.Sync()
+}
+
+func (s taskSink) Flush(ctx context.Context) error {
+	return Flush(ctx, s.s)
+}
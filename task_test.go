@@ -0,0 +1,30 @@
+package slog_test
+
+import (
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestTasks(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(slog.Tasks(s))
+	l.Info(bg, "goroutine")
+	l.Info(slog.WithTaskID(bg, "job-1"), "task")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Info(bg, "other goroutine")
+	}()
+	<-done
+
+	assert.Len(t, "entries", 3, s.entries)
+	id, ok := fieldValue(s.entries[0].Fields, "goroutine").(uint64)
+	assert.True(t, "goroutine", ok && id > 0)
+	assert.Equal(t, "task", slog.M(slog.F("task", "job-1")), s.entries[1].Fields)
+	assert.True(t, "other goroutine", fieldValue(s.entries[2].Fields, "goroutine") != id)
+}
@@ -0,0 +1,144 @@
+package slog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Tee returns a sink that logs every entry to all of sinks and reports
+// the failures of every sink together instead of only the first.
+//
+// Sinks that implement ErrorSink report their failures with LogEntryErr
+// and SyncErr. The failures of an entry or sync are returned by
+// LogEntryErr and SyncErr of the returned sink as a *TeeError and are
+// printed to stderr by LogEntry and Sync.
+//
+// The returned sink holds no lock of its own and calls the sinks one at a
+// time in order, so it never holds the lock of a sink while another is
+// called and cannot cause a lock order inversion between them. A sink must
+// not log to a Tee it is part of; see Guard to divert such entries.
+func Tee(sinks ...Sink) ErrorSink {
+	return &teeSink{
+		sinks: sinks,
+		errorf: func(f string, v ...interface{}) {
+			println(fmt.Sprintf(f, v...))
+		},
+	}
+}
+
+// SinkError is the failure of a sink of Tee or Logger.Flush.
+type SinkError struct {
+	// Index is the index of the sink in the arguments to Tee or Make.
+	Index int
+	Sink  Sink
+	Err   error
+}
+
+func (e *SinkError) Error() string {
+	return fmt.Sprintf("sink %v (%T): %v", e.Index, e.Sink, e.Err)
+}
+
+// Unwrap returns the error of the sink.
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// TeeError reports the failures of the sinks of Tee or Logger.Flush.
+type TeeError struct {
+	// Errors are the failures in the order of the sinks.
+	Errors []*SinkError
+}
+
+func (e *TeeError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%v sinks failed: %v", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the error of the first failed sink so that
+// errors.Is and errors.As match it.
+func (e *TeeError) Unwrap() error {
+	return e.Errors[0]
+}
+
+type teeSink struct {
+	sinks []Sink
+
+	errorf func(f string, v ...interface{})
+}
+
+// LogEntry implements Sink.
+func (t *teeSink) LogEntry(ctx context.Context, ent SinkEntry) {
+	err := t.LogEntryErr(ctx, ent)
+	if err != nil {
+		t.errorf("slog.Tee: failed to log entry: %v", err)
+	}
+}
+
+// LogEntryErr implements ErrorSink.
+func (t *teeSink) LogEntryErr(ctx context.Context, ent SinkEntry) error {
+	var te *TeeError
+	for i, s := range t.sinks {
+		es, ok := s.(ErrorSink)
+		if !ok {
+			s.LogEntry(ctx, ent)
+			continue
+		}
+		err := es.LogEntryErr(ctx, ent)
+		te = te.add(i, s, err)
+	}
+	return te.err()
+}
+
+// Sync implements Sink.
+func (t *teeSink) Sync() {
+	err := t.SyncErr()
+	if err != nil {
+		t.errorf("slog.Tee: failed to sync: %v", err)
+	}
+}
+
+// SyncErr implements ErrorSink.
+func (t *teeSink) SyncErr() error {
+	var te *TeeError
+	for i, s := range t.sinks {
+		es, ok := s.(ErrorSink)
+		if !ok {
+			s.Sync()
+			continue
+		}
+		err := es.SyncErr()
+		te = te.add(i, s, err)
+	}
+	return te.err()
+}
+
+// Flush implements Flusher.
+//
+// It flushes the sinks concurrently.
+func (t *teeSink) Flush(ctx context.Context) error {
+	return flushAll(ctx, t.sinks).err()
+}
+
+// add returns te with err of the sink s at index i if it is not nil.
+func (te *TeeError) add(i int, s Sink, err error) *TeeError {
+	if err == nil {
+		return te
+	}
+	if te == nil {
+		te = &TeeError{}
+	}
+	te.Errors = append(te.Errors, &SinkError{Index: i, Sink: s, Err: err})
+	return te
+}
+
+// err returns te as an error that is nil if there were no failures.
+func (te *TeeError) err() error {
+	if te == nil {
+		return nil
+	}
+	return te
+}
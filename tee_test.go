@@ -0,0 +1,70 @@
+package slog_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+// failSink fails every entry and sync with err.
+type failSink struct {
+	err     error
+	entries int
+}
+
+func (s *failSink) LogEntry(ctx context.Context, ent slog.SinkEntry) {
+	_ = s.LogEntryErr(ctx, ent)
+}
+
+func (s *failSink) LogEntryErr(_ context.Context, _ slog.SinkEntry) error {
+	s.entries++
+	return s.err
+}
+
+func (s *failSink) Sync() {}
+
+func (s *failSink) SyncErr() error {
+	return s.err
+}
+
+func TestTee(t *testing.T) {
+	t.Parallel()
+
+	ok := &fakeSink{}
+	eof := &failSink{err: io.EOF}
+	closed := &failSink{err: io.ErrClosedPipe}
+	tee := slog.Tee(eof, ok, closed)
+
+	var printed []string
+	slog.SetTeeErrorf(tee, func(f string, v ...interface{}) {
+		printed = append(printed, fmt.Sprintf(f, v...))
+	})
+
+	err := tee.LogEntryErr(bg, slog.SinkEntry{Message: "hello"})
+	assert.Error(t, "log entry", err)
+	assert.Len(t, "ok entries", 1, ok.entries)
+	assert.Equal(t, "failed entries", 1, closed.entries)
+
+	var te *slog.TeeError
+	assert.True(t, "tee error", xerrors.As(err, &te))
+	assert.Len(t, "errors", 2, te.Errors)
+	assert.Equal(t, "first index", 0, te.Errors[0].Index)
+	assert.Equal(t, "second index", 2, te.Errors[1].Index)
+	assert.True(t, "is EOF", xerrors.Is(err, io.EOF))
+	assert.Equal(t, "message", "2 sinks failed: sink 0 (*slog_test.failSink): EOF; sink 2 (*slog_test.failSink): io: read/write on closed pipe", err.Error())
+
+	slog.Make(tee).Error(bg, "meow")
+	assert.Len(t, "printed", 2, printed)
+	assert.True(t, "printed entry", strings.HasPrefix(printed[0], "slog.Tee: failed to log entry: 2 sinks failed"))
+	assert.True(t, "printed sync", strings.HasPrefix(printed[1], "slog.Tee: failed to sync: 2 sinks failed"))
+	assert.Equal(t, "syncs", 1, ok.syncs)
+
+	assert.Success(t, "no failures", slog.Tee(ok).SyncErr())
+}
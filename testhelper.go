@@ -0,0 +1,33 @@
+package slog
+
+import (
+	"context"
+)
+
+// testSink is implemented by sinks that log to tests, such as the sink
+// of slogtest, so that the frames of Logger are marked as helpers of the
+// test and the testing package reports the location of the caller of
+// Logger.
+type testSink interface {
+	// TestHelper returns the test that entries logged with ctx
+	// are logged to or nil if there is none.
+	TestHelper(ctx context.Context) interface{ Helper() }
+}
+
+type noHelper struct{}
+
+func (noHelper) Helper() {}
+
+// testHelper returns the test of the first sink that logs to a test
+// with ctx or a no-op. Callers call its Helper method themselves as
+// it marks the frame of its caller.
+func (l Logger) testHelper(ctx context.Context) interface{ Helper() } {
+	for _, s := range l.sinks {
+		if ts, ok := s.(testSink); ok {
+			if h := ts.TestHelper(ctx); h != nil {
+				return h
+			}
+		}
+	}
+	return noHelper{}
+}
@@ -0,0 +1,92 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"cdr.dev/slog/slogval"
+)
+
+// ValueOf returns the typed representation of the field value v.
+//
+// It follows the rules of Map.MarshalJSON so the result encodes to
+// the same JSON, e.g. errors become a List of their chain and structs
+// with json tags become a slogval.Map.
+func ValueOf(v interface{}) slogval.Value {
+	return decodeSlogval(encode(v))
+}
+
+// Value returns the typed representation of m.
+// See ValueOf.
+func (m Map) Value() slogval.Map {
+	vm := make(slogval.Map, 0, len(m))
+	for _, f := range m {
+		vm = append(vm, slogval.Field{
+			Name:  f.Name,
+			Value: ValueOf(f.Value),
+		})
+	}
+	return vm
+}
+
+// decodeSlogval decodes the JSON b as produced by encode.
+func decodeSlogval(b []byte) slogval.Value {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	v, err := decodeSlogvalToken(d)
+	if err != nil {
+		// encode always produces valid JSON.
+		return slogval.String(string(b))
+	}
+	return v
+}
+
+func decodeSlogvalToken(d *json.Decoder) (slogval.Value, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		if t == '[' {
+			l := slogval.List{}
+			for d.More() {
+				v, err := decodeSlogvalToken(d)
+				if err != nil {
+					return nil, err
+				}
+				l = append(l, v)
+			}
+			_, err = d.Token()
+			return l, err
+		}
+
+		m := slogval.Map{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeSlogvalToken(d)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, slogval.Field{Name: k.(string), Value: v})
+		}
+		_, err = d.Token()
+		return m, err
+	case string:
+		return slogval.String(t), nil
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return slogval.Int(i), nil
+		}
+		f, err := t.Float64()
+		return slogval.Float(f), err
+	case bool:
+		return slogval.Bool(t), nil
+	default:
+		return slogval.Nil{}, nil
+	}
+}
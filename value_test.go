@@ -0,0 +1,58 @@
+package slog_test
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogval"
+)
+
+func TestValueOf(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	assert.Equal(t, "string", slogval.String("hi"), slog.ValueOf("hi"))
+	assert.Equal(t, "int", slogval.Int(-3), slog.ValueOf(-3))
+	assert.Equal(t, "uint64", slogval.Float(1<<64-1), slog.ValueOf(uint64(1<<64-1)))
+	assert.Equal(t, "float", slogval.Float(1.5), slog.ValueOf(1.5))
+	assert.Equal(t, "bool", slogval.Bool(true), slog.ValueOf(true))
+	assert.Equal(t, "nil", slogval.Nil{}, slog.ValueOf(nil))
+	assert.Equal(t, "duration", slogval.String("1s"), slog.ValueOf(time.Second))
+	assert.Equal(t, "error", slogval.String("EOF"), slog.ValueOf(io.EOF))
+	assert.Equal(t, "list", slogval.List{slogval.Int(1), slogval.String("a")}, slog.ValueOf([]interface{}{1, "a"}))
+	assert.Equal(t, "struct", slogval.Map{
+		{Name: "name", Value: slogval.String("jane")},
+		{Name: "age", Value: slogval.Int(42)},
+	}, slog.ValueOf(user{"jane", 42}))
+}
+
+func TestMapValue(t *testing.T) {
+	t.Parallel()
+
+	m := slog.M(
+		slog.F("b", 1),
+		slog.F("a", slog.M(slog.F("nested", []string{"x"}))),
+	)
+	v := m.Value()
+	assert.Equal(t, "value", slogval.Map{
+		{Name: "b", Value: slogval.Int(1)},
+		{Name: "a", Value: slogval.Map{
+			{Name: "nested", Value: slogval.List{slogval.String("x")}},
+		}},
+	}, v)
+
+	// The value encodes to the same JSON as the map.
+	exp, err := json.Marshal(m)
+	assert.Success(t, "marshal map", err)
+	act, err := json.Marshal(v)
+	assert.Success(t, "marshal value", err)
+	assert.Equal(t, "JSON", indentJSON(t, string(exp)), indentJSON(t, string(act)))
+}
@@ -0,0 +1,158 @@
+package slog
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"cdr.dev/slog/slogval"
+)
+
+// Value is the typed representation of an encoded field value.
+// See package slogval.
+type Value = slogval.Value
+
+// valueEncoders are the registered encoders of field value types.
+type valueEncoders struct {
+	// types maps concrete types to their encoder.
+	types map[reflect.Type]reflect.Value
+	// ifaces are the encoders of interface types
+	// in the order they were registered.
+	ifaces []ifaceEncoder
+}
+
+type ifaceEncoder struct {
+	t  reflect.Type
+	fn reflect.Value
+}
+
+var globalEncoders struct {
+	mu sync.Mutex
+	// encoders is a *valueEncoders.
+	encoders atomic.Value
+}
+
+var valueType = reflect.TypeOf((*Value)(nil)).Elem()
+
+// checkEncoder returns the argument type of fn and fn
+// or panics if fn is not a func(T) Value.
+func checkEncoder(fn interface{}) (reflect.Type, reflect.Value) {
+	rv := reflect.ValueOf(fn)
+	t := rv.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 1 || t.IsVariadic() || t.Out(0) != valueType {
+		panic(fmt.Sprintf("slog: encoder must be a func(T) slog.Value, got %v", t))
+	}
+	return t.In(0), rv
+}
+
+// with returns a copy of encs with fn registered.
+func (encs *valueEncoders) with(fn interface{}) *valueEncoders {
+	t, rv := checkEncoder(fn)
+
+	encs2 := &valueEncoders{
+		types: make(map[reflect.Type]reflect.Value),
+	}
+	if encs != nil {
+		for t, fn := range encs.types {
+			encs2.types[t] = fn
+		}
+		encs2.ifaces = append(encs2.ifaces, encs.ifaces...)
+	}
+	if t.Kind() == reflect.Interface {
+		encs2.ifaces = append(encs2.ifaces, ifaceEncoder{t: t, fn: rv})
+	} else {
+		encs2.types[t] = rv
+	}
+	return encs2
+}
+
+// RegisterEncoder registers fn, a func(T) slog.Value, as the encoder of
+// field values of type T. It is used instead of the process described in
+// Map.MarshalJSON so that e.g. protobufs, decimals or big.Ints get fast
+// purpose built encoding.
+//
+// If T is an interface, fn encodes every value that implements it and has
+// no encoder of its concrete type. Interfaces are checked in the order
+// they were registered. Registering an encoder for T again replaces it.
+//
+// It panics if fn is not a func(T) slog.Value. It is safe to call
+// concurrently with logging but is meant to be called in init.
+func RegisterEncoder(fn interface{}) {
+	globalEncoders.mu.Lock()
+	defer globalEncoders.mu.Unlock()
+
+	encs, _ := globalEncoders.encoders.Load().(*valueEncoders)
+	globalEncoders.encoders.Store(encs.with(fn))
+}
+
+// WithEncoder returns a Logger that encodes field values of type T with
+// fn, a func(T) slog.Value, before they reach its sinks. It takes
+// precedence over RegisterEncoder. See RegisterEncoder.
+//
+// Only the values of fields and of fields in nested Maps are encoded.
+func (l Logger) WithEncoder(fn interface{}) Logger {
+	l.encoders = l.encoders.with(fn)
+	return l
+}
+
+// encode returns the value of v if there is an encoder for its type.
+func (encs *valueEncoders) encode(v interface{}) (Value, bool) {
+	if encs == nil || v == nil {
+		return nil, false
+	}
+	t := reflect.TypeOf(v)
+	fn, ok := encs.types[t]
+	if !ok {
+		for _, e := range encs.ifaces {
+			if t.Implements(e.t) {
+				fn, ok = e.fn, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+
+	out := fn.Call([]reflect.Value{reflect.ValueOf(v)})[0]
+	if out.IsNil() {
+		return slogval.Nil{}, true
+	}
+	return out.Interface().(Value), true
+}
+
+// encodeMap returns m with the values encoded by encs and whether any
+// was. m is only copied if a value is encoded as its fields are shared.
+func (encs *valueEncoders) encodeMap(m Map) (Map, bool) {
+	var m2 Map
+	for i, f := range m {
+		var v interface{}
+		if val, ok := encs.encode(f.Value); ok {
+			v = val
+		} else if nested, ok := f.Value.(Map); ok {
+			nested, ok = encs.encodeMap(nested)
+			if !ok {
+				continue
+			}
+			v = nested
+		} else {
+			continue
+		}
+
+		if m2 == nil {
+			m2 = make(Map, len(m))
+			copy(m2, m)
+		}
+		m2[i] = F(f.Name, v)
+	}
+	if m2 == nil {
+		return m, false
+	}
+	return m2, true
+}
+
+func registeredEncoders() *valueEncoders {
+	encs, _ := globalEncoders.encoders.Load().(*valueEncoders)
+	return encs
+}
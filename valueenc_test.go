@@ -0,0 +1,81 @@
+package slog_test
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+	"cdr.dev/slog/slogval"
+)
+
+type celsius float64
+
+type temperature interface {
+	Kelvin() float64
+}
+
+type fahrenheit float64
+
+func (f fahrenheit) Kelvin() float64 {
+	return (float64(f)-32)*5/9 + 273.15
+}
+
+func init() {
+	slog.RegisterEncoder(func(c celsius) slog.Value {
+		return slogval.String(fmt.Sprintf("%.1f°C", float64(c)))
+	})
+	slog.RegisterEncoder(func(t temperature) slog.Value {
+		return slogval.Map{{Name: "kelvin", Value: slogval.Float(t.Kelvin())}}
+	})
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	t.Parallel()
+
+	m := slog.M(
+		slog.F("c", celsius(21.5)),
+		slog.F("f", fahrenheit(32)),
+		slog.F("list", []interface{}{celsius(1)}),
+	)
+	assert.Equal(t, "JSON", indentJSON(t, `{
+		"c": "21.5°C",
+		"f": {"kelvin": 273.15},
+		"list": ["1.0°C"]
+	}`), marshalJSON(t, m))
+
+	assert.Equal(t, "value", slogval.String("3.0°C"), slog.ValueOf(celsius(3)))
+}
+
+func TestRegisterEncoder_Panic(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		assert.True(t, "panicked", recover() != nil)
+	}()
+	slog.RegisterEncoder(func(c celsius) string {
+		return ""
+	})
+}
+
+func TestLogger_WithEncoder(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	l := slog.Make(s).WithEncoder(func(i *big.Int) slog.Value {
+		return slogval.String(i.Text(16))
+	}).With(slog.F("with", big.NewInt(255)))
+
+	fields := slog.M(slog.F("nested", slog.M(slog.F("n", big.NewInt(16)))), slog.F("c", celsius(1)))
+	l.Info(bg, "msg", fields...)
+
+	assert.Len(t, "entries", 1, s.entries)
+	assert.Equal(t, "fields", slog.M(
+		slog.F("with", slogval.String("ff")),
+		slog.F("nested", slog.M(slog.F("n", slogval.String("10")))),
+		// Registered encoders are applied by the sinks.
+		slog.F("c", celsius(1)),
+	), s.entries[0].Fields)
+	assert.Equal(t, "shared fields", big.NewInt(16), fields[0].Value.(slog.Map)[0].Value)
+}
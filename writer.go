@@ -0,0 +1,84 @@
+package slog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// maxWriterLine is the maximum size of a line logged by the writer
+// returned by Writer. Longer lines are logged in parts.
+const maxWriterLine = 64 << 10
+
+// Writer returns a writer that logs every line written to it as an
+// entry at level with the context, e.g. for the Stdout of an exec.Cmd
+// or the ErrorLog of an http.Server with log.New.
+//
+// Partial lines are buffered until the rest is written or the writer is
+// closed. Lines longer than 64 KiB are logged in parts so that output
+// without newlines cannot grow the buffer without bound. Empty lines and
+// trailing carriage returns are dropped.
+//
+// The location of the entries is the caller of Write. It is safe for
+// concurrent use.
+func Writer(ctx context.Context, l Logger, level Level) io.WriteCloser {
+	return &lineWriter{
+		ctx:   ctx,
+		l:     l,
+		level: level,
+	}
+}
+
+type lineWriter struct {
+	ctx   context.Context
+	l     Logger
+	level Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			rest := maxWriterLine - len(w.buf)
+			if len(p) < rest {
+				w.buf = append(w.buf, p...)
+				break
+			}
+			// The line is too long, log what fits.
+			i = rest
+			w.buf = append(w.buf, p[:i]...)
+			p = p[i:]
+		} else {
+			w.buf = append(w.buf, p[:i]...)
+			p = p[i+1:]
+		}
+
+		msg := string(bytes.TrimSuffix(w.buf, []byte("\r")))
+		w.buf = w.buf[:0]
+		if msg != "" {
+			w.l.log(w.ctx, w.level, msg, nil)
+		}
+	}
+	return n, nil
+}
+
+// Close logs the buffered partial line, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	msg := string(bytes.TrimSuffix(w.buf, []byte("\r")))
+	w.buf = nil
+	if msg != "" {
+		w.l.log(w.ctx, w.level, msg, nil)
+	}
+	return nil
+}
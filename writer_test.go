@@ -0,0 +1,44 @@
+package slog_test
+
+import (
+	"strings"
+	"testing"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/internal/assert"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	s := &fakeSink{}
+	w := slog.Writer(bg, slog.Make(s), slog.LevelWarn)
+	_, err := w.Write([]byte("first\r\nsec"))
+	assert.Success(t, "write", err)
+	_, err = w.Write([]byte("ond\n\nthi"))
+	assert.Success(t, "write", err)
+	_, err = w.Write([]byte("rd"))
+	assert.Success(t, "write", err)
+
+	msgs := func() []string {
+		var msgs []string
+		for _, ent := range s.entries {
+			msgs = append(msgs, ent.Message)
+		}
+		return msgs
+	}
+	assert.Equal(t, "messages", []string{"first", "second"}, msgs())
+	assert.Equal(t, "level", slog.LevelWarn, s.entries[0].Level)
+	assert.Equal(t, "file", slogTestFile[:len(slogTestFile)-len("slog_test.go")]+"writer_test.go", s.entries[0].File)
+
+	assert.Success(t, "close", w.Close())
+	assert.Equal(t, "messages", []string{"first", "second", "third"}, msgs())
+
+	s.entries = nil
+	_, err = w.Write([]byte(strings.Repeat("a", 100<<10)))
+	assert.Success(t, "write long", err)
+	assert.Success(t, "close", w.Close())
+	assert.Len(t, "entries", 2, s.entries)
+	assert.Equal(t, "first part", 64<<10, len(s.entries[0].Message))
+	assert.Equal(t, "second part", 36<<10, len(s.entries[1].Message))
+}